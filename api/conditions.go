@@ -13,6 +13,60 @@ const (
 
 	// OvnKubeReady indicates that the ovnkube-node DaemonSet is ready
 	OvnKubeReady string = "OvnKubeReady"
+	// WaitingForPreflight indicates that a MachineConfig update is held
+	// until the pre-flight checks pass
+	WaitingForPreflight string = "WaitingForPreflight"
+	// PendingChanges indicates that disruptive changes are queued until the
+	// next maintenance window
+	PendingChanges string = "PendingChanges"
+	// PendingRollout indicates that an ovnkube-node rollout is queued until
+	// the next maintenance window
+	PendingRollout string = "PendingRollout"
+	// VersionSkew indicates that the ovnkube-node image is not compatible
+	// with the OVN control plane of the tenant cluster
+	VersionSkew string = "VersionSkew"
+	// TenantClusterReachable indicates that the tenant API server is
+	// reachable. The rendered objects are left untouched while it is not
+	TenantClusterReachable string = "TenantClusterReachable"
+	// DaemonSetHooks indicates that the rollout hooks of the ovnkube-node
+	// DaemonSet succeeded
+	DaemonSetHooks string = "DaemonSetHooks"
+	// MachineConfigHooks indicates that the rollout hooks of the
+	// MachineConfig succeeded
+	MachineConfigHooks string = "MachineConfigHooks"
+	// FleetSynced indicates that an OVNKubeConfig is stamped out for every
+	// tenant selected by a DpuFleetPolicy
+	FleetSynced string = "FleetSynced"
+	// PoolReady indicates that the labels and taints of a DpuNodePool are
+	// applied to its nodes, and its MachineConfigPool is synced
+	PoolReady string = "PoolReady"
+	// LogForwarding indicates that the logs of the DPU data plane are
+	// forwarded to the logForwarding endpoint
+	LogForwarding string = "LogForwarding"
+	// UnsupportedTenantNetwork indicates that the tenant cluster runs a
+	// network plugin other than OVN-Kubernetes, so nothing is rendered
+	UnsupportedTenantNetwork string = "UnsupportedTenantNetwork"
+	// SwitchdevReady indicates that the NICs of every DPU node are in
+	// switchdev mode, as reported by devlink on the nodes
+	SwitchdevReady string = "SwitchdevReady"
+	// RenderFailed indicates that templates of the CR keep failing to
+	// render, and are retried with a backoff while their inputs don't change
+	RenderFailed string = "RenderFailed"
+	// Delegated indicates that the workloads of a CR with another
+	// spec.targetNamespace are rendered from its managed copy there
+	Delegated string = "Delegated"
+	// CertInvalidForNodes indicates that the OVN certificate doesn't hold
+	// the identity of some DPU nodes, set by spec.ovn.certIdentity
+	CertInvalidForNodes string = "CertInvalidForNodes"
+	// ImageArchMismatch indicates that an image of the data plane is not
+	// built for the architecture of the DPUs, so it is not rolled out
+	ImageArchMismatch string = "ImageArchMismatch"
+	// ZeroNodesSelected indicates that the node selector of the DPU nodes
+	// matches no node, so the data plane DaemonSets schedule no pod
+	ZeroNodesSelected string = "ZeroNodesSelected"
+	// Ready aggregates McpReady, TenantObjsSynced and OvnKubeReady, so
+	// automation can wait on a single condition
+	Ready string = "Ready"
 
 	// ReasonCreated is used when desired objects are created
 	ReasonCreated = "Created"
@@ -28,25 +82,122 @@ const (
 
 	// ReasonCreated is used when desired objects failed to start
 	ReasonFailedStart = "FailedStart"
+	// ReasonPreflightFailed is used when the pre-flight checks did not pass
+	ReasonPreflightFailed = "PreflightFailed"
+	// ReasonOutsideMaintenanceWindow is used when changes wait for the maintenance window
+	ReasonOutsideMaintenanceWindow = "OutsideMaintenanceWindow"
+	// ReasonUpdatePending is used when a DaemonSet doesn't run its current template on every node yet
+	ReasonUpdatePending = "UpdatePending"
+	// ReasonPrepullingImages is used when a rollout waits for its images to be pulled
+	ReasonPrepullingImages = "PrepullingImages"
+	// ReasonWaitingForShard is used when a shard rollout waits for the previous shards
+	ReasonWaitingForShard = "WaitingForShard"
+	// ReasonInvalidCertificate is used when the OVN certificates don't chain to the OVN CA
+	ReasonInvalidCertificate = "InvalidCertificate"
+	// ReasonMissingIdentities is used when the OVN certificate doesn't hold the identity of some DPU nodes
+	ReasonMissingIdentities = "MissingIdentities"
+	// ReasonIdentitiesCovered is used when the OVN certificate holds the identity of every DPU node
+	ReasonIdentitiesCovered = "IdentitiesCovered"
+	// ReasonNoMatchingNodes is used when the node selector of the DPU nodes matches no node
+	ReasonNoMatchingNodes = "NoMatchingNodes"
+	// ReasonNodesSelected is used when the node selector of the DPU nodes matches nodes
+	ReasonNodesSelected = "NodesSelected"
+	// ReasonCompatible is used when the component versions are compatible
+	ReasonCompatible = "Compatible"
+	// ReasonIncompatible is used when the component versions are not compatible
+	ReasonIncompatible = "Incompatible"
+	// ReasonVersionUnknown is used when a component version cannot be determined
+	ReasonVersionUnknown = "VersionUnknown"
+	// ReasonHookRunning is used when a change waits for a rollout hook
+	ReasonHookRunning = "HookRunning"
+	// ReasonHookFailed is used when a rollout hook failed
+	ReasonHookFailed = "HookFailed"
+	// ReasonHookSucceeded is used when a rollout hook succeeded
+	ReasonHookSucceeded = "HookSucceeded"
+	// ReasonReachable is used when the tenant API server is reachable
+	ReasonReachable = "Reachable"
+	// ReasonTenantUnreachable is used when the tenant API server cannot be reached
+	ReasonTenantUnreachable = "TenantUnreachable"
+	// ReasonInvalidKubeconfig is used when the tenant kubeconfig cannot be loaded
+	ReasonInvalidKubeconfig = "InvalidKubeconfig"
+	// ReasonRenderFailed is used when manifests or MachineConfigs fail to render
+	ReasonRenderFailed = "RenderFailed"
+	// ReasonInvalidObject is used when a rendered object fails its validation before the apply
+	ReasonInvalidObject = "InvalidObject"
+	// ReasonApplyConflict is used when an object was modified concurrently
+	ReasonApplyConflict = "ApplyConflict"
+	// ReasonMcDegraded is used when the MachineConfigPool is degraded
+	ReasonMcDegraded = "McDegraded"
+	// ReasonFeatureMismatch is used when an OVN feature is not enabled in the tenant cluster
+	ReasonFeatureMismatch = "FeatureMismatch"
+	// ReasonInvalidPatch is used when a TenantObjectPatch is not a valid JSON Patch
+	ReasonInvalidPatch = "InvalidPatch"
+	// ReasonUnsupportedFlavor is used when the spec is not supported by the infra flavor
+	ReasonUnsupportedFlavor = "UnsupportedFlavor"
+	// ReasonConflict is used when an object is already managed by someone else
+	ReasonConflict = "Conflict"
+	// ReasonInvalidImage is used when the ovnkube image is missing or not a valid reference
+	ReasonInvalidImage = "InvalidImage"
+	// ReasonImageArchMismatch is used when an image is not built for the architecture of the DPUs
+	ReasonImageArchMismatch = "ImageArchMismatch"
+	// ReasonAPINotInstalled is used when the CRD of an optional integration is not installed
+	ReasonAPINotInstalled = "APINotInstalled"
+	// ReasonSecretReadForbidden is used when the operator may not read a Secret of another namespace
+	ReasonSecretReadForbidden = "SecretReadForbidden"
+	// ReasonSecretNotShared is used when a Secret of another namespace is not shared with the namespace of the CR
+	ReasonSecretNotShared = "SecretNotShared"
+	// ReasonUnsupportedNetworkType is used when the tenant cluster doesn't run OVN-Kubernetes
+	ReasonUnsupportedNetworkType = "UnsupportedNetworkType"
+	// ReasonSwitchdev is used when the NICs of every DPU node are in switchdev mode
+	ReasonSwitchdev = "Switchdev"
+	// ReasonLegacyMode is used when a NIC of a DPU node is not in switchdev mode
+	ReasonLegacyMode = "LegacyMode"
+	// ReasonInvalidUplinks is used when spec.uplinks is not consistent
+	ReasonInvalidUplinks = "InvalidUplinks"
+	// ReasonUplinkNotFound is used when the PF of an uplink is not found on a DPU node
+	ReasonUplinkNotFound = "UplinkNotFound"
+	// ReasonInsufficientPermissions is used when the tenant identity lacks a permission
+	ReasonInsufficientPermissions = "InsufficientPermissions"
+	// ReasonWorkloadsNotManaged is used when spec.manageWorkloads is false
+	ReasonWorkloadsNotManaged = "WorkloadsNotManaged"
+	// ReasonInvalidTargetNamespace is used when spec.targetNamespace is
+	// neither the namespace of the CR nor the one of the operator
+	ReasonInvalidTargetNamespace = "InvalidTargetNamespace"
+	// ReasonComponentsReady is used when every condition aggregated by Ready
+	// is True
+	ReasonComponentsReady = "ComponentsReady"
+	// ReasonPodsFailing is used when ovnkube-node pods cannot pull their
+	// image or crash in a loop, which a rollout doesn't fix by itself
+	ReasonPodsFailing = "PodsFailing"
+	// ReasonPriorityClassNotAdmitted is used when no ResourceQuota of the
+	// namespace admits the pods of a critical priority class
+	ReasonPriorityClassNotAdmitted = "PriorityClassNotAdmitted"
 )
 
 type conditionsBuilder struct {
-	cndType string
-	status  v1.ConditionStatus
-	reason  string
-	message string
+	cndType            string
+	status             v1.ConditionStatus
+	reason             string
+	message            string
+	observedGeneration int64
 }
 
-func Conditions() *conditionsBuilder {
-	return &conditionsBuilder{}
+// Conditions returns a builder of a condition of obj, stamped with the
+// generation of obj the reconcile acted on, so that kubectl wait and the
+// GitOps health checks can tell a condition of the current spec from a
+// stale one. The LastTransitionTime is left to meta.SetStatusCondition,
+// which only moves it when the status changes.
+func Conditions(obj v1.Object) *conditionsBuilder {
+	return &conditionsBuilder{observedGeneration: obj.GetGeneration()}
 }
 
 func (builder *conditionsBuilder) Build() *v1.Condition {
 	return &v1.Condition{
-		Type:    builder.cndType,
-		Status:  builder.status,
-		Reason:  builder.reason,
-		Message: builder.message,
+		Type:               builder.cndType,
+		Status:             builder.status,
+		Reason:             builder.reason,
+		Message:            builder.message,
+		ObservedGeneration: builder.observedGeneration,
 	}
 }
 
@@ -86,6 +237,205 @@ func (builder *conditionsBuilder) McpReady() *conditionsBuilder {
 	return builder
 }
 
+func (builder *conditionsBuilder) WaitingForPreflight() *conditionsBuilder {
+	builder.status = v1.ConditionTrue
+	builder.cndType = WaitingForPreflight
+	return builder
+}
+
+func (builder *conditionsBuilder) PendingChanges() *conditionsBuilder {
+	builder.status = v1.ConditionTrue
+	builder.cndType = PendingChanges
+	return builder
+}
+
+func (builder *conditionsBuilder) PendingRollout() *conditionsBuilder {
+	builder.status = v1.ConditionTrue
+	builder.cndType = PendingRollout
+	return builder
+}
+
+func (builder *conditionsBuilder) VersionSkew() *conditionsBuilder {
+	builder.status = v1.ConditionTrue
+	builder.cndType = VersionSkew
+	return builder
+}
+
+func (builder *conditionsBuilder) NoVersionSkew() *conditionsBuilder {
+	builder.status = v1.ConditionFalse
+	builder.cndType = VersionSkew
+	return builder
+}
+
+func (builder *conditionsBuilder) UnknownVersionSkew() *conditionsBuilder {
+	builder.status = v1.ConditionUnknown
+	builder.cndType = VersionSkew
+	return builder
+}
+
+func (builder *conditionsBuilder) TenantClusterReachable() *conditionsBuilder {
+	builder.status = v1.ConditionTrue
+	builder.cndType = TenantClusterReachable
+	return builder
+}
+
+func (builder *conditionsBuilder) NotTenantClusterReachable() *conditionsBuilder {
+	builder.status = v1.ConditionFalse
+	builder.cndType = TenantClusterReachable
+	return builder
+}
+
+func (builder *conditionsBuilder) DaemonSetHooks() *conditionsBuilder {
+	builder.status = v1.ConditionTrue
+	builder.cndType = DaemonSetHooks
+	return builder
+}
+
+func (builder *conditionsBuilder) NotDaemonSetHooks() *conditionsBuilder {
+	builder.status = v1.ConditionFalse
+	builder.cndType = DaemonSetHooks
+	return builder
+}
+
+func (builder *conditionsBuilder) MachineConfigHooks() *conditionsBuilder {
+	builder.status = v1.ConditionTrue
+	builder.cndType = MachineConfigHooks
+	return builder
+}
+
+func (builder *conditionsBuilder) NotMachineConfigHooks() *conditionsBuilder {
+	builder.status = v1.ConditionFalse
+	builder.cndType = MachineConfigHooks
+	return builder
+}
+
+func (builder *conditionsBuilder) FleetSynced() *conditionsBuilder {
+	builder.status = v1.ConditionTrue
+	builder.cndType = FleetSynced
+	return builder
+}
+
+func (builder *conditionsBuilder) NotFleetSynced() *conditionsBuilder {
+	builder.status = v1.ConditionFalse
+	builder.cndType = FleetSynced
+	return builder
+}
+
+func (builder *conditionsBuilder) PoolReady() *conditionsBuilder {
+	builder.status = v1.ConditionTrue
+	builder.cndType = PoolReady
+	return builder
+}
+
+func (builder *conditionsBuilder) NotPoolReady() *conditionsBuilder {
+	builder.status = v1.ConditionFalse
+	builder.cndType = PoolReady
+	return builder
+}
+
+func (builder *conditionsBuilder) LogForwarding() *conditionsBuilder {
+	builder.status = v1.ConditionTrue
+	builder.cndType = LogForwarding
+	return builder
+}
+
+func (builder *conditionsBuilder) NotLogForwarding() *conditionsBuilder {
+	builder.status = v1.ConditionFalse
+	builder.cndType = LogForwarding
+	return builder
+}
+
+func (builder *conditionsBuilder) UnsupportedTenantNetwork() *conditionsBuilder {
+	builder.status = v1.ConditionTrue
+	builder.cndType = UnsupportedTenantNetwork
+	return builder
+}
+
+func (builder *conditionsBuilder) SwitchdevReady() *conditionsBuilder {
+	builder.status = v1.ConditionTrue
+	builder.cndType = SwitchdevReady
+	return builder
+}
+
+func (builder *conditionsBuilder) NotSwitchdevReady() *conditionsBuilder {
+	builder.status = v1.ConditionFalse
+	builder.cndType = SwitchdevReady
+	return builder
+}
+
+func (builder *conditionsBuilder) RenderFailed() *conditionsBuilder {
+	builder.status = v1.ConditionTrue
+	builder.cndType = RenderFailed
+	return builder
+}
+
+func (builder *conditionsBuilder) Delegated() *conditionsBuilder {
+	builder.status = v1.ConditionTrue
+	builder.cndType = Delegated
+	return builder
+}
+
+func (builder *conditionsBuilder) NotDelegated() *conditionsBuilder {
+	builder.status = v1.ConditionFalse
+	builder.cndType = Delegated
+	return builder
+}
+
+func (builder *conditionsBuilder) CertInvalidForNodes() *conditionsBuilder {
+	builder.status = v1.ConditionTrue
+	builder.cndType = CertInvalidForNodes
+	return builder
+}
+
+func (builder *conditionsBuilder) CertValidForNodes() *conditionsBuilder {
+	builder.status = v1.ConditionFalse
+	builder.cndType = CertInvalidForNodes
+	return builder
+}
+
+func (builder *conditionsBuilder) ZeroNodesSelected() *conditionsBuilder {
+	builder.status = v1.ConditionTrue
+	builder.cndType = ZeroNodesSelected
+	return builder
+}
+
+func (builder *conditionsBuilder) NodesSelected() *conditionsBuilder {
+	builder.status = v1.ConditionFalse
+	builder.cndType = ZeroNodesSelected
+	return builder
+}
+
+func (builder *conditionsBuilder) ImageArchMismatch() *conditionsBuilder {
+	builder.status = v1.ConditionTrue
+	builder.cndType = ImageArchMismatch
+	return builder
+}
+
+func (builder *conditionsBuilder) Ready() *conditionsBuilder {
+	builder.status = v1.ConditionTrue
+	builder.cndType = Ready
+	return builder
+}
+
+func (builder *conditionsBuilder) NotReady() *conditionsBuilder {
+	builder.status = v1.ConditionFalse
+	builder.cndType = Ready
+	return builder
+}
+
+func (builder *conditionsBuilder) UnknownReady() *conditionsBuilder {
+	builder.status = v1.ConditionUnknown
+	builder.cndType = Ready
+	return builder
+}
+
+// ObservedGeneration overrides the generation the condition is stamped
+// with, e.g. for a condition aggregating conditions of older generations.
+func (builder *conditionsBuilder) ObservedGeneration(generation int64) *conditionsBuilder {
+	builder.observedGeneration = generation
+	return builder
+}
+
 func (builder *conditionsBuilder) Reason(r string) *conditionsBuilder {
 	builder.reason = r
 	return builder
@@ -0,0 +1,92 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DpuFleetPolicySpec defines the desired state of DpuFleetPolicy
+type DpuFleetPolicySpec struct {
+	// TenantSelector selects the tenant kubeconfig Secrets, in any
+	// namespace. An OVNKubeConfig is stamped out in the namespace of each
+	// selected Secret.
+	TenantSelector metav1.LabelSelector `json:"tenantSelector"`
+
+	// Template is the spec of the stamped out OVNKubeConfigs. Its
+	// kubeConfigFile is set to the name of the selected Secret, and its
+	// poolName, unless it is the worker pool, is suffixed with the namespace
+	// of the Secret.
+	Template OVNKubeConfigSpec `json:"template"`
+}
+
+// DpuFleetPolicyStatus defines the observed state of DpuFleetPolicy
+type DpuFleetPolicyStatus struct {
+	// Conditions represent the latest available observations of an object's state
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Tenants lists the tenant clusters selected by the policy.
+	// +optional
+	Tenants []FleetTenantStatus `json:"tenants,omitempty"`
+}
+
+// FleetTenantStatus reports the OVNKubeConfig stamped out for a tenant
+// kubeconfig Secret.
+type FleetTenantStatus struct {
+	// Namespace is the namespace of the tenant kubeconfig Secret.
+	Namespace string `json:"namespace"`
+
+	// Secret is the name of the tenant kubeconfig Secret.
+	Secret string `json:"secret"`
+
+	// Config is the name of the OVNKubeConfig stamped out for the tenant,
+	// empty if it could not be created.
+	// +optional
+	Config string `json:"config,omitempty"`
+
+	// Message explains why no OVNKubeConfig is managed for the tenant.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+
+// DpuFleetPolicy is the Schema for the dpufleetpolicies API. It manages one
+// OVNKubeConfig per selected tenant cluster.
+type DpuFleetPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DpuFleetPolicySpec   `json:"spec,omitempty"`
+	Status DpuFleetPolicyStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// DpuFleetPolicyList contains a list of DpuFleetPolicy
+type DpuFleetPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DpuFleetPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DpuFleetPolicy{}, &DpuFleetPolicyList{})
+}
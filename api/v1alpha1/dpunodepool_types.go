@@ -0,0 +1,150 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DpuNodePoolSpec defines the desired state of DpuNodePool
+type DpuNodePoolSpec struct {
+	// NodeSelector selects the DPU nodes of the pool, e.g. on a Node
+	// Feature Discovery label.
+	NodeSelector metav1.LabelSelector `json:"nodeSelector"`
+
+	// Labels are applied to the nodes of the pool. The MachineConfigPool
+	// selects the nodes on them, or on NodeSelector when empty.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Taints are applied to the nodes of the pool, e.g. to keep the general
+	// workloads off the DPUs.
+	// +optional
+	Taints []corev1.Taint `json:"taints,omitempty"`
+
+	// DpuType is the model of the DPUs of the pool, e.g. bluefield2. It is
+	// applied to the nodes as the dpu.openshift.io/dpu-type label.
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +optional
+	DpuType string `json:"dpuType,omitempty"`
+}
+
+// DpuNodePoolStatus defines the observed state of DpuNodePool
+type DpuNodePoolStatus struct {
+	// Conditions represent the latest available observations of an object's state
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Nodes lists the nodes of the pool.
+	// +optional
+	Nodes []string `json:"nodes,omitempty"`
+
+	// Inventory reports the resources, OS and NIC firmware of the nodes of
+	// the pool.
+	// +optional
+	Inventory *DpuPoolInventory `json:"inventory,omitempty"`
+}
+
+// DpuPoolInventory aggregates the inventory of the nodes of a pool.
+type DpuPoolInventory struct {
+	// Allocatable sums the cpu, memory and hugepages allocatable on the
+	// nodes of the pool.
+	// +optional
+	Allocatable corev1.ResourceList `json:"allocatable,omitempty"`
+
+	// KernelVersions counts the nodes per kernel version.
+	// +optional
+	KernelVersions []VersionCount `json:"kernelVersions,omitempty"`
+
+	// OSImages counts the nodes per OS image.
+	// +optional
+	OSImages []VersionCount `json:"osImages,omitempty"`
+
+	// NicFirmwareVersions counts the NICs per firmware version, as
+	// published by the VF representor discovery DaemonSet.
+	// +optional
+	NicFirmwareVersions []VersionCount `json:"nicFirmwareVersions,omitempty"`
+
+	// Nodes details the inventory of each node of the pool.
+	// +optional
+	Nodes []DpuNodeInventory `json:"nodes,omitempty"`
+}
+
+// VersionCount is the number of items running a version.
+type VersionCount struct {
+	// Version is the version, e.g. a kernel release.
+	Version string `json:"version"`
+
+	// Count is the number of items running the version.
+	Count int32 `json:"count"`
+}
+
+// DpuNodeInventory defines the inventory of a DPU node.
+type DpuNodeInventory struct {
+	// Name is the name of the node.
+	Name string `json:"name"`
+
+	// Architecture is the CPU architecture of the node, e.g. arm64.
+	// +optional
+	Architecture string `json:"architecture,omitempty"`
+
+	// KernelVersion is the kernel release of the node.
+	// +optional
+	KernelVersion string `json:"kernelVersion,omitempty"`
+
+	// OSImage is the OS of the node, e.g. Red Hat Enterprise Linux CoreOS.
+	// +optional
+	OSImage string `json:"osImage,omitempty"`
+
+	// Allocatable is the cpu, memory and hugepages allocatable on the node.
+	// +optional
+	Allocatable corev1.ResourceList `json:"allocatable,omitempty"`
+
+	// NicFirmware maps the devlink devices of the node, e.g.
+	// pci/0000:03:00.0, to their running firmware version.
+	// +optional
+	NicFirmware map[string]string `json:"nicFirmware,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+
+// DpuNodePool is the Schema for the dpunodepools API. It manages the labels
+// and taints of a pool of DPU nodes, and its MachineConfigPool.
+type DpuNodePool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DpuNodePoolSpec   `json:"spec,omitempty"`
+	Status DpuNodePoolStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// DpuNodePoolList contains a list of DpuNodePool
+type DpuNodePoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DpuNodePool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DpuNodePool{}, &DpuNodePoolList{})
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	NotificationFormatJSON        = "JSON"
+	NotificationFormatCloudEvents = "CloudEvents"
+)
+
+// DpuOperatorConfigSpec defines the desired state of DpuOperatorConfig
+type DpuOperatorConfigSpec struct {
+	// Notifications lists the sinks notified of the condition transitions
+	// of the OVNKubeConfigs, e.g. OvnKubeReady turning False.
+	// +optional
+	Notifications []NotificationSink `json:"notifications,omitempty"`
+}
+
+// NotificationSink is an HTTP endpoint receiving a POST request per
+// condition transition.
+type NotificationSink struct {
+	// Name identifies the sink in the operator logs and metrics.
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// URL is the http or https endpoint of the sink.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// Format is JSON for a plain JSON body, or CloudEvents for a CloudEvent
+	// in the structured content mode.
+	// +kubebuilder:validation:Enum=JSON;CloudEvents
+	// +kubebuilder:default=JSON
+	// +optional
+	Format string `json:"format,omitempty"`
+
+	// Secret references a Secret holding a bearer token for the sink in its
+	// token key, and the CA bundle of an https sink in its ca.crt key.
+	// +optional
+	Secret *corev1.SecretReference `json:"secret,omitempty"`
+
+	// Conditions restricts the notified transitions to these condition
+	// types. Every condition is notified when empty.
+	// +optional
+	Conditions []string `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+
+// DpuOperatorConfig is the Schema for the dpuoperatorconfigs API. It holds
+// the settings of the operator itself, only the one named cluster is used.
+type DpuOperatorConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec DpuOperatorConfigSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// DpuOperatorConfigList contains a list of DpuOperatorConfig
+type DpuOperatorConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DpuOperatorConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DpuOperatorConfig{}, &DpuOperatorConfigList{})
+}
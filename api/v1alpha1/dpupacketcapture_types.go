@@ -0,0 +1,139 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DpuPacketCaptureSpec defines the desired state of DpuPacketCapture
+type DpuPacketCaptureSpec struct {
+	// NodeName is the DPU node to capture on. It must be a DPU node of the
+	// OVNKubeConfig of the namespace.
+	NodeName string `json:"nodeName"`
+
+	// Interface is the representor or the uplink to capture, e.g. pf0vf3
+	// or p0.
+	// +kubebuilder:validation:MaxLength=15
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_.-]+$`
+	Interface string `json:"interface"`
+
+	// Filter is a pcap filter expression, e.g. "tcp port 80".
+	// +optional
+	Filter string `json:"filter,omitempty"`
+
+	// DurationSeconds is how long the packets are captured.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=3600
+	// +kubebuilder:default=30
+	// +optional
+	DurationSeconds int32 `json:"durationSeconds,omitempty"`
+
+	// MaxSizeMiB caps the size of the capture, which is cut once reached.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=2048
+	// +kubebuilder:default=100
+	// +optional
+	MaxSizeMiB int32 `json:"maxSizeMiB,omitempty"`
+
+	// PersistentVolumeClaim stores the capture in the claim, as
+	// <name>.pcap. Without it, the capture is kept in the capture pod for
+	// RetentionSeconds, to be copied with oc cp.
+	// +optional
+	PersistentVolumeClaim *corev1.LocalObjectReference `json:"persistentVolumeClaim,omitempty"`
+
+	// RetentionSeconds is how long the capture pod keeps the capture,
+	// without PersistentVolumeClaim.
+	// +kubebuilder:validation:Minimum=60
+	// +kubebuilder:validation:Maximum=86400
+	// +kubebuilder:default=3600
+	// +optional
+	RetentionSeconds int32 `json:"retentionSeconds,omitempty"`
+}
+
+// DpuPacketCapturePhase is the progress of a DpuPacketCapture.
+type DpuPacketCapturePhase string
+
+const (
+	DpuPacketCapturePending   DpuPacketCapturePhase = "Pending"
+	DpuPacketCaptureCapturing DpuPacketCapturePhase = "Capturing"
+	DpuPacketCaptureSucceeded DpuPacketCapturePhase = "Succeeded"
+	DpuPacketCaptureFailed    DpuPacketCapturePhase = "Failed"
+)
+
+// DpuPacketCaptureStatus defines the observed state of DpuPacketCapture
+type DpuPacketCaptureStatus struct {
+	// Phase is Succeeded once the capture is stored.
+	// +optional
+	Phase DpuPacketCapturePhase `json:"phase,omitempty"`
+
+	// Message explains a Pending or Failed phase, or an expired capture.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Pod is the capture pod.
+	// +optional
+	Pod string `json:"pod,omitempty"`
+
+	// StartTime is when the capture started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is when the capture was stored.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// SizeBytes is the size of the pcap file.
+	// +optional
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+
+	// Artifact tells where to get the pcap file: its path in the
+	// PersistentVolumeClaim, or the oc cp command copying it from the
+	// capture pod until it expires.
+	// +optional
+	Artifact string `json:"artifact,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Node",type=string,JSONPath=`.spec.nodeName`
+//+kubebuilder:printcolumn:name="Interface",type=string,JSONPath=`.spec.interface`
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+
+// DpuPacketCapture is the Schema for the dpupacketcaptures API. It captures
+// the packets of a representor or an uplink of a DPU node, once.
+type DpuPacketCapture struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DpuPacketCaptureSpec   `json:"spec,omitempty"`
+	Status DpuPacketCaptureStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// DpuPacketCaptureList contains a list of DpuPacketCapture
+type DpuPacketCaptureList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DpuPacketCapture `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DpuPacketCapture{}, &DpuPacketCaptureList{})
+}
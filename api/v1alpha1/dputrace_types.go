@@ -0,0 +1,127 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TraceEndpoint is an end of the traced packet, a pod of the tenant cluster
+// or an IP.
+type TraceEndpoint struct {
+	// Pod is the name of a pod of the tenant cluster.
+	// +optional
+	Pod string `json:"pod,omitempty"`
+
+	// Namespace is the namespace of Pod in the tenant cluster.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// IP is the address of the endpoint, or the one of Pod when empty.
+	// +optional
+	IP string `json:"ip,omitempty"`
+}
+
+// DpuTraceSpec defines the desired state of DpuTrace
+type DpuTraceSpec struct {
+	// Source is the pod sending the traced packet. It must run on a host
+	// whose DPU is managed by the OVNKubeConfig of the namespace.
+	Source TraceEndpoint `json:"source"`
+
+	// Destination is the pod or the IP the packet is sent to.
+	Destination TraceEndpoint `json:"destination"`
+
+	// Protocol is the protocol of the packet.
+	// +kubebuilder:validation:Enum=TCP;UDP;ICMP
+	// +kubebuilder:default=ICMP
+	// +optional
+	Protocol string `json:"protocol,omitempty"`
+
+	// Port is the destination port of TCP and UDP packets.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+}
+
+// DpuTracePhase is the progress of a DpuTrace.
+type DpuTracePhase string
+
+const (
+	DpuTracePending   DpuTracePhase = "Pending"
+	DpuTraceRunning   DpuTracePhase = "Running"
+	DpuTraceSucceeded DpuTracePhase = "Succeeded"
+	DpuTraceFailed    DpuTracePhase = "Failed"
+)
+
+// DpuTraceStatus defines the observed state of DpuTrace
+type DpuTraceStatus struct {
+	// Phase is Succeeded once the traces are recorded.
+	// +optional
+	Phase DpuTracePhase `json:"phase,omitempty"`
+
+	// Message explains a Pending or Failed phase.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Node is the DPU node the traces run on.
+	// +optional
+	Node string `json:"node,omitempty"`
+
+	// OvnTrace is the output of ovn-trace for the packet, through the
+	// logical network.
+	// +optional
+	OvnTrace string `json:"ovnTrace,omitempty"`
+
+	// OfprotoTrace is the output of ovs-appctl ofproto/trace for the
+	// packet, through the OpenFlow tables of the DPU.
+	// +optional
+	OfprotoTrace string `json:"ofprotoTrace,omitempty"`
+
+	// CompletionTime is when the traces were recorded.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Node",type=string,JSONPath=`.status.node`
+
+// DpuTrace is the Schema for the dputraces API. It traces a packet from a
+// pod of the tenant cluster through OVN and the OpenFlow tables of its DPU,
+// once.
+type DpuTrace struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DpuTraceSpec   `json:"spec,omitempty"`
+	Status DpuTraceStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// DpuTraceList contains a list of DpuTrace
+type DpuTraceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DpuTrace `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DpuTrace{}, &DpuTraceList{})
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -30,13 +31,702 @@ type OVNKubeConfigSpec struct {
 
 	// KubeConfigFile is the secret name of the tenant cluster kubeconfig file
 	KubeConfigFile string `json:"kubeConfigFile,omitempty"`
+	// KubeConfigSecretRef references the Secret of the tenant cluster
+	// kubeconfig, e.g. one managed by an ExternalSecret or a
+	// VaultStaticSecret, and takes precedence over kubeConfigFile.
+	// +optional
+	KubeConfigSecretRef *KubeConfigSecretReference `json:"kubeConfigSecretRef,omitempty"`
+	// TargetNamespace is the namespace the workloads are rendered into,
+	// the namespace of the CR when empty. It may only be the namespace of
+	// the CR or the one of the operator: the latter lets a CR live in a
+	// user namespace while the operator renders its workloads, from a
+	// managed copy of the CR and of its kubeconfig Secret, in its own
+	// namespace.
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +optional
+	TargetNamespace string `json:"targetNamespace,omitempty"`
 	// PoolName is the name of the MachineConfigPool CR which contains
-	// the BF2 nodes in the infra cluster.
-	PoolName string `json:"poolName"`
+	// the BF2 nodes in the infra cluster. Either poolName or poolRef must
+	// be set.
+	// +optional
+	PoolName string `json:"poolName,omitempty"`
+	// AllowWorkerPool lets poolName be the worker MachineConfigPool, for the
+	// single pool clusters where every worker has a DPU. The operator then
+	// leaves the pool as it is and applies the switchdev MachineConfig to
+	// every worker. The master pool is never allowed.
+	// +optional
+	AllowWorkerPool bool `json:"allowWorkerPool,omitempty"`
 	// nodeSelector specifies a label selector for Machines
 	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+
+	// PoolRef references the DpuNodePool of the DPU nodes. The pool, its
+	// node labels and its MachineConfigPool are then managed by the
+	// DpuNodePool, and poolName and nodeSelector are ignored.
+	// +optional
+	PoolRef *PoolReference `json:"poolRef,omitempty"`
+
+	// Tenant holds the settings of the tenant cluster.
+	// +optional
+	Tenant *TenantSpec `json:"tenant,omitempty"`
+
+	// InfraFlavor is the distribution of the cluster the DPUs belong to.
+	// On microshift, the switchdev configuration is written to the DPU
+	// hosts by a DaemonSet instead of a MachineConfig.
+	// +kubebuilder:validation:Enum=openshift;microshift
+	// +kubebuilder:default=openshift
+	// +optional
+	InfraFlavor string `json:"infraFlavor,omitempty"`
+
+	// MachineConfig tunes the MachineConfig rendered for the pool.
+	// +optional
+	MachineConfig *MachineConfigSpec `json:"machineConfig,omitempty"`
+
+	// ManageMachineConfig set to false leaves the MachineConfigPool and the
+	// switchdev configuration of the DPU nodes to the user, e.g. when it is
+	// baked into the OS image of the DPUs. Only the ovnkube workload is
+	// managed then, and its containers run privileged.
+	// +kubebuilder:default=true
+	// +optional
+	ManageMachineConfig *bool `json:"manageMachineConfig,omitempty"`
+
+	// ManageWorkloads set to false makes the operator only prepare the DPU
+	// nodes, e.g. while staging a migration: the ovnkube workload is not
+	// deployed, and the one already deployed is left untouched.
+	// +kubebuilder:default=true
+	// +optional
+	ManageWorkloads *bool `json:"manageWorkloads,omitempty"`
+
+	// MaintenanceWindow restricts the changes which restart the data plane,
+	// such as MachineConfig updates and ovnkube-node rollouts, to a recurring
+	// time window. Changes are applied right away if not set.
+	// +optional
+	MaintenanceWindow *MaintenanceWindow `json:"maintenanceWindow,omitempty"`
+
+	// Ovn holds the OVN specific settings of the DPU data plane.
+	// +optional
+	Ovn OvnSpec `json:"ovn,omitempty"`
+
+	// OvnKubeNode holds the settings of the ovnkube-node container.
+	// +optional
+	OvnKubeNode *OvnKubeNodeSpec `json:"ovnKubeNode,omitempty"`
+
+	// DataPlane selects the data plane offloaded to the DPUs, OVN-Kubernetes
+	// by default.
+	// +optional
+	DataPlane *DataPlaneSpec `json:"dataPlane,omitempty"`
+
+	// UplinkBond bonds the uplinks of dual-port DPUs in active-backup mode,
+	// so a link failure fails over to the other uplink without intervention.
+	// +optional
+	UplinkBond *UplinkBond `json:"uplinkBond,omitempty"`
+
+	// Uplinks lists the PFs of multi-PF DPUs, e.g. BlueField-3 with two
+	// PFs, each put in switchdev mode. The Gateway uplink is attached to
+	// br-ex, the Secondary ones to a bridge of their own. The first PF of
+	// the NIC is the gateway uplink if not set.
+	// +optional
+	Uplinks []Uplink `json:"uplinks,omitempty"`
+
+	// ManageNodeLabels makes the operator apply the matchLabels of
+	// NodeSelector to the DPU nodes, so the pool membership follows the CR.
+	// +optional
+	ManageNodeLabels *NodeLabelManagement `json:"manageNodeLabels,omitempty"`
+
+	// NodeTaints are applied to the DPU nodes, so general workloads are kept
+	// off of them. The pods rendered by the operator tolerate them. They are
+	// removed from the nodes when the CR is deleted.
+	// +optional
+	NodeTaints []corev1.Taint `json:"nodeTaints,omitempty"`
+
+	// ReadinessTaint keeps the dpu.openshift.io/not-ready:NoSchedule taint on
+	// the DPU nodes until their ovnkube-node pod is ready and the tenant host
+	// they serve is Ready with its network available, so other workloads
+	// don't land on nodes with a broken data plane. The pods rendered by the
+	// operator tolerate it.
+	// +optional
+	ReadinessTaint bool `json:"readinessTaint,omitempty"`
+
+	// ExtraRenderData are added to the variables of the rendered manifests,
+	// so customized manifests can consume user-provided values. The
+	// variables set by the operator take precedence.
+	// +optional
+	ExtraRenderData map[string]string `json:"extraRenderData,omitempty"`
+
+	// PriorityClassName is the priority class of the pods rendered on the
+	// DPU nodes. It must be a critical class, so memory pressure never
+	// evicts the data plane.
+	// +kubebuilder:default=system-node-critical
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// Privileged runs the ovnkube-node containers privileged, instead of
+	// confined by the SELinux policy and the seccomp profile installed by the
+	// MachineConfig. Meant for debugging.
+	// +optional
+	Privileged bool `json:"privileged,omitempty"`
+
+	// ImagePullSecrets are the Secrets, in the namespace of the CR, used to
+	// pull the images of the rendered pods. They are also added to the
+	// ServiceAccounts of the rendered pods.
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// Images sets the images of the rendered containers, for the builds
+	// shipping OVN and ovn-kubernetes in separate images.
+	// +optional
+	Images *ImagesSpec `json:"images,omitempty"`
+
+	// Hooks run before and after the ovnkube-node DaemonSet or the
+	// MachineConfig of the pool are changed, e.g. to quiesce the traffic or
+	// to verify the BGP sessions.
+	// +optional
+	Hooks *RolloutHooks `json:"hooks,omitempty"`
+
+	// Rollout tunes how ovnkube-node changes are rolled out to the DPU
+	// nodes.
+	// +optional
+	Rollout *RolloutSpec `json:"rollout,omitempty"`
+
+	// DriftDetection periodically re-renders the objects managed for the
+	// CR and reports their differences with the live objects in
+	// status.drift, without applying anything.
+	// +optional
+	DriftDetection *DriftDetection `json:"driftDetection,omitempty"`
+
+	// TenantObjectPatches rewrite the objects synced from the tenant
+	// cluster before they are written into the namespace of the CR, e.g. to
+	// adapt the ovnkube-config to the DPU.
+	// +optional
+	TenantObjectPatches []TenantObjectPatch `json:"tenantObjectPatches,omitempty"`
+
+	// LogForwarding ships the logs of ovn-controller, ovnkube-node and
+	// ovs-vswitchd on the DPU nodes to an external endpoint, through the
+	// OpenShift Logging operator.
+	// +optional
+	LogForwarding *LogForwarding `json:"logForwarding,omitempty"`
+
+	// Verification runs a connectivity test in the tenant cluster once
+	// every change of the ovnkube-node DaemonSet or of the switchdev
+	// MachineConfig is rolled out, and reports it in
+	// status.lastVerification.
+	// +optional
+	Verification *VerificationSpec `json:"verification,omitempty"`
+}
+
+// VerificationSpec defines the connectivity test run after the rollouts.
+type VerificationSpec struct {
+	// Enabled runs the test after every rollout.
+	Enabled bool `json:"enabled"`
+
+	// Namespace is the namespace of the tenant cluster the test pods run
+	// in. Defaults to default.
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Image is the image of the test pods, which must provide bash, curl,
+	// getent and python3. Defaults to the ovnkube image.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// EgressURL is fetched from a test pod to check the egress traffic,
+	// e.g. https://example.com. The egress check is skipped when not set.
+	// +optional
+	EgressURL string `json:"egressURL,omitempty"`
+
+	// TimeoutSeconds bounds the test, 300 by default.
+	// +kubebuilder:validation:Minimum=30
+	// +optional
+	TimeoutSeconds int64 `json:"timeoutSeconds,omitempty"`
+}
+
+// The distributions of the cluster the DPUs belong to.
+const (
+	InfraFlavorOpenShift  = "openshift"
+	InfraFlavorMicroShift = "microshift"
+)
+
+// RolloutHooks defines the hooks run around the changes which restart the
+// data plane.
+type RolloutHooks struct {
+	// PreRollout runs before a change is applied. The change is held until
+	// the hook succeeds.
+	// +optional
+	PreRollout *RolloutHook `json:"preRollout,omitempty"`
+
+	// PostRollout runs once a change is rolled out to every DPU node.
+	// +optional
+	PostRollout *RolloutHook `json:"postRollout,omitempty"`
+}
+
+// DriftDetection defines the periodic drift check.
+type DriftDetection struct {
+	// Interval is the time between two checks, e.g. 24h.
+	// +kubebuilder:default="24h"
+	// +optional
+	Interval metav1.Duration `json:"interval,omitempty"`
+}
+
+// RolloutSpec defines how ovnkube-node changes are rolled out.
+type RolloutSpec struct {
+	// Shards splits the ovnkube-node DaemonSet into as many DaemonSets,
+	// each running on the DPU nodes whose name hashes to it. A change is
+	// rolled out one shard at a time, and a shard is updated only once the
+	// previous ones are ready again, which bounds the blast radius of a bad
+	// change on large pools.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=64
+	// +optional
+	Shards int32 `json:"shards,omitempty"`
+}
+
+// RolloutHook is run as a Job in the namespace of the CR, once per change.
+// Exactly one of Script and JobTemplate must be set.
+type RolloutHook struct {
+	// Script selects the key of a ConfigMap holding a script, run by bash
+	// in the ovnkube image.
+	// +optional
+	Script *corev1.ConfigMapKeySelector `json:"script,omitempty"`
+
+	// JobTemplate references a suspended Job whose spec is run as the hook.
+	// +optional
+	JobTemplate *corev1.LocalObjectReference `json:"jobTemplate,omitempty"`
+
+	// TimeoutSeconds bounds the run time of the hook.
+	// +kubebuilder:default=600
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds int64 `json:"timeoutSeconds,omitempty"`
+}
+
+// TenantObjectPatch is a JSON Patch applied to an object synced from the
+// tenant cluster.
+type TenantObjectPatch struct {
+	// Kind is the kind of the synced object.
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	Kind string `json:"kind"`
+
+	// Name is the name of the synced object, e.g. ovnkube-config.
+	Name string `json:"name"`
+
+	// Patch is a JSON Patch (RFC 6902), e.g. [{"op": "replace", "path":
+	// "/data/ovnkube.conf", "value": "..."}]. The values of the data of a
+	// Secret are base64 encoded.
+	Patch string `json:"patch"`
+}
+
+// LogForwarding defines the endpoint receiving the logs of the DPU data
+// plane.
+type LogForwarding struct {
+	// Type is the protocol of the endpoint.
+	// +kubebuilder:validation:Enum=syslog;http;loki;kafka;elasticsearch;splunk
+	Type string `json:"type"`
+	// URL of the endpoint, e.g. tls://syslog.example.com:6514.
+	// +kubebuilder:validation:MinLength=1
+	URL string `json:"url"`
+	// Secret references a Secret in the namespace of the CR holding the TLS
+	// and authentication settings of the endpoint.
+	// +optional
+	Secret *corev1.LocalObjectReference `json:"secret,omitempty"`
+}
+
+// NodeLabelManagement defines which nodes are labeled into the pool.
+type NodeLabelManagement struct {
+	// DiscoverySelector selects the DPU nodes, e.g. on a label published by
+	// the Node Feature Discovery. Matching nodes get the matchLabels of
+	// NodeSelector, the labels are removed from the nodes which no longer
+	// match or when NodeSelector changes.
+	DiscoverySelector *metav1.LabelSelector `json:"discoverySelector"`
+}
+
+// UplinkBond defines the bond of the DPU uplinks attached to br-ex.
+type UplinkBond struct {
+	// Interfaces are the uplink netdevs enslaved into the bond, e.g. p0 and
+	// p1. The first one is the primary uplink, the traffic fails back to it
+	// once its link recovers.
+	// +kubebuilder:validation:MinItems=2
+	Interfaces []string `json:"interfaces"`
+}
+
+const (
+	UplinkRoleGateway   = "Gateway"
+	UplinkRoleSecondary = "Secondary"
+)
+
+// Uplink defines a PF of the DPU NICs.
+type Uplink struct {
+	// Name is the uplink netdev of the PF on the DPU, e.g. p1.
+	// +kubebuilder:validation:MaxLength=15
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_.-]+$`
+	Name string `json:"name"`
+
+	// PCIAddress is the PCI address of the PF, e.g. 0000:03:00.1.
+	// +kubebuilder:validation:Pattern=`^[0-9a-fA-F]{4}:[0-9a-fA-F]{2}:[0-9a-fA-F]{2}\.[0-7]$`
+	PCIAddress string `json:"pciAddress"`
+
+	// Role is Gateway for the uplink attached to br-ex, which carries the
+	// traffic of the cluster network, or Secondary for an uplink attached
+	// with its PF representor to the br-<name> bridge. Only one uplink is
+	// the gateway, unless the gateway uplinks are bonded by UplinkBond.
+	// +kubebuilder:validation:Enum=Gateway;Secondary
+	// +kubebuilder:default=Secondary
+	// +optional
+	Role string `json:"role,omitempty"`
+}
+
+// KubeConfigSecretReference references the Secret of a tenant kubeconfig
+// written by a secret store.
+type KubeConfigSecretReference struct {
+	// Name is the name of the Secret.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the Secret, the one of the
+	// OVNKubeConfig when empty. The operator must be allowed to read the
+	// Secrets of that namespace, and copies the kubeconfig into the
+	// namespace of the OVNKubeConfig for the rendered pods.
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Key is the data key of the Secret holding the kubeconfig.
+	// +kubebuilder:default=config
+	// +optional
+	Key string `json:"key,omitempty"`
+
+	// VersionAnnotation is an annotation of the Secret set by the secret
+	// store to the version of its data, e.g.
+	// reconcile.external-secrets.io/data-hash. When not set, or missing on
+	// the Secret, the version is the hash of the kubeconfig.
+	// +optional
+	VersionAnnotation string `json:"versionAnnotation,omitempty"`
+}
+
+// PoolReference references a DpuNodePool.
+type PoolReference struct {
+	// Name is the name of the DpuNodePool.
+	Name string `json:"name"`
+}
+
+// TenantSpec defines where OVN-Kubernetes runs in the tenant cluster.
+type TenantSpec struct {
+	// OvnNamespace is the namespace of OVN-Kubernetes in the tenant
+	// cluster. When not set, it is detected by searching the
+	// ovnkube-config ConfigMap in TENANT_NAMESPACE, openshift-ovn-kubernetes
+	// and ovn-kubernetes.
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +optional
+	OvnNamespace string `json:"ovnNamespace,omitempty"`
+
+	// ImpersonateUser is the user the operator impersonates in the tenant
+	// cluster, rather than acting as the identity of the kubeconfig, which
+	// then only needs to be allowed to impersonate it. Its access to the
+	// OVN-Kubernetes objects is checked with SelfSubjectAccessReviews.
+	// +optional
+	ImpersonateUser string `json:"impersonateUser,omitempty"`
+
+	// ImpersonateGroups are the groups impersonated with ImpersonateUser.
+	// +optional
+	ImpersonateGroups []string `json:"impersonateGroups,omitempty"`
+
+	// CleanupTimeout bounds how long the deletion of the OVNKubeConfig
+	// retries removing the objects created in the tenant cluster while it
+	// is unreachable. Defaults to 5m.
+	// +optional
+	CleanupTimeout *metav1.Duration `json:"cleanupTimeout,omitempty"`
+
+	// ForceCleanup lets the deletion of the OVNKubeConfig complete once
+	// CleanupTimeout expired, leaving the objects in the unreachable tenant
+	// cluster. Otherwise the deletion waits for the tenant cluster.
+	// +optional
+	ForceCleanup bool `json:"forceCleanup,omitempty"`
+
+	// ManageDevicePlugin renders the configuration of the SR-IOV network
+	// device plugin of the tenant cluster, the sriovdp-config ConfigMap,
+	// with a resource pool of the VFs the DPUs expose to their hosts.
+	// +optional
+	ManageDevicePlugin bool `json:"manageDevicePlugin,omitempty"`
+
+	// DevicePlugin tunes the configuration rendered with
+	// ManageDevicePlugin.
+	// +optional
+	DevicePlugin *DevicePluginSpec `json:"devicePlugin,omitempty"`
+}
+
+// DevicePluginSpec defines the resource pool of the DPU VFs in the SR-IOV
+// network device plugin of the tenant cluster.
+type DevicePluginSpec struct {
+	// Namespace is the namespace of the device plugin in the tenant
+	// cluster. Defaults to kube-system.
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// ResourceName is the name of the resource pool of the VFs. Defaults
+	// to dpu_vfs.
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_]+$`
+	// +optional
+	ResourceName string `json:"resourceName,omitempty"`
+
+	// ResourcePrefix prefixes the resource name, the default one of the
+	// device plugin when empty.
+	// +optional
+	ResourcePrefix string `json:"resourcePrefix,omitempty"`
+
+	// Devices are the PCI device IDs of the VFs. Defaults to 101e, the VFs
+	// of the BlueField-2 and BlueField-3 DPUs.
+	// +optional
+	Devices []string `json:"devices,omitempty"`
+
+	// PfNames restricts the pool to the VFs of these PFs of the hosts.
+	// +optional
+	PfNames []string `json:"pfNames,omitempty"`
+}
+
+// OvnSpec defines the OVN settings of the DPU data plane.
+type OvnSpec struct {
+	// ExternalDbEndpoints lists the OVN NB and SB DB endpoints of the tenant
+	// cluster when they are exposed through hostnames, e.g. routes, instead of
+	// the ovnkube-master pod IPs. The pod discovery is skipped when set.
+	// +optional
+	ExternalDbEndpoints *ExternalDbEndpoints `json:"externalDbEndpoints,omitempty"`
+
+	// CASecretRef references a Secret in the namespace of the CR holding the
+	// CA bundle, under the ca-bundle.crt key, which signed the OVN
+	// certificates. It replaces the ovn-ca ConfigMap synced from the tenant
+	// cluster, for tenant clusters using a custom signer.
+	// +optional
+	CASecretRef *corev1.LocalObjectReference `json:"caSecretRef,omitempty"`
+
+	// CertIdentity is the identity the ovn-cert certificate must hold for
+	// every DPU node, as its common name or one of its subject alternative
+	// names, {node} standing for the name of the node, e.g.
+	// system:ovn-node:{node}. The nodes it doesn't cover are reported by the
+	// CertInvalidForNodes condition. Empty disables the check, for the
+	// certificates shared by every chassis.
+	// +optional
+	CertIdentity string `json:"certIdentity,omitempty"`
+
+	// EncapInterface selects the interface whose address ovnkube-node uses
+	// as the OVN encapsulation IP, instead of the node IP.
+	// +optional
+	EncapInterface *EncapInterface `json:"encapInterface,omitempty"`
+
+	// Features toggles the ovn-kubernetes features which need node-side
+	// flags with the DPU offload. Every enabled feature must also be enabled
+	// in the tenant cluster.
+	// +optional
+	Features *OvnFeatures `json:"features,omitempty"`
+
+	// OvnLogLevel is the console log level of ovn-controller. It is applied
+	// to the running ovn-controller without restarting the pods. An
+	// OVN_LOG_LEVEL set in the env-overrides of a node takes precedence.
+	// +kubebuilder:validation:Enum=off;emer;err;warn;info;dbg
+	// +optional
+	OvnLogLevel string `json:"ovnLogLevel,omitempty"`
+
+	// ChassisVerification periodically samples the tenant pods of the hosts
+	// served by the DPUs and checks that their logical switch ports request,
+	// and are bound to, the chassis of their DPU. The outcome is reported
+	// in status.chassisVerification.
+	// +optional
+	ChassisVerification *ChassisVerification `json:"chassisVerification,omitempty"`
+}
+
+// ChassisVerification defines the periodic check of the requested-chassis
+// of the tenant pods.
+type ChassisVerification struct {
+	// Interval is the time between two checks, e.g. 1h.
+	// +kubebuilder:default="1h"
+	// +optional
+	Interval metav1.Duration `json:"interval,omitempty"`
+
+	// SampleSize is the number of pods checked at a time.
+	// +kubebuilder:default=20
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=200
+	// +optional
+	SampleSize int32 `json:"sampleSize,omitempty"`
+}
+
+// OvnKubeNodeSpec defines the settings of the ovnkube-node container.
+type OvnKubeNodeSpec struct {
+	// LogLevel is the log verbosity of ovnkube-node, 4 by default. Changing
+	// it restarts the ovnkube-node pods.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=5
+	// +optional
+	LogLevel *int32 `json:"logLevel,omitempty"`
+
+	// Image overrides the ovnkube image of the DPUs of this CR, e.g. to run
+	// a hotfix build on one pool. It takes precedence over OVNKUBE_IMAGE.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Env sets environment variables in every container of the
+	// ovnkube-node pods, replacing the rendered ones of the same name, e.g.
+	// to turn on a debug setting of a hotfix build. Changing it restarts the
+	// pods.
+	// +optional
+	Env map[string]string `json:"env,omitempty"`
+
+	// ContainerImages overrides the image of containers of the ovnkube-node
+	// pods by container name, e.g. ovn-controller. It takes precedence over
+	// images.
+	// +optional
+	ContainerImages map[string]string `json:"containerImages,omitempty"`
+
+	// HostNetwork runs the ovnkube-node pods in the host network, true by
+	// default. DPU OS images running the OVN components under systemd only
+	// need the kube wrapper, which may then run in the pod network. The pods
+	// keep resolving names with the resolver of the node, and the metrics
+	// of ovnkube-node are served on the pod IP rather than on the loopback
+	// of the node.
+	// +optional
+	HostNetwork *bool `json:"hostNetwork,omitempty"`
+}
+
+// ImagesSpec defines the images of the containers rendered on the DPU
+// nodes. The images left empty default to the ovnkube image.
+type ImagesSpec struct {
+	// OvnKube is the image of the ovnkube-node container, and of the helper
+	// DaemonSets unless nodeAgent is set. It takes precedence over
+	// ovnKubeNode.image and OVNKUBE_IMAGE.
+	// +optional
+	OvnKube string `json:"ovnKube,omitempty"`
+
+	// OvnController is the image of the ovn-controller container. It
+	// defaults to the OVN_CONTROLLER_IMAGE env of the operator, or else the
+	// ovnkube image.
+	// +optional
+	OvnController string `json:"ovnController,omitempty"`
+
+	// OvsDaemons is the image of the containers running Open vSwitch
+	// daemons, ovs-monitor-ipsec in ovn-ipsec. It defaults to the
+	// OVS_DAEMONS_IMAGE env of the operator, or else the ovnkube image.
+	// +optional
+	OvsDaemons string `json:"ovsDaemons,omitempty"`
+
+	// NodeAgent is the image of the helper DaemonSets publishing the state
+	// of the DPU nodes, the VF representor discovery and the switchdev
+	// check, which need bash, kubectl, ip and devlink. It defaults to the
+	// NODE_AGENT_IMAGE env of the operator, or else the ovnkube image.
+	// +optional
+	NodeAgent string `json:"nodeAgent,omitempty"`
+}
+
+// MachineConfigSpec defines the MachineConfig rendered for the pool.
+type MachineConfigSpec struct {
+	// NamePrefix is the prefix of the name of the MachineConfig,
+	// <namePrefix>-<pool>-bluefield-switchdev. The MachineConfigs of a pool
+	// are merged in the lexical order of their names, so it orders the
+	// switchdev configuration against the other MachineConfigs.
+	// +kubebuilder:validation:Pattern=`^[0-9a-z]([-0-9a-z]*[0-9a-z])?$`
+	// +kubebuilder:validation:MaxLength=16
+	// +kubebuilder:default="00"
+	// +optional
+	NamePrefix string `json:"namePrefix,omitempty"`
+}
+
+// The data plane providers.
+const (
+	DataPlaneOVNKubernetes = "OVNKubernetes"
+	DataPlaneCilium        = "Cilium"
+)
+
+// DataPlaneSpec defines the data plane offloaded to the DPUs.
+type DataPlaneSpec struct {
+	// Provider is the data plane run on the DPUs: OVNKubernetes, or Cilium
+	// as a tech preview. It must match the network plugin of the tenant
+	// cluster.
+	// +kubebuilder:validation:Enum=OVNKubernetes;Cilium
+	// +kubebuilder:default=OVNKubernetes
+	// +optional
+	Provider string `json:"provider,omitempty"`
+
+	// Cilium holds the settings of the Cilium provider.
+	// +optional
+	Cilium *CiliumSpec `json:"cilium,omitempty"`
+}
+
+// CiliumSpec defines the settings of the cilium-agent run on the DPUs.
+type CiliumSpec struct {
+	// Image is the cilium-agent image.
+	Image string `json:"image"`
+
+	// Config is added to the cilium-config ConfigMap read by the agent,
+	// e.g. tunnel-protocol: geneve. It should match the configuration of
+	// Cilium in the tenant cluster.
+	// +optional
+	Config map[string]string `json:"config,omitempty"`
+}
+
+// OvnFeatures defines the ovn-kubernetes features enabled in ovnkube-node.
+type OvnFeatures struct {
+	// EgressIP enables the EgressIP support.
+	// +optional
+	EgressIP bool `json:"egressIP,omitempty"`
+
+	// EgressFirewall enables the EgressFirewall support.
+	// +optional
+	EgressFirewall bool `json:"egressFirewall,omitempty"`
+
+	// MulticastSnooping enables the IGMP and MLD snooping of the multicast
+	// traffic.
+	// +optional
+	MulticastSnooping bool `json:"multicastSnooping,omitempty"`
+}
+
+// EncapInterface defines the interface carrying the OVN encapsulation
+// traffic on the DPU nodes.
+type EncapInterface struct {
+	// Name is the name of the interface, or a regular expression matching
+	// the whole name, e.g. "bond0|p0".
+	Name string `json:"name"`
+
+	// Subnet is the CIDR the encapsulation IP must belong to. The first
+	// global address of the interface is used if empty.
+	// +optional
+	Subnet string `json:"subnet,omitempty"`
+}
+
+// ExternalDbEndpoints defines the addresses of the OVN databases. Each entry
+// is a host or host:port, the default OVN DB port is used if omitted.
+type ExternalDbEndpoints struct {
+	// Nb lists the endpoints of the northbound DB.
+	// +kubebuilder:validation:MinItems=1
+	Nb []string `json:"nb"`
+
+	// Sb lists the endpoints of the southbound DB.
+	// +kubebuilder:validation:MinItems=1
+	Sb []string `json:"sb"`
+}
+
+// MaintenanceWindow defines a recurring time window in UTC.
+type MaintenanceWindow struct {
+	// Start is the time of day, in UTC, at which the window opens, formatted as HH:MM.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+
+	// Duration is how long the window stays open, e.g. 2h30m.
+	Duration metav1.Duration `json:"duration"`
+
+	// Days restricts the window to the given days of the week. The window
+	// opens every day if empty.
+	// +optional
+	Days []Weekday `json:"days,omitempty"`
 }
 
+// Weekday is a day of the week, abbreviated to its first three letters.
+// +kubebuilder:validation:Enum=Mon;Tue;Wed;Thu;Fri;Sat;Sun
+type Weekday string
+
 // OVNKubeConfigStatus defines the observed state of OVNKubeConfig
 type OVNKubeConfigStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
@@ -44,10 +734,399 @@ type OVNKubeConfigStatus struct {
 
 	// Conditions represent the latest available observations of an object's state
 	Conditions []metav1.Condition `json:"conditions"`
+
+	// Nodes reports the state of each DPU node of the pool.
+	// +optional
+	Nodes []DpuNodeStatus `json:"nodes,omitempty"`
+
+	// Versions reports the versions of the components involved in the DPU
+	// data plane, checked by the VersionSkew condition.
+	// +optional
+	Versions *ComponentVersions `json:"versions,omitempty"`
+
+	// TargetNamespace is the namespace the workloads are rendered into,
+	// spec.targetNamespace or the namespace of the CR.
+	// +optional
+	TargetNamespace string `json:"targetNamespace,omitempty"`
+
+	// TenantOvnNamespace is the namespace of OVN-Kubernetes in the tenant
+	// cluster, as set in the spec or detected.
+	// +optional
+	TenantOvnNamespace string `json:"tenantOvnNamespace,omitempty"`
+
+	// TenantNetworkType is the network plugin of the tenant cluster, e.g.
+	// OVNKubernetes, if known.
+	// +optional
+	TenantNetworkType string `json:"tenantNetworkType,omitempty"`
+
+	// NbEndpoints are the OVN northbound DB addresses last rendered into
+	// the ovnkube-node DaemonSet, e.g. ssl:10.0.0.10:9641.
+	// +optional
+	NbEndpoints []string `json:"nbEndpoints,omitempty"`
+
+	// SbEndpoints are the OVN southbound DB addresses last rendered into
+	// the ovnkube-node DaemonSet, e.g. ssl:10.0.0.10:9642.
+	// +optional
+	SbEndpoints []string `json:"sbEndpoints,omitempty"`
+
+	// TenantEndpoint reports the connectivity check of the tenant API
+	// server, run before the syncer starts.
+	// +optional
+	TenantEndpoint *TenantEndpointStatus `json:"tenantEndpoint,omitempty"`
+
+	// TenantKubeconfigVersion is the version of the tenant kubeconfig the
+	// syncer runs with. The syncer restarts when it changes.
+	// +optional
+	TenantKubeconfigVersion string `json:"tenantKubeconfigVersion,omitempty"`
+
+	// Drift reports the last drift check, when DriftDetection is set.
+	// +optional
+	Drift *DriftReport `json:"drift,omitempty"`
+
+	// ChassisVerification reports the last check of the requested-chassis
+	// of the tenant pods, when ovn.chassisVerification is set.
+	// +optional
+	ChassisVerification *ChassisVerificationReport `json:"chassisVerification,omitempty"`
+
+	// LastVerification reports the connectivity test run after the last
+	// rollout, when verification is enabled.
+	// +optional
+	LastVerification *VerificationReport `json:"lastVerification,omitempty"`
+
+	// VerifiedRevisions are the last revisions tested of the daemonset and
+	// machineconfig targets, so a rollout is tested once.
+	// +optional
+	VerifiedRevisions map[string]string `json:"verifiedRevisions,omitempty"`
+
+	// Reconciles reports the last pass of each controller reconciling the
+	// CR, e.g. to spot the passes slowed down by the tenant API server.
+	// +listType=map
+	// +listMapKey=controller
+	// +optional
+	Reconciles []ReconcileStatus `json:"reconciles,omitempty"`
+
+	// ReconcileHistory lists the last passes of the controllers, oldest
+	// first, with the event which triggered them.
+	// +optional
+	ReconcileHistory []ReconcileEvent `json:"reconcileHistory,omitempty"`
+}
+
+// ReconcileEvent defines a pass of a controller.
+type ReconcileEvent struct {
+	// Time is the end of the pass.
+	Time metav1.Time `json:"time"`
+
+	// Controller is the name of the controller.
+	Controller string `json:"controller"`
+
+	// Trigger is the last object change which queued the pass, e.g.
+	// "DaemonSet ns/ovnkube-node", or requeue when it was requeued.
+	Trigger string `json:"trigger"`
+
+	// Outcome is success or error.
+	Outcome string `json:"outcome"`
+}
+
+// ReconcileStatus defines the outcome of the last pass of a controller.
+type ReconcileStatus struct {
+	// Controller is the name of the controller, e.g.
+	// ovnkubeconfig-workload.
+	Controller string `json:"controller"`
+
+	// LastReconcileTime is the time the pass ended.
+	LastReconcileTime metav1.Time `json:"lastReconcileTime"`
+
+	// LastReconcileDuration is the duration of the pass.
+	LastReconcileDuration metav1.Duration `json:"lastReconcileDuration"`
+
+	// LastError is the error the pass failed with, if any.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+}
+
+// TenantEndpointStatus defines the outcome of the connectivity check of the
+// tenant API server. The latencies are set for the steps which ran.
+type TenantEndpointStatus struct {
+	// Server is the API server URL of the tenant kubeconfig.
+	Server string `json:"server"`
+
+	// Addresses are the addresses the host of Server resolved to.
+	// +optional
+	Addresses []string `json:"addresses,omitempty"`
+
+	// Reachable is true when the /version endpoint answered.
+	Reachable bool `json:"reachable"`
+
+	// LastCheckTime is the time of the check.
+	LastCheckTime metav1.Time `json:"lastCheckTime"`
+
+	// ResolveLatency is the duration of the DNS resolution of the host.
+	// +optional
+	ResolveLatency *metav1.Duration `json:"resolveLatency,omitempty"`
+
+	// DialLatency is the duration of the TCP connection.
+	// +optional
+	DialLatency *metav1.Duration `json:"dialLatency,omitempty"`
+
+	// TLSLatency is the duration of the TLS handshake.
+	// +optional
+	TLSLatency *metav1.Duration `json:"tlsLatency,omitempty"`
+
+	// VersionLatency is the duration of the /version request.
+	// +optional
+	VersionLatency *metav1.Duration `json:"versionLatency,omitempty"`
+
+	// Error is the step which failed and its error, if any.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// DriftReport defines the outcome of a drift check.
+type DriftReport struct {
+	// LastCheckTime is the time of the check.
+	LastCheckTime metav1.Time `json:"lastCheckTime"`
+
+	// Objects lists the live objects which differ from their rendering,
+	// with the first differing fields, e.g. "DaemonSet ovnkube-node:
+	// spec.template.spec.containers[0].image".
+	// +optional
+	Objects []string `json:"objects,omitempty"`
+
+	// Error is set when the objects could not be rendered.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// ChassisVerificationReport defines the outcome of a requested-chassis
+// check.
+type ChassisVerificationReport struct {
+	// LastCheckTime is the time of the check.
+	LastCheckTime metav1.Time `json:"lastCheckTime"`
+
+	// CheckedPorts is the number of logical switch ports checked.
+	// +optional
+	CheckedPorts int32 `json:"checkedPorts,omitempty"`
+
+	// Misbound lists the pods whose logical switch port doesn't request, or
+	// isn't bound to, the chassis of their host, e.g. "default/web-0:
+	// requested-chassis host-b, expected host-a".
+	// +optional
+	Misbound []string `json:"misbound,omitempty"`
+
+	// Error is set when the check could not run.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// VerificationPhase is the progress of a connectivity test.
+type VerificationPhase string
+
+const (
+	VerificationRunning   VerificationPhase = "Running"
+	VerificationSucceeded VerificationPhase = "Succeeded"
+	VerificationFailed    VerificationPhase = "Failed"
+)
+
+// The results of a check of a connectivity test.
+const (
+	VerificationCheckPassed  = "Passed"
+	VerificationCheckFailed  = "Failed"
+	VerificationCheckSkipped = "Skipped"
+)
+
+// VerificationReport defines the outcome of the connectivity test of a
+// rollout.
+type VerificationReport struct {
+	// Target is the rolled out object, daemonset or machineconfig.
+	Target string `json:"target"`
+
+	// Revision is the revision of the rolled out change.
+	Revision string `json:"revision"`
+
+	// Phase is Succeeded once every check passed or was skipped.
+	Phase VerificationPhase `json:"phase"`
+
+	// StartTime is the start of the test.
+	StartTime metav1.Time `json:"startTime"`
+
+	// CompletionTime is the end of the test.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Checks are the results of the pod-to-pod, dns and egress checks.
+	// +optional
+	Checks []VerificationCheck `json:"checks,omitempty"`
+
+	// Message explains a test which could not run.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// VerificationCheck defines the result of a check of a connectivity test.
+type VerificationCheck struct {
+	// Name is the name of the check, e.g. pod-to-pod.
+	Name string `json:"name"`
+
+	// Result is Passed, Failed or Skipped.
+	Result string `json:"result"`
+
+	// Message is the error of a failed check.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// The sources of the ovnkube image.
+const (
+	OvnKubeImageSourceSpec   = "spec-override"
+	OvnKubeImageSourceEnv    = "env"
+	OvnKubeImageSourceTenant = "tenant"
+	OvnKubeImageSourceLocal  = "local-ds"
+)
+
+// ComponentVersions defines the observed versions of the operator, of the
+// ovnkube-node DaemonSet and of the tenant OVN control plane.
+type ComponentVersions struct {
+	// Operator is the version of the dpu-network-operator.
+	// +optional
+	Operator string `json:"operator,omitempty"`
+
+	// OperatorCommit is the git commit the operator was built from, if
+	// known.
+	// +optional
+	OperatorCommit string `json:"operatorCommit,omitempty"`
+
+	// OvnControllerImage is the image rendered into ovn-controller.
+	// +optional
+	OvnControllerImage string `json:"ovnControllerImage,omitempty"`
+
+	// OvnKubeImage is the ovnkube image rendered into ovnkube-node.
+	// +optional
+	OvnKubeImage string `json:"ovnKubeImage,omitempty"`
+
+	// OvnKubeImageSource is where OvnKubeImage comes from, by precedence:
+	// spec-override for spec.images.ovnKube or spec.ovnKubeNode.image, env
+	// for the OVNKUBE_IMAGE
+	// of the operator, tenant for the ovnkube-master pods of the tenant
+	// cluster, or local-ds for the ovnkube-node DaemonSet of the infra
+	// cluster.
+	// +optional
+	OvnKubeImageSource string `json:"ovnKubeImageSource,omitempty"`
+
+	// OvnKubeVersion is the OpenShift version of the ovnkube image, if known.
+	// +optional
+	OvnKubeVersion string `json:"ovnKubeVersion,omitempty"`
+
+	// OvsDaemonsImage is the image rendered into the containers running
+	// Open vSwitch daemons.
+	// +optional
+	OvsDaemonsImage string `json:"ovsDaemonsImage,omitempty"`
+
+	// TenantOvnKubeImage is the ovnkube-master image of the tenant cluster.
+	// +optional
+	TenantOvnKubeImage string `json:"tenantOvnKubeImage,omitempty"`
+
+	// TenantVersion is the OpenShift version of the tenant cluster, if known.
+	// +optional
+	TenantVersion string `json:"tenantVersion,omitempty"`
+}
+
+// DpuNodeStatus defines the observed state of a DPU node.
+type DpuNodeStatus struct {
+	// Name is the name of the node.
+	Name string `json:"name"`
+
+	// ActiveUplink is the uplink currently carrying the traffic of the bond.
+	// +optional
+	ActiveUplink string `json:"activeUplink,omitempty"`
+
+	// EncapInterface is the interface selected for the OVN encapsulation.
+	// +optional
+	EncapInterface string `json:"encapInterface,omitempty"`
+
+	// EncapIP is the OVN encapsulation IP of the node.
+	// +optional
+	EncapIP string `json:"encapIP,omitempty"`
+
+	// EncapError explains why no interface of the node is valid for the OVN
+	// encapsulation.
+	// +optional
+	EncapError string `json:"encapError,omitempty"`
+
+	// EswitchModes maps the devlink devices of the node to their eswitch
+	// mode, as reported by devlink on the node.
+	// +optional
+	EswitchModes map[string]string `json:"eswitchModes,omitempty"`
+
+	// SwitchdevError explains why the node is not in switchdev mode, e.g.
+	// when the firmware kept a NIC in legacy mode.
+	// +optional
+	SwitchdevError string `json:"switchdevError,omitempty"`
+
+	// MissingUplinks are the uplinks of spec.uplinks whose PF is not found
+	// on the node.
+	// +optional
+	MissingUplinks []string `json:"missingUplinks,omitempty"`
+
+	// UnderMaintenance is true while the node is cordoned, e.g. drained by
+	// the NodeMaintenance operator. Its ovnkube-node pod is expected to be
+	// down, and doesn't make the OVNKubeConfig not ready.
+	// +optional
+	UnderMaintenance bool `json:"underMaintenance,omitempty"`
+
+	// Onboarding is the progress of the first boot of the node since it
+	// joined the pool. It is no longer updated once complete.
+	// +optional
+	Onboarding *DpuNodeOnboarding `json:"onboarding,omitempty"`
+}
+
+// The steps of the onboarding of a DPU node, in order. The MachineConfig
+// steps are skipped when the operator doesn't manage the MachineConfigs,
+// and the chassis one with another data plane than OVN-Kubernetes.
+const (
+	OnboardingPending              = "Pending"
+	OnboardingMachineConfigApplied = "MachineConfigApplied"
+	OnboardingRebooted             = "Rebooted"
+	OnboardingSwitchdevVerified    = "SwitchdevVerified"
+	OnboardingOvnKubeScheduled     = "OvnKubeScheduled"
+	OnboardingChassisRegistered    = "ChassisRegistered"
+)
+
+// DpuNodeOnboarding is the progress of the first boot of a DPU node: the
+// switchdev MachineConfig is applied, the node is rebooted into it, the NICs
+// are verified in switchdev mode, the ovnkube-node pod is scheduled and the
+// tenant host is registered as an OVN chassis.
+type DpuNodeOnboarding struct {
+	// Phase is the last step the node reached, Pending before the first.
+	Phase string `json:"phase"`
+
+	// Message tells what the next step is waiting for.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Steps are the steps reached, with the time each was first observed.
+	// +optional
+	Steps []DpuNodeOnboardingStep `json:"steps,omitempty"`
+
+	// StartTime is when the node was first observed in the pool.
+	StartTime metav1.Time `json:"startTime"`
+
+	// CompletionTime is when the node reached the last step.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// DpuNodeOnboardingStep is a step reached by a DPU node.
+type DpuNodeOnboardingStep struct {
+	// Name is the name of the step.
+	Name string `json:"name"`
+
+	// Time is when the step was first observed.
+	Time metav1.Time `json:"time"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
 
 // OVNKubeConfig is the Schema for the ovnkubeconfigs API
 type OVNKubeConfig struct {
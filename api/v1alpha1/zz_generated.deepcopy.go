@@ -22,12 +22,132 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OVNKubeConfig) DeepCopyInto(out *OVNKubeConfig) {
+func (in *ChassisVerification) DeepCopyInto(out *ChassisVerification) {
+	*out = *in
+	out.Interval = in.Interval
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChassisVerification.
+func (in *ChassisVerification) DeepCopy() *ChassisVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(ChassisVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChassisVerificationReport) DeepCopyInto(out *ChassisVerificationReport) {
+	*out = *in
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+	if in.Misbound != nil {
+		in, out := &in.Misbound, &out.Misbound
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChassisVerificationReport.
+func (in *ChassisVerificationReport) DeepCopy() *ChassisVerificationReport {
+	if in == nil {
+		return nil
+	}
+	out := new(ChassisVerificationReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CiliumSpec) DeepCopyInto(out *CiliumSpec) {
+	*out = *in
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CiliumSpec.
+func (in *CiliumSpec) DeepCopy() *CiliumSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CiliumSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentVersions) DeepCopyInto(out *ComponentVersions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentVersions.
+func (in *ComponentVersions) DeepCopy() *ComponentVersions {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentVersions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataPlaneSpec) DeepCopyInto(out *DataPlaneSpec) {
+	*out = *in
+	if in.Cilium != nil {
+		in, out := &in.Cilium, &out.Cilium
+		*out = new(CiliumSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPlaneSpec.
+func (in *DataPlaneSpec) DeepCopy() *DataPlaneSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DataPlaneSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DevicePluginSpec) DeepCopyInto(out *DevicePluginSpec) {
+	*out = *in
+	if in.Devices != nil {
+		in, out := &in.Devices, &out.Devices
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PfNames != nil {
+		in, out := &in.PfNames, &out.PfNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DevicePluginSpec.
+func (in *DevicePluginSpec) DeepCopy() *DevicePluginSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DevicePluginSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DpuFleetPolicy) DeepCopyInto(out *DpuFleetPolicy) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -35,18 +155,18 @@ func (in *OVNKubeConfig) DeepCopyInto(out *OVNKubeConfig) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNKubeConfig.
-func (in *OVNKubeConfig) DeepCopy() *OVNKubeConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DpuFleetPolicy.
+func (in *DpuFleetPolicy) DeepCopy() *DpuFleetPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(OVNKubeConfig)
+	out := new(DpuFleetPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *OVNKubeConfig) DeepCopyObject() runtime.Object {
+func (in *DpuFleetPolicy) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -54,31 +174,31 @@ func (in *OVNKubeConfig) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OVNKubeConfigList) DeepCopyInto(out *OVNKubeConfigList) {
+func (in *DpuFleetPolicyList) DeepCopyInto(out *DpuFleetPolicyList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]OVNKubeConfig, len(*in))
+		*out = make([]DpuFleetPolicy, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNKubeConfigList.
-func (in *OVNKubeConfigList) DeepCopy() *OVNKubeConfigList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DpuFleetPolicyList.
+func (in *DpuFleetPolicyList) DeepCopy() *DpuFleetPolicyList {
 	if in == nil {
 		return nil
 	}
-	out := new(OVNKubeConfigList)
+	out := new(DpuFleetPolicyList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *OVNKubeConfigList) DeepCopyObject() runtime.Object {
+func (in *DpuFleetPolicyList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -86,27 +206,212 @@ func (in *OVNKubeConfigList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OVNKubeConfigSpec) DeepCopyInto(out *OVNKubeConfigSpec) {
+func (in *DpuFleetPolicySpec) DeepCopyInto(out *DpuFleetPolicySpec) {
 	*out = *in
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
-		*out = new(v1.LabelSelector)
-		(*in).DeepCopyInto(*out)
+	in.TenantSelector.DeepCopyInto(&out.TenantSelector)
+	in.Template.DeepCopyInto(&out.Template)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DpuFleetPolicySpec.
+func (in *DpuFleetPolicySpec) DeepCopy() *DpuFleetPolicySpec {
+	if in == nil {
+		return nil
 	}
+	out := new(DpuFleetPolicySpec)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNKubeConfigSpec.
-func (in *OVNKubeConfigSpec) DeepCopy() *OVNKubeConfigSpec {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DpuFleetPolicyStatus) DeepCopyInto(out *DpuFleetPolicyStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Tenants != nil {
+		in, out := &in.Tenants, &out.Tenants
+		*out = make([]FleetTenantStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DpuFleetPolicyStatus.
+func (in *DpuFleetPolicyStatus) DeepCopy() *DpuFleetPolicyStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(OVNKubeConfigSpec)
+	out := new(DpuFleetPolicyStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OVNKubeConfigStatus) DeepCopyInto(out *OVNKubeConfigStatus) {
+func (in *DpuNodeInventory) DeepCopyInto(out *DpuNodeInventory) {
+	*out = *in
+	if in.Allocatable != nil {
+		in, out := &in.Allocatable, &out.Allocatable
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.NicFirmware != nil {
+		in, out := &in.NicFirmware, &out.NicFirmware
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DpuNodeInventory.
+func (in *DpuNodeInventory) DeepCopy() *DpuNodeInventory {
+	if in == nil {
+		return nil
+	}
+	out := new(DpuNodeInventory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DpuNodeOnboarding) DeepCopyInto(out *DpuNodeOnboarding) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]DpuNodeOnboardingStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DpuNodeOnboarding.
+func (in *DpuNodeOnboarding) DeepCopy() *DpuNodeOnboarding {
+	if in == nil {
+		return nil
+	}
+	out := new(DpuNodeOnboarding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DpuNodeOnboardingStep) DeepCopyInto(out *DpuNodeOnboardingStep) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DpuNodeOnboardingStep.
+func (in *DpuNodeOnboardingStep) DeepCopy() *DpuNodeOnboardingStep {
+	if in == nil {
+		return nil
+	}
+	out := new(DpuNodeOnboardingStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DpuNodePool) DeepCopyInto(out *DpuNodePool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DpuNodePool.
+func (in *DpuNodePool) DeepCopy() *DpuNodePool {
+	if in == nil {
+		return nil
+	}
+	out := new(DpuNodePool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DpuNodePool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DpuNodePoolList) DeepCopyInto(out *DpuNodePoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DpuNodePool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DpuNodePoolList.
+func (in *DpuNodePoolList) DeepCopy() *DpuNodePoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(DpuNodePoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DpuNodePoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DpuNodePoolSpec) DeepCopyInto(out *DpuNodePoolSpec) {
+	*out = *in
+	in.NodeSelector.DeepCopyInto(&out.NodeSelector)
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Taints != nil {
+		in, out := &in.Taints, &out.Taints
+		*out = make([]corev1.Taint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DpuNodePoolSpec.
+func (in *DpuNodePoolSpec) DeepCopy() *DpuNodePoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DpuNodePoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DpuNodePoolStatus) DeepCopyInto(out *DpuNodePoolStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -115,14 +420,1292 @@ func (in *OVNKubeConfigStatus) DeepCopyInto(out *OVNKubeConfigStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Inventory != nil {
+		in, out := &in.Inventory, &out.Inventory
+		*out = new(DpuPoolInventory)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNKubeConfigStatus.
-func (in *OVNKubeConfigStatus) DeepCopy() *OVNKubeConfigStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DpuNodePoolStatus.
+func (in *DpuNodePoolStatus) DeepCopy() *DpuNodePoolStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(OVNKubeConfigStatus)
+	out := new(DpuNodePoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DpuNodeStatus) DeepCopyInto(out *DpuNodeStatus) {
+	*out = *in
+	if in.EswitchModes != nil {
+		in, out := &in.EswitchModes, &out.EswitchModes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MissingUplinks != nil {
+		in, out := &in.MissingUplinks, &out.MissingUplinks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Onboarding != nil {
+		in, out := &in.Onboarding, &out.Onboarding
+		*out = new(DpuNodeOnboarding)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DpuNodeStatus.
+func (in *DpuNodeStatus) DeepCopy() *DpuNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DpuNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DpuOperatorConfig) DeepCopyInto(out *DpuOperatorConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DpuOperatorConfig.
+func (in *DpuOperatorConfig) DeepCopy() *DpuOperatorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DpuOperatorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DpuOperatorConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DpuOperatorConfigList) DeepCopyInto(out *DpuOperatorConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DpuOperatorConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DpuOperatorConfigList.
+func (in *DpuOperatorConfigList) DeepCopy() *DpuOperatorConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(DpuOperatorConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DpuOperatorConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DpuOperatorConfigSpec) DeepCopyInto(out *DpuOperatorConfigSpec) {
+	*out = *in
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = make([]NotificationSink, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DpuOperatorConfigSpec.
+func (in *DpuOperatorConfigSpec) DeepCopy() *DpuOperatorConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DpuOperatorConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DpuPacketCapture) DeepCopyInto(out *DpuPacketCapture) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DpuPacketCapture.
+func (in *DpuPacketCapture) DeepCopy() *DpuPacketCapture {
+	if in == nil {
+		return nil
+	}
+	out := new(DpuPacketCapture)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DpuPacketCapture) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DpuPacketCaptureList) DeepCopyInto(out *DpuPacketCaptureList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DpuPacketCapture, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DpuPacketCaptureList.
+func (in *DpuPacketCaptureList) DeepCopy() *DpuPacketCaptureList {
+	if in == nil {
+		return nil
+	}
+	out := new(DpuPacketCaptureList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DpuPacketCaptureList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DpuPacketCaptureSpec) DeepCopyInto(out *DpuPacketCaptureSpec) {
+	*out = *in
+	if in.PersistentVolumeClaim != nil {
+		in, out := &in.PersistentVolumeClaim, &out.PersistentVolumeClaim
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DpuPacketCaptureSpec.
+func (in *DpuPacketCaptureSpec) DeepCopy() *DpuPacketCaptureSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DpuPacketCaptureSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DpuPacketCaptureStatus) DeepCopyInto(out *DpuPacketCaptureStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DpuPacketCaptureStatus.
+func (in *DpuPacketCaptureStatus) DeepCopy() *DpuPacketCaptureStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DpuPacketCaptureStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DpuPoolInventory) DeepCopyInto(out *DpuPoolInventory) {
+	*out = *in
+	if in.Allocatable != nil {
+		in, out := &in.Allocatable, &out.Allocatable
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.KernelVersions != nil {
+		in, out := &in.KernelVersions, &out.KernelVersions
+		*out = make([]VersionCount, len(*in))
+		copy(*out, *in)
+	}
+	if in.OSImages != nil {
+		in, out := &in.OSImages, &out.OSImages
+		*out = make([]VersionCount, len(*in))
+		copy(*out, *in)
+	}
+	if in.NicFirmwareVersions != nil {
+		in, out := &in.NicFirmwareVersions, &out.NicFirmwareVersions
+		*out = make([]VersionCount, len(*in))
+		copy(*out, *in)
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]DpuNodeInventory, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DpuPoolInventory.
+func (in *DpuPoolInventory) DeepCopy() *DpuPoolInventory {
+	if in == nil {
+		return nil
+	}
+	out := new(DpuPoolInventory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DpuTrace) DeepCopyInto(out *DpuTrace) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DpuTrace.
+func (in *DpuTrace) DeepCopy() *DpuTrace {
+	if in == nil {
+		return nil
+	}
+	out := new(DpuTrace)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DpuTrace) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DpuTraceList) DeepCopyInto(out *DpuTraceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DpuTrace, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DpuTraceList.
+func (in *DpuTraceList) DeepCopy() *DpuTraceList {
+	if in == nil {
+		return nil
+	}
+	out := new(DpuTraceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DpuTraceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DpuTraceSpec) DeepCopyInto(out *DpuTraceSpec) {
+	*out = *in
+	out.Source = in.Source
+	out.Destination = in.Destination
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DpuTraceSpec.
+func (in *DpuTraceSpec) DeepCopy() *DpuTraceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DpuTraceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DpuTraceStatus) DeepCopyInto(out *DpuTraceStatus) {
+	*out = *in
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DpuTraceStatus.
+func (in *DpuTraceStatus) DeepCopy() *DpuTraceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DpuTraceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftDetection) DeepCopyInto(out *DriftDetection) {
+	*out = *in
+	out.Interval = in.Interval
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftDetection.
+func (in *DriftDetection) DeepCopy() *DriftDetection {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftDetection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftReport) DeepCopyInto(out *DriftReport) {
+	*out = *in
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftReport.
+func (in *DriftReport) DeepCopy() *DriftReport {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EncapInterface) DeepCopyInto(out *EncapInterface) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EncapInterface.
+func (in *EncapInterface) DeepCopy() *EncapInterface {
+	if in == nil {
+		return nil
+	}
+	out := new(EncapInterface)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalDbEndpoints) DeepCopyInto(out *ExternalDbEndpoints) {
+	*out = *in
+	if in.Nb != nil {
+		in, out := &in.Nb, &out.Nb
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Sb != nil {
+		in, out := &in.Sb, &out.Sb
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalDbEndpoints.
+func (in *ExternalDbEndpoints) DeepCopy() *ExternalDbEndpoints {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalDbEndpoints)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FleetTenantStatus) DeepCopyInto(out *FleetTenantStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FleetTenantStatus.
+func (in *FleetTenantStatus) DeepCopy() *FleetTenantStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FleetTenantStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagesSpec) DeepCopyInto(out *ImagesSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagesSpec.
+func (in *ImagesSpec) DeepCopy() *ImagesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeConfigSecretReference) DeepCopyInto(out *KubeConfigSecretReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeConfigSecretReference.
+func (in *KubeConfigSecretReference) DeepCopy() *KubeConfigSecretReference {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeConfigSecretReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogForwarding) DeepCopyInto(out *LogForwarding) {
+	*out = *in
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogForwarding.
+func (in *LogForwarding) DeepCopy() *LogForwarding {
+	if in == nil {
+		return nil
+	}
+	out := new(LogForwarding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineConfigSpec) DeepCopyInto(out *MachineConfigSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineConfigSpec.
+func (in *MachineConfigSpec) DeepCopy() *MachineConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	out.Duration = in.Duration
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]Weekday, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeLabelManagement) DeepCopyInto(out *NodeLabelManagement) {
+	*out = *in
+	if in.DiscoverySelector != nil {
+		in, out := &in.DiscoverySelector, &out.DiscoverySelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeLabelManagement.
+func (in *NodeLabelManagement) DeepCopy() *NodeLabelManagement {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeLabelManagement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationSink) DeepCopyInto(out *NotificationSink) {
+	*out = *in
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(corev1.SecretReference)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationSink.
+func (in *NotificationSink) DeepCopy() *NotificationSink {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVNKubeConfig) DeepCopyInto(out *OVNKubeConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNKubeConfig.
+func (in *OVNKubeConfig) DeepCopy() *OVNKubeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OVNKubeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OVNKubeConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVNKubeConfigList) DeepCopyInto(out *OVNKubeConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OVNKubeConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNKubeConfigList.
+func (in *OVNKubeConfigList) DeepCopy() *OVNKubeConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(OVNKubeConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OVNKubeConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVNKubeConfigSpec) DeepCopyInto(out *OVNKubeConfigSpec) {
+	*out = *in
+	if in.KubeConfigSecretRef != nil {
+		in, out := &in.KubeConfigSecretRef, &out.KubeConfigSecretRef
+		*out = new(KubeConfigSecretReference)
+		**out = **in
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PoolRef != nil {
+		in, out := &in.PoolRef, &out.PoolRef
+		*out = new(PoolReference)
+		**out = **in
+	}
+	if in.Tenant != nil {
+		in, out := &in.Tenant, &out.Tenant
+		*out = new(TenantSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MachineConfig != nil {
+		in, out := &in.MachineConfig, &out.MachineConfig
+		*out = new(MachineConfigSpec)
+		**out = **in
+	}
+	if in.ManageMachineConfig != nil {
+		in, out := &in.ManageMachineConfig, &out.ManageMachineConfig
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ManageWorkloads != nil {
+		in, out := &in.ManageWorkloads, &out.ManageWorkloads
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(MaintenanceWindow)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Ovn.DeepCopyInto(&out.Ovn)
+	if in.OvnKubeNode != nil {
+		in, out := &in.OvnKubeNode, &out.OvnKubeNode
+		*out = new(OvnKubeNodeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DataPlane != nil {
+		in, out := &in.DataPlane, &out.DataPlane
+		*out = new(DataPlaneSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UplinkBond != nil {
+		in, out := &in.UplinkBond, &out.UplinkBond
+		*out = new(UplinkBond)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Uplinks != nil {
+		in, out := &in.Uplinks, &out.Uplinks
+		*out = make([]Uplink, len(*in))
+		copy(*out, *in)
+	}
+	if in.ManageNodeLabels != nil {
+		in, out := &in.ManageNodeLabels, &out.ManageNodeLabels
+		*out = new(NodeLabelManagement)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeTaints != nil {
+		in, out := &in.NodeTaints, &out.NodeTaints
+		*out = make([]corev1.Taint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraRenderData != nil {
+		in, out := &in.ExtraRenderData, &out.ExtraRenderData
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]corev1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = new(ImagesSpec)
+		**out = **in
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = new(RolloutHooks)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutSpec)
+		**out = **in
+	}
+	if in.DriftDetection != nil {
+		in, out := &in.DriftDetection, &out.DriftDetection
+		*out = new(DriftDetection)
+		**out = **in
+	}
+	if in.TenantObjectPatches != nil {
+		in, out := &in.TenantObjectPatches, &out.TenantObjectPatches
+		*out = make([]TenantObjectPatch, len(*in))
+		copy(*out, *in)
+	}
+	if in.LogForwarding != nil {
+		in, out := &in.LogForwarding, &out.LogForwarding
+		*out = new(LogForwarding)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(VerificationSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNKubeConfigSpec.
+func (in *OVNKubeConfigSpec) DeepCopy() *OVNKubeConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OVNKubeConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVNKubeConfigStatus) DeepCopyInto(out *OVNKubeConfigStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]DpuNodeStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Versions != nil {
+		in, out := &in.Versions, &out.Versions
+		*out = new(ComponentVersions)
+		**out = **in
+	}
+	if in.NbEndpoints != nil {
+		in, out := &in.NbEndpoints, &out.NbEndpoints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SbEndpoints != nil {
+		in, out := &in.SbEndpoints, &out.SbEndpoints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TenantEndpoint != nil {
+		in, out := &in.TenantEndpoint, &out.TenantEndpoint
+		*out = new(TenantEndpointStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = new(DriftReport)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ChassisVerification != nil {
+		in, out := &in.ChassisVerification, &out.ChassisVerification
+		*out = new(ChassisVerificationReport)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastVerification != nil {
+		in, out := &in.LastVerification, &out.LastVerification
+		*out = new(VerificationReport)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VerifiedRevisions != nil {
+		in, out := &in.VerifiedRevisions, &out.VerifiedRevisions
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Reconciles != nil {
+		in, out := &in.Reconciles, &out.Reconciles
+		*out = make([]ReconcileStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ReconcileHistory != nil {
+		in, out := &in.ReconcileHistory, &out.ReconcileHistory
+		*out = make([]ReconcileEvent, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNKubeConfigStatus.
+func (in *OVNKubeConfigStatus) DeepCopy() *OVNKubeConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OVNKubeConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OvnFeatures) DeepCopyInto(out *OvnFeatures) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OvnFeatures.
+func (in *OvnFeatures) DeepCopy() *OvnFeatures {
+	if in == nil {
+		return nil
+	}
+	out := new(OvnFeatures)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OvnKubeNodeSpec) DeepCopyInto(out *OvnKubeNodeSpec) {
+	*out = *in
+	if in.LogLevel != nil {
+		in, out := &in.LogLevel, &out.LogLevel
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ContainerImages != nil {
+		in, out := &in.ContainerImages, &out.ContainerImages
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.HostNetwork != nil {
+		in, out := &in.HostNetwork, &out.HostNetwork
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OvnKubeNodeSpec.
+func (in *OvnKubeNodeSpec) DeepCopy() *OvnKubeNodeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OvnKubeNodeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OvnSpec) DeepCopyInto(out *OvnSpec) {
+	*out = *in
+	if in.ExternalDbEndpoints != nil {
+		in, out := &in.ExternalDbEndpoints, &out.ExternalDbEndpoints
+		*out = new(ExternalDbEndpoints)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CASecretRef != nil {
+		in, out := &in.CASecretRef, &out.CASecretRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.EncapInterface != nil {
+		in, out := &in.EncapInterface, &out.EncapInterface
+		*out = new(EncapInterface)
+		**out = **in
+	}
+	if in.Features != nil {
+		in, out := &in.Features, &out.Features
+		*out = new(OvnFeatures)
+		**out = **in
+	}
+	if in.ChassisVerification != nil {
+		in, out := &in.ChassisVerification, &out.ChassisVerification
+		*out = new(ChassisVerification)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OvnSpec.
+func (in *OvnSpec) DeepCopy() *OvnSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OvnSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolReference) DeepCopyInto(out *PoolReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolReference.
+func (in *PoolReference) DeepCopy() *PoolReference {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReconcileEvent) DeepCopyInto(out *ReconcileEvent) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReconcileEvent.
+func (in *ReconcileEvent) DeepCopy() *ReconcileEvent {
+	if in == nil {
+		return nil
+	}
+	out := new(ReconcileEvent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReconcileStatus) DeepCopyInto(out *ReconcileStatus) {
+	*out = *in
+	in.LastReconcileTime.DeepCopyInto(&out.LastReconcileTime)
+	out.LastReconcileDuration = in.LastReconcileDuration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReconcileStatus.
+func (in *ReconcileStatus) DeepCopy() *ReconcileStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReconcileStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutHook) DeepCopyInto(out *RolloutHook) {
+	*out = *in
+	if in.Script != nil {
+		in, out := &in.Script, &out.Script
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.JobTemplate != nil {
+		in, out := &in.JobTemplate, &out.JobTemplate
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutHook.
+func (in *RolloutHook) DeepCopy() *RolloutHook {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutHooks) DeepCopyInto(out *RolloutHooks) {
+	*out = *in
+	if in.PreRollout != nil {
+		in, out := &in.PreRollout, &out.PreRollout
+		*out = new(RolloutHook)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PostRollout != nil {
+		in, out := &in.PostRollout, &out.PostRollout
+		*out = new(RolloutHook)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutHooks.
+func (in *RolloutHooks) DeepCopy() *RolloutHooks {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutHooks)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutSpec) DeepCopyInto(out *RolloutSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutSpec.
+func (in *RolloutSpec) DeepCopy() *RolloutSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantEndpointStatus) DeepCopyInto(out *TenantEndpointStatus) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+	if in.ResolveLatency != nil {
+		in, out := &in.ResolveLatency, &out.ResolveLatency
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.DialLatency != nil {
+		in, out := &in.DialLatency, &out.DialLatency
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.TLSLatency != nil {
+		in, out := &in.TLSLatency, &out.TLSLatency
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.VersionLatency != nil {
+		in, out := &in.VersionLatency, &out.VersionLatency
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantEndpointStatus.
+func (in *TenantEndpointStatus) DeepCopy() *TenantEndpointStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantEndpointStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantObjectPatch) DeepCopyInto(out *TenantObjectPatch) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantObjectPatch.
+func (in *TenantObjectPatch) DeepCopy() *TenantObjectPatch {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantObjectPatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantSpec) DeepCopyInto(out *TenantSpec) {
+	*out = *in
+	if in.ImpersonateGroups != nil {
+		in, out := &in.ImpersonateGroups, &out.ImpersonateGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CleanupTimeout != nil {
+		in, out := &in.CleanupTimeout, &out.CleanupTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.DevicePlugin != nil {
+		in, out := &in.DevicePlugin, &out.DevicePlugin
+		*out = new(DevicePluginSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantSpec.
+func (in *TenantSpec) DeepCopy() *TenantSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TraceEndpoint) DeepCopyInto(out *TraceEndpoint) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TraceEndpoint.
+func (in *TraceEndpoint) DeepCopy() *TraceEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(TraceEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Uplink) DeepCopyInto(out *Uplink) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Uplink.
+func (in *Uplink) DeepCopy() *Uplink {
+	if in == nil {
+		return nil
+	}
+	out := new(Uplink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UplinkBond) DeepCopyInto(out *UplinkBond) {
+	*out = *in
+	if in.Interfaces != nil {
+		in, out := &in.Interfaces, &out.Interfaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UplinkBond.
+func (in *UplinkBond) DeepCopy() *UplinkBond {
+	if in == nil {
+		return nil
+	}
+	out := new(UplinkBond)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerificationCheck) DeepCopyInto(out *VerificationCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerificationCheck.
+func (in *VerificationCheck) DeepCopy() *VerificationCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(VerificationCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerificationReport) DeepCopyInto(out *VerificationReport) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Checks != nil {
+		in, out := &in.Checks, &out.Checks
+		*out = make([]VerificationCheck, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerificationReport.
+func (in *VerificationReport) DeepCopy() *VerificationReport {
+	if in == nil {
+		return nil
+	}
+	out := new(VerificationReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerificationSpec) DeepCopyInto(out *VerificationSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerificationSpec.
+func (in *VerificationSpec) DeepCopy() *VerificationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VerificationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VersionCount) DeepCopyInto(out *VersionCount) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionCount.
+func (in *VersionCount) DeepCopy() *VersionCount {
+	if in == nil {
+		return nil
+	}
+	out := new(VersionCount)
 	in.DeepCopyInto(out)
 	return out
 }
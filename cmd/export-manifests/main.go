@@ -0,0 +1,275 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// export-manifests renders the manifests installing the operator without
+// OLM: a kustomize base holding the CRDs and the ClusterRole, and an overlay
+// per profile holding the objects rendered from bindata/operator, such as
+// the operator Deployment.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openshift/cluster-network-operator/pkg/render"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	defaultNamespace = "openshift-dpu-network-operator"
+	clusterRoleName  = "dpu-network-operator"
+	// namespacedRoleName is bound by the operator in the namespaces of the
+	// OVNKubeConfigs
+	namespacedRoleName = "dpu-network-operator-namespaced"
+)
+
+// profile holds the settings of an infra cluster flavor.
+type profile struct {
+	name string
+	// platform is passed to the --platform flag of the operator
+	platform string
+	// controlPlaneLabel selects the nodes running the operator, if set
+	controlPlaneLabel string
+	leaderElect       bool
+	// needsOvnkubeImage is set when the infra cluster doesn't run an
+	// ovnkube-node DaemonSet the image can be taken from
+	needsOvnkubeImage bool
+	// serviceCA is set when the OpenShift service CA can issue the serving
+	// certificate of the admission webhook, the default provider then
+	serviceCA bool
+}
+
+const (
+	certProviderServiceCA   = "service-ca"
+	certProviderCertManager = "cert-manager"
+)
+
+// webhookCertProvider returns the issuer of the serving certificate of the
+// admission webhook in the profile: the one asked for, else the service CA
+// when available, else cert-manager.
+func (p profile) webhookCertProvider(requested string) string {
+	if requested != "" {
+		return requested
+	}
+	if p.serviceCA {
+		return certProviderServiceCA
+	}
+	return certProviderCertManager
+}
+
+var profiles = []profile{
+	{name: "openshift", platform: "openshift", controlPlaneLabel: "node-role.kubernetes.io/master", leaderElect: true, serviceCA: true},
+	{name: "kubernetes", platform: "kubernetes", controlPlaneLabel: "node-role.kubernetes.io/control-plane", leaderElect: true, needsOvnkubeImage: true},
+	// single node, with SCCs but without the machine-config-operator
+	{name: "microshift", platform: "auto", needsOvnkubeImage: true, serviceCA: true},
+}
+
+type options struct {
+	output         string
+	bindata        string
+	crds           string
+	role           string
+	namespacedRole string
+	namespace      string
+	image          string
+	ovnkubeImage   string
+	profiles       string
+	// nodeSelectorAdmission is the mode of the node selector admission
+	// webhook, disabled when empty
+	nodeSelectorAdmission string
+	// webhookCertProvider issues the serving certificate of the webhook,
+	// the default one of each profile when empty
+	webhookCertProvider string
+}
+
+func main() {
+	opts := options{}
+	flag.StringVar(&opts.output, "output", "", "Directory the base and the overlays are written to.")
+	flag.StringVar(&opts.bindata, "bindata", "./bindata/operator", "Directory of the operator manifest templates.")
+	flag.StringVar(&opts.crds, "crds", "./config/crd/bases", "Directory of the generated CRDs.")
+	flag.StringVar(&opts.role, "role", "./config/rbac/role.yaml", "ClusterRole generated from the RBAC markers.")
+	flag.StringVar(&opts.namespacedRole, "namespaced-role", "./config/rbac/namespaced_role.yaml", "ClusterRole bound by the operator in the namespaces of the OVNKubeConfigs.")
+	flag.StringVar(&opts.namespace, "namespace", defaultNamespace, "Namespace the operator is installed in.")
+	flag.StringVar(&opts.image, "image", "controller:latest", "Image of the operator.")
+	flag.StringVar(&opts.ovnkubeImage, "ovnkube-image", "", "ovnkube image rendered on the DPUs, required by the profiles without OVN-Kubernetes in the infra cluster.")
+	flag.StringVar(&opts.profiles, "profiles", "openshift,kubernetes,microshift", "Comma separated list of the overlays to render.")
+	flag.StringVar(&opts.nodeSelectorAdmission, "node-selector-admission", "", "Mode of the node selector admission webhook, warn or reject, disabled when empty.")
+	flag.StringVar(&opts.webhookCertProvider, "webhook-cert-provider", "", "Issuer of the serving certificate of the admission webhook, service-ca or cert-manager, the service CA when the profile has it and cert-manager otherwise when empty.")
+	flag.Parse()
+
+	if err := run(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(opts options) error {
+	if opts.output == "" {
+		return fmt.Errorf("--output is required")
+	}
+	switch opts.nodeSelectorAdmission {
+	case "", "warn", "reject":
+	default:
+		return fmt.Errorf("unknown --node-selector-admission mode %q", opts.nodeSelectorAdmission)
+	}
+	switch opts.webhookCertProvider {
+	case "", certProviderServiceCA, certProviderCertManager:
+	default:
+		return fmt.Errorf("unknown --webhook-cert-provider %q", opts.webhookCertProvider)
+	}
+	selected := []profile{}
+	for _, name := range strings.Split(opts.profiles, ",") {
+		p, ok := findProfile(name)
+		if !ok {
+			return fmt.Errorf("unknown profile %q", name)
+		}
+		if p.needsOvnkubeImage && opts.ovnkubeImage == "" {
+			return fmt.Errorf("--ovnkube-image is required by the %s profile", p.name)
+		}
+		if p.webhookCertProvider(opts.webhookCertProvider) == certProviderServiceCA && !p.serviceCA {
+			return fmt.Errorf("--webhook-cert-provider=%s needs the OpenShift service CA, not available in the %s profile", certProviderServiceCA, p.name)
+		}
+		selected = append(selected, p)
+	}
+
+	if err := writeBase(opts); err != nil {
+		return err
+	}
+	for _, p := range selected {
+		if err := writeOverlay(opts, p); err != nil {
+			return fmt.Errorf("failed to render the %s overlay: %v", p.name, err)
+		}
+	}
+	return nil
+}
+
+func findProfile(name string) (profile, bool) {
+	for _, p := range profiles {
+		if p.name == name {
+			return p, true
+		}
+	}
+	return profile{}, false
+}
+
+// writeBase writes the objects shared by every profile: the CRDs, the
+// ClusterRole generated by controller-gen and the namespaced ClusterRole.
+func writeBase(opts options) error {
+	dir := filepath.Join(opts.output, "base")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	resources := []string{}
+	crds, err := filepath.Glob(filepath.Join(opts.crds, "*.yaml"))
+	if err != nil {
+		return err
+	}
+	for _, path := range crds {
+		obj, err := readObject(path)
+		if err != nil {
+			return err
+		}
+		name, err := writeObject(dir, obj)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, name)
+	}
+
+	for _, r := range []struct{ path, name string }{{opts.role, clusterRoleName}, {opts.namespacedRole, namespacedRoleName}} {
+		role, err := readObject(r.path)
+		if err != nil {
+			return err
+		}
+		role.SetName(r.name)
+		unstructured.RemoveNestedField(role.Object, "metadata", "creationTimestamp")
+		name, err := writeObject(dir, role)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, name)
+	}
+	return writeKustomization(dir, resources)
+}
+
+// writeOverlay renders bindata/operator for the profile, on top of the base.
+func writeOverlay(opts options, p profile) error {
+	dir := filepath.Join(opts.output, "overlays", p.name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data := render.MakeRenderData()
+	data.Data["Namespace"] = opts.namespace
+	data.Data["Image"] = opts.image
+	data.Data["OvnkubeImage"] = opts.ovnkubeImage
+	data.Data["Platform"] = p.platform
+	data.Data["ControlPlaneLabel"] = p.controlPlaneLabel
+	data.Data["LeaderElect"] = p.leaderElect
+	data.Data["NodeSelectorAdmission"] = opts.nodeSelectorAdmission
+	data.Data["WebhookCertProvider"] = p.webhookCertProvider(opts.webhookCertProvider)
+	objs, err := render.RenderDir(opts.bindata, &data)
+	if err != nil {
+		return err
+	}
+	resources := []string{"../../base"}
+	for _, obj := range objs {
+		name, err := writeObject(dir, obj)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, name)
+	}
+	return writeKustomization(dir, resources)
+}
+
+func readObject(path string) (*unstructured.Unstructured, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal(b, &obj.Object); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	return obj, nil
+}
+
+// writeObject writes obj to a file named after its kind and name, and
+// returns the file name.
+func writeObject(dir string, obj *unstructured.Unstructured) (string, error) {
+	b, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return "", err
+	}
+	name := strings.ToLower(obj.GetKind()) + "_" + obj.GetName() + ".yaml"
+	return name, os.WriteFile(filepath.Join(dir, name), append([]byte("---\n"), b...), 0644)
+}
+
+func writeKustomization(dir string, resources []string) error {
+	b, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "kustomize.config.k8s.io/v1beta1",
+		"kind":       "Kustomization",
+		"resources":  resources,
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "kustomization.yaml"), b, 0644)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/yaml"
+)
+
+// readDeployment returns the Deployment of a multi-document manifest.
+func readDeployment(t *testing.T, path string) *appsv1.Deployment {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, doc := range strings.Split(string(b), "\n---\n") {
+		ds := &appsv1.Deployment{}
+		if err := yaml.Unmarshal([]byte(doc), ds); err != nil {
+			t.Fatalf("failed to parse %s: %v", path, err)
+		}
+		if ds.Kind == "Deployment" {
+			return ds
+		}
+	}
+	t.Fatalf("no Deployment in %s", path)
+	return nil
+}
+
+// TestDeploymentMatchesKustomize checks that the operator Deployment of the
+// openshift overlay doesn't drift from the one of config/manager, which is
+// installed by make deploy and the bundle. Only the fields set per install
+// method differ: the names, the image, the args and the probe port, which
+// config/default sets with --health-probe-bind-address.
+func TestDeploymentMatchesKustomize(t *testing.T) {
+	output := t.TempDir()
+	opts := options{
+		output:         output,
+		bindata:        "../../bindata/operator",
+		crds:           "../../config/crd/bases",
+		role:           "../../config/rbac/role.yaml",
+		namespacedRole: "../../config/rbac/namespaced_role.yaml",
+		namespace:      defaultNamespace,
+		image:          "controller:latest",
+		profiles:       "openshift",
+	}
+	if err := run(opts); err != nil {
+		t.Fatal(err)
+	}
+	rendered := readDeployment(t, filepath.Join(output, "overlays", "openshift", "deployment_dpu-network-operator.yaml"))
+	kustomize := readDeployment(t, "../../config/manager/manager.yaml")
+
+	want := kustomize.Spec.DeepCopy()
+	got := rendered.Spec.DeepCopy()
+	for _, spec := range []*appsv1.DeploymentSpec{want, got} {
+		pod := &spec.Template.Spec
+		pod.ServiceAccountName = ""
+		if len(pod.Containers) == 0 {
+			t.Fatal("the Deployment has no container")
+		}
+		c := &pod.Containers[0]
+		c.Image = ""
+		c.Args = nil
+		if c.LivenessProbe != nil && c.LivenessProbe.HTTPGet != nil {
+			c.LivenessProbe.HTTPGet.Port = intstr.IntOrString{}
+		}
+		if c.ReadinessProbe != nil && c.ReadinessProbe.HTTPGet != nil {
+			c.ReadinessProbe.HTTPGet.Port = intstr.IntOrString{}
+		}
+	}
+	if !equality.Semantic.DeepEqual(want, got) {
+		wantYAML, _ := yaml.Marshal(want)
+		gotYAML, _ := yaml.Marshal(got)
+		t.Errorf("bindata/operator/deployment.yaml drifted from config/manager/manager.yaml:\nconfig/manager:\n%s\nbindata/operator:\n%s", wantYAML, gotYAML)
+	}
+}
@@ -0,0 +1,413 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// gen-tenant-kubeconfig creates a dedicated ServiceAccount in the tenant
+// cluster that is only allowed to read the objects the operator syncs, and
+// prints a Secret holding a kubeconfig for it, ready to be applied to the
+// infra cluster.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+const (
+	defaultName        = "dpu-network-operator-tenant"
+	tokenSecretSuffix  = "-token"
+	tokenTimeout       = 60 * time.Second
+	tokenRetryInterval = 2 * time.Second
+)
+
+type options struct {
+	kubeconfig            string
+	tenantNamespace       string
+	name                  string
+	secretName            string
+	secretNamespace       string
+	ipsec                 bool
+	verification          bool
+	verificationNamespace string
+	devicePlugin          bool
+	devicePluginNamespace string
+	versionSkew           bool
+	networkType           bool
+	detectNamespace       bool
+}
+
+func main() {
+	opts := options{}
+	flag.StringVar(&opts.kubeconfig, "kubeconfig", os.Getenv("KUBECONFIG"), "Path to an admin kubeconfig of the tenant cluster.")
+	flag.StringVar(&opts.tenantNamespace, "tenant-namespace", utils.LocalOvnkbueNamespace, "Namespace of ovn-kubernetes in the tenant cluster.")
+	flag.StringVar(&opts.name, "name", defaultName, "Name of the ServiceAccount, Role and RoleBinding created in the tenant cluster.")
+	flag.StringVar(&opts.secretName, "secret-name", "tenant-cluster-1-kubeconf", "Name of the Secret to emit for the infra cluster.")
+	flag.StringVar(&opts.secretNamespace, "secret-namespace", "", "Namespace of the Secret to emit for the infra cluster.")
+	flag.BoolVar(&opts.ipsec, "ipsec", false, "Allow the DPUs to request their OVN IPsec certificates from the tenant cluster.")
+	flag.BoolVar(&opts.verification, "verification", false, "Allow the rollout verification to run its test pods, and the deletion of the OVNKubeConfig to clean them up.")
+	flag.StringVar(&opts.verificationNamespace, "verification-namespace", "default", "Namespace of the test pods of the rollout verification, its verification.namespace.")
+	flag.BoolVar(&opts.devicePlugin, "device-plugin", false, "Allow the operator to manage the ConfigMap of the SR-IOV network device plugin, with tenant.manageDevicePlugin.")
+	flag.StringVar(&opts.devicePluginNamespace, "device-plugin-namespace", "kube-system", "Namespace of the SR-IOV network device plugin, its tenant.devicePlugin.namespace.")
+	flag.BoolVar(&opts.versionSkew, "version-skew", false, "Allow the version skew check to read the ClusterVersion of the tenant cluster.")
+	flag.BoolVar(&opts.networkType, "network-type", false, "Allow the operator to check the network plugin of the tenant cluster in its Network config.")
+	flag.BoolVar(&opts.detectNamespace, "detect-namespace", false, "Allow the operator to detect the namespace of ovn-kubernetes, when tenant.ovnNamespace is not set.")
+	flag.Parse()
+
+	if err := run(context.Background(), opts); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, opts options) error {
+	restConfig, err := clientcmd.BuildConfigFromFlags("", opts.kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to load tenant kubeconfig: %v", err)
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create tenant client: %v", err)
+	}
+
+	if err := ensureRBAC(ctx, clientset, opts); err != nil {
+		return err
+	}
+	token, ca, err := waitForToken(ctx, clientset, opts)
+	if err != nil {
+		return err
+	}
+
+	kubeconfig, err := buildKubeconfig(restConfig, opts.name, token, ca)
+	if err != nil {
+		return err
+	}
+
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      opts.secretName,
+			Namespace: opts.secretNamespace,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{"config": kubeconfig},
+	}
+	out, err := yaml.Marshal(secret)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(out))
+	return nil
+}
+
+// tenantRules returns the minimal set of permissions the operator needs in the
+// tenant namespace: the syncer watches the ovn ConfigMaps and Secrets, and the
+// reconciler lists and watches the ovnkube-master pods to discover the OVN DB
+// addresses.
+func tenantRules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		{
+			APIGroups:     []string{""},
+			Resources:     []string{"configmaps"},
+			ResourceNames: []string{utils.CmNameOvnCa, utils.CmNameOvnkubeConfig, utils.CmNameSignerCa},
+			// the syncer lists and watches each object with a field
+			// selector on its name, which resourceNames can restrict
+			Verbs: []string{"get", "list", "watch"},
+		},
+		{
+			APIGroups:     []string{""},
+			Resources:     []string{"secrets"},
+			ResourceNames: []string{utils.SecretNameOvnCert},
+			Verbs:         []string{"get", "list", "watch"},
+		},
+		{
+			APIGroups: []string{""},
+			Resources: []string{"pods"},
+			Verbs:     []string{"list", "watch"},
+		},
+	}
+}
+
+// ipsecRules returns the cluster-scoped permissions the ovn-keys init
+// container of the DPUs needs to get its IPsec certificate signed.
+func ipsecRules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{"certificates.k8s.io"},
+			Resources: []string{"certificatesigningrequests"},
+			Verbs:     []string{"create", "get", "list", "watch"},
+		},
+	}
+}
+
+// verificationRules returns the permissions the rollout verification needs
+// to run its test pods and read their logs, and the deletion of an
+// OVNKubeConfig to list and delete the leftover ones.
+func verificationRules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{""},
+			Resources: []string{"pods"},
+			Verbs:     []string{"create", "get", "list", "delete"},
+		},
+		{
+			APIGroups: []string{""},
+			Resources: []string{"pods/log"},
+			Verbs:     []string{"get"},
+		},
+	}
+}
+
+// devicePluginRules returns the permissions the operator needs to manage the
+// ConfigMap of the SR-IOV network device plugin and restart its pods, and
+// the deletion of an OVNKubeConfig to list and delete the ConfigMap.
+func devicePluginRules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		{
+			APIGroups:     []string{""},
+			Resources:     []string{"configmaps"},
+			ResourceNames: []string{utils.CmNameSriovDevicePlugin},
+			Verbs:         []string{"get", "update", "delete"},
+		},
+		{
+			// create can't be restricted by resourceNames, and the cleanup
+			// lists the ConfigMaps by label
+			APIGroups: []string{""},
+			Resources: []string{"configmaps"},
+			Verbs:     []string{"create", "list"},
+		},
+		{
+			APIGroups: []string{""},
+			Resources: []string{"pods"},
+			Verbs:     []string{"deletecollection"},
+		},
+	}
+}
+
+// clusterConfigRule returns the permission to get the named cluster-scoped
+// config.openshift.io object.
+func clusterConfigRule(resource, name string) rbacv1.PolicyRule {
+	return rbacv1.PolicyRule{
+		APIGroups:     []string{"config.openshift.io"},
+		Resources:     []string{resource},
+		ResourceNames: []string{name},
+		Verbs:         []string{"get"},
+	}
+}
+
+// namespaceDetectionRules returns the permission to look the ovnkube-config
+// ConfigMap up in every namespace, the candidate namespaces of
+// ovn-kubernetes included.
+func namespaceDetectionRules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		{
+			APIGroups:     []string{""},
+			Resources:     []string{"configmaps"},
+			ResourceNames: []string{utils.CmNameOvnkubeConfig},
+			Verbs:         []string{"get"},
+		},
+	}
+}
+
+// tenantGrants returns the rules granted to the ServiceAccount for the
+// features enabled in opts, by namespace, "" holding the cluster-scoped
+// ones.
+func tenantGrants(opts options) map[string][]rbacv1.PolicyRule {
+	grants := map[string][]rbacv1.PolicyRule{opts.tenantNamespace: tenantRules()}
+	if opts.verification {
+		grants[opts.verificationNamespace] = append(grants[opts.verificationNamespace], verificationRules()...)
+	}
+	if opts.devicePlugin {
+		grants[opts.devicePluginNamespace] = append(grants[opts.devicePluginNamespace], devicePluginRules()...)
+	}
+	if opts.ipsec {
+		grants[""] = append(grants[""], ipsecRules()...)
+	}
+	if opts.versionSkew {
+		grants[""] = append(grants[""], clusterConfigRule("clusterversions", "version"))
+	}
+	if opts.networkType {
+		grants[""] = append(grants[""], clusterConfigRule("networks", "cluster"))
+	}
+	if opts.detectNamespace {
+		grants[""] = append(grants[""], namespaceDetectionRules()...)
+	}
+	return grants
+}
+
+func ensureRBAC(ctx context.Context, clientset kubernetes.Interface, opts options) error {
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: opts.name, Namespace: opts.tenantNamespace},
+	}
+	_, err := clientset.CoreV1().ServiceAccounts(opts.tenantNamespace).Create(ctx, sa, metav1.CreateOptions{})
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("couldn't create ServiceAccount: %v", err)
+	}
+
+	subjects := []rbacv1.Subject{{
+		Kind:      rbacv1.ServiceAccountKind,
+		Name:      opts.name,
+		Namespace: opts.tenantNamespace,
+	}}
+	grants := tenantGrants(opts)
+	namespaces := []string{}
+	for namespace := range grants {
+		if namespace != "" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		if err := ensureRole(ctx, clientset, opts.name, namespace, grants[namespace], subjects); err != nil {
+			return err
+		}
+	}
+	if rules := grants[""]; len(rules) > 0 {
+		if err := ensureClusterRole(ctx, clientset, opts.name, rules, subjects); err != nil {
+			return err
+		}
+	}
+
+	tokenSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        opts.name + tokenSecretSuffix,
+			Namespace:   opts.tenantNamespace,
+			Annotations: map[string]string{corev1.ServiceAccountNameKey: opts.name},
+		},
+		Type: corev1.SecretTypeServiceAccountToken,
+	}
+	_, err = clientset.CoreV1().Secrets(opts.tenantNamespace).Create(ctx, tokenSecret, metav1.CreateOptions{})
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("couldn't create token Secret: %v", err)
+	}
+	return nil
+}
+
+// ensureRole grants the rules in namespace to the subjects with a Role and
+// a RoleBinding.
+func ensureRole(ctx context.Context, clientset kubernetes.Interface, name, namespace string, rules []rbacv1.PolicyRule, subjects []rbacv1.Subject) error {
+	role := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Rules:      rules,
+	}
+	_, err := clientset.RbacV1().Roles(namespace).Create(ctx, role, metav1.CreateOptions{})
+	if errors.IsAlreadyExists(err) {
+		_, err = clientset.RbacV1().Roles(namespace).Update(ctx, role, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't create Role in namespace %s: %v", namespace, err)
+	}
+
+	binding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     name,
+		},
+		Subjects: subjects,
+	}
+	_, err = clientset.RbacV1().RoleBindings(namespace).Create(ctx, binding, metav1.CreateOptions{})
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("couldn't create RoleBinding in namespace %s: %v", namespace, err)
+	}
+	return nil
+}
+
+// ensureClusterRole grants the cluster-scoped rules to the subjects with a
+// ClusterRole and a ClusterRoleBinding.
+func ensureClusterRole(ctx context.Context, clientset kubernetes.Interface, name string, rules []rbacv1.PolicyRule, subjects []rbacv1.Subject) error {
+	clusterRole := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Rules:      rules,
+	}
+	_, err := clientset.RbacV1().ClusterRoles().Create(ctx, clusterRole, metav1.CreateOptions{})
+	if errors.IsAlreadyExists(err) {
+		_, err = clientset.RbacV1().ClusterRoles().Update(ctx, clusterRole, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't create ClusterRole: %v", err)
+	}
+	clusterBinding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     name,
+		},
+		Subjects: subjects,
+	}
+	_, err = clientset.RbacV1().ClusterRoleBindings().Create(ctx, clusterBinding, metav1.CreateOptions{})
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("couldn't create ClusterRoleBinding: %v", err)
+	}
+	return nil
+}
+
+// waitForToken waits for the token controller to populate the long-lived
+// ServiceAccount token Secret.
+func waitForToken(ctx context.Context, clientset kubernetes.Interface, opts options) ([]byte, []byte, error) {
+	var token, ca []byte
+	err := wait.PollImmediate(tokenRetryInterval, tokenTimeout, func() (bool, error) {
+		s, err := clientset.CoreV1().Secrets(opts.tenantNamespace).Get(ctx, opts.name+tokenSecretSuffix, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		token = s.Data[corev1.ServiceAccountTokenKey]
+		ca = s.Data[corev1.ServiceAccountRootCAKey]
+		return len(token) > 0, nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("token for ServiceAccount %s was never populated: %v", opts.name, err)
+	}
+	return token, ca, nil
+}
+
+func buildKubeconfig(restConfig *rest.Config, user string, token, ca []byte) ([]byte, error) {
+	if len(ca) == 0 {
+		ca = restConfig.CAData
+	}
+	const contextName = "tenant"
+	config := clientcmdapi.NewConfig()
+	config.Clusters[contextName] = &clientcmdapi.Cluster{
+		Server:                   restConfig.Host,
+		CertificateAuthorityData: ca,
+	}
+	config.AuthInfos[user] = &clientcmdapi.AuthInfo{
+		Token: string(token),
+	}
+	config.Contexts[contextName] = &clientcmdapi.Context{
+		Cluster:  contextName,
+		AuthInfo: user,
+	}
+	config.CurrentContext = contextName
+	return clientcmd.Write(*config)
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// allows reports whether one of the rules allows the verb on the resource
+// of the API group.
+func allows(rules []rbacv1.PolicyRule, group, resource, verb string) bool {
+	for _, rule := range rules {
+		if contains(rule.APIGroups, group) && contains(rule.Resources, resource) && contains(rule.Verbs, verb) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestTenantGrants(t *testing.T) {
+	base := options{tenantNamespace: "openshift-ovn-kubernetes", verificationNamespace: "default", devicePluginNamespace: "kube-system"}
+	type access struct {
+		namespace, group, resource, verb string
+	}
+	for _, tc := range []struct {
+		name           string
+		enable         func(*options)
+		wantNamespaces []string
+		want           []access
+	}{
+		{name: "syncer only", enable: func(*options) {},
+			wantNamespaces: []string{"openshift-ovn-kubernetes"},
+			want:           []access{{"openshift-ovn-kubernetes", "", "configmaps", "watch"}, {"openshift-ovn-kubernetes", "", "pods", "list"}}},
+		{name: "verification", enable: func(o *options) { o.verification = true },
+			wantNamespaces: []string{"default", "openshift-ovn-kubernetes"},
+			want:           []access{{"default", "", "pods", "create"}, {"default", "", "pods", "delete"}, {"default", "", "pods/log", "get"}}},
+		{name: "verification in the namespace of ovn-kubernetes", enable: func(o *options) {
+			o.verification = true
+			o.verificationNamespace = "openshift-ovn-kubernetes"
+		},
+			wantNamespaces: []string{"openshift-ovn-kubernetes"},
+			want:           []access{{"openshift-ovn-kubernetes", "", "configmaps", "get"}, {"openshift-ovn-kubernetes", "", "pods", "create"}}},
+		{name: "device plugin", enable: func(o *options) { o.devicePlugin = true },
+			wantNamespaces: []string{"kube-system", "openshift-ovn-kubernetes"},
+			want: []access{{"kube-system", "", "configmaps", "create"}, {"kube-system", "", "configmaps", "update"},
+				{"kube-system", "", "configmaps", "list"}, {"kube-system", "", "pods", "deletecollection"}}},
+		{name: "cluster-scoped features", enable: func(o *options) {
+			o.ipsec, o.versionSkew, o.networkType, o.detectNamespace = true, true, true, true
+		},
+			wantNamespaces: []string{"", "openshift-ovn-kubernetes"},
+			want: []access{{"", "certificates.k8s.io", "certificatesigningrequests", "create"}, {"", "config.openshift.io", "clusterversions", "get"},
+				{"", "config.openshift.io", "networks", "get"}, {"", "", "configmaps", "get"}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := base
+			tc.enable(&opts)
+			grants := tenantGrants(opts)
+			namespaces := []string{}
+			for namespace := range grants {
+				namespaces = append(namespaces, namespace)
+			}
+			sort.Strings(namespaces)
+			if !reflect.DeepEqual(namespaces, tc.wantNamespaces) {
+				t.Errorf("rules granted in namespaces %q, want %q", namespaces, tc.wantNamespaces)
+			}
+			for _, a := range tc.want {
+				if !allows(grants[a.namespace], a.group, a.resource, a.verb) {
+					t.Errorf("%s %s.%s is not allowed in namespace %q", a.verb, a.resource, a.group, a.namespace)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// render prints the objects the operator creates in the infra cluster for
+// an OVNKubeConfig, without connecting to a cluster, so the MachineConfigs
+// of the DPU nodes can be baked into the installation manifests of an
+// air-gapped cluster. It can also write the MachineConfigs into the
+// manifests directory of openshift-install, or print the switchdev
+// configuration as an Ignition config for the DPU nodes joining at install
+// time.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/controllers"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+type options struct {
+	config       string
+	platform     string
+	ovnkubeImage string
+	ipsec        bool
+	ignition     bool
+	installDir   string
+}
+
+func main() {
+	opts := options{}
+	flag.StringVar(&opts.config, "config", "", "OVNKubeConfig to render, - to read it from stdin.")
+	flag.StringVar(&opts.platform, "platform", "openshift", "Platform of the infra cluster, openshift or kubernetes.")
+	flag.StringVar(&opts.ovnkubeImage, "ovnkube-image", os.Getenv("OVNKUBE_IMAGE"), "ovnkube image, unless set in the OVNKubeConfig.")
+	flag.BoolVar(&opts.ipsec, "ipsec", false, "Whether OVN IPsec is enabled in the tenant cluster.")
+	flag.BoolVar(&opts.ignition, "ignition", false, "Print the switchdev configuration as an Ignition config instead of the manifests.")
+	flag.StringVar(&opts.installDir, "install-dir", "", "Write the MachineConfigPool and the MachineConfig into the openshift manifests directory of this openshift-install directory, instead of printing the manifests.")
+	flag.Parse()
+
+	if err := run(opts, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(opts options, out io.Writer) error {
+	if opts.config == "" {
+		return fmt.Errorf("--config is required")
+	}
+	if opts.ignition && opts.installDir != "" {
+		return fmt.Errorf("--ignition and --install-dir are mutually exclusive")
+	}
+	if opts.platform == "auto" {
+		return fmt.Errorf("the platform can't be detected offline, --platform must be openshift or kubernetes")
+	}
+	platform, err := utils.GetPlatform(opts.platform, nil)
+	if err != nil {
+		return err
+	}
+	cfg, err := readConfig(opts.config)
+	if err != nil {
+		return err
+	}
+	renderOpts := controllers.OfflineRenderOptions{
+		Platform:     platform,
+		OvnkubeImage: opts.ovnkubeImage,
+		IPsec:        opts.ipsec,
+	}
+	if opts.ignition {
+		b, err := controllers.RenderIgnition(cfg, renderOpts)
+		if err != nil {
+			return fmt.Errorf("failed to render OVNKubeConfig %s/%s: %v", cfg.Namespace, cfg.Name, err)
+		}
+		_, err = out.Write(append(b, '\n'))
+		return err
+	}
+	if opts.installDir != "" {
+		// the ovnkube-node objects are left to the operator
+		objs, err := controllers.RenderOfflineMachineConfigs(cfg, renderOpts)
+		if err != nil {
+			return fmt.Errorf("failed to render OVNKubeConfig %s/%s: %v", cfg.Namespace, cfg.Name, err)
+		}
+		if len(objs) == 0 {
+			return fmt.Errorf("the MachineConfigs of OVNKubeConfig %s/%s are not managed by the operator on the %s platform", cfg.Namespace, cfg.Name, opts.platform)
+		}
+		return writeInstallManifests(opts.installDir, objs)
+	}
+	objs, err := controllers.RenderOffline(context.Background(), cfg, renderOpts)
+	if err != nil {
+		return fmt.Errorf("failed to render OVNKubeConfig %s/%s: %v", cfg.Namespace, cfg.Name, err)
+	}
+	for _, obj := range objs {
+		b, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return err
+		}
+		if _, err := out.Write(append([]byte("---\n"), b...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeInstallManifests writes objs into the openshift directory of an
+// openshift-install directory, whose manifests are applied with the
+// cluster, before the workers boot.
+func writeInstallManifests(dir string, objs []*unstructured.Unstructured) error {
+	dir = filepath.Join(dir, "openshift")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		b, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return err
+		}
+		name := "99_dpu_" + strings.ToLower(obj.GetKind()) + "_" + obj.GetName() + ".yaml"
+		if err := os.WriteFile(filepath.Join(dir, name), b, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readConfig decodes the OVNKubeConfig of path strictly, so a misspelled
+// field isn't silently left out of the rendered objects.
+func readConfig(path string) (*dpuv1alpha1.OVNKubeConfig, error) {
+	var b []byte
+	var err error
+	if path == "-" {
+		b, err = io.ReadAll(os.Stdin)
+	} else {
+		b, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	cfg := &dpuv1alpha1.OVNKubeConfig{}
+	if err := yaml.UnmarshalStrict(b, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	if cfg.Kind != "OVNKubeConfig" {
+		return nil, fmt.Errorf("%s holds a %q, not an OVNKubeConfig", path, cfg.Kind)
+	}
+	return cfg, nil
+}
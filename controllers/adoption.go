@@ -0,0 +1,100 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+// adoptable reports whether obj, created by hand, is annotated to be taken
+// over by the operator.
+func adoptable(obj metav1.Object) bool {
+	return obj.GetAnnotations()[utils.AdoptAnnotation] == "true"
+}
+
+// checkConfigMapWriter refuses to overwrite found, a live ConfigMap of
+// ovnkube-node, when it is managed by another controller than the operator
+// acting for owner, e.g. a network operator writing ovnkube-config in the
+// same namespace, see utils.ForeignManager. The operator is the single
+// writer of these ConfigMaps.
+func checkConfigMapWriter(found metav1.Object, owner types.UID) error {
+	manager := utils.ForeignManager(found, owner)
+	if manager == "" {
+		return nil
+	}
+	if metav1.GetControllerOf(found) != nil {
+		return dpuerrors.Conflict(fmt.Errorf("ConfigMap %s/%s is managed by %s", found.GetNamespace(), found.GetName(), manager))
+	}
+	return dpuerrors.Conflict(fmt.Errorf("ConfigMap %s/%s is managed by %s, annotate it with %s=true to take it over",
+		found.GetNamespace(), found.GetName(), manager, utils.AdoptAnnotation))
+}
+
+// markManaged annotates obj as written by the operator.
+func markManaged(obj metav1.Object) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[utils.ManagedByAnnotation] = utils.ManagedByOperator
+	obj.SetAnnotations(annotations)
+}
+
+// adoptDaemonSets takes over the DaemonSets of the namespace of cfg created
+// by hand and annotated for adoption, e.g. the ovnkube-node DaemonSet of a
+// brownfield install. They are then rolled to the rendered DaemonSets like
+// the ones of a previous revision, within the maintenance window. A
+// DaemonSet of the rendered name created by hand and not annotated is
+// reported, rather than overwritten.
+func (r *OVNKubeConfigReconciler) adoptDaemonSets(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) error {
+	list := &appsv1.DaemonSetList{}
+	if err := r.List(ctx, list, client.InNamespace(cfg.Namespace)); err != nil {
+		return err
+	}
+	for i := range list.Items {
+		ds := &list.Items[i]
+		if metav1.GetControllerOf(ds) != nil {
+			continue
+		}
+		if !adoptable(ds) {
+			if isOvnkubeNodeDaemonSet(ds.Name) {
+				return dpuerrors.Conflict(fmt.Errorf("DaemonSet %s was not created by the operator, annotate it with %s=true to take it over", ds.Name, utils.AdoptAnnotation))
+			}
+			continue
+		}
+		logger.Info("Adopt DaemonSet", "name", ds.Name)
+		err := retryUpdate(ctx, r.Client, ds, func() {
+			if metav1.GetControllerOf(ds) == nil {
+				_ = ctrl.SetControllerReference(cfg, ds, r.Scheme)
+			}
+		})
+		if err != nil {
+			return conflictError(fmt.Errorf("couldn't adopt DaemonSet %s: %w", ds.Name, err))
+		}
+	}
+	return nil
+}
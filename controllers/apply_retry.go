@@ -0,0 +1,122 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	applyRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dpu_network_operator_apply_retries_total",
+		Help: "Number of writes to the API server retried after a conflict or throttling.",
+	}, []string{"kind", "reason"})
+	applyFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dpu_network_operator_apply_failures_total",
+		Help: "Number of writes to the API server which failed, after the retries.",
+	}, []string{"kind", "reason"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(applyRetries, applyFailures)
+}
+
+// applyBackoff bounds the retries of a write to about 3s, so a contended
+// object doesn't stall the reconcile for long.
+var applyBackoff = wait.Backoff{
+	Steps:    5,
+	Duration: 200 * time.Millisecond,
+	Factor:   2,
+	Jitter:   0.1,
+}
+
+// withApplyRetry runs the write fn, retrying it with a bounded backoff on
+// conflicts and throttling, and records the retries and the failures of the
+// kind. After a conflict, fn must apply over the latest version of the object.
+func withApplyRetry(ctx context.Context, kind string, fn func() error) error {
+	backoff := applyBackoff
+	for {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		reason := applyErrorReason(err)
+		if reason == "other" || backoff.Steps <= 1 {
+			applyFailures.WithLabelValues(kind, reason).Inc()
+			return err
+		}
+		applyRetries.WithLabelValues(kind, reason).Inc()
+		select {
+		case <-ctx.Done():
+			applyFailures.WithLabelValues(kind, reason).Inc()
+			return err
+		case <-time.After(backoff.Step()):
+		}
+	}
+}
+
+// retryUpdate applies mutate to obj and updates it, reading the latest
+// version of obj before retrying a conflicting update.
+func retryUpdate(ctx context.Context, c client.Client, obj client.Object, mutate func()) error {
+	kind := objectKind(c, obj)
+	first := true
+	return withApplyRetry(ctx, kind, func() error {
+		if !first {
+			if err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+				return err
+			}
+		}
+		first = false
+		mutate()
+		return c.Update(ctx, obj)
+	})
+}
+
+// retryCreate creates obj, retrying on throttling.
+func retryCreate(ctx context.Context, c client.Client, obj client.Object) error {
+	return withApplyRetry(ctx, objectKind(c, obj), func() error {
+		return c.Create(ctx, obj)
+	})
+}
+
+func objectKind(c client.Client, obj client.Object) string {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return "unknown"
+	}
+	return gvk.Kind
+}
+
+func applyErrorReason(err error) string {
+	switch {
+	case errors.IsConflict(err):
+		return "conflict"
+	case errors.IsTooManyRequests(err):
+		return "throttled"
+	case errors.IsServerTimeout(err) || errors.IsTimeout(err):
+		return "timeout"
+	}
+	return "other"
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/apply"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var applyRollbacks = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "dpu_network_operator_apply_rollbacks_total",
+	Help: "Number of objects rolled back after a failed apply pass, by kind and result.",
+}, []string{"kind", "result"})
+
+func init() {
+	metrics.Registry.MustRegister(applyRollbacks)
+}
+
+// appliedObject is an object changed by an apply pass, with its version
+// before the pass, nil if the pass created it.
+type appliedObject struct {
+	obj   *unstructured.Unstructured
+	prior *unstructured.Unstructured
+}
+
+// applyTransaction applies the objects of a pass, recording the ones it
+// changes, so they can be rolled back when a later object fails to apply
+// and the pass doesn't leave a mix of old and new objects.
+type applyTransaction struct {
+	c       client.Client
+	applied []appliedObject
+}
+
+func newApplyTransaction(c client.Client) *applyTransaction {
+	return &applyTransaction{c: c}
+}
+
+// apply applies obj with withApplyRetry, after reading its current version.
+func (t *applyTransaction) apply(ctx context.Context, obj *unstructured.Unstructured) error {
+	prior := &unstructured.Unstructured{}
+	prior.SetGroupVersionKind(obj.GroupVersionKind())
+	if err := t.c.Get(ctx, client.ObjectKeyFromObject(obj), prior); errors.IsNotFound(err) {
+		prior = nil
+	} else if err != nil {
+		return err
+	}
+	if obj.GetKind() == "ConfigMap" {
+		if prior != nil {
+			var owner types.UID
+			if ref := metav1.GetControllerOf(obj); ref != nil {
+				owner = ref.UID
+			}
+			if err := checkConfigMapWriter(prior, owner); err != nil {
+				return err
+			}
+		}
+		markManaged(obj)
+	}
+	if err := withApplyRetry(ctx, obj.GetKind(), func() error {
+		return apply.ApplyObject(ctx, t.c, obj)
+	}); err != nil {
+		return err
+	}
+	// ApplyObject sets the resourceVersion of obj to the one it wrote, or
+	// to the current one when nothing changed
+	if prior == nil || prior.GetResourceVersion() != obj.GetResourceVersion() {
+		t.applied = append(t.applied, appliedObject{obj: obj, prior: prior})
+	}
+	return nil
+}
+
+// rollback restores the objects changed by the pass in the reverse order,
+// deleting the created ones. Failures are logged and counted, the rollback
+// goes on with the other objects.
+func (t *applyTransaction) rollback(ctx context.Context) {
+	for i := len(t.applied) - 1; i >= 0; i-- {
+		a := t.applied[i]
+		kind := a.obj.GetKind()
+		var err error
+		if a.prior == nil {
+			err = client.IgnoreNotFound(t.c.Delete(ctx, a.obj))
+		} else {
+			err = t.restore(ctx, a.prior)
+		}
+		if err != nil {
+			logger.Error(err, "failed to roll back object", "kind", kind, "namespace", a.obj.GetNamespace(), "name", a.obj.GetName())
+			applyRollbacks.WithLabelValues(kind, "failed").Inc()
+			continue
+		}
+		logger.Info("Rolled back object", "kind", kind, "namespace", a.obj.GetNamespace(), "name", a.obj.GetName())
+		applyRollbacks.WithLabelValues(kind, "succeeded").Inc()
+	}
+	t.applied = nil
+}
+
+// restore writes prior over the current version of the object.
+func (t *applyTransaction) restore(ctx context.Context, prior *unstructured.Unstructured) error {
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(prior.GroupVersionKind())
+	return withApplyRetry(ctx, prior.GetKind(), func() error {
+		if err := t.c.Get(ctx, client.ObjectKeyFromObject(prior), current); err != nil {
+			return err
+		}
+		restored := prior.DeepCopy()
+		restored.SetResourceVersion(current.GetResourceVersion())
+		return t.c.Update(ctx, restored)
+	})
+}
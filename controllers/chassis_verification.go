@@ -0,0 +1,360 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+const (
+	// chassisVerificationTick is how often the OVNKubeConfigs are checked
+	// for a due verification, or for the outcome of a running one.
+	chassisVerificationTick = 1 * time.Minute
+
+	defaultChassisVerificationInterval   = 1 * time.Hour
+	defaultChassisVerificationSampleSize = 20
+	chassisVerificationTimeoutSeconds    = 120
+
+	chassisVerificationPodName = "chassis-verification"
+	chassisPortMarker          = "### port"
+
+	eventReasonChassisMisbound = "ChassisMisbound"
+)
+
+// chassisVerificationScript prints, for each "port=host" of PORTS, the
+// requested-chassis of the logical switch port in the NB DB and the
+// hostname of the chassis it is bound to in the SB DB, "-" when not set.
+const chassisVerificationScript = `
+ssl="-p /ovn-cert/tls.key -c /ovn-cert/tls.crt -C /ovn-ca/` + utils.OvnCABundleKey + `"
+for entry in ${PORTS}; do
+  port="${entry%%=*}"
+  expected="${entry#*=}"
+  requested=$(ovn-nbctl --db "${NB}" ${ssl} get logical_switch_port "${port}" options:requested-chassis 2>/dev/null | tr -d '"')
+  chassis=$(ovn-sbctl --db "${SB}" ${ssl} --bare --columns=chassis find port_binding "logical_port=${port}" 2>/dev/null)
+  bound=""
+  if [[ -n "${chassis}" ]]; then
+    bound=$(ovn-sbctl --db "${SB}" ${ssl} get chassis "${chassis}" hostname 2>/dev/null | tr -d '"')
+  fi
+  echo "` + chassisPortMarker + ` ${port} ${expected} ${requested:--} ${bound:--}"
+done
+`
+
+var misboundPorts = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "dpu_network_operator_misbound_ports",
+	Help: "Number of sampled tenant pods whose logical switch port didn't request, or wasn't bound to, the chassis of their host at the last check.",
+}, []string{"namespace"})
+
+func init() {
+	metrics.Registry.MustRegister(misboundPorts)
+}
+
+// sampledPort is a logical switch port of a tenant pod, with the host its
+// DPU serves.
+type sampledPort struct {
+	port string
+	host string
+}
+
+// runChassisVerification runs, until ctx is done, the requested-chassis
+// checks of the OVNKubeConfigs with ovn.chassisVerification set. A check
+// runs in a pod on a DPU node, whose outcome is collected at a later tick.
+// Like the drift detection, it runs as a manager Runnable, outside of the
+// reconcile loops.
+func (r *OVNKubeConfigReconciler) runChassisVerification(ctx context.Context) error {
+	ticker := time.NewTicker(chassisVerificationTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		cfgList := &dpuv1alpha1.OVNKubeConfigList{}
+		if err := r.List(ctx, cfgList); err != nil {
+			logger.Error(err, "failed to list OVNKubeConfigs for the chassis verification")
+			continue
+		}
+		for i := range cfgList.Items {
+			cfg := &cfgList.Items[i]
+			if delegated(cfg) {
+				continue
+			}
+			if err := r.verifyChassis(ctx, cfg); err != nil {
+				logger.Error(err, "failed to verify the requested-chassis", "namespace", cfg.Namespace, "name", cfg.Name)
+			}
+		}
+	}
+}
+
+// verifyChassis collects the outcome of the finished verification pod of
+// cfg, or starts a new one once the last check is older than the interval.
+// A report left over from a disabled verification is cleared.
+func (r *OVNKubeConfigReconciler) verifyChassis(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) error {
+	pod := &corev1.Pod{}
+	err := r.APIReader.Get(ctx, types.NamespacedName{Namespace: cfg.Namespace, Name: chassisVerificationPodName}, pod)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	running := err == nil
+	if cfg.Spec.Ovn.ChassisVerification == nil {
+		if running {
+			if err := r.Delete(ctx, pod); client.IgnoreNotFound(err) != nil {
+				return err
+			}
+		}
+		if cfg.Status.ChassisVerification == nil {
+			return nil
+		}
+		misboundPorts.DeleteLabelValues(cfg.Namespace)
+		return r.reportChassisVerification(ctx, cfg, nil)
+	}
+
+	if running {
+		if pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+			return nil
+		}
+		report := r.chassisVerificationOutcome(ctx, pod)
+		if err := r.Delete(ctx, pod); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		return r.reportChassisVerification(ctx, cfg, report)
+	}
+
+	if !chassisVerificationDue(cfg, time.Now()) {
+		return nil
+	}
+	pod, checked, err := r.renderChassisVerificationPod(ctx, cfg)
+	if err != nil {
+		return r.reportChassisVerification(ctx, cfg, &dpuv1alpha1.ChassisVerificationReport{LastCheckTime: metav1.Now(), Error: err.Error()})
+	}
+	if pod == nil {
+		return r.reportChassisVerification(ctx, cfg, &dpuv1alpha1.ChassisVerificationReport{LastCheckTime: metav1.Now()})
+	}
+	logger.Info("Verify the requested-chassis of the tenant pods", "namespace", cfg.Namespace, "pods", checked, "node", pod.Spec.NodeName)
+	return retryCreate(ctx, r.Client, pod)
+}
+
+// chassisVerificationDue reports whether cfg must be checked at now.
+func chassisVerificationDue(cfg *dpuv1alpha1.OVNKubeConfig, now time.Time) bool {
+	if cfg.Status.ChassisVerification == nil {
+		return true
+	}
+	interval := cfg.Spec.Ovn.ChassisVerification.Interval.Duration
+	if interval <= 0 {
+		interval = defaultChassisVerificationInterval
+	}
+	return !now.Before(cfg.Status.ChassisVerification.LastCheckTime.Add(interval))
+}
+
+// reportChassisVerification publishes the report of cfg in its status, in
+// the misbound ports metric and, when pods are misbound, in a warning
+// event. A nil report clears the status.
+func (r *OVNKubeConfigReconciler) reportChassisVerification(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, report *dpuv1alpha1.ChassisVerificationReport) error {
+	cfg.Status.ChassisVerification = report
+	if report != nil && report.Error == "" {
+		misboundPorts.WithLabelValues(cfg.Namespace).Set(float64(len(report.Misbound)))
+		if len(report.Misbound) > 0 && r.Recorder != nil {
+			r.Recorder.Eventf(cfg, corev1.EventTypeWarning, eventReasonChassisMisbound,
+				"%d of %d sampled pods are not bound to the chassis of their host: %s",
+				len(report.Misbound), report.CheckedPorts, strings.Join(report.Misbound, "; "))
+		}
+	}
+	return r.updateStatus(ctx, cfg, nil, func(dst, src *dpuv1alpha1.OVNKubeConfigStatus) {
+		dst.ChassisVerification = src.ChassisVerification
+	})
+}
+
+// chassisVerificationOutcome parses the log of the finished verification
+// pod into a report.
+func (r *OVNKubeConfigReconciler) chassisVerificationOutcome(ctx context.Context, pod *corev1.Pod) *dpuv1alpha1.ChassisVerificationReport {
+	report := &dpuv1alpha1.ChassisVerificationReport{LastCheckTime: metav1.Now()}
+	if pod.Status.Phase == corev1.PodFailed {
+		report.Error = "the verification pod failed"
+		if pod.Status.Message != "" {
+			report.Error = fmt.Sprintf("the verification pod failed: %s", pod.Status.Message)
+		}
+		return report
+	}
+	out, err := r.kubeClient.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{Container: "verify"}).DoRaw(ctx)
+	if err != nil {
+		report.Error = fmt.Sprintf("couldn't read the logs of the verification pod: %v", err)
+		return report
+	}
+	report.CheckedPorts, report.Misbound = parseChassisVerification(string(out))
+	return report
+}
+
+// parseChassisVerification returns the number of ports checked by the
+// verification script and the misbound ones.
+func parseChassisVerification(out string) (int32, []string) {
+	var checked int32
+	misbound := []string{}
+	for _, line := range strings.Split(out, "\n") {
+		cols := strings.Fields(strings.TrimPrefix(line, chassisPortMarker))
+		if !strings.HasPrefix(line, chassisPortMarker) || len(cols) != 4 {
+			continue
+		}
+		checked++
+		port, expected, requested, bound := cols[0], cols[1], cols[2], cols[3]
+		// the logical port of a pod is <namespace>_<name>
+		pod := strings.Replace(port, "_", "/", 1)
+		switch {
+		case requested == "-":
+			misbound = append(misbound, fmt.Sprintf("%s: no requested-chassis, expected %s", pod, expected))
+		case requested != expected:
+			misbound = append(misbound, fmt.Sprintf("%s: requested-chassis %s, expected %s", pod, requested, expected))
+		case bound == "-":
+			misbound = append(misbound, fmt.Sprintf("%s: not bound, expected %s", pod, expected))
+		case bound != expected:
+			misbound = append(misbound, fmt.Sprintf("%s: bound to %s, expected %s", pod, bound, expected))
+		}
+	}
+	sort.Strings(misbound)
+	return checked, misbound
+}
+
+// renderChassisVerificationPod samples the tenant pods of the hosts served
+// by the DPUs of cfg, and returns the pod checking their ports on one of
+// these DPUs with the number of sampled pods. The pod is nil when there is
+// no pod to check.
+func (r *OVNKubeConfigReconciler) renderChassisVerificationPod(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) (*corev1.Pod, int, error) {
+	if !isOvnDataPlane(cfg) {
+		return nil, 0, fmt.Errorf("the DPUs of namespace %s don't run OVN-Kubernetes", cfg.Namespace)
+	}
+	tenantClient := r.connectedTenantClient(cfg.Namespace)
+	if tenantClient == nil {
+		return nil, 0, fmt.Errorf("the tenant cluster is not connected yet")
+	}
+	dpuNodes, err := r.dpuNodesByHost(ctx, cfg.Namespace)
+	if err != nil {
+		return nil, 0, err
+	}
+	size := int(cfg.Spec.Ovn.ChassisVerification.SampleSize)
+	if size <= 0 {
+		size = defaultChassisVerificationSampleSize
+	}
+	sample, err := sampleTenantPorts(ctx, tenantClient, dpuNodes, size)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(sample) == 0 {
+		return nil, 0, nil
+	}
+	ports := make([]string, 0, len(sample))
+	for _, p := range sample {
+		ports = append(ports, p.port+"="+p.host)
+	}
+
+	image, err := r.getOvnkubeImage(ctx, cfg)
+	if err != nil {
+		return nil, 0, err
+	}
+	nbDbList, sbDbList, err := r.getOvnDbLists(ctx, cfg)
+	if err != nil {
+		return nil, 0, err
+	}
+	certMounts, certVolumes := ovnCertVolumes(cfg)
+	timeout := int64(chassisVerificationTimeoutSeconds)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      chassisVerificationPodName,
+			Namespace: cfg.Namespace,
+		},
+		Spec: corev1.PodSpec{
+			// the DBs are reachable from every DPU
+			NodeName:              dpuNodes[sample[0].host],
+			RestartPolicy:         corev1.RestartPolicyNever,
+			HostNetwork:           true,
+			ServiceAccountName:    utils.SaNameOvnkubeNode,
+			ImagePullSecrets:      cfg.Spec.ImagePullSecrets,
+			Tolerations:           []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			ActiveDeadlineSeconds: &timeout,
+			Containers: []corev1.Container{{
+				Name:    "verify",
+				Image:   image,
+				Command: []string{"/bin/bash", "-c", chassisVerificationScript},
+				Env: []corev1.EnvVar{
+					{Name: "NB", Value: nbDbList},
+					{Name: "SB", Value: sbDbList},
+					{Name: "PORTS", Value: strings.Join(ports, " ")},
+				},
+				VolumeMounts: certMounts,
+			}},
+			Volumes: certVolumes,
+		},
+	}
+	if err := ctrl.SetControllerReference(cfg, pod, r.Scheme); err != nil {
+		return nil, 0, err
+	}
+	return pod, len(sample), nil
+}
+
+// sampleTenantPorts picks up to size running pods of the OVN-Kubernetes
+// network on the hosts served by the DPUs, at random.
+func sampleTenantPorts(ctx context.Context, c client.Client, dpuNodes map[string]string, size int) ([]sampledPort, error) {
+	hosts := make([]string, 0, len(dpuNodes))
+	for host := range dpuNodes {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	candidates := []sampledPort{}
+	for _, host := range hosts {
+		pods := &corev1.PodList{}
+		fieldSelector := fields.AndSelectors(fields.OneTermEqualSelector("spec.nodeName", host),
+			fields.OneTermEqualSelector("status.phase", string(corev1.PodRunning)))
+		if err := utils.ListAll(ctx, c, pods, &client.ListOptions{FieldSelector: fieldSelector}); err != nil {
+			return nil, dpuerrors.TenantUnreachable(err)
+		}
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			if pod.Spec.HostNetwork {
+				continue
+			}
+			networks := map[string]podNetwork{}
+			if err := json.Unmarshal([]byte(pod.Annotations[podNetworksAnnotation]), &networks); err != nil || networks["default"].MACAddress == "" {
+				continue
+			}
+			candidates = append(candidates, sampledPort{port: pod.Namespace + "_" + pod.Name, host: host})
+		}
+	}
+	rand.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+	if len(candidates) > size {
+		candidates = candidates[:size]
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].port < candidates[j].port })
+	return candidates, nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+// clusterManagedKinds are the cluster-scoped kinds rendered for an
+// OVNKubeConfig, e.g. the ClusterRoles of the helper DaemonSets or the
+// SecurityContextConstraints of ovnkube-node. They are labeled with its
+// namespace since they cannot be owned by it.
+var clusterManagedKinds = []schema.GroupVersionKind{
+	rbacv1.SchemeGroupVersion.WithKind("ClusterRoleBinding"),
+	rbacv1.SchemeGroupVersion.WithKind("ClusterRole"),
+	{Group: "security.openshift.io", Version: "v1", Kind: "SecurityContextConstraints"},
+}
+
+// ownClusterObject labels the cluster-scoped obj rendered for cfg with its
+// namespace, and holds the deletion of cfg until the object is removed.
+func (r *OVNKubeConfigReconciler) ownClusterObject(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, obj *unstructured.Unstructured) error {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[utils.OwnerLabel] = cfg.Namespace
+	obj.SetLabels(labels)
+	if !controllerutil.AddFinalizer(cfg, utils.ClusterObjectsFinalizer) {
+		return nil
+	}
+	return r.Update(ctx, cfg)
+}
+
+// finalizeClusterObjects deletes the objects of the clusterManagedKinds
+// labeled with the namespace of the deleted cfg, then its finalizer. The
+// kinds unknown to the cluster, e.g. the SCCs out of OpenShift, are skipped.
+func (r *OVNKubeConfigReconciler) finalizeClusterObjects(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(cfg, utils.ClusterObjectsFinalizer) {
+		return ctrl.Result{}, nil
+	}
+	for _, gvk := range clusterManagedKinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := r.List(ctx, list, client.MatchingLabels{utils.OwnerLabel: cfg.Namespace}); err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return ctrl.Result{}, fmt.Errorf("failed to list the %s objects of namespace %s: %w", gvk.Kind, cfg.Namespace, err)
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			logger.Info("Delete cluster-scoped object", "kind", gvk.Kind, "name", obj.GetName())
+			if err := r.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to delete %s %s: %w", gvk.Kind, obj.GetName(), err)
+			}
+		}
+	}
+	controllerutil.RemoveFinalizer(cfg, utils.ClusterObjectsFinalizer)
+	return ctrl.Result{}, client.IgnoreNotFound(r.Update(ctx, cfg))
+}
@@ -0,0 +1,282 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/openshift/dpu-network-operator/api"
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+const (
+	// ClusterOperatorName is the name of the cluster-scoped ClusterOperator
+	// summarizing the health of all OVNKubeConfigs.
+	ClusterOperatorName = "dpu-network-operator"
+
+	reasonAsExpected   = "AsExpected"
+	reasonNoConfig     = "NoOVNKubeConfig"
+	reasonDegraded     = "OVNKubeConfigDegraded"
+	reasonProgressing  = "OVNKubeConfigProgressing"
+	reasonNotAvailable = "OVNKubeConfigNotAvailable"
+)
+
+// ClusterOperatorReconciler aggregates the conditions of every OVNKubeConfig
+// in the cluster into a single ClusterOperator, so cluster admins and upgrade
+// tooling can check the overall health in one place.
+type ClusterOperatorReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=config.openshift.io,resources=clusteroperators,verbs=get;list;watch;create;update;patch
+//+kubebuilder:rbac:groups=config.openshift.io,resources=clusteroperators/status,verbs=get;update;patch
+
+// Reconcile recomputes the ClusterOperator status from all OVNKubeConfigs.
+// The request is ignored since every event leads to the same aggregation.
+func (r *ClusterOperatorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("reconcile ClusterOperator", ClusterOperatorName)
+
+	cfgList := &dpuv1alpha1.OVNKubeConfigList{}
+	if err := r.List(ctx, cfgList); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	co := &configv1.ClusterOperator{}
+	err := r.Get(ctx, types.NamespacedName{Name: ClusterOperatorName}, co)
+	if errors.IsNotFound(err) {
+		co.Name = ClusterOperatorName
+		if err = retryCreate(ctx, r.Client, co); err != nil {
+			return ctrl.Result{}, fmt.Errorf("couldn't create ClusterOperator: %v", err)
+		}
+		logger.Info("Created ClusterOperator", "name", ClusterOperatorName)
+	} else if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	status := aggregateClusterOperatorStatus(cfgList.Items)
+	if version := os.Getenv("RELEASE_VERSION"); version != "" {
+		status.Versions = []configv1.OperandVersion{{Name: "operator", Version: version}}
+	}
+	for i := range status.Conditions {
+		status.Conditions[i].LastTransitionTime = metav1.Now()
+		if old := findClusterOperatorCondition(co.Status.Conditions, status.Conditions[i].Type); old != nil && old.Status == status.Conditions[i].Status {
+			status.Conditions[i].LastTransitionTime = old.LastTransitionTime
+		}
+	}
+	if equality.Semantic.DeepEqual(co.Status, status) {
+		return ctrl.Result{}, nil
+	}
+	co.Status = status
+	if err := r.Status().Update(ctx, co); err != nil {
+		return ctrl.Result{}, fmt.Errorf("couldn't update ClusterOperator status: %v", err)
+	}
+	return ctrl.Result{}, nil
+}
+
+// aggregateClusterOperatorStatus maps the conditions of the OVNKubeConfigs to
+// Available, Progressing, Degraded and Upgradeable. A config is degraded when
+// one of its conditions is False for another reason than progressing, or has
+// a version skew or failing templates. The configs which don't manage their
+// workload are left out of Available.
+func aggregateClusterOperatorStatus(cfgs []dpuv1alpha1.OVNKubeConfig) configv1.ClusterOperatorStatus {
+	var notAvailable, progressing, degraded []string
+	for _, cfg := range cfgs {
+		name := cfg.Namespace + "/" + cfg.Name
+		if !meta.IsStatusConditionTrue(cfg.Status.Conditions, api.OvnKubeReady) && managesWorkloads(&cfg) {
+			notAvailable = append(notAvailable, name)
+		}
+		for _, c := range cfg.Status.Conditions {
+			// Ready only aggregates other conditions
+			if c.Type == api.Ready || c.Status == metav1.ConditionUnknown || c.Reason == api.ReasonWorkloadsNotManaged {
+				continue
+			}
+			if abnormalTrueConditions[c.Type] {
+				if c.Status == metav1.ConditionTrue && (c.Type == api.VersionSkew || c.Type == api.RenderFailed || c.Type == api.CertInvalidForNodes || c.Type == api.ImageArchMismatch) {
+					degraded = append(degraded, fmt.Sprintf("%s: %s %s", name, c.Type, c.Message))
+				} else if c.Status == metav1.ConditionTrue {
+					progressing = append(progressing, fmt.Sprintf("%s: %s", name, c.Type))
+				}
+				continue
+			}
+			if c.Status == metav1.ConditionTrue {
+				continue
+			}
+			if c.Reason == api.ReasonProgressing || c.Reason == api.ReasonHookRunning || c.Reason == api.ReasonUpdatePending {
+				progressing = append(progressing, fmt.Sprintf("%s: %s", name, c.Type))
+			} else {
+				degraded = append(degraded, fmt.Sprintf("%s: %s %s", name, c.Type, c.Message))
+			}
+		}
+	}
+	sort.Strings(notAvailable)
+	sort.Strings(progressing)
+	sort.Strings(degraded)
+
+	status := configv1.ClusterOperatorStatus{RelatedObjects: clusterOperatorRelatedObjects(cfgs)}
+	switch {
+	case len(cfgs) == 0:
+		status.Conditions = append(status.Conditions, clusterOperatorCondition(configv1.OperatorAvailable, configv1.ConditionTrue, reasonNoConfig, "No OVNKubeConfig is defined"))
+	case len(notAvailable) > 0:
+		status.Conditions = append(status.Conditions, clusterOperatorCondition(configv1.OperatorAvailable, configv1.ConditionFalse, reasonNotAvailable, "ovnkube-node is not ready for "+strings.Join(notAvailable, ", ")))
+	default:
+		status.Conditions = append(status.Conditions, clusterOperatorCondition(configv1.OperatorAvailable, configv1.ConditionTrue, reasonAsExpected, ""))
+	}
+	if len(progressing) > 0 {
+		status.Conditions = append(status.Conditions, clusterOperatorCondition(configv1.OperatorProgressing, configv1.ConditionTrue, reasonProgressing, strings.Join(progressing, "; ")))
+	} else {
+		status.Conditions = append(status.Conditions, clusterOperatorCondition(configv1.OperatorProgressing, configv1.ConditionFalse, reasonAsExpected, ""))
+	}
+	if len(degraded) > 0 {
+		status.Conditions = append(status.Conditions,
+			clusterOperatorCondition(configv1.OperatorDegraded, configv1.ConditionTrue, reasonDegraded, strings.Join(degraded, "; ")),
+			clusterOperatorCondition(configv1.OperatorUpgradeable, configv1.ConditionFalse, reasonDegraded, "Upgrades are blocked while an OVNKubeConfig is degraded"))
+	} else {
+		status.Conditions = append(status.Conditions,
+			clusterOperatorCondition(configv1.OperatorDegraded, configv1.ConditionFalse, reasonAsExpected, ""),
+			clusterOperatorCondition(configv1.OperatorUpgradeable, configv1.ConditionTrue, reasonAsExpected, ""))
+	}
+	return status
+}
+
+// clusterOperatorRelatedObjects lists the objects managed by the operator,
+// which oc adm inspect and must-gather collect with the ClusterOperator: the
+// namespaces of the operator and of the OVNKubeConfigs, the
+// MachineConfigPools and switchdev MachineConfigs of the CRs, and every
+// object of the kinds of the operator, an empty name standing for all of
+// them. The list is sorted, so the status only changes with its content.
+func clusterOperatorRelatedObjects(cfgs []dpuv1alpha1.OVNKubeConfig) []configv1.ObjectReference {
+	group := dpuv1alpha1.GroupVersion.Group
+	refs := map[configv1.ObjectReference]bool{
+		{Group: group, Resource: "ovnkubeconfigs"}:     true,
+		{Group: group, Resource: "dpunodepools"}:       true,
+		{Group: group, Resource: "dpufleetpolicies"}:   true,
+		{Group: group, Resource: "dpuoperatorconfigs"}: true,
+		{Group: group, Resource: "dputraces"}:          true,
+		{Group: group, Resource: "dpupacketcaptures"}:  true,
+	}
+	if utils.Namespace != "" {
+		refs[configv1.ObjectReference{Resource: "namespaces", Name: utils.Namespace}] = true
+	}
+	for i := range cfgs {
+		cfg := &cfgs[i]
+		refs[configv1.ObjectReference{Resource: "namespaces", Name: cfg.Namespace}] = true
+		if poolName := cfgPoolName(cfg); poolName != "" && (cfg.Spec.ManageMachineConfig == nil || *cfg.Spec.ManageMachineConfig) {
+			refs[configv1.ObjectReference{Group: mcfgv1.GroupName, Resource: "machineconfigpools", Name: poolName}] = true
+			refs[configv1.ObjectReference{Group: mcfgv1.GroupName, Resource: "machineconfigs", Name: switchdevMachineConfigName(cfg)}] = true
+		}
+	}
+	related := make([]configv1.ObjectReference, 0, len(refs))
+	for ref := range refs {
+		related = append(related, ref)
+	}
+	sort.Slice(related, func(i, j int) bool {
+		a, b := related[i], related[j]
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		if a.Resource != b.Resource {
+			return a.Resource < b.Resource
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return related
+}
+
+// abnormalTrueConditions are the OVNKubeConfig conditions reporting a problem
+// when True. Held changes and a node selector matching no node are
+// progressing, a version skew, a certificate missing node identities or an
+// image of another architecture is degraded.
+var abnormalTrueConditions = map[string]bool{
+	api.WaitingForPreflight: true,
+	api.PendingChanges:      true,
+	api.PendingRollout:      true,
+	api.VersionSkew:         true,
+	api.RenderFailed:        true,
+	api.CertInvalidForNodes: true,
+	api.ImageArchMismatch:   true,
+	api.ZeroNodesSelected:   true,
+}
+
+func clusterOperatorCondition(t configv1.ClusterStatusConditionType, s configv1.ConditionStatus, reason, msg string) configv1.ClusterOperatorStatusCondition {
+	return configv1.ClusterOperatorStatusCondition{
+		Type:    t,
+		Status:  s,
+		Reason:  reason,
+		Message: msg,
+	}
+}
+
+func findClusterOperatorCondition(conditions []configv1.ClusterOperatorStatusCondition, t configv1.ClusterStatusConditionType) *configv1.ClusterOperatorStatusCondition {
+	for i := range conditions {
+		if conditions[i].Type == t {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager. The
+// ClusterOperator is reconciled once at start, so it is published as
+// Available before any OVNKubeConfig is created, and whenever it changes,
+// e.g. deleted by hand.
+func (r *ClusterOperatorReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("clusteroperator").
+		For(&dpuv1alpha1.OVNKubeConfig{}).
+		Watches(&source.Kind{Type: &configv1.ClusterOperator{}},
+			&handler.EnqueueRequestForObject{},
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return obj.GetName() == ClusterOperatorName
+			}))).
+		Watches(startupEvent(&configv1.ClusterOperator{ObjectMeta: metav1.ObjectMeta{Name: ClusterOperatorName}}),
+			&handler.EnqueueRequestForObject{}).
+		Complete(r)
+}
+
+// startupEvent returns a source emitting a single event of obj once the
+// controller starts.
+func startupEvent(obj client.Object) source.Source {
+	events := make(chan event.GenericEvent, 1)
+	events <- event.GenericEvent{Object: obj}
+	return &source.Channel{Source: events}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var conditionStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "dpu_network_operator_condition",
+	Help: "Status conditions of the OVNKubeConfig of the namespace: 1 when True, 0 when False, -1 when Unknown, labeled with the reason.",
+}, []string{"namespace", "type", "reason"})
+
+func init() {
+	metrics.Registry.MustRegister(conditionStatus)
+}
+
+// conditionReasons holds the reason exported for every condition type, by
+// namespace, so the series of a previous reason are deleted.
+var conditionReasons = struct {
+	sync.Mutex
+	byNamespace map[string]map[string]string
+}{byNamespace: map[string]map[string]string{}}
+
+// recordConditionMetrics exports the conditions of the OVNKubeConfig of the
+// namespace, e.g. to alert on OvnKubeReady being 0, and drops the series of
+// the removed conditions and of the previous reasons.
+func recordConditionMetrics(namespace string, conditions []metav1.Condition) {
+	conditionReasons.Lock()
+	defer conditionReasons.Unlock()
+	previous := conditionReasons.byNamespace[namespace]
+	current := map[string]string{}
+	for _, c := range conditions {
+		value := -1.0
+		switch c.Status {
+		case metav1.ConditionTrue:
+			value = 1
+		case metav1.ConditionFalse:
+			value = 0
+		}
+		conditionStatus.WithLabelValues(namespace, c.Type, c.Reason).Set(value)
+		current[c.Type] = c.Reason
+	}
+	for t, reason := range previous {
+		if r, ok := current[t]; !ok || r != reason {
+			conditionStatus.DeleteLabelValues(namespace, t, reason)
+		}
+	}
+	conditionReasons.byNamespace[namespace] = current
+}
+
+// resetConditionMetrics drops the condition series of the namespace, once
+// its OVNKubeConfig is deleted.
+func resetConditionMetrics(namespace string) {
+	conditionReasons.Lock()
+	defer conditionReasons.Unlock()
+	conditionStatus.DeletePartialMatch(prometheus.Labels{"namespace": namespace})
+	delete(conditionReasons.byNamespace, namespace)
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// daemonSetMutation changes a rendered DaemonSet before it is applied.
+type daemonSetMutation func(ds *appsv1.DaemonSet) error
+
+// mutateDaemonSet decodes the rendered DaemonSet strictly, so a field the
+// typed object doesn't know fails instead of being dropped, and runs the
+// mutations in order.
+func mutateDaemonSet(obj *unstructured.Unstructured, mutations ...daemonSetMutation) (*appsv1.DaemonSet, error) {
+	if gk := obj.GroupVersionKind().GroupKind(); gk != appsv1.SchemeGroupVersion.WithKind("DaemonSet").GroupKind() {
+		return nil, fmt.Errorf("%s %s is not a DaemonSet", gk, obj.GetName())
+	}
+	ds := &appsv1.DaemonSet{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructuredWithValidation(obj.Object, ds, true); err != nil {
+		return nil, fmt.Errorf("failed to decode DaemonSet %s: %v", obj.GetName(), err)
+	}
+	for _, mutate := range mutations {
+		if err := mutate(ds); err != nil {
+			return nil, fmt.Errorf("DaemonSet %s: %v", ds.Name, err)
+		}
+	}
+	return ds, nil
+}
+
+// mergeNodeSelector adds the labels of the selector to the node selector of
+// the pods.
+func mergeNodeSelector(selector *metav1.LabelSelector) daemonSetMutation {
+	return func(ds *appsv1.DaemonSet) error {
+		if selector == nil || len(selector.MatchLabels) == 0 {
+			return nil
+		}
+		spec := &ds.Spec.Template.Spec
+		if spec.NodeSelector == nil {
+			spec.NodeSelector = map[string]string{}
+		}
+		for k, v := range selector.MatchLabels {
+			spec.NodeSelector[k] = v
+		}
+		return nil
+	}
+}
+
+// tolerateTaints adds a toleration for each taint the pods don't tolerate
+// yet, so a template tolerating every taint is left untouched.
+func tolerateTaints(taints []corev1.Taint) daemonSetMutation {
+	return func(ds *appsv1.DaemonSet) error {
+		spec := &ds.Spec.Template.Spec
+		for i := range taints {
+			if tolerated(spec.Tolerations, &taints[i]) {
+				continue
+			}
+			spec.Tolerations = append(spec.Tolerations, corev1.Toleration{
+				Key:      taints[i].Key,
+				Operator: corev1.TolerationOpEqual,
+				Value:    taints[i].Value,
+				Effect:   taints[i].Effect,
+			})
+		}
+		return nil
+	}
+}
+
+func tolerated(tolerations []corev1.Toleration, taint *corev1.Taint) bool {
+	for i := range tolerations {
+		if tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// setEnv sets the variables in every container and init container,
+// replacing the rendered variables of the same name. They are set in the
+// order of their names, so the pod template doesn't change from a pass to
+// the next.
+func setEnv(env map[string]string) daemonSetMutation {
+	return func(ds *appsv1.DaemonSet) error {
+		names := make([]string, 0, len(env))
+		for name := range env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		forEachContainer(&ds.Spec.Template.Spec, func(c *corev1.Container) {
+			for _, name := range names {
+				setContainerEnv(c, corev1.EnvVar{Name: name, Value: env[name]})
+			}
+		})
+		return nil
+	}
+}
+
+func setContainerEnv(c *corev1.Container, v corev1.EnvVar) {
+	for i := range c.Env {
+		if c.Env[i].Name == v.Name {
+			c.Env[i] = v
+			return
+		}
+	}
+	c.Env = append(c.Env, v)
+}
+
+// overrideImages sets the image of the containers and init containers by
+// name. A name matching no container is an error, rather than an override
+// silently ignored.
+func overrideImages(images map[string]string) daemonSetMutation {
+	return func(ds *appsv1.DaemonSet) error {
+		found := map[string]bool{}
+		forEachContainer(&ds.Spec.Template.Spec, func(c *corev1.Container) {
+			if image, ok := images[c.Name]; ok {
+				c.Image = image
+				found[c.Name] = true
+			}
+		})
+		names := make([]string, 0, len(images))
+		for name := range images {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if !found[name] {
+				return fmt.Errorf("no container %s to override the image of", name)
+			}
+		}
+		return nil
+	}
+}
+
+func forEachContainer(spec *corev1.PodSpec, f func(c *corev1.Container)) {
+	for i := range spec.InitContainers {
+		f(&spec.InitContainers[i])
+	}
+	for i := range spec.Containers {
+		f(&spec.Containers[i])
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// renderedDaemonSet returns a DaemonSet as rendered from the templates,
+// whose first container is not the ovnkube one.
+func renderedDaemonSet() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "DaemonSet",
+		"metadata":   map[string]interface{}{"name": "ovnkube-node", "namespace": "tenant-a"},
+		"spec": map[string]interface{}{
+			"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"app": "ovnkube-node"}},
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "ovnkube-node"}},
+				"spec": map[string]interface{}{
+					"initContainers": []interface{}{
+						map[string]interface{}{"name": "ovn-keys", "image": "ovnkube:1"},
+					},
+					"containers": []interface{}{
+						map[string]interface{}{"name": "ovn-controller", "image": "ovn:1",
+							"env": []interface{}{map[string]interface{}{"name": "OVN_LOG_LEVEL", "value": "info"}}},
+						map[string]interface{}{"name": "ovnkube-node", "image": "ovnkube:1"},
+					},
+				},
+			},
+		},
+	}}
+}
+
+func TestMutateDaemonSet(t *testing.T) {
+	taint := corev1.Taint{Key: "dpu.openshift.io/not-ready", Effect: corev1.TaintEffectNoSchedule}
+	ds, err := mutateDaemonSet(renderedDaemonSet(),
+		mergeNodeSelector(&metav1.LabelSelector{MatchLabels: map[string]string{"node-role.kubernetes.io/dpu-worker": ""}}),
+		tolerateTaints([]corev1.Taint{taint, taint}),
+		setEnv(map[string]string{"OVN_LOG_LEVEL": "dbg", "DEBUG": "1"}),
+		overrideImages(map[string]string{"ovn-controller": "ovn:2", "ovn-keys": "ovnkube:2"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := ds.Spec.Template.Spec
+	if !reflect.DeepEqual(spec.NodeSelector, map[string]string{"node-role.kubernetes.io/dpu-worker": ""}) {
+		t.Errorf("unexpected nodeSelector %v", spec.NodeSelector)
+	}
+	if len(spec.Tolerations) != 1 || !spec.Tolerations[0].ToleratesTaint(&taint) {
+		t.Errorf("unexpected tolerations %v", spec.Tolerations)
+	}
+	images := map[string]string{}
+	forEachContainer(&spec, func(c *corev1.Container) { images[c.Name] = c.Image })
+	if want := map[string]string{"ovn-keys": "ovnkube:2", "ovn-controller": "ovn:2", "ovnkube-node": "ovnkube:1"}; !reflect.DeepEqual(images, want) {
+		t.Errorf("unexpected images %v, want %v", images, want)
+	}
+	wantEnv := []corev1.EnvVar{{Name: "OVN_LOG_LEVEL", Value: "dbg"}, {Name: "DEBUG", Value: "1"}}
+	if !reflect.DeepEqual(spec.Containers[0].Env, wantEnv) {
+		t.Errorf("unexpected env %v, want %v", spec.Containers[0].Env, wantEnv)
+	}
+	wantEnv = []corev1.EnvVar{{Name: "DEBUG", Value: "1"}, {Name: "OVN_LOG_LEVEL", Value: "dbg"}}
+	if !reflect.DeepEqual(spec.Containers[1].Env, wantEnv) {
+		t.Errorf("unexpected env %v, want %v", spec.Containers[1].Env, wantEnv)
+	}
+}
+
+func TestTolerateTaintsKeepsWildcard(t *testing.T) {
+	ds := &appsv1.DaemonSet{}
+	ds.Spec.Template.Spec.Tolerations = []corev1.Toleration{{Operator: corev1.TolerationOpExists}}
+	taints := []corev1.Taint{{Key: "dpu.openshift.io/not-ready", Effect: corev1.TaintEffectNoSchedule}}
+	if err := tolerateTaints(taints)(ds); err != nil {
+		t.Fatal(err)
+	}
+	if len(ds.Spec.Template.Spec.Tolerations) != 1 {
+		t.Errorf("the pod template changed: %v", ds.Spec.Template.Spec.Tolerations)
+	}
+}
+
+func TestMutateDaemonSetErrors(t *testing.T) {
+	unknownField := renderedDaemonSet()
+	if err := unstructured.SetNestedField(unknownField.Object, int64(5), "spec", "template", "spec", "periodSeconds"); err != nil {
+		t.Fatal(err)
+	}
+	notDaemonSet := renderedDaemonSet()
+	notDaemonSet.SetKind("Deployment")
+
+	for _, tc := range []struct {
+		name      string
+		obj       *unstructured.Unstructured
+		mutations []daemonSetMutation
+		want      string
+	}{
+		{name: "unknown field", obj: unknownField, want: `unknown field "spec.template.spec.periodSeconds"`},
+		{name: "not a DaemonSet", obj: notDaemonSet, want: "is not a DaemonSet"},
+		{name: "unknown container", obj: renderedDaemonSet(),
+			mutations: []daemonSetMutation{overrideImages(map[string]string{"ovs-daemons": "ovs:1"})},
+			want:      "no container ovs-daemons"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := mutateDaemonSet(tc.obj, tc.mutations...)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("got error %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,352 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+
+// The causes keeping an ovnkube-node pod from being ready.
+const (
+	podIssueImagePull     = "ImagePullBackOff"
+	podIssueUnschedulable = "Unschedulable"
+	podIssueCrashLoop     = "CrashLoopBackOff"
+)
+
+var podIssues = []string{podIssueImagePull, podIssueUnschedulable, podIssueCrashLoop}
+
+// failingPodIssues are the podIssues degrading the workload rather than
+// delaying its rollout.
+var failingPodIssues = map[string]bool{podIssueImagePull: true, podIssueCrashLoop: true}
+
+// dataPlanePodApps are the app labels of the pods of the data plane
+// DaemonSets.
+var dataPlanePodApps = []string{utils.LocalOvnkbueNodeDsName, "cilium-agent"}
+
+// DataPlanePodSelector returns the selector of the pods of the data plane
+// DaemonSets, the only pods the workload controller watches, so the cache of
+// the manager can be restricted to them.
+func DataPlanePodSelector() (labels.Selector, error) {
+	req, err := labels.NewRequirement("app", selection.In, dataPlanePodApps)
+	if err != nil {
+		return nil, err
+	}
+	return labels.NewSelector().Add(*req), nil
+}
+
+// podToOVNKubeConfig maps a data plane pod to the OVNKubeConfig of its
+// namespace.
+func (r *OVNKubeConfigReconciler) podToOVNKubeConfig(obj client.Object) []reconcile.Request {
+	cfg, err := r.getNamespaceConfig(context.TODO(), obj.GetNamespace())
+	if err != nil {
+		logger.Error(err, "failed to get the OVNKubeConfig of the pod", "namespace", obj.GetNamespace(), "name", obj.GetName())
+		return nil
+	}
+	if cfg == nil {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name}}}
+}
+
+// maxPodIssueNodes bounds the nodes listed per cause in the condition message.
+const maxPodIssueNodes = 5
+
+var ovnkubeNodePodIssues = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "dpu_network_operator_ovnkube_node_pod_issues",
+	Help: "Number of ovnkube-node pods not ready, by cause.",
+}, []string{"namespace", "cause"})
+
+func init() {
+	metrics.Registry.MustRegister(ovnkubeNodePodIssues)
+}
+
+// daemonSetRolloutMessage describes why the pods of the DaemonSets which are
+// not ready yet are stuck, e.g. "ImagePullBackOff on dpu-1, dpu-2", and
+// reports whether one of them is failing rather than progressing. The pods
+// are read from the API server, so the operator doesn't cache every pod of
+// the cluster. The pods of the nodes under maintenance are left out.
+func (r *OVNKubeConfigReconciler) daemonSetRolloutMessage(ctx context.Context, dss []appsv1.DaemonSet, maintenance map[string]bool) (string, bool) {
+	names := []string{}
+	for i := range dss {
+		names = append(names, "'"+dss[i].Name+"'")
+	}
+	msg := fmt.Sprintf("DaemonSet %s is rolling out", strings.Join(names, ", "))
+	if len(dss) > 1 {
+		msg = fmt.Sprintf("DaemonSets %s are rolling out", strings.Join(names, ", "))
+	}
+	nodes := map[string][]string{}
+	for i := range dss {
+		issues, err := r.daemonSetPodIssues(ctx, &dss[i], maintenance)
+		if err != nil {
+			logger.Error(err, "failed to inspect the pods of the DaemonSet", "name", dss[i].Name)
+			return msg, false
+		}
+		for cause, n := range issues {
+			nodes[cause] = append(nodes[cause], n...)
+		}
+	}
+	details := []string{}
+	failing := false
+	for _, cause := range podIssues {
+		ovnkubeNodePodIssues.WithLabelValues(dss[0].Namespace, cause).Set(float64(len(nodes[cause])))
+		if len(nodes[cause]) == 0 {
+			continue
+		}
+		failing = failing || failingPodIssues[cause]
+		sort.Strings(nodes[cause])
+		list := nodes[cause]
+		if len(list) > maxPodIssueNodes {
+			list = append(list[:maxPodIssueNodes:maxPodIssueNodes], fmt.Sprintf("%d more", len(nodes[cause])-maxPodIssueNodes))
+		}
+		details = append(details, fmt.Sprintf("%s on %s", cause, strings.Join(list, ", ")))
+	}
+	if len(details) == 0 {
+		return msg, false
+	}
+	return msg + ": " + strings.Join(details, "; "), failing
+}
+
+// resetPodIssues clears the pod issues metrics of the namespace once the
+// DaemonSets are ready.
+func resetPodIssues(namespace string) {
+	for _, cause := range podIssues {
+		ovnkubeNodePodIssues.WithLabelValues(namespace, cause).Set(0)
+	}
+}
+
+// daemonSetPodIssues returns the nodes of the pods of the DaemonSet keyed by
+// the cause keeping them from being ready, but the nodes under maintenance.
+func (r *OVNKubeConfigReconciler) daemonSetPodIssues(ctx context.Context, ds *appsv1.DaemonSet, maintenance map[string]bool) (map[string][]string, error) {
+	pods, err := r.daemonSetPods(ctx, ds)
+	if err != nil {
+		return nil, err
+	}
+	nodes := map[string][]string{}
+	for i := range pods {
+		node := podNodeName(&pods[i])
+		if maintenance[node] {
+			continue
+		}
+		if cause := podIssue(&pods[i]); cause != "" {
+			nodes[cause] = append(nodes[cause], node)
+		}
+	}
+	return nodes, nil
+}
+
+// daemonSetPods reads the pods of the DaemonSet from the API server.
+func (r *OVNKubeConfigReconciler) daemonSetPods(ctx context.Context, ds *appsv1.DaemonSet) ([]corev1.Pod, error) {
+	selector, err := metav1.LabelSelectorAsSelector(ds.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	pods := &corev1.PodList{}
+	if err := r.APIReader.List(ctx, pods, client.InNamespace(ds.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+	return pods.Items, nil
+}
+
+// nodesUnderMaintenance returns the names of the DPU nodes under
+// maintenance.
+func nodesUnderMaintenance(nodes []dpuv1alpha1.DpuNodeStatus) map[string]bool {
+	maintenance := map[string]bool{}
+	for _, node := range nodes {
+		if node.UnderMaintenance {
+			maintenance[node.Name] = true
+		}
+	}
+	return maintenance
+}
+
+// maintenanceMessage lists the nodes under maintenance, if any.
+func maintenanceMessage(maintenance map[string]bool) string {
+	if len(maintenance) == 0 {
+		return ""
+	}
+	nodes := []string{}
+	for node := range maintenance {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	if len(nodes) > maxPodIssueNodes {
+		nodes = append(nodes[:maxPodIssueNodes:maxPodIssueNodes], fmt.Sprintf("%d more", len(maintenance)-maxPodIssueNodes))
+	}
+	return "UnderMaintenance: " + strings.Join(nodes, ", ")
+}
+
+// readyOutsideMaintenance reports whether the pods of the DaemonSet are
+// ready on every node which is not under maintenance, since a cordoned node
+// is expected to take its pod down, e.g. while it reboots.
+func (r *OVNKubeConfigReconciler) readyOutsideMaintenance(ctx context.Context, ds *appsv1.DaemonSet, maintenance map[string]bool) (bool, error) {
+	pods, err := r.daemonSetPods(ctx, ds)
+	if err != nil {
+		return false, err
+	}
+	scheduled := map[string]bool{}
+	for i := range pods {
+		node := podNodeName(&pods[i])
+		scheduled[node] = true
+		if !maintenance[node] && !podReady(&pods[i]) {
+			return false, nil
+		}
+	}
+	// the pod of a node down for maintenance may be gone, the nodes the
+	// DaemonSet doesn't target, e.g. of another shard, have none anyway
+	missing := int(ds.Status.DesiredNumberScheduled) - len(scheduled)
+	for name := range maintenance {
+		if scheduled[name] {
+			continue
+		}
+		node := &corev1.Node{}
+		if err := r.Get(ctx, types.NamespacedName{Name: name}, node); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return false, err
+		}
+		if daemonSetTargetsNode(ds, node) {
+			missing--
+		}
+	}
+	return missing <= 0, nil
+}
+
+// daemonSetTargetsNode reports whether the node matches the node selector
+// and the required node affinity of the pods of the DaemonSet. The taints
+// are left out: the DaemonSets of the operator tolerate them all.
+func daemonSetTargetsNode(ds *appsv1.DaemonSet, node *corev1.Node) bool {
+	spec := &ds.Spec.Template.Spec
+	if !labels.SelectorFromSet(spec.NodeSelector).Matches(labels.Set(node.Labels)) {
+		return false
+	}
+	if spec.Affinity == nil || spec.Affinity.NodeAffinity == nil || spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+	// the terms are ORed
+	for _, term := range spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		if nodeMatchesTerm(node, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeSelectorOperators maps the operators of the node selector
+// requirements to the ones of the label selectors.
+var nodeSelectorOperators = map[corev1.NodeSelectorOperator]selection.Operator{
+	corev1.NodeSelectorOpIn:           selection.In,
+	corev1.NodeSelectorOpNotIn:        selection.NotIn,
+	corev1.NodeSelectorOpExists:       selection.Exists,
+	corev1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+	corev1.NodeSelectorOpGt:           selection.GreaterThan,
+	corev1.NodeSelectorOpLt:           selection.LessThan,
+}
+
+// nodeMatchesTerm reports whether the node matches all the requirements of
+// the node selector term. A term without requirements matches no node, and
+// an invalid requirement fails the match.
+func nodeMatchesTerm(node *corev1.Node, term corev1.NodeSelectorTerm) bool {
+	if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+		return false
+	}
+	for _, expr := range term.MatchExpressions {
+		req, err := labels.NewRequirement(expr.Key, nodeSelectorOperators[expr.Operator], expr.Values)
+		if err != nil || !req.Matches(labels.Set(node.Labels)) {
+			return false
+		}
+	}
+	for _, f := range term.MatchFields {
+		req, err := labels.NewRequirement(f.Key, nodeSelectorOperators[f.Operator], f.Values)
+		if err != nil || f.Key != metav1.ObjectNameField || !req.Matches(labels.Set{metav1.ObjectNameField: node.Name}) {
+			return false
+		}
+	}
+	return true
+}
+
+// podReady reports whether the Ready condition of the pod is true.
+func podReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// podIssue returns the cause keeping the pod from being ready, or "" if it
+// is not one of the podIssues.
+func podIssue(pod *corev1.Pod) string {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodScheduled && c.Status == corev1.ConditionFalse && c.Reason == corev1.PodReasonUnschedulable {
+			return podIssueUnschedulable
+		}
+	}
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		if cs.State.Waiting == nil {
+			continue
+		}
+		switch cs.State.Waiting.Reason {
+		case "ImagePullBackOff", "ErrImagePull", "InvalidImageName":
+			return podIssueImagePull
+		case "CrashLoopBackOff":
+			return podIssueCrashLoop
+		}
+	}
+	return ""
+}
+
+// podNodeName returns the node of a DaemonSet pod. Pods which are not
+// scheduled yet only name their node in the node affinity set by the
+// DaemonSet controller.
+func podNodeName(pod *corev1.Pod) string {
+	if pod.Spec.NodeName != "" {
+		return pod.Spec.NodeName
+	}
+	if a := pod.Spec.Affinity; a != nil && a.NodeAffinity != nil && a.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		for _, term := range a.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+			for _, f := range term.MatchFields {
+				if f.Key == metav1.ObjectNameField && len(f.Values) == 1 {
+					return f.Values[0]
+				}
+			}
+		}
+	}
+	return pod.Name
+}
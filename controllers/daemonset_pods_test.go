@@ -0,0 +1,62 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDaemonSetTargetsNode(t *testing.T) {
+	shard := func(value string) *corev1.Affinity {
+		return &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "shard", Operator: corev1.NodeSelectorOpIn, Values: []string{value}}},
+			}}},
+		}}
+	}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "dpu-1", Labels: map[string]string{"pool": "dpu", "shard": "0"}}}
+	for _, tc := range []struct {
+		name         string
+		nodeSelector map[string]string
+		affinity     *corev1.Affinity
+		want         bool
+	}{
+		{name: "no selector", want: true},
+		{name: "matching node selector", nodeSelector: map[string]string{"pool": "dpu"}, want: true},
+		{name: "other pool", nodeSelector: map[string]string{"pool": "other"}, want: false},
+		{name: "matching affinity", nodeSelector: map[string]string{"pool": "dpu"}, affinity: shard("0"), want: true},
+		{name: "other shard", nodeSelector: map[string]string{"pool": "dpu"}, affinity: shard("1"), want: false},
+		{name: "matching field", affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchFields: []corev1.NodeSelectorRequirement{{Key: metav1.ObjectNameField, Operator: corev1.NodeSelectorOpIn, Values: []string{"dpu-1"}}},
+			}}},
+		}}, want: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ds := &appsv1.DaemonSet{}
+			ds.Spec.Template.Spec.NodeSelector = tc.nodeSelector
+			ds.Spec.Template.Spec.Affinity = tc.affinity
+			if got := daemonSetTargetsNode(ds, node); got != tc.want {
+				t.Errorf("daemonSetTargetsNode() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,313 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/tracing"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+// shardRolloutError is returned when the rollout of a shard of the
+// ovnkube-node DaemonSet waits for the previous shards to be ready, or the
+// deletion of a DaemonSet of the previous shard layout for the new layout.
+type shardRolloutError struct {
+	shard      string
+	waitingFor string
+	// retiring tells shard is a DaemonSet of the previous layout.
+	retiring bool
+}
+
+func (e *shardRolloutError) Error() string {
+	if e.retiring {
+		return fmt.Sprintf("DaemonSet %s of the previous shard layout is kept until DaemonSet %s is ready", e.shard, e.waitingFor)
+	}
+	return fmt.Sprintf("DaemonSet %s rollout waits for DaemonSet %s to be ready", e.shard, e.waitingFor)
+}
+
+// rolloutShards returns the number of shards of the ovnkube-node DaemonSet.
+func rolloutShards(cfg *dpuv1alpha1.OVNKubeConfig) int32 {
+	if cfg.Spec.Rollout == nil || cfg.Spec.Rollout.Shards < 1 {
+		return 1
+	}
+	return cfg.Spec.Rollout.Shards
+}
+
+// nodeShard returns the shard of a node, from the hash of its name so the
+// shards stay balanced and a node keeps its shard as long as their number
+// doesn't change.
+func nodeShard(name string, shards int32) int32 {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return int32(h.Sum32() % uint32(shards))
+}
+
+func shardDaemonSetName(base string, i int32) string {
+	return fmt.Sprintf("%s-shard-%d", base, i)
+}
+
+// ovnkubeNodeDaemonSetNames returns the names of the data plane DaemonSets
+// named base, in rollout order.
+func ovnkubeNodeDaemonSetNames(base string, shards int32) []string {
+	if shards <= 1 {
+		return []string{base}
+	}
+	names := []string{}
+	for i := int32(0); i < shards; i++ {
+		names = append(names, shardDaemonSetName(base, i))
+	}
+	return names
+}
+
+// isOvnkubeNodeDaemonSet reports whether name is a data plane DaemonSet of
+// any provider, so switching providers replaces the DaemonSets of the
+// previous one.
+func isOvnkubeNodeDaemonSet(name string) bool {
+	for _, base := range dataPlaneDaemonSetNames {
+		if name == base || strings.HasPrefix(name, base+"-shard-") {
+			return true
+		}
+	}
+	return false
+}
+
+// listOvnkubeNodeDaemonSets returns the data plane DaemonSets of the
+// OVNKubeConfig, whether sharded or not, and the DaemonSets it adopted.
+func (r *OVNKubeConfigReconciler) listOvnkubeNodeDaemonSets(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) ([]appsv1.DaemonSet, error) {
+	list := &appsv1.DaemonSetList{}
+	if err := r.List(ctx, list, client.InNamespace(cfg.Namespace)); err != nil {
+		return nil, err
+	}
+	dss := []appsv1.DaemonSet{}
+	for _, ds := range list.Items {
+		if (isOvnkubeNodeDaemonSet(ds.Name) || ds.Annotations[utils.AdoptAnnotation] == "true") && metav1.IsControlledBy(&ds, cfg) {
+			dss = append(dss, ds)
+		}
+	}
+	return dss, nil
+}
+
+// shardDaemonSets splits ds into one DaemonSet per shard. The pods of a
+// shard run on the nodes labeled with it, and carry it as a label so the
+// selectors of the shards don't overlap.
+func shardDaemonSets(ds *appsv1.DaemonSet, shards int32) []*appsv1.DaemonSet {
+	if shards <= 1 {
+		return []*appsv1.DaemonSet{ds}
+	}
+	dss := []*appsv1.DaemonSet{}
+	for i := int32(0); i < shards; i++ {
+		shard := ds.DeepCopy()
+		shard.Name = shardDaemonSetName(ds.Name, i)
+		value := strconv.Itoa(int(i))
+		if shard.Spec.Selector.MatchLabels == nil {
+			shard.Spec.Selector.MatchLabels = map[string]string{}
+		}
+		shard.Spec.Selector.MatchLabels[utils.OvnkubeShardLabel] = value
+		if shard.Spec.Template.Labels == nil {
+			shard.Spec.Template.Labels = map[string]string{}
+		}
+		shard.Spec.Template.Labels[utils.OvnkubeShardLabel] = value
+		if shard.Spec.Template.Spec.NodeSelector == nil {
+			shard.Spec.Template.Spec.NodeSelector = map[string]string{}
+		}
+		shard.Spec.Template.Spec.NodeSelector[utils.OvnkubeShardLabel] = value
+		dss = append(dss, shard)
+	}
+	return dss
+}
+
+// daemonSetRolledOut reports whether every pod of the DaemonSet runs its
+// current template and is ready.
+func daemonSetRolledOut(ds *appsv1.DaemonSet) bool {
+	return ds.Status.ObservedGeneration >= ds.Generation &&
+		ds.Status.UpdatedNumberScheduled == ds.Status.DesiredNumberScheduled &&
+		ds.Status.NumberReady == ds.Status.DesiredNumberScheduled
+}
+
+// applyOvnkubeNodeDaemonSets applies ds, split into the shards of the
+// rollout. A missing shard is created right away, but an existing shard is
+// only updated once the previous shards are rolled out and ready, so a bad
+// change stops at the first shard. The DaemonSets of a previous layout are
+// then retired one at a time. The shards are applied in tx.
+func (r *OVNKubeConfigReconciler) applyOvnkubeNodeDaemonSets(ctx context.Context, tx *applyTransaction, cfg *dpuv1alpha1.OVNKubeConfig, ds *appsv1.DaemonSet, nodeSelector *metav1.LabelSelector) error {
+	shards := rolloutShards(cfg)
+	if err := r.syncShardLabels(ctx, nodeSelector, shards); err != nil {
+		return err
+	}
+	desired := shardDaemonSets(ds, shards)
+	layout := map[string]bool{}
+	for _, d := range desired {
+		layout[d.Name] = true
+	}
+
+	existing, err := r.listOvnkubeNodeDaemonSets(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	selectors := map[string]*metav1.LabelSelector{}
+	for _, d := range desired {
+		selectors[d.Name] = d.Spec.Selector
+	}
+	found := map[string]*appsv1.DaemonSet{}
+	previous := []*appsv1.DaemonSet{}
+	for i := range existing {
+		// the selector is immutable, e.g. the one of an adopted DaemonSet
+		if layout[existing[i].Name] && equality.Semantic.DeepEqual(existing[i].Spec.Selector, selectors[existing[i].Name]) {
+			found[existing[i].Name] = &existing[i]
+			continue
+		}
+		previous = append(previous, &existing[i])
+	}
+
+	waitingFor := ""
+	for _, d := range desired {
+		f, ok := found[d.Name]
+		changed := !ok || f.Annotations[utils.TemplateHashAnnotation] != d.Annotations[utils.TemplateHashAnnotation]
+		if ok && changed && waitingFor != "" {
+			return &shardRolloutError{shard: d.Name, waitingFor: waitingFor}
+		}
+		if err := r.applyDaemonSet(ctx, tx, cfg, d); err != nil {
+			return err
+		}
+		// the cached status of a DaemonSet updated by this pass is stale
+		if waitingFor == "" && (changed || !daemonSetRolledOut(f)) {
+			waitingFor = d.Name
+		}
+	}
+	return r.retirePreviousLayout(ctx, desired, found, previous)
+}
+
+// retirePreviousLayout deletes the DaemonSets of a previous shard layout, one
+// per pass. Their pods conflict with the ones of the new layout on the same
+// node, so the new pods of a node only turn ready once its previous
+// DaemonSet is deleted. The next one is deleted once every new DaemonSet is
+// observed and the new layout is ready on all the nodes but the ones the
+// remaining previous DaemonSets serve.
+func (r *OVNKubeConfigReconciler) retirePreviousLayout(ctx context.Context, desired []*appsv1.DaemonSet, found map[string]*appsv1.DaemonSet, previous []*appsv1.DaemonSet) error {
+	if len(previous) == 0 {
+		return nil
+	}
+	sort.Slice(previous, func(i, j int) bool { return previous[i].Name < previous[j].Name })
+	if next := retirementBlocker(desired, found, previous); next != "" {
+		return &shardRolloutError{shard: previous[0].Name, waitingFor: next, retiring: true}
+	}
+	logger.Info("Delete DaemonSet of the previous shard layout", "name", previous[0].Name)
+	if err := r.Delete(ctx, previous[0]); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if len(previous) > 1 {
+		return &shardRolloutError{shard: previous[1].Name, waitingFor: desired[0].Name, retiring: true}
+	}
+	return nil
+}
+
+// retirementBlocker returns the DaemonSet of the new layout the deletion of
+// the next previous DaemonSet waits for, "" when it may be deleted.
+func retirementBlocker(desired []*appsv1.DaemonSet, found map[string]*appsv1.DaemonSet, previous []*appsv1.DaemonSet) string {
+	served := int32(0)
+	for _, p := range previous {
+		served += p.Status.DesiredNumberScheduled
+	}
+	unavailable := int32(0)
+	waitingFor := ""
+	for _, d := range desired {
+		f, ok := found[d.Name]
+		if !ok || f.Status.ObservedGeneration < f.Generation {
+			// created by this pass, or not observed yet
+			return d.Name
+		}
+		if n := f.Status.DesiredNumberScheduled - f.Status.NumberReady; n > 0 {
+			unavailable += n
+			if waitingFor == "" {
+				waitingFor = d.Name
+			}
+		}
+	}
+	if unavailable > served {
+		return waitingFor
+	}
+	return ""
+}
+
+func (r *OVNKubeConfigReconciler) applyDaemonSet(ctx context.Context, tx *applyTransaction, cfg *dpuv1alpha1.OVNKubeConfig, ds *appsv1.DaemonSet) error {
+	if err := ctrl.SetControllerReference(cfg, ds, r.Scheme); err != nil {
+		return err
+	}
+	obj, err := r.toUnstructured(ds)
+	if err != nil {
+		return err
+	}
+	_, span := tracing.Start(ctx, "apply", "kind", "DaemonSet", "name", ds.Name)
+	err = tx.apply(ctx, obj)
+	span.RecordError(err)
+	span.End()
+	if err != nil {
+		return conflictError(fmt.Errorf("failed to apply DaemonSet %s with err: %w", ds.Name, err))
+	}
+	return nil
+}
+
+// syncShardLabels labels the nodes of the pool with their shard, and removes
+// the label when the rollout is not sharded.
+func (r *OVNKubeConfigReconciler) syncShardLabels(ctx context.Context, nodeSelector *metav1.LabelSelector, shards int32) error {
+	selector, err := metav1.LabelSelectorAsSelector(nodeSelector)
+	if err != nil {
+		return err
+	}
+	nodes := &corev1.NodeList{}
+	if err := r.List(ctx, nodes, &client.ListOptions{LabelSelector: selector}); err != nil {
+		return err
+	}
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		want := ""
+		if shards > 1 {
+			want = strconv.Itoa(int(nodeShard(node.Name, shards)))
+		}
+		if node.Labels[utils.OvnkubeShardLabel] == want {
+			continue
+		}
+		patch := client.MergeFrom(node.DeepCopy())
+		if want == "" {
+			delete(node.Labels, utils.OvnkubeShardLabel)
+		} else {
+			if node.Labels == nil {
+				node.Labels = map[string]string{}
+			}
+			node.Labels[utils.OvnkubeShardLabel] = want
+		}
+		if err := r.Patch(ctx, node, patch); err != nil {
+			return fmt.Errorf("failed to label node %s with its shard: %v", node.Name, err)
+		}
+	}
+	return nil
+}
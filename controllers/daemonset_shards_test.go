@@ -0,0 +1,255 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+func TestShardDaemonSets(t *testing.T) {
+	ds := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "ovnkube-node"}}
+	ds.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "ovnkube-node"}}
+	ds.Spec.Template.Spec.NodeSelector = map[string]string{"node-role.kubernetes.io/dpu-worker": ""}
+
+	if dss := shardDaemonSets(ds, 1); len(dss) != 1 || dss[0] != ds {
+		t.Fatalf("a single shard changed the DaemonSet: %v", dss)
+	}
+	dss := shardDaemonSets(ds, 3)
+	if len(dss) != 3 {
+		t.Fatalf("got %d shards, want 3", len(dss))
+	}
+	for i, shard := range dss {
+		value := []string{"0", "1", "2"}[i]
+		if want := ovnkubeNodeDaemonSetNames("ovnkube-node", 3)[i]; shard.Name != want {
+			t.Errorf("shard %d is named %s, want %s", i, shard.Name, want)
+		}
+		if want := map[string]string{"app": "ovnkube-node", utils.OvnkubeShardLabel: value}; !reflect.DeepEqual(shard.Spec.Selector.MatchLabels, want) {
+			t.Errorf("shard %d selects %v, want %v", i, shard.Spec.Selector.MatchLabels, want)
+		}
+		if shard.Spec.Template.Labels[utils.OvnkubeShardLabel] != value {
+			t.Errorf("shard %d pods are labeled %v", i, shard.Spec.Template.Labels)
+		}
+		if want := map[string]string{"node-role.kubernetes.io/dpu-worker": "", utils.OvnkubeShardLabel: value}; !reflect.DeepEqual(shard.Spec.Template.Spec.NodeSelector, want) {
+			t.Errorf("shard %d runs on %v, want %v", i, shard.Spec.Template.Spec.NodeSelector, want)
+		}
+	}
+	if len(ds.Spec.Selector.MatchLabels) != 1 || len(ds.Spec.Template.Spec.NodeSelector) != 1 {
+		t.Errorf("sharding changed the source DaemonSet: %v", ds.Spec)
+	}
+}
+
+func TestIsOvnkubeNodeDaemonSet(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		want bool
+	}{
+		{name: utils.LocalOvnkbueNodeDsName, want: true},
+		{name: shardDaemonSetName(utils.LocalOvnkbueNodeDsName, 2), want: true},
+		{name: shardDaemonSetName(ciliumAgentDsName, 0), want: true},
+		{name: "ovnkube-master"},
+		{name: utils.LocalOvnkbueNodeDsName + "-metrics"},
+	} {
+		if got := isOvnkubeNodeDaemonSet(tc.name); got != tc.want {
+			t.Errorf("isOvnkubeNodeDaemonSet(%q) = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRetirementBlocker(t *testing.T) {
+	daemonSet := func(name string, desired, ready int32, observed bool) *appsv1.DaemonSet {
+		ds := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: name, Generation: 2}}
+		ds.Status.DesiredNumberScheduled = desired
+		ds.Status.NumberReady = ready
+		ds.Status.ObservedGeneration = 1
+		if observed {
+			ds.Status.ObservedGeneration = 2
+		}
+		return ds
+	}
+	desired := []*appsv1.DaemonSet{daemonSet("ovnkube-node-shard-0", 0, 0, false), daemonSet("ovnkube-node-shard-1", 0, 0, false)}
+	previous := []*appsv1.DaemonSet{daemonSet("ovnkube-node", 4, 4, true)}
+
+	for _, tc := range []struct {
+		name  string
+		found []*appsv1.DaemonSet
+		want  string
+	}{
+		{name: "shard not created yet",
+			found: []*appsv1.DaemonSet{daemonSet("ovnkube-node-shard-0", 2, 2, true)},
+			want:  "ovnkube-node-shard-1"},
+		{name: "shard not observed yet",
+			found: []*appsv1.DaemonSet{daemonSet("ovnkube-node-shard-0", 2, 2, true), daemonSet("ovnkube-node-shard-1", 2, 0, false)},
+			want:  "ovnkube-node-shard-1"},
+		{name: "new layout ready",
+			found: []*appsv1.DaemonSet{daemonSet("ovnkube-node-shard-0", 2, 2, true), daemonSet("ovnkube-node-shard-1", 2, 2, true)}},
+		{name: "unavailable pods served by the previous layout",
+			found: []*appsv1.DaemonSet{daemonSet("ovnkube-node-shard-0", 2, 0, true), daemonSet("ovnkube-node-shard-1", 2, 0, true)}},
+		{name: "more unavailable pods than the previous layout serves",
+			found: []*appsv1.DaemonSet{daemonSet("ovnkube-node-shard-0", 3, 0, true), daemonSet("ovnkube-node-shard-1", 3, 1, true)},
+			want:  "ovnkube-node-shard-0"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			found := map[string]*appsv1.DaemonSet{}
+			for _, ds := range tc.found {
+				found[ds.Name] = ds
+			}
+			if got := retirementBlocker(desired, found, previous); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// objectStore serves the objects of a transaction from memory.
+type objectStore struct {
+	client.Client
+	objs    map[string]*unstructured.Unstructured
+	version int
+}
+
+func storeKey(obj client.Object) string {
+	return obj.GetObjectKind().GroupVersionKind().Kind + "/" + obj.GetNamespace() + "/" + obj.GetName()
+}
+
+func (s *objectStore) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	u := obj.(*unstructured.Unstructured)
+	stored, ok := s.objs[u.GetKind()+"/"+key.Namespace+"/"+key.Name]
+	if !ok {
+		return errors.NewNotFound(appsv1.Resource("daemonsets"), key.Name)
+	}
+	stored.DeepCopyInto(u)
+	return nil
+}
+
+func (s *objectStore) write(obj client.Object) {
+	s.version++
+	obj.SetResourceVersion(strconv.Itoa(s.version))
+	s.objs[storeKey(obj)] = obj.(*unstructured.Unstructured).DeepCopy()
+}
+
+func (s *objectStore) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	s.write(obj)
+	return nil
+}
+
+func (s *objectStore) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	s.write(obj)
+	return nil
+}
+
+func (s *objectStore) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	delete(s.objs, storeKey(obj))
+	return nil
+}
+
+func TestApplyDaemonSetRollback(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := dpuv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &dpuv1alpha1.OVNKubeConfig{ObjectMeta: metav1.ObjectMeta{Name: "ovnkubeconfig", Namespace: "tenant-a", UID: "uid"}}
+	daemonSet := func(image string) *appsv1.DaemonSet {
+		return &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "ovnkube-node", Namespace: "tenant-a"},
+			Spec: appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "ovnkube-node", Image: image}},
+			}}},
+		}
+	}
+	image := func(s *objectStore) string {
+		u, ok := s.objs["DaemonSet/tenant-a/ovnkube-node"]
+		if !ok {
+			return ""
+		}
+		ds := &appsv1.DaemonSet{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, ds); err != nil {
+			t.Fatal(err)
+		}
+		return ds.Spec.Template.Spec.Containers[0].Image
+	}
+	tests := []struct {
+		name  string
+		prior *appsv1.DaemonSet
+		want  string
+	}{
+		{name: "created", want: ""},
+		{name: "updated", prior: daemonSet("old"), want: "old"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &objectStore{objs: map[string]*unstructured.Unstructured{}}
+			r := &OVNKubeConfigReconciler{Client: store, Scheme: scheme}
+			if tt.prior != nil {
+				obj, err := r.toUnstructured(tt.prior)
+				if err != nil {
+					t.Fatal(err)
+				}
+				store.write(obj)
+			}
+			tx := newApplyTransaction(store)
+			if err := r.applyDaemonSet(context.Background(), tx, cfg, daemonSet("new")); err != nil {
+				t.Fatalf("applyDaemonSet() error = %v", err)
+			}
+			if got := image(store); got != "new" {
+				t.Fatalf("applied image = %q, want new", got)
+			}
+			tx.rollback(context.Background())
+			if got := image(store); got != tt.want {
+				t.Errorf("image after rollback = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRolloutHeld(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "maintenance window", err: &pendingChangesError{}, want: true},
+		{name: "pre-pull", err: &prepullError{}, want: true},
+		{name: "hook", err: &hookError{}, want: true},
+		{name: "failure", err: fmt.Errorf("failed")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rolloutHeld(tt.err); got != tt.want {
+				t.Errorf("rolloutHeld() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
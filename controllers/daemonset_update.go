@@ -0,0 +1,114 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// templateGenerationAnnotation is the generation of the template of a
+	// DaemonSet, bumped by the API server on every template change.
+	templateGenerationAnnotation = "deprecated.daemonset.template.generation"
+	// podTemplateGenerationLabel is set by the DaemonSet controller to the
+	// template generation a pod was created from.
+	podTemplateGenerationLabel = "pod-template-generation"
+)
+
+var (
+	ovnkubeNodeUnobservedGenerations = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "dpu_network_operator_ovnkube_node_unobserved_generations",
+		Help: "Number of generations of the ovnkube-node DaemonSet not observed yet by the DaemonSet controller.",
+	}, []string{"namespace", "daemonset"})
+	ovnkubeNodeOutdatedPods = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "dpu_network_operator_ovnkube_node_outdated_pods",
+		Help: "Number of nodes not running the current template of the ovnkube-node DaemonSet, nodes under maintenance excluded.",
+	}, []string{"namespace", "daemonset"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(ovnkubeNodeUnobservedGenerations, ovnkubeNodeOutdatedPods)
+}
+
+// daemonSetUpdatePending describes why the DaemonSet doesn't run its
+// current template on every node yet, or returns "" once it does. Until the
+// DaemonSet controller observes the generation, the readiness of the
+// DaemonSet is the one of the previous template. The nodes under
+// maintenance are not expected to update their pod.
+func (r *OVNKubeConfigReconciler) daemonSetUpdatePending(ctx context.Context, ds *appsv1.DaemonSet, maintenance map[string]bool) (string, error) {
+	unobserved := ds.Generation - ds.Status.ObservedGeneration
+	if unobserved < 0 {
+		unobserved = 0
+	}
+	ovnkubeNodeUnobservedGenerations.WithLabelValues(ds.Namespace, ds.Name).Set(float64(unobserved))
+	if unobserved > 0 {
+		ovnkubeNodeOutdatedPods.WithLabelValues(ds.Namespace, ds.Name).Set(float64(ds.Status.DesiredNumberScheduled))
+		return fmt.Sprintf("DaemonSet '%s' generation %d is not observed yet, observedGeneration is %d",
+			ds.Name, ds.Generation, ds.Status.ObservedGeneration), nil
+	}
+	outdated := int(ds.Status.DesiredNumberScheduled - ds.Status.UpdatedNumberScheduled)
+	if outdated > 0 && len(maintenance) > 0 {
+		var err error
+		if outdated, err = r.outdatedPodsOutsideMaintenance(ctx, ds, maintenance); err != nil {
+			return "", err
+		}
+	}
+	if outdated < 0 {
+		outdated = 0
+	}
+	ovnkubeNodeOutdatedPods.WithLabelValues(ds.Namespace, ds.Name).Set(float64(outdated))
+	if outdated == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("DaemonSet '%s' runs the current template on %d of %d nodes",
+		ds.Name, ds.Status.DesiredNumberScheduled-int32(outdated), ds.Status.DesiredNumberScheduled), nil
+}
+
+// outdatedPodsOutsideMaintenance counts the pods of the DaemonSet created
+// from a previous template, but the ones of the nodes under maintenance.
+func (r *OVNKubeConfigReconciler) outdatedPodsOutsideMaintenance(ctx context.Context, ds *appsv1.DaemonSet, maintenance map[string]bool) (int, error) {
+	generation, ok := ds.Annotations[templateGenerationAnnotation]
+	if !ok {
+		return int(ds.Status.DesiredNumberScheduled - ds.Status.UpdatedNumberScheduled), nil
+	}
+	pods, err := r.daemonSetPods(ctx, ds)
+	if err != nil {
+		return 0, err
+	}
+	outdated := 0
+	for i := range pods {
+		if maintenance[podNodeName(&pods[i])] {
+			continue
+		}
+		if pods[i].Labels[podTemplateGenerationLabel] != generation {
+			outdated++
+		}
+	}
+	return outdated, nil
+}
+
+// resetDaemonSetUpdateMetrics drops the update metrics of the DaemonSets of
+// the namespace, e.g. of the shards of a previous layout.
+func resetDaemonSetUpdateMetrics(namespace string) {
+	ovnkubeNodeUnobservedGenerations.DeletePartialMatch(prometheus.Labels{"namespace": namespace})
+	ovnkubeNodeOutdatedPods.DeletePartialMatch(prometheus.Labels{"namespace": namespace})
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift/cluster-network-operator/pkg/render"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+	"github.com/openshift/dpu-network-operator/pkg/tracing"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+const (
+	ciliumAgentDsName   = "cilium-agent"
+	ciliumNetworkType   = "Cilium"
+	ciliumKubeconfigKey = "k8s-kubeconfig-path"
+)
+
+// dataPlaneDaemonSetNames are the names of the DaemonSets of every data
+// plane provider.
+var dataPlaneDaemonSetNames = []string{utils.LocalOvnkbueNodeDsName, ciliumAgentDsName}
+
+// dataPlaneProvider renders the data plane run on the DPUs. The rendered
+// DaemonSet goes through the rollout of ovnkube-node: pre-pull, maintenance
+// window, hooks and shards.
+type dataPlaneProvider interface {
+	// daemonSetName is the name of the rendered DaemonSet.
+	daemonSetName() string
+	// networkType is the network type of the tenant clusters the data
+	// plane can join.
+	networkType() string
+	// render renders the objects of the data plane. No objects are
+	// returned while the control plane of the tenant cluster is not found.
+	render(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) ([]*unstructured.Unstructured, error)
+}
+
+// dataPlane returns the data plane provider selected by spec.dataPlane.
+func (r *OVNKubeConfigReconciler) dataPlane(cfg *dpuv1alpha1.OVNKubeConfig) dataPlaneProvider {
+	if cfg.Spec.DataPlane != nil && cfg.Spec.DataPlane.Provider == dpuv1alpha1.DataPlaneCilium {
+		return &ciliumDataPlane{r}
+	}
+	return &ovnDataPlane{r}
+}
+
+// isOvnDataPlane reports whether the DPUs of cfg run OVN-Kubernetes.
+func isOvnDataPlane(cfg *dpuv1alpha1.OVNKubeConfig) bool {
+	return cfg.Spec.DataPlane == nil || cfg.Spec.DataPlane.Provider != dpuv1alpha1.DataPlaneCilium
+}
+
+// ovnDataPlane runs ovnkube-node on the DPUs, the default.
+type ovnDataPlane struct {
+	r *OVNKubeConfigReconciler
+}
+
+func (p *ovnDataPlane) daemonSetName() string {
+	return utils.LocalOvnkbueNodeDsName
+}
+
+func (p *ovnDataPlane) networkType() string {
+	return ovnKubernetesNetworkType
+}
+
+func (p *ovnDataPlane) render(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) ([]*unstructured.Unstructured, error) {
+	image, err := p.r.getOvnkubeImage(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return p.r.renderOvnkubeNode(ctx, cfg, image)
+}
+
+// ciliumDataPlane runs cilium-agent on the DPUs, joined to a tenant cluster
+// running Cilium. Tech preview.
+type ciliumDataPlane struct {
+	r *OVNKubeConfigReconciler
+}
+
+func (p *ciliumDataPlane) daemonSetName() string {
+	return ciliumAgentDsName
+}
+
+func (p *ciliumDataPlane) networkType() string {
+	return ciliumNetworkType
+}
+
+func (p *ciliumDataPlane) render(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) ([]*unstructured.Unstructured, error) {
+	// cilium-agent doesn't connect to the OVN DBs
+	cfg.Status.NbEndpoints, cfg.Status.SbEndpoints = nil, nil
+	spec := cfg.Spec.DataPlane.Cilium
+	if spec == nil || spec.Image == "" {
+		return nil, dpuerrors.InvalidImage(fmt.Errorf("spec.dataPlane.cilium.image must be set for the Cilium data plane"))
+	}
+	if err := validateImageReference(spec.Image, "spec.dataPlane.cilium.image"); err != nil {
+		return nil, err
+	}
+	priorityClassName, err := p.r.getPriorityClassName(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	config := map[string]string{}
+	for k, v := range spec.Config {
+		config[k] = v
+	}
+	// the agent watches the tenant cluster, not the infra one
+	config[ciliumKubeconfigKey] = "/var/run/secrets/tenant-kubeconfig/config"
+
+	data := render.MakeRenderData()
+	data.Data["CiliumImage"] = spec.Image
+	data.Data["CiliumConfig"] = config
+	data.Data["CiliumConfigMap"] = utils.CmNameCiliumConfig
+	data.Data["Namespace"] = cfg.Namespace
+	data.Data["PriorityClassName"] = priorityClassName
+	data.Data["ImagePullSecrets"] = imagePullSecretNames(cfg)
+	data.Data["SecurityContextConstraints"] = p.r.Platform.Get().SecurityContextConstraints
+	data.Data["TenantKubeconfig"], data.Data["TenantKubeconfigKey"] = tenantKubeconfigMount(cfg)
+
+	addExtraRenderData(data.Data, cfg)
+	_, span := tracing.Start(ctx, "render", "manifests", utils.CiliumAgentManifestPath)
+	objs, err := renderDir(cfg, utils.CiliumAgentManifestPath, &data)
+	span.RecordError(err)
+	span.End()
+	if err != nil {
+		logger.Error(err, "Fail to render cilium-agent manifests")
+		return nil, dpuerrors.RenderFailed(err)
+	}
+	return objs, nil
+}
@@ -0,0 +1,344 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/openshift/dpu-network-operator/api"
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+	"github.com/openshift/dpu-network-operator/pkg/tracing"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+// delegationRequeueInterval is how often the removal of the managed copies
+// of a CR is checked while the copy of the OVNKubeConfig is finalized.
+const delegationRequeueInterval = 10 * time.Second
+
+// delegatedConditions are the conditions of the managed copy reported on
+// the delegating CR, along with Delegated.
+var delegatedConditions = []string{
+	api.McpReady, api.TenantObjsSynced, api.OvnKubeReady, api.WaitingForPreflight,
+	api.PendingChanges, api.PendingRollout, api.VersionSkew, api.TenantClusterReachable,
+	api.DaemonSetHooks, api.MachineConfigHooks, api.LogForwarding,
+	api.UnsupportedTenantNetwork, api.SwitchdevReady, api.RenderFailed, api.Delegated,
+	api.CertInvalidForNodes, api.ImageArchMismatch, api.ZeroNodesSelected,
+}
+
+// targetNamespace returns the namespace the workloads of cfg are rendered
+// into.
+func targetNamespace(cfg *dpuv1alpha1.OVNKubeConfig) string {
+	if cfg.Spec.TargetNamespace != "" {
+		return cfg.Spec.TargetNamespace
+	}
+	return cfg.Namespace
+}
+
+// delegated returns whether the workloads of cfg are not rendered from cfg
+// itself: its spec.targetNamespace is another namespace, or the managed
+// copies of a previous one are not removed yet. The workload, machine-config
+// and tenant-sync controllers leave such CRs to the delegation controller.
+func delegated(cfg *dpuv1alpha1.OVNKubeConfig) bool {
+	return targetNamespace(cfg) != cfg.Namespace || controllerutil.ContainsFinalizer(cfg, utils.DelegationFinalizer)
+}
+
+// validateTargetNamespace checks that the spec.targetNamespace of cfg is its
+// own namespace or the one of the operator.
+func validateTargetNamespace(cfg *dpuv1alpha1.OVNKubeConfig) error {
+	target := targetNamespace(cfg)
+	if target == cfg.Namespace || target == utils.Namespace {
+		return nil
+	}
+	if utils.Namespace == "" {
+		return fmt.Errorf("spec.targetNamespace %s is not supported, the namespace of the operator is unknown", target)
+	}
+	return fmt.Errorf("spec.targetNamespace %s must be the namespace of the OVNKubeConfig, %s, or the one of the operator, %s",
+		target, cfg.Namespace, utils.Namespace)
+}
+
+// delegatedKubeconfigName is the name of the copy of the tenant kubeconfig
+// Secret of cfg in its spec.targetNamespace.
+func delegatedKubeconfigName(cfg *dpuv1alpha1.OVNKubeConfig) string {
+	return cfg.Namespace + "-tenant-kubeconfig"
+}
+
+// delegationConflictError is returned when the target namespace of a CR
+// already has an OVNKubeConfig which is not its managed copy.
+type delegationConflictError struct {
+	namespace string
+	name      string
+}
+
+func (e *delegationConflictError) Error() string {
+	return fmt.Sprintf("OVNKubeConfig %s/%s already renders the workloads of the namespace", e.namespace, e.name)
+}
+
+// copyTargetNamespace copies the status field owned by the delegation
+// controller on a CR rendering its own workloads.
+func copyTargetNamespace(dst, src *dpuv1alpha1.OVNKubeConfigStatus) {
+	dst.TargetNamespace = src.TargetNamespace
+}
+
+// copyDelegatedStatus copies the status of the managed copy reported on the
+// delegating CR, but the conditions.
+func copyDelegatedStatus(dst, src *dpuv1alpha1.OVNKubeConfigStatus) {
+	conditions := dst.Conditions
+	*dst = *src.DeepCopy()
+	dst.Conditions = conditions
+}
+
+// reconcileDelegation renders the workloads of a CR whose
+// spec.targetNamespace is the namespace of the operator: it keeps a managed
+// copy of the CR and of its tenant kubeconfig Secret there, reconciled by
+// the other controllers, and reports the status of the copy on the CR. The
+// copies are removed with the CR, or once spec.targetNamespace is reset. On
+// the other CRs, only status.targetNamespace is reported.
+func (r *OVNKubeConfigReconciler) reconcileDelegation(ctx context.Context, req ctrl.Request) (result ctrl.Result, reterr error) {
+	ctx, span := tracing.Start(ctx, "reconcile delegation", "namespace", req.Namespace, "name", req.Name)
+	defer func() {
+		span.RecordError(reterr)
+		span.End()
+	}()
+	logger := log.FromContext(ctx).WithValues("reconcile OVNKubeConfig delegation", req.NamespacedName)
+
+	cfg, err := r.getNamespaceConfig(ctx, req.Namespace)
+	if err != nil || cfg == nil {
+		return ctrl.Result{}, err
+	}
+	if !delegated(cfg) {
+		meta.RemoveStatusCondition(&cfg.Status.Conditions, api.Delegated)
+		cfg.Status.TargetNamespace = cfg.Namespace
+		return ctrl.Result{}, r.updateStatus(ctx, cfg, []string{api.Delegated}, copyTargetNamespace)
+	}
+	logger.Info("Reconcile")
+	start := time.Now()
+	defer func() {
+		recordReconcile(cfg, delegationControllerName, start, reterr)
+		if err := r.updateStatus(ctx, cfg, delegatedConditions, copyReconcileStatus(delegationControllerName, copyDelegatedStatus)); err != nil {
+			logger.Error(err, "unable to update OVNKubeConfig status")
+		}
+	}()
+
+	target := targetNamespace(cfg)
+	if !cfg.DeletionTimestamp.IsZero() || target == cfg.Namespace {
+		return r.removeDelegatedCopies(ctx, cfg)
+	}
+	if err := validateTargetNamespace(cfg); err != nil {
+		// the copies of a previous target, if any, keep running
+		cfg.Status.TargetNamespace = ""
+		meta.SetStatusCondition(&cfg.Status.Conditions, *api.Conditions(cfg).NotDelegated().Reason(api.ReasonInvalidTargetNamespace).Msg(err.Error()).Build())
+		return ctrl.Result{}, nil
+	}
+	if controllerutil.AddFinalizer(cfg, utils.DelegationFinalizer) {
+		if err := r.Update(ctx, cfg); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	copied, err := r.syncDelegatedConfig(ctx, cfg, target)
+	if conflict, ok := err.(*delegationConflictError); ok {
+		meta.SetStatusCondition(&cfg.Status.Conditions, *api.Conditions(cfg).NotDelegated().Reason(api.ReasonConflict).Msg(conflict.Error()).Build())
+		return ctrl.Result{}, nil
+	} else if err != nil {
+		meta.SetStatusCondition(&cfg.Status.Conditions, *api.Conditions(cfg).NotDelegated().Reason(dpuerrors.Reason(err, api.ReasonFailedCreated)).Msg(err.Error()).Build())
+		return ctrl.Result{}, err
+	}
+	cfg.Status = *delegatedStatus(cfg, copied)
+	meta.SetStatusCondition(&cfg.Status.Conditions, *api.Conditions(cfg).Delegated().Reason(api.ReasonCreated).
+		Msg(fmt.Sprintf("the workloads are rendered from OVNKubeConfig %s/%s", copied.Namespace, copied.Name)).Build())
+	return ctrl.Result{}, nil
+}
+
+// syncDelegatedConfig creates or updates the managed copies of cfg in the
+// target namespace: the OVNKubeConfig, named after cfg, and its tenant
+// kubeconfig Secret. The copy of the OVNKubeConfig is returned.
+func (r *OVNKubeConfigReconciler) syncDelegatedConfig(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, target string) (*dpuv1alpha1.OVNKubeConfig, error) {
+	cfgList := &dpuv1alpha1.OVNKubeConfigList{}
+	if err := r.List(ctx, cfgList, client.InNamespace(target)); err != nil {
+		return nil, err
+	}
+	for _, other := range cfgList.Items {
+		if other.Name != cfg.Name || other.Labels[utils.DelegatedFromLabel] != cfg.Namespace {
+			return nil, &delegationConflictError{namespace: other.Namespace, name: other.Name}
+		}
+	}
+	// the Secret is read before anything is written, so an invalid one is
+	// reported rather than breaking the running copy
+	kubeconfig, version, err := r.tenantKubeconfig(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	copied := &dpuv1alpha1.OVNKubeConfig{ObjectMeta: metav1.ObjectMeta{Namespace: target, Name: cfg.Name}}
+	var op controllerutil.OperationResult
+	err = withApplyRetry(ctx, "OVNKubeConfig", func() error {
+		var err error
+		op, err = controllerutil.CreateOrUpdate(ctx, r.Client, copied, func() error {
+			if copied.Labels == nil {
+				copied.Labels = map[string]string{}
+			}
+			copied.Labels[utils.DelegatedFromLabel] = cfg.Namespace
+			copied.Spec = *cfg.Spec.DeepCopy()
+			copied.Spec.TargetNamespace = ""
+			copied.Spec.KubeConfigFile = ""
+			copied.Spec.KubeConfigSecretRef = &dpuv1alpha1.KubeConfigSecretReference{
+				Name: delegatedKubeconfigName(cfg),
+				Key:  defaultTenantKubeconfigKey,
+			}
+			if ref := cfg.Spec.KubeConfigSecretRef; ref != nil {
+				copied.Spec.KubeConfigSecretRef.VersionAnnotation = ref.VersionAnnotation
+			}
+			return nil
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if op != controllerutil.OperationResultNone {
+		logger.Info("Synced delegated OVNKubeConfig", "namespace", copied.Namespace, "name", copied.Name, "operation", op)
+	}
+	// the operator may only write Secrets where an OVNKubeConfig lives
+	if err := r.syncNamespaceRoleBinding(ctx, copied); err != nil {
+		return nil, err
+	}
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: target, Name: delegatedKubeconfigName(cfg)}}
+	err = withApplyRetry(ctx, "Secret", func() error {
+		_, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+			if secret.Labels == nil {
+				secret.Labels = map[string]string{}
+			}
+			secret.Labels[utils.DelegatedFromLabel] = cfg.Namespace
+			if ref := copied.Spec.KubeConfigSecretRef; ref.VersionAnnotation != "" {
+				if secret.Annotations == nil {
+					secret.Annotations = map[string]string{}
+				}
+				secret.Annotations[ref.VersionAnnotation] = version
+			}
+			secret.Data = map[string][]byte{defaultTenantKubeconfigKey: kubeconfig}
+			// garbage collected once the copy of the OVNKubeConfig removed
+			// its tenant objects with it
+			return ctrl.SetControllerReference(copied, secret, r.Scheme)
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copied, nil
+}
+
+// delegatedStatus returns the status of the managed copy of cfg to report
+// on cfg. The copy was just synced with the current spec of cfg, so its
+// conditions observing its current generation are stamped with the one of
+// cfg, the older ones with the previous generation of cfg.
+func delegatedStatus(cfg, copied *dpuv1alpha1.OVNKubeConfig) *dpuv1alpha1.OVNKubeConfigStatus {
+	status := copied.Status.DeepCopy()
+	for i := range status.Conditions {
+		c := &status.Conditions[i]
+		if c.ObservedGeneration >= copied.Generation {
+			c.ObservedGeneration = cfg.Generation
+		} else if cfg.Generation > 0 {
+			c.ObservedGeneration = cfg.Generation - 1
+		}
+	}
+	status.TargetNamespace = copied.Namespace
+	status.Reconciles = cfg.Status.Reconciles
+	status.ReconcileHistory = cfg.Status.ReconcileHistory
+	return status
+}
+
+// removeDelegatedCopies deletes the managed copy of the OVNKubeConfig of cfg,
+// then removes its finalizer once the copy is gone, since the copy removes
+// the objects it created in turn. The copy of the Secret is owned by the
+// copy of the OVNKubeConfig.
+func (r *OVNKubeConfigReconciler) removeDelegatedCopies(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(cfg, utils.DelegationFinalizer) {
+		return ctrl.Result{}, nil
+	}
+	cfgList := &dpuv1alpha1.OVNKubeConfigList{}
+	if err := r.List(ctx, cfgList, client.MatchingLabels{utils.DelegatedFromLabel: cfg.Namespace}); err != nil {
+		return ctrl.Result{}, err
+	}
+	pending := false
+	for i := range cfgList.Items {
+		copied := &cfgList.Items[i]
+		if copied.Name != cfg.Name {
+			continue
+		}
+		pending = true
+		if copied.DeletionTimestamp.IsZero() {
+			logger.Info("Delete delegated OVNKubeConfig", "namespace", copied.Namespace, "name", copied.Name)
+			if err := r.Delete(ctx, copied); err != nil && !errors.IsNotFound(err) {
+				return ctrl.Result{}, err
+			}
+		}
+	}
+	if pending {
+		return ctrl.Result{RequeueAfter: delegationRequeueInterval}, nil
+	}
+	meta.RemoveStatusCondition(&cfg.Status.Conditions, api.Delegated)
+	controllerutil.RemoveFinalizer(cfg, utils.DelegationFinalizer)
+	return ctrl.Result{}, r.Update(ctx, cfg)
+}
+
+// delegatedCopyToOVNKubeConfig maps a managed copy to the OVNKubeConfig it
+// was created from, the only one of its namespace.
+func delegatedCopyToOVNKubeConfig(obj client.Object) []reconcile.Request {
+	namespace := obj.GetLabels()[utils.DelegatedFromLabel]
+	if namespace == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: obj.GetName()}}}
+}
+
+func (r *OVNKubeConfigReconciler) setupDelegationController(mgr ctrl.Manager) error {
+	trigger := recordTrigger(r.Scheme, delegationControllerName)
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(delegationControllerName).
+		WithOptions(workerOptions(r.MaxConcurrentReconciles)).
+		For(&dpuv1alpha1.OVNKubeConfig{}, builder.WithPredicates(configChanged, trigger)).
+		Watches(&source.Kind{Type: &dpuv1alpha1.OVNKubeConfig{}},
+			// the status of the copy is mirrored onto the CR
+			handler.EnqueueRequestsFromMapFunc(delegatedCopyToOVNKubeConfig),
+			builder.WithPredicates(reconcileStatusChanged, trigger)).
+		Watches(&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(r.secretToOVNKubeConfigs),
+			builder.WithPredicates(trigger)).
+		Watches(&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(delegatedCopyToOVNKubeConfig),
+			builder.WithPredicates(trigger)).
+		Complete(isolateReconcile(delegationControllerName, r.reconcileDelegation))
+}
@@ -21,6 +21,8 @@ import (
 	"time"
 
 	nmoapiv1beta1 "github.com/medik8s/node-maintenance-operator/api/v1beta1"
+	"github.com/openshift/dpu-network-operator/api"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
 	"github.com/openshift/dpu-network-operator/pkg/utils"
 	"github.com/sirupsen/logrus"
 	appsv1 "k8s.io/api/apps/v1"
@@ -30,6 +32,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	restclient "k8s.io/client-go/rest"
@@ -37,6 +40,7 @@ import (
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 )
 
 type Config struct {
@@ -47,11 +51,22 @@ type Config struct {
 
 type DpuNodeLifecycleController struct {
 	client.Client
-	Config       *Config
-	Scheme       *runtime.Scheme
-	Log          logrus.FieldLogger
+	Config *Config
+	Scheme *runtime.Scheme
+	Log    logrus.FieldLogger
+	// TenantRestConfigs returns the config of the tenant cluster of the
+	// OVNKubeConfig selecting a DPU node, nil when none does or its syncer
+	// doesn't run.
+	TenantRestConfigs func(ctx context.Context, node *corev1.Node) *restclient.Config
+	Namespace         string
+
 	tenantClient client.Client
-	Namespace    string
+	// syncerConfig and syncerClient are the last tenant config returned by
+	// TenantRestConfigs and its client, secretClient the one of the
+	// tenant-kubeconfig secret.
+	syncerConfig *restclient.Config
+	syncerClient client.Client
+	secretClient client.Client
 }
 
 const (
@@ -77,15 +92,6 @@ func (r *DpuNodeLifecycleController) Reconcile(ctx context.Context, req ctrl.Req
 		})
 	defer log.Info("node controller lifecycle reconcile ended")
 
-	var err error
-	r.tenantClient, err = r.ensureTenantClient(log)
-	// if no tenant client, nothing to do, on error it will retry reconcile
-	if err != nil || r.tenantClient == nil {
-		return ctrl.Result{}, err
-	}
-
-	log.Info("node controller lifecycle reconcile started")
-	namespace := r.Namespace
 	node := &corev1.Node{}
 	if err := r.Get(ctx, req.NamespacedName, node); err != nil {
 		log.WithError(err).Errorf("Failed to get node %s", req.Name)
@@ -97,6 +103,16 @@ func (r *DpuNodeLifecycleController) Reconcile(ctx context.Context, req ctrl.Req
 		return ctrl.Result{}, nil
 	}
 
+	var err error
+	r.tenantClient, err = r.ensureTenantClient(ctx, log, node)
+	// if no tenant client, nothing to do, on error it will retry reconcile
+	if err != nil || r.tenantClient == nil {
+		return ctrl.Result{}, err
+	}
+
+	log.Info("node controller lifecycle reconcile started")
+	namespace := r.Namespace
+
 	tenantNode, err := utils.GetMatchedTenantNode(node.Name)
 	if err != nil {
 		r.Log.WithError(err).Errorf("failed to get tenant node that matches %s", node.Name)
@@ -174,10 +190,10 @@ func (r *DpuNodeLifecycleController) ensurePDBSpecIsAsExpected(log logrus.FieldL
 		log.Infof("No changes in pdb spec, MaxUnavailable is %d", expectedPDB.Spec.MaxUnavailable.IntVal)
 		return nil
 	}
-	pdb.Spec = expectedPDB.Spec
 	log.Infof("Setting pdb's spec to %+v", expectedPDB.Spec)
-	err := r.Update(context.TODO(), pdb)
-	return err
+	return retryUpdate(context.TODO(), r.Client, pdb, func() {
+		pdb.Spec = expectedPDB.Spec
+	})
 }
 
 func (r *DpuNodeLifecycleController) buildPDB(node *corev1.Node, namespace string) *policyv1.PodDisruptionBudget {
@@ -264,7 +280,17 @@ func (r *DpuNodeLifecycleController) shouldTenantHostBeDrained(node *corev1.Node
 // build the nmName
 // if it should be drained, drain it, if it should be undrained, undrain it.
 // return if node is in required state
+// without the NodeMaintenance operator in the tenant cluster, the tenant host
+// can't be drained and the pdb keeps blocking the drain of the dpu
 func (r *DpuNodeLifecycleController) ensureNodeDrainState(tenantNode string, shouldBeDrained bool) (bool, error) {
+	gk := schema.GroupKind{Group: nmoapiv1beta1.GroupVersion.Group, Kind: "NodeMaintenance"}
+	if err := requireAPI(r.tenantClient.RESTMapper(), gk, nmoapiv1beta1.GroupVersion.Version, "the NodeMaintenance operator"); err != nil {
+		if dpuerrors.Reason(err, "") != api.ReasonAPINotInstalled {
+			return false, err
+		}
+		r.Log.WithError(err).Warnf("Cannot drain tenant node %s", tenantNode)
+		return !shouldBeDrained, nil
+	}
 	nmName := maintenancePrefix + tenantNode
 	if shouldBeDrained {
 		return r.drainTenantNode(nmName, tenantNode)
@@ -329,15 +355,29 @@ func (r *DpuNodeLifecycleController) buildNodeMaintenanceCR(name, tenantNodeHost
 		}}
 }
 
-// Return client that will handle hosts with dpu status
-func (r *DpuNodeLifecycleController) ensureTenantClient(log logrus.FieldLogger) (client.Client, error) {
-	if r.tenantClient != nil {
-		return r.tenantClient, nil
-	}
+// Return client that will handle hosts with dpu status: the one of the
+// tenant cluster of the OVNKubeConfig selecting the node, else the one of
+// the tenant-kubeconfig secret.
+func (r *DpuNodeLifecycleController) ensureTenantClient(ctx context.Context, log logrus.FieldLogger, node *corev1.Node) (client.Client, error) {
 	if r.Config.SingleClusterDesign {
 		log.Infof("Single cluster design is on, tenant client is the same as local")
 		return r.Client, nil
 	}
+	if r.TenantRestConfigs != nil {
+		if config := r.TenantRestConfigs(ctx, node); config != nil {
+			if config != r.syncerConfig {
+				tenantClient, err := r.newTenantClient(log, config)
+				if err != nil {
+					return nil, err
+				}
+				r.syncerConfig, r.syncerClient = config, tenantClient
+			}
+			return r.syncerClient, nil
+		}
+	}
+	if r.secretClient != nil {
+		return r.secretClient, nil
+	}
 
 	tenantKubeconfig, err := r.getTenantRestClientConfig()
 	if err != nil {
@@ -348,13 +388,18 @@ func (r *DpuNodeLifecycleController) ensureTenantClient(log logrus.FieldLogger)
 	if tenantKubeconfig == nil {
 		return nil, nil
 	}
-	tenantClient, err := client.New(tenantKubeconfig, client.Options{})
+	r.secretClient, err = r.newTenantClient(log, tenantKubeconfig)
+	return r.secretClient, err
+}
+
+func (r *DpuNodeLifecycleController) newTenantClient(log logrus.FieldLogger, config *restclient.Config) (client.Client, error) {
+	tenantClient, err := client.New(config, client.Options{})
 	if err != nil {
-		r.Log.WithError(err).Errorf("Fail to create client for the tenant cluster")
+		log.WithError(err).Errorf("Fail to create client for the tenant cluster")
 		return nil, err
 	}
 	nmoapiv1beta1.AddToScheme(tenantClient.Scheme())
-	return tenantClient, err
+	return tenantClient, nil
 }
 
 // Since, at this point, it's difficult to set up a fully functioning two-cluster design.
@@ -362,11 +407,6 @@ func (r *DpuNodeLifecycleController) ensureTenantClient(log logrus.FieldLogger)
 // a secret. In this development/debugging mode, the OVNKubeConfigReconciler is disabled
 // while the dpu node controller just runs.
 func (r *DpuNodeLifecycleController) getTenantRestClientConfig() (*restclient.Config, error) {
-	// If TenantRestConfig was set by ovn controller we should use it
-	if utils.TenantRestConfig != nil {
-		return utils.TenantRestConfig, nil
-	}
-
 	tenantKubeconfigName := "tenant-kubeconfig"
 	var err error
 
@@ -387,12 +427,19 @@ func (r *DpuNodeLifecycleController) getTenantRestClientConfig() (*restclient.Co
 		return nil, err
 	}
 
-	return clientcmd.RESTConfigFromKubeConfig(bytes)
+	config, err := clientcmd.RESTConfigFromKubeConfig(bytes)
+	if err != nil {
+		return nil, err
+	}
+	instrumentTenantConfig(config)
+	return config, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *DpuNodeLifecycleController) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
+		// the tenant client of the pass is kept on the controller
+		WithOptions(controller.Options{MaxConcurrentReconciles: 1}).
 		For(&corev1.Node{}).
 		Owns(&appsv1.Deployment{}).
 		Owns(&policyv1.PodDisruptionBudget{}).
@@ -0,0 +1,269 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/openshift/dpu-network-operator/api"
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+// DpuFleetPolicyReconciler stamps out an OVNKubeConfig, named after the
+// policy, in the namespace of every tenant kubeconfig Secret selected by a
+// DpuFleetPolicy.
+type DpuFleetPolicyReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=dpu.openshift.io,resources=dpufleetpolicies,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=dpu.openshift.io,resources=dpufleetpolicies/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=dpu.openshift.io,resources=dpufleetpolicies/finalizers,verbs=update
+
+// Reconcile creates or updates the OVNKubeConfigs of the tenants selected by
+// the policy and deletes the ones of the tenants it no longer selects. The
+// stamped out configs are owned by the policy, so they are garbage collected
+// with it.
+func (r *DpuFleetPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("reconcile DpuFleetPolicy", req.Name)
+	logger.Info("Reconcile")
+
+	policy := &dpuv1alpha1.DpuFleetPolicy{}
+	if err := r.Get(ctx, req.NamespacedName, policy); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.TenantSelector)
+	if err != nil {
+		meta.SetStatusCondition(&policy.Status.Conditions, *api.Conditions(policy).NotFleetSynced().Reason(api.ReasonFailedCreated).Msg(err.Error()).Build())
+		return ctrl.Result{}, r.updateFleetStatus(ctx, policy)
+	}
+	secrets := &corev1.SecretList{}
+	if err := r.List(ctx, secrets, &client.ListOptions{LabelSelector: selector}); err != nil {
+		return ctrl.Result{}, err
+	}
+	sort.Slice(secrets.Items, func(i, j int) bool {
+		a, b := secrets.Items[i], secrets.Items[j]
+		return a.Namespace < b.Namespace || (a.Namespace == b.Namespace && a.Name < b.Name)
+	})
+
+	tenants := []dpuv1alpha1.FleetTenantStatus{}
+	managed := map[string]bool{}
+	conflicts := 0
+	for _, secret := range secrets.Items {
+		tenant := dpuv1alpha1.FleetTenantStatus{Namespace: secret.Namespace, Secret: secret.Name}
+		if managed[secret.Namespace] {
+			// an OVNKubeConfig is the only one of its namespace
+			tenant.Message = "another tenant of the namespace is already selected"
+		} else if err := r.syncFleetConfig(ctx, policy, &secret); err != nil {
+			if _, ok := err.(*fleetConflictError); !ok {
+				return ctrl.Result{}, err
+			}
+			tenant.Message = err.Error()
+		} else {
+			managed[secret.Namespace] = true
+			tenant.Config = policy.Name
+		}
+		if tenant.Message != "" {
+			conflicts++
+		}
+		tenants = append(tenants, tenant)
+	}
+
+	if err := r.deleteStaleFleetConfigs(ctx, policy, managed); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	policy.Status.Tenants = tenants
+	if conflicts > 0 {
+		msg := fmt.Sprintf("%d/%d selected tenants are not managed", conflicts, len(tenants))
+		meta.SetStatusCondition(&policy.Status.Conditions, *api.Conditions(policy).NotFleetSynced().Reason(api.ReasonConflict).Msg(msg).Build())
+	} else {
+		meta.SetStatusCondition(&policy.Status.Conditions, *api.Conditions(policy).FleetSynced().Reason(api.ReasonCreated).Build())
+	}
+	return ctrl.Result{}, r.updateFleetStatus(ctx, policy)
+}
+
+// fleetConflictError is returned when the namespace of a selected tenant
+// already has an OVNKubeConfig which is not managed by the policy.
+type fleetConflictError struct {
+	namespace string
+	name      string
+}
+
+func (e *fleetConflictError) Error() string {
+	return fmt.Sprintf("OVNKubeConfig %s/%s is not managed by the policy", e.namespace, e.name)
+}
+
+// syncFleetConfig creates or updates the OVNKubeConfig of the tenant from the
+// template of the policy.
+func (r *DpuFleetPolicyReconciler) syncFleetConfig(ctx context.Context, policy *dpuv1alpha1.DpuFleetPolicy, secret *corev1.Secret) error {
+	cfgList := &dpuv1alpha1.OVNKubeConfigList{}
+	if err := r.List(ctx, cfgList, client.InNamespace(secret.Namespace)); err != nil {
+		return err
+	}
+	for _, cfg := range cfgList.Items {
+		if cfg.Name != policy.Name || cfg.Labels[utils.FleetPolicyLabel] != policy.Name {
+			return &fleetConflictError{namespace: cfg.Namespace, name: cfg.Name}
+		}
+	}
+
+	cfg := &dpuv1alpha1.OVNKubeConfig{ObjectMeta: metav1.ObjectMeta{Namespace: secret.Namespace, Name: policy.Name}}
+	var op controllerutil.OperationResult
+	err := withApplyRetry(ctx, "OVNKubeConfig", func() error {
+		var err error
+		op, err = controllerutil.CreateOrUpdate(ctx, r.Client, cfg, func() error {
+			if cfg.Labels == nil {
+				cfg.Labels = map[string]string{}
+			}
+			cfg.Labels[utils.FleetPolicyLabel] = policy.Name
+			cfg.Spec = *policy.Spec.Template.DeepCopy()
+			cfg.Spec.KubeConfigFile = secret.Name
+			cfg.Spec.PoolName = fleetPoolName(&cfg.Spec, secret.Namespace)
+			return ctrl.SetControllerReference(policy, cfg, r.Scheme)
+		})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if op != controllerutil.OperationResultNone {
+		logger.Info("Synced fleet OVNKubeConfig", "namespace", cfg.Namespace, "name", cfg.Name, "operation", op)
+	}
+	return nil
+}
+
+// fleetPoolName returns the poolName of the OVNKubeConfig stamped out for the
+// tenant of namespace. Each tenant has its own MachineConfigPool, named after
+// the one of the template, unless the template uses a DpuNodePool or the
+// worker pool.
+func fleetPoolName(spec *dpuv1alpha1.OVNKubeConfigSpec, namespace string) string {
+	if spec.PoolRef != nil || spec.PoolName == "" || (spec.PoolName == workerPool && spec.AllowWorkerPool) {
+		return spec.PoolName
+	}
+	return spec.PoolName + "-" + namespace
+}
+
+// deleteStaleFleetConfigs deletes the OVNKubeConfigs stamped out by the policy
+// for tenants it no longer selects.
+func (r *DpuFleetPolicyReconciler) deleteStaleFleetConfigs(ctx context.Context, policy *dpuv1alpha1.DpuFleetPolicy, managed map[string]bool) error {
+	cfgList := &dpuv1alpha1.OVNKubeConfigList{}
+	if err := r.List(ctx, cfgList, client.MatchingLabels{utils.FleetPolicyLabel: policy.Name}); err != nil {
+		return err
+	}
+	for i := range cfgList.Items {
+		cfg := &cfgList.Items[i]
+		if managed[cfg.Namespace] || !metav1.IsControlledBy(cfg, policy) {
+			continue
+		}
+		logger.Info("Delete stale fleet OVNKubeConfig", "namespace", cfg.Namespace, "name", cfg.Name)
+		if err := r.Delete(ctx, cfg); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *DpuFleetPolicyReconciler) updateFleetStatus(ctx context.Context, policy *dpuv1alpha1.DpuFleetPolicy) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &dpuv1alpha1.DpuFleetPolicy{}
+		if err := r.Get(ctx, types.NamespacedName{Name: policy.Name}, latest); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if equality.Semantic.DeepEqual(latest.Status, policy.Status) {
+			return nil
+		}
+		latest.Status = policy.Status
+		return r.Status().Update(ctx, latest)
+	})
+}
+
+// secretToDpuFleetPolicies maps a Secret event to the policies selecting it,
+// or which stamped out an OVNKubeConfig for it.
+func (r *DpuFleetPolicyReconciler) secretToDpuFleetPolicies(obj client.Object) []reconcile.Request {
+	policies := &dpuv1alpha1.DpuFleetPolicyList{}
+	if err := r.List(context.TODO(), policies); err != nil {
+		logger.Error(err, "failed to list DpuFleetPolicies")
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, policy := range policies.Items {
+		selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.TenantSelector)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(obj.GetLabels())) || fleetSelectsSecret(&policy, obj) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: policy.Name}})
+		}
+	}
+	return requests
+}
+
+func fleetSelectsSecret(policy *dpuv1alpha1.DpuFleetPolicy, obj client.Object) bool {
+	for _, tenant := range policy.Status.Tenants {
+		if tenant.Namespace == obj.GetNamespace() && tenant.Secret == obj.GetName() {
+			return true
+		}
+	}
+	return false
+}
+
+// secretLabelsChanged filters the Secret events which may change the tenants
+// selected by a policy.
+var secretLabelsChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return !equality.Semantic.DeepEqual(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels())
+	},
+	GenericFunc: func(e event.GenericEvent) bool {
+		return false
+	},
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DpuFleetPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&dpuv1alpha1.DpuFleetPolicy{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&dpuv1alpha1.OVNKubeConfig{}, builder.WithPredicates(configChanged)).
+		Watches(&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(r.secretToDpuFleetPolicies),
+			builder.WithPredicates(secretLabelsChanged)).
+		Complete(r)
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+)
+
+func TestFleetPoolName(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		spec dpuv1alpha1.OVNKubeConfigSpec
+		want string
+	}{
+		{name: "pool per tenant", spec: dpuv1alpha1.OVNKubeConfigSpec{PoolName: "dpu"}, want: "dpu-tenant-a"},
+		{name: "no pool"},
+		{name: "worker pool allowed", spec: dpuv1alpha1.OVNKubeConfigSpec{PoolName: workerPool, AllowWorkerPool: true}, want: workerPool},
+		{name: "worker pool not allowed", spec: dpuv1alpha1.OVNKubeConfigSpec{PoolName: workerPool}, want: "worker-tenant-a"},
+		{name: "DpuNodePool", spec: dpuv1alpha1.OVNKubeConfigSpec{PoolName: "dpu", PoolRef: &dpuv1alpha1.PoolReference{Name: "dpus"}}, want: "dpu"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := fleetPoolName(&tc.spec, "tenant-a"); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/openshift/dpu-network-operator/api"
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+// DpuNodePoolReconciler applies the labels and taints of the DpuNodePools to
+// their nodes, and manages their MachineConfigPools, independently of the
+// OVNKubeConfigs referencing them.
+type DpuNodePoolReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// Platform holds the OpenShift APIs available in the infra cluster.
+	Platform *utils.DynamicPlatform
+	// MaxConcurrentReconciles is the number of pools reconciled in
+	// parallel, DefaultMaxConcurrentReconciles when not set.
+	MaxConcurrentReconciles int
+}
+
+//+kubebuilder:rbac:groups=dpu.openshift.io,resources=dpunodepools,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=dpu.openshift.io,resources=dpunodepools/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=dpu.openshift.io,resources=dpunodepools/finalizers,verbs=update
+
+// Reconcile applies the labels and taints of the pool to the nodes it
+// selects, and removes them from the nodes it no longer selects. A node
+// already managed by another pool is left to it. The labels and taints are
+// removed from every node when the pool is deleted.
+func (r *DpuNodePoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("reconcile DpuNodePool", req.Name)
+	logger.Info("Reconcile")
+
+	pool := &dpuv1alpha1.DpuNodePool{}
+	if err := r.Get(ctx, req.NamespacedName, pool); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := r.List(ctx, nodes); err != nil {
+		return ctrl.Result{}, err
+	}
+	if !pool.DeletionTimestamp.IsZero() {
+		for i := range nodes.Items {
+			if nodes.Items[i].Annotations[utils.NodePoolAnnotation] == pool.Name {
+				if err := r.syncPoolNode(ctx, &nodes.Items[i], nil, nil); err != nil {
+					return ctrl.Result{}, err
+				}
+			}
+		}
+		if controllerutil.RemoveFinalizer(pool, utils.NodePoolFinalizer) {
+			return ctrl.Result{}, r.Update(ctx, pool)
+		}
+		return ctrl.Result{}, nil
+	}
+	if controllerutil.AddFinalizer(pool, utils.NodePoolFinalizer) {
+		if err := r.Update(ctx, pool); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(&pool.Spec.NodeSelector)
+	if err != nil {
+		meta.SetStatusCondition(&pool.Status.Conditions, *api.Conditions(pool).NotPoolReady().Reason(api.ReasonFailedCreated).Msg(fmt.Sprintf("invalid nodeSelector: %v", err)).Build())
+		return ctrl.Result{}, r.updatePoolStatus(ctx, pool)
+	}
+	poolLabels := map[string]string{}
+	for k, v := range pool.Spec.Labels {
+		poolLabels[k] = v
+	}
+	if pool.Spec.DpuType != "" {
+		poolLabels[utils.DpuTypeLabel] = pool.Spec.DpuType
+	}
+
+	members := []string{}
+	memberNodes := []*corev1.Node{}
+	conflicts := []string{}
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		owner := node.Annotations[utils.NodePoolAnnotation]
+		if owner != "" && owner != pool.Name {
+			if selector.Matches(labels.Set(node.Labels)) {
+				conflicts = append(conflicts, fmt.Sprintf("%s (DpuNodePool %s)", node.Name, owner))
+			}
+			continue
+		}
+		if !selector.Matches(labels.Set(node.Labels)) {
+			if owner == pool.Name {
+				if err := r.syncPoolNode(ctx, node, nil, nil); err != nil {
+					return ctrl.Result{}, err
+				}
+			}
+			continue
+		}
+		if err := r.syncPoolNode(ctx, node, poolLabels, pool); err != nil {
+			return ctrl.Result{}, err
+		}
+		members = append(members, node.Name)
+		memberNodes = append(memberNodes, node)
+	}
+	sort.Strings(members)
+	pool.Status.Nodes = members
+	pool.Status.Inventory = poolInventory(memberNodes)
+
+	if r.Platform.Get().MachineConfig {
+		mcp, err := desiredMachineConfigPool(pool.Name, nodePoolSelector(pool))
+		if err == nil {
+			err = ctrl.SetControllerReference(pool, mcp, r.Scheme)
+		}
+		if err == nil {
+			_, err = syncMachineConfigPool(ctx, r.Client, mcp)
+		}
+		if err != nil {
+			meta.SetStatusCondition(&pool.Status.Conditions, *api.Conditions(pool).NotPoolReady().Reason(api.ReasonFailedCreated).Msg(err.Error()).Build())
+			if serr := r.updatePoolStatus(ctx, pool); serr != nil {
+				logger.Error(serr, "unable to update DpuNodePool status")
+			}
+			return ctrl.Result{}, err
+		}
+	}
+
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		msg := "nodes managed by another pool: " + strings.Join(conflicts, ", ")
+		meta.SetStatusCondition(&pool.Status.Conditions, *api.Conditions(pool).NotPoolReady().Reason(api.ReasonConflict).Msg(msg).Build())
+	} else {
+		meta.SetStatusCondition(&pool.Status.Conditions, *api.Conditions(pool).PoolReady().Reason(api.ReasonCreated).Build())
+	}
+	return ctrl.Result{}, r.updatePoolStatus(ctx, pool)
+}
+
+// nodePoolSelector returns the selector of the nodes of the pool: its
+// labels, once applied, or else its nodeSelector.
+func nodePoolSelector(pool *dpuv1alpha1.DpuNodePool) *metav1.LabelSelector {
+	if len(pool.Spec.Labels) > 0 {
+		return &metav1.LabelSelector{MatchLabels: pool.Spec.Labels}
+	}
+	return &pool.Spec.NodeSelector
+}
+
+// syncPoolNode applies the labels and the taints of the pool to the node,
+// and removes the ones previously applied which the pool no longer sets. The
+// applied labels and taints are recorded in annotations, so the ones set by
+// the admin are never removed. A nil pool releases the node.
+func (r *DpuNodePoolReconciler) syncPoolNode(ctx context.Context, node *corev1.Node, poolLabels map[string]string, pool *dpuv1alpha1.DpuNodePool) error {
+	managedLabels := map[string]string{}
+	if v, ok := node.Annotations[utils.PoolLabelsAnnotation]; ok {
+		if err := json.Unmarshal([]byte(v), &managedLabels); err != nil {
+			logger.Error(err, "ignoring invalid pool labels annotation", "node", node.Name)
+		}
+	}
+	managed := managedTaintsAnnotation(node, utils.PoolTaintsAnnotation)
+	// only the labels and taints not already set by hand are managed
+	desiredLabels := map[string]string{}
+	for k, v := range poolLabels {
+		if _, ok := managedLabels[k]; ok || node.Labels[k] != v {
+			desiredLabels[k] = v
+		}
+	}
+	var taints []corev1.Taint
+	if pool != nil {
+		taints = desiredTaints(node.Spec.Taints, managed, pool.Spec.Taints)
+	}
+
+	updated := node.DeepCopy()
+	if updated.Labels == nil {
+		updated.Labels = map[string]string{}
+	}
+	if updated.Annotations == nil {
+		updated.Annotations = map[string]string{}
+	}
+	for k, v := range managedLabels {
+		if _, ok := desiredLabels[k]; !ok && updated.Labels[k] == v {
+			delete(updated.Labels, k)
+		}
+	}
+	for k, v := range desiredLabels {
+		updated.Labels[k] = v
+	}
+	updated.Spec.Taints = mergeTaints(updated.Spec.Taints, managed, taints)
+	if err := setJSONAnnotation(updated, utils.PoolLabelsAnnotation, desiredLabels, len(desiredLabels) > 0); err != nil {
+		return err
+	}
+	if err := setJSONAnnotation(updated, utils.PoolTaintsAnnotation, taints, len(taints) > 0); err != nil {
+		return err
+	}
+	if pool != nil {
+		updated.Annotations[utils.NodePoolAnnotation] = pool.Name
+	} else {
+		delete(updated.Annotations, utils.NodePoolAnnotation)
+	}
+	if equality.Semantic.DeepEqual(node.Labels, updated.Labels) && equality.Semantic.DeepEqual(node.Annotations, updated.Annotations) && equality.Semantic.DeepEqual(node.Spec.Taints, updated.Spec.Taints) {
+		return nil
+	}
+
+	logger.Info("Update DPU node labels and taints", "node", node.Name, "labels", desiredLabels, "taints", taints)
+	err := withApplyRetry(ctx, "Node", func() error {
+		return r.Patch(ctx, updated, client.MergeFrom(node))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update node %s: %v", node.Name, err)
+	}
+	return nil
+}
+
+// setJSONAnnotation sets the annotation to the JSON encoding of v, or
+// removes it when set is false.
+func setJSONAnnotation(obj client.Object, key string, v interface{}, set bool) error {
+	annotations := obj.GetAnnotations()
+	if !set {
+		delete(annotations, key)
+		obj.SetAnnotations(annotations)
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[key] = string(b)
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+func (r *DpuNodePoolReconciler) updatePoolStatus(ctx context.Context, pool *dpuv1alpha1.DpuNodePool) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &dpuv1alpha1.DpuNodePool{}
+		if err := r.Get(ctx, types.NamespacedName{Name: pool.Name}, latest); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if equality.Semantic.DeepEqual(latest.Status, pool.Status) {
+			return nil
+		}
+		latest.Status = pool.Status
+		return r.Status().Update(ctx, latest)
+	})
+}
+
+// allDpuNodePools maps an event to every DpuNodePool: of a node, since any
+// of them may select it, or of a change of the platform.
+func (r *DpuNodePoolReconciler) allDpuNodePools(obj client.Object) []reconcile.Request {
+	pools := &dpuv1alpha1.DpuNodePoolList{}
+	if err := r.List(context.TODO(), pools); err != nil {
+		logger.Error(err, "failed to list DpuNodePools")
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, pool := range pools.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: pool.Name}})
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DpuNodePoolReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&dpuv1alpha1.DpuNodePool{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WithOptions(workerOptions(r.MaxConcurrentReconciles)).
+		Watches(&source.Kind{Type: &corev1.Node{}},
+			handler.EnqueueRequestsFromMapFunc(r.allDpuNodePools),
+			builder.WithPredicates(predicate.Or(nodeLabelsChanged, nodeInventoryChanged))).
+		Watches(platformChanges(r.Platform, &dpuv1alpha1.DpuNodePool{}), handler.EnqueueRequestsFromMapFunc(r.allDpuNodePools))
+	c, err := b.Build(isolateReconcile("dpunodepool", r.Reconcile))
+	if err != nil {
+		return err
+	}
+	return whenMachineConfigServed(r.Platform, func() error {
+		return c.Watch(&source.Kind{Type: &mcfgv1.MachineConfigPool{}},
+			&handler.EnqueueRequestForOwner{OwnerType: &dpuv1alpha1.DpuNodePool{}, IsController: true})
+	})
+}
@@ -0,0 +1,334 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+//+kubebuilder:rbac:groups=dpu.openshift.io,resources=dpupacketcaptures,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=dpu.openshift.io,resources=dpupacketcaptures/status,verbs=get;update;patch
+
+const (
+	captureControllerName = "dpupacketcapture"
+
+	// the capture pods are polled like the trace pods
+	captureRequeueInterval = 5 * time.Second
+
+	defaultCaptureDurationSeconds  = 30
+	defaultCaptureMaxSizeMiB       = 100
+	defaultCaptureRetentionSeconds = 3600
+	captureMountPath               = "/captures"
+	// captureGraceSeconds is left to the capture pod to start, over the
+	// duration and the retention
+	captureGraceSeconds = 300
+
+	captureDoneMarker = "### capture done"
+)
+
+// captureScript captures the packets of the interface until the duration or
+// the size cap is reached, then keeps the pod up for the retention, if any,
+// so the capture can be copied out.
+const captureScript = `
+if ! ip link show "${IFACE}" >/dev/null; then
+  echo "no interface ${IFACE} on this DPU"
+  exit 1
+fi
+file="` + captureMountPath + `/${CAPTURE_NAME}.pcap"
+echo "capturing ${IFACE} for ${DURATION}s, up to ${MAX_BYTES} bytes"
+timeout "${DURATION}" tcpdump -n -U -i "${IFACE}" -w - ${FILTER:+"${FILTER}"} | head -c "${MAX_BYTES}" > "${file}"
+echo "` + captureDoneMarker + ` $(stat -c %s "${file}")"
+if [[ "${RETENTION}" -gt 0 ]]; then
+  sleep "${RETENTION}"
+fi
+`
+
+var captureDoneRegexp = regexp.MustCompile(regexp.QuoteMeta(captureDoneMarker) + ` (\d+)`)
+
+func (r *OVNKubeConfigReconciler) setupCaptureController(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(captureControllerName).
+		For(&dpuv1alpha1.DpuPacketCapture{}).
+		Complete(reconcile.Func(r.reconcileCapture))
+}
+
+// reconcileCapture runs the capture of a DpuPacketCapture once, in a
+// privileged pod on its DPU node, and reports where to get the pcap file.
+// Without a PersistentVolumeClaim, the pod keeps the capture for the
+// retention and is then deleted.
+func (r *OVNKubeConfigReconciler) reconcileCapture(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("reconcile DpuPacketCapture", req.NamespacedName)
+	logger.Info("Reconcile")
+
+	capture := &dpuv1alpha1.DpuPacketCapture{}
+	if err := r.Get(ctx, req.NamespacedName, capture); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	// a stored capture is done with, one kept in its pod once it expired
+	if capture.Status.Phase == dpuv1alpha1.DpuPacketCaptureFailed ||
+		(capture.Status.Phase == dpuv1alpha1.DpuPacketCaptureSucceeded && (capture.Spec.PersistentVolumeClaim != nil || capture.Status.Artifact == "")) {
+		return ctrl.Result{}, nil
+	}
+
+	result, err := r.runCapture(ctx, capture)
+	if _, ok := err.(*permanentError); ok {
+		setCaptureFailed(capture, err.Error())
+		err = nil
+	} else if err != nil && capture.Status.Phase != dpuv1alpha1.DpuPacketCaptureSucceeded {
+		capture.Status.Phase = dpuv1alpha1.DpuPacketCapturePending
+		capture.Status.Message = err.Error()
+	}
+	if uerr := r.updateCaptureStatus(ctx, capture); uerr != nil {
+		logger.Error(uerr, "unable to update DpuPacketCapture status")
+	}
+	return result, err
+}
+
+// runCapture starts the capture pod, records the capture once it is stored,
+// and deletes the pod once the capture is no longer needed in it.
+func (r *OVNKubeConfigReconciler) runCapture(ctx context.Context, capture *dpuv1alpha1.DpuPacketCapture) (ctrl.Result, error) {
+	podName := capturePodName(capture)
+	pod := &corev1.Pod{}
+	err := r.APIReader.Get(ctx, types.NamespacedName{Namespace: capture.Namespace, Name: podName}, pod)
+	if errors.IsNotFound(err) {
+		switch capture.Status.Phase {
+		case dpuv1alpha1.DpuPacketCaptureCapturing:
+			return ctrl.Result{}, permanentErrorf("the capture pod %s was deleted", podName)
+		case dpuv1alpha1.DpuPacketCaptureSucceeded:
+			expireCapture(capture)
+			return ctrl.Result{}, nil
+		}
+		pod, err = r.renderCapturePod(ctx, capture)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("Run packet capture", "pod", pod.Name, "node", pod.Spec.NodeName, "interface", capture.Spec.Interface)
+		if err := retryCreate(ctx, r.Client, pod); err != nil {
+			return ctrl.Result{}, err
+		}
+		now := metav1.Now()
+		capture.Status.Phase = dpuv1alpha1.DpuPacketCaptureCapturing
+		capture.Status.Message = ""
+		capture.Status.Pod = pod.Name
+		capture.Status.StartTime = &now
+		return ctrl.Result{RequeueAfter: time.Duration(captureDurationSeconds(capture)) * time.Second}, nil
+	} else if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	podDone := pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+	if capture.Status.Phase == dpuv1alpha1.DpuPacketCaptureSucceeded {
+		if !podDone {
+			return ctrl.Result{RequeueAfter: captureRequeueInterval}, nil
+		}
+		expireCapture(capture)
+		return ctrl.Result{}, client.IgnoreNotFound(r.Delete(ctx, pod))
+	}
+
+	out, err := r.kubeClient.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{Container: "capture"}).DoRaw(ctx)
+	if err != nil && pod.Status.Phase != corev1.PodFailed {
+		return ctrl.Result{RequeueAfter: captureRequeueInterval}, nil
+	}
+	match := captureDoneRegexp.FindStringSubmatch(string(out))
+	switch {
+	case match != nil:
+		now := metav1.Now()
+		capture.Status.Phase = dpuv1alpha1.DpuPacketCaptureSucceeded
+		capture.Status.Message = ""
+		capture.Status.CompletionTime = &now
+		capture.Status.SizeBytes, _ = strconv.ParseInt(match[1], 10, 64)
+		capture.Status.Artifact = captureArtifact(capture)
+		if capture.Spec.PersistentVolumeClaim != nil {
+			return ctrl.Result{}, client.IgnoreNotFound(r.Delete(ctx, pod))
+		}
+		return ctrl.Result{RequeueAfter: time.Duration(captureRetentionSeconds(capture)) * time.Second}, nil
+	case podDone:
+		msg := "the capture pod failed"
+		if logs := strings.TrimSpace(string(out)); logs != "" {
+			msg = fmt.Sprintf("the capture pod failed: %s", truncateTraceOutput(logs))
+		} else if pod.Status.Message != "" {
+			msg = fmt.Sprintf("the capture pod failed: %s", pod.Status.Message)
+		}
+		setCaptureFailed(capture, msg)
+		return ctrl.Result{}, client.IgnoreNotFound(r.Delete(ctx, pod))
+	}
+	return ctrl.Result{RequeueAfter: captureRequeueInterval}, nil
+}
+
+func setCaptureFailed(capture *dpuv1alpha1.DpuPacketCapture, msg string) {
+	now := metav1.Now()
+	capture.Status.Phase = dpuv1alpha1.DpuPacketCaptureFailed
+	capture.Status.Message = msg
+	capture.Status.CompletionTime = &now
+}
+
+// expireCapture records that the capture kept in the pod is gone.
+func expireCapture(capture *dpuv1alpha1.DpuPacketCapture) {
+	capture.Status.Artifact = ""
+	capture.Status.Message = "the capture expired with its pod"
+}
+
+func captureArtifact(capture *dpuv1alpha1.DpuPacketCapture) string {
+	file := capture.Name + ".pcap"
+	if claim := capture.Spec.PersistentVolumeClaim; claim != nil {
+		return fmt.Sprintf("persistentvolumeclaim/%s:/%s", claim.Name, file)
+	}
+	return fmt.Sprintf("oc cp -c capture %s/%s:%s/%s %s", capture.Namespace, capturePodName(capture), captureMountPath, file, file)
+}
+
+func capturePodName(capture *dpuv1alpha1.DpuPacketCapture) string {
+	name := "dpucapture-" + capture.Name
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return strings.TrimRight(name, "-.")
+}
+
+func captureDurationSeconds(capture *dpuv1alpha1.DpuPacketCapture) int32 {
+	if capture.Spec.DurationSeconds == 0 {
+		return defaultCaptureDurationSeconds
+	}
+	return capture.Spec.DurationSeconds
+}
+
+func captureRetentionSeconds(capture *dpuv1alpha1.DpuPacketCapture) int32 {
+	if capture.Spec.PersistentVolumeClaim != nil {
+		return 0
+	}
+	if capture.Spec.RetentionSeconds == 0 {
+		return defaultCaptureRetentionSeconds
+	}
+	return capture.Spec.RetentionSeconds
+}
+
+// renderCapturePod returns the pod capturing the packets of the
+// DpuPacketCapture on its DPU node, in the ovnkube image.
+func (r *OVNKubeConfigReconciler) renderCapturePod(ctx context.Context, capture *dpuv1alpha1.DpuPacketCapture) (*corev1.Pod, error) {
+	cfg, err := r.getNamespaceConfig(ctx, capture.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, permanentErrorf("no OVNKubeConfig in namespace %s", capture.Namespace)
+	}
+	if delegated(cfg) {
+		return nil, permanentErrorf("the DPUs of OVNKubeConfig %s are run from namespace %s", cfg.Name, targetNamespace(cfg))
+	}
+	// a capture may only run on the DPUs of the namespace
+	nodeSelector, err := r.poolNodeSelector(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(nodeSelector)
+	if err != nil {
+		return nil, err
+	}
+	node := &corev1.Node{}
+	err = r.Get(ctx, types.NamespacedName{Name: capture.Spec.NodeName}, node)
+	if errors.IsNotFound(err) {
+		return nil, permanentErrorf("node %s not found", capture.Spec.NodeName)
+	} else if err != nil {
+		return nil, err
+	}
+	if !selector.Matches(labels.Set(node.Labels)) {
+		return nil, permanentErrorf("node %s is not a DPU node of the OVNKubeConfig of namespace %s", node.Name, capture.Namespace)
+	}
+	image, err := r.getOvnkubeImage(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	maxSizeMiB := capture.Spec.MaxSizeMiB
+	if maxSizeMiB == 0 {
+		maxSizeMiB = defaultCaptureMaxSizeMiB
+	}
+	storage := corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}
+	if claim := capture.Spec.PersistentVolumeClaim; claim != nil {
+		storage = corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim.Name}}
+	}
+	privileged := true
+	deadline := int64(captureDurationSeconds(capture)+captureRetentionSeconds(capture)) + captureGraceSeconds
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      capturePodName(capture),
+			Namespace: capture.Namespace,
+		},
+		Spec: corev1.PodSpec{
+			NodeName:              node.Name,
+			RestartPolicy:         corev1.RestartPolicyNever,
+			HostNetwork:           true,
+			ServiceAccountName:    utils.SaNameOvnkubeNode,
+			ImagePullSecrets:      cfg.Spec.ImagePullSecrets,
+			Tolerations:           []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			ActiveDeadlineSeconds: &deadline,
+			Containers: []corev1.Container{{
+				Name:    "capture",
+				Image:   image,
+				Command: []string{"/bin/bash", "-c", captureScript},
+				Env: []corev1.EnvVar{
+					{Name: "CAPTURE_NAME", Value: capture.Name},
+					{Name: "IFACE", Value: capture.Spec.Interface},
+					{Name: "FILTER", Value: capture.Spec.Filter},
+					{Name: "DURATION", Value: strconv.Itoa(int(captureDurationSeconds(capture)))},
+					{Name: "MAX_BYTES", Value: strconv.Itoa(int(maxSizeMiB) << 20)},
+					{Name: "RETENTION", Value: strconv.Itoa(int(captureRetentionSeconds(capture)))},
+				},
+				SecurityContext: &corev1.SecurityContext{Privileged: &privileged},
+				VolumeMounts:    []corev1.VolumeMount{{Name: "captures", MountPath: captureMountPath}},
+			}},
+			Volumes: []corev1.Volume{{Name: "captures", VolumeSource: storage}},
+		},
+	}
+	if err := ctrl.SetControllerReference(capture, pod, r.Scheme); err != nil {
+		return nil, err
+	}
+	return pod, nil
+}
+
+func (r *OVNKubeConfigReconciler) updateCaptureStatus(ctx context.Context, capture *dpuv1alpha1.DpuPacketCapture) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &dpuv1alpha1.DpuPacketCapture{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: capture.Namespace, Name: capture.Name}, latest); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if equality.Semantic.DeepEqual(latest.Status, capture.Status) {
+			return nil
+		}
+		latest.Status = capture.Status
+		return r.Status().Update(ctx, latest)
+	})
+}
@@ -0,0 +1,479 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+//+kubebuilder:rbac:groups=dpu.openshift.io,resources=dputraces,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=dpu.openshift.io,resources=dputraces/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups="",resources=pods,verbs=create;delete
+//+kubebuilder:rbac:groups="",resources=pods/log,verbs=get
+
+const (
+	traceControllerName = "dputrace"
+
+	// the trace pods are polled rather than watched, to not cache every pod
+	traceRequeueInterval = 5 * time.Second
+	traceTimeoutSeconds  = 120
+	// traceOutputLimit bounds each trace output recorded in the status
+	traceOutputLimit = 32 * 1024
+
+	ovnTraceMarker     = "### ovn-trace"
+	ofprotoTraceMarker = "### ofproto/trace"
+
+	podNetworksAnnotation = "k8s.ovn.org/pod-networks"
+)
+
+// traceScript runs the traces on the DPU node with the OVN certificates of
+// ovnkube-node. The packet is sent to the gateway router of the node, unless
+// the destination is a pod of the same node.
+const traceScript = `
+ssl="-p /ovn-cert/tls.key -c /ovn-cert/tls.crt -C /ovn-ca/` + utils.OvnCABundleKey + `"
+if [[ -z "${DST_MAC}" ]]; then
+  DST_MAC=$(ovn-nbctl --db "${NB}" ${ssl} get logical_router_port "rtos-${SWITCH}" mac | tr -d '"')
+fi
+rc=0
+echo "` + ovnTraceMarker + `"
+ovn-trace --db "${SB}" ${ssl} "${SWITCH}" "eth.dst == ${DST_MAC} && ${MICROFLOW}" 2>&1 || rc=1
+echo "` + ofprotoTraceMarker + `"
+iface=$(ovs-vsctl --bare --columns=name find Interface "external_ids:iface-id=${INPORT}")
+if [[ -z "${iface}" ]]; then
+  echo "no OVS interface of the logical port ${INPORT} on this DPU"
+  exit 1
+fi
+ofport=$(ovs-vsctl get Interface "${iface}" ofport)
+ovs-appctl ofproto/trace br-int "in_port=${ofport},dl_dst=${DST_MAC},${FLOW}" 2>&1 || rc=1
+exit ${rc}
+`
+
+// permanentError is returned when a DpuTrace or a DpuPacketCapture cannot
+// run, whatever the retries.
+type permanentError struct {
+	msg string
+}
+
+func (e *permanentError) Error() string {
+	return e.msg
+}
+
+func permanentErrorf(format string, a ...interface{}) error {
+	return &permanentError{msg: fmt.Sprintf(format, a...)}
+}
+
+// tracedEndpoint is an end of the traced packet, resolved in the tenant
+// cluster.
+type tracedEndpoint struct {
+	ip  string
+	mac string
+	// node and port are the logical switch and the logical port of a pod
+	node string
+	port string
+}
+
+// podNetwork is the default network in the pod-networks annotation set by
+// OVN-Kubernetes.
+type podNetwork struct {
+	IPAddresses []string `json:"ip_addresses"`
+	MACAddress  string   `json:"mac_address"`
+}
+
+func (r *OVNKubeConfigReconciler) setupTraceController(mgr ctrl.Manager) error {
+	var err error
+	r.kubeClient, err = kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(traceControllerName).
+		For(&dpuv1alpha1.DpuTrace{}).
+		Complete(reconcile.Func(r.reconcileTrace))
+}
+
+// reconcileTrace runs the traces of a DpuTrace once, in a pod on the DPU
+// node of the source pod, and records their output in its status. The pod is
+// deleted once done; deleting and creating the DpuTrace again traces again.
+func (r *OVNKubeConfigReconciler) reconcileTrace(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("reconcile DpuTrace", req.NamespacedName)
+	logger.Info("Reconcile")
+
+	trace := &dpuv1alpha1.DpuTrace{}
+	if err := r.Get(ctx, req.NamespacedName, trace); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if trace.Status.Phase == dpuv1alpha1.DpuTraceSucceeded || trace.Status.Phase == dpuv1alpha1.DpuTraceFailed {
+		return ctrl.Result{}, nil
+	}
+
+	result, err := r.runTrace(ctx, trace)
+	if _, ok := err.(*permanentError); ok {
+		setTraceDone(trace, dpuv1alpha1.DpuTraceFailed, err.Error())
+		err = nil
+	} else if err != nil {
+		trace.Status.Phase = dpuv1alpha1.DpuTracePending
+		trace.Status.Message = err.Error()
+	}
+	if uerr := r.updateTraceStatus(ctx, trace); uerr != nil {
+		logger.Error(uerr, "unable to update DpuTrace status")
+	}
+	return result, err
+}
+
+// runTrace starts the trace pod, and records the traces once it is done.
+func (r *OVNKubeConfigReconciler) runTrace(ctx context.Context, trace *dpuv1alpha1.DpuTrace) (ctrl.Result, error) {
+	pod := &corev1.Pod{}
+	err := r.APIReader.Get(ctx, types.NamespacedName{Namespace: trace.Namespace, Name: tracePodName(trace)}, pod)
+	if errors.IsNotFound(err) {
+		if trace.Status.Phase == dpuv1alpha1.DpuTraceRunning {
+			return ctrl.Result{}, permanentErrorf("the trace pod %s was deleted", tracePodName(trace))
+		}
+		pod, err = r.renderTracePod(ctx, trace)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("Run trace", "pod", pod.Name, "node", pod.Spec.NodeName)
+		if err := retryCreate(ctx, r.Client, pod); err != nil {
+			return ctrl.Result{}, err
+		}
+		trace.Status.Phase = dpuv1alpha1.DpuTraceRunning
+		trace.Status.Message = ""
+		trace.Status.Node = pod.Spec.NodeName
+		return ctrl.Result{RequeueAfter: traceRequeueInterval}, nil
+	} else if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+		return ctrl.Result{RequeueAfter: traceRequeueInterval}, nil
+	}
+	out, err := r.kubeClient.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{Container: "trace"}).DoRaw(ctx)
+	if err != nil && pod.Status.Phase == corev1.PodSucceeded {
+		return ctrl.Result{}, fmt.Errorf("couldn't read the logs of the trace pod %s: %v", pod.Name, err)
+	}
+	trace.Status.OvnTrace, trace.Status.OfprotoTrace = parseTraceOutput(string(out))
+	if pod.Status.Phase == corev1.PodSucceeded {
+		setTraceDone(trace, dpuv1alpha1.DpuTraceSucceeded, "")
+	} else {
+		msg := "the trace pod failed"
+		if pod.Status.Message != "" {
+			msg = fmt.Sprintf("the trace pod failed: %s", pod.Status.Message)
+		}
+		setTraceDone(trace, dpuv1alpha1.DpuTraceFailed, msg)
+	}
+	if err := r.Delete(ctx, pod); client.IgnoreNotFound(err) != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+func setTraceDone(trace *dpuv1alpha1.DpuTrace, phase dpuv1alpha1.DpuTracePhase, msg string) {
+	now := metav1.Now()
+	trace.Status.Phase = phase
+	trace.Status.Message = msg
+	trace.Status.CompletionTime = &now
+}
+
+func tracePodName(trace *dpuv1alpha1.DpuTrace) string {
+	name := "dputrace-" + trace.Name
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return strings.TrimRight(name, "-.")
+}
+
+// renderTracePod returns the pod tracing the packet of the DpuTrace on the
+// DPU node of its source pod, in the ovnkube image.
+func (r *OVNKubeConfigReconciler) renderTracePod(ctx context.Context, trace *dpuv1alpha1.DpuTrace) (*corev1.Pod, error) {
+	cfg, err := r.getNamespaceConfig(ctx, trace.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, permanentErrorf("no OVNKubeConfig in namespace %s", trace.Namespace)
+	}
+	if delegated(cfg) {
+		return nil, permanentErrorf("the DPUs of OVNKubeConfig %s are run from namespace %s", cfg.Name, targetNamespace(cfg))
+	}
+	if !isOvnDataPlane(cfg) {
+		return nil, permanentErrorf("the DPUs of namespace %s don't run OVN-Kubernetes", trace.Namespace)
+	}
+	tenantClient := r.connectedTenantClient(cfg.Namespace)
+	if tenantClient == nil {
+		return nil, fmt.Errorf("the tenant cluster is not connected yet")
+	}
+	if trace.Spec.Source.Pod == "" {
+		return nil, permanentErrorf("spec.source.pod must be set")
+	}
+	src, err := resolveTraceEndpoint(ctx, tenantClient, trace.Spec.Source, "spec.source")
+	if err != nil {
+		return nil, err
+	}
+	dst, err := resolveTraceEndpoint(ctx, tenantClient, trace.Spec.Destination, "spec.destination")
+	if err != nil {
+		return nil, err
+	}
+	microflow, flow, err := traceFlows(trace, src, dst)
+	if err != nil {
+		return nil, err
+	}
+	dstMac := ""
+	if dst.node == src.node {
+		dstMac = dst.mac
+	}
+	dpuNode, err := r.dpuNodeOfHost(ctx, cfg.Namespace, src.node)
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := r.getOvnkubeImage(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	nbDbList, sbDbList, err := r.getOvnDbLists(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	certMounts, certVolumes := ovnCertVolumes(cfg)
+	privileged := true
+	timeout := int64(traceTimeoutSeconds)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      tracePodName(trace),
+			Namespace: trace.Namespace,
+		},
+		Spec: corev1.PodSpec{
+			NodeName:              dpuNode,
+			RestartPolicy:         corev1.RestartPolicyNever,
+			HostNetwork:           true,
+			ServiceAccountName:    utils.SaNameOvnkubeNode,
+			ImagePullSecrets:      cfg.Spec.ImagePullSecrets,
+			Tolerations:           []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			ActiveDeadlineSeconds: &timeout,
+			Containers: []corev1.Container{{
+				Name:    "trace",
+				Image:   image,
+				Command: []string{"/bin/bash", "-c", traceScript},
+				Env: []corev1.EnvVar{
+					{Name: "NB", Value: nbDbList},
+					{Name: "SB", Value: sbDbList},
+					{Name: "SWITCH", Value: src.node},
+					{Name: "INPORT", Value: src.port},
+					{Name: "DST_MAC", Value: dstMac},
+					{Name: "MICROFLOW", Value: microflow},
+					{Name: "FLOW", Value: flow},
+				},
+				SecurityContext: &corev1.SecurityContext{Privileged: &privileged},
+				VolumeMounts: append([]corev1.VolumeMount{
+					{Name: "run-openvswitch", MountPath: "/run/openvswitch"},
+				}, certMounts...),
+			}},
+			Volumes: append([]corev1.Volume{
+				{Name: "run-openvswitch", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/run/openvswitch"}}},
+			}, certVolumes...),
+		},
+	}
+	if err := ctrl.SetControllerReference(trace, pod, r.Scheme); err != nil {
+		return nil, err
+	}
+	return pod, nil
+}
+
+// ovnCertVolumes returns the mounts and volumes of the OVN certificate of
+// ovnkube-node and of the OVN CA, under /ovn-cert and /ovn-ca, for the pods
+// connecting to the OVN DBs.
+func ovnCertVolumes(cfg *dpuv1alpha1.OVNKubeConfig) ([]corev1.VolumeMount, []corev1.Volume) {
+	caVolume := corev1.VolumeSource{
+		ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: utils.CmNameOvnCa}},
+	}
+	if cfg.Spec.Ovn.CASecretRef != nil {
+		caVolume = corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: cfg.Spec.Ovn.CASecretRef.Name}}
+	}
+	mounts := []corev1.VolumeMount{
+		{Name: "ovn-cert", MountPath: "/ovn-cert", ReadOnly: true},
+		{Name: "ovn-ca", MountPath: "/ovn-ca", ReadOnly: true},
+	}
+	volumes := []corev1.Volume{
+		{Name: "ovn-cert", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: utils.SecretNameOvnCert}}},
+		{Name: "ovn-ca", VolumeSource: caVolume},
+	}
+	return mounts, volumes
+}
+
+// resolveTraceEndpoint returns the addresses of the endpoint, and its
+// logical switch and port if it is a pod.
+func resolveTraceEndpoint(ctx context.Context, c client.Client, ep dpuv1alpha1.TraceEndpoint, field string) (*tracedEndpoint, error) {
+	if ep.IP != "" && net.ParseIP(ep.IP) == nil {
+		return nil, permanentErrorf("%s.ip %q is not an IP address", field, ep.IP)
+	}
+	if ep.Pod == "" {
+		if ep.IP == "" {
+			return nil, permanentErrorf("%s must set pod or ip", field)
+		}
+		return &tracedEndpoint{ip: ep.IP}, nil
+	}
+
+	pod := &corev1.Pod{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: ep.Namespace, Name: ep.Pod}, pod)
+	if errors.IsNotFound(err) {
+		return nil, permanentErrorf("%s pod %s/%s not found in the tenant cluster", field, ep.Namespace, ep.Pod)
+	} else if err != nil {
+		return nil, dpuerrors.TenantUnreachable(err)
+	}
+	networks := map[string]podNetwork{}
+	if err := json.Unmarshal([]byte(pod.Annotations[podNetworksAnnotation]), &networks); err != nil || networks["default"].MACAddress == "" {
+		return nil, permanentErrorf("%s pod %s/%s is not attached to the OVN-Kubernetes network", field, ep.Namespace, ep.Pod)
+	}
+	network := networks["default"]
+	resolved := &tracedEndpoint{
+		ip:   ep.IP,
+		mac:  network.MACAddress,
+		node: pod.Spec.NodeName,
+		port: ep.Namespace + "_" + ep.Pod,
+	}
+	if resolved.ip == "" && len(network.IPAddresses) > 0 {
+		ip, _, err := net.ParseCIDR(network.IPAddresses[0])
+		if err != nil {
+			return nil, permanentErrorf("%s pod %s/%s has an invalid address %q", field, ep.Namespace, ep.Pod, network.IPAddresses[0])
+		}
+		resolved.ip = ip.String()
+	}
+	return resolved, nil
+}
+
+// traceFlows returns the ovn-trace microflow and the OpenFlow flow of the
+// packet, without their destination MAC.
+func traceFlows(trace *dpuv1alpha1.DpuTrace, src, dst *tracedEndpoint) (string, string, error) {
+	ipv6 := strings.Contains(src.ip, ":")
+	if ipv6 != strings.Contains(dst.ip, ":") {
+		return "", "", permanentErrorf("the source %s and the destination %s are not of the same IP family", src.ip, dst.ip)
+	}
+	ip, nwSrc, nwDst, suffix := "ip4", "nw_src", "nw_dst", ""
+	if ipv6 {
+		ip, nwSrc, nwDst, suffix = "ip6", "ipv6_src", "ipv6_dst", "6"
+	}
+	microflow := fmt.Sprintf("inport == %q && eth.src == %s && %s.src == %s && %s.dst == %s && ip.ttl == 64",
+		src.port, src.mac, ip, src.ip, ip, dst.ip)
+	flow := fmt.Sprintf("dl_src=%s,%s=%s,%s=%s,nw_ttl=64", src.mac, nwSrc, src.ip, nwDst, dst.ip)
+
+	switch protocol := strings.ToLower(trace.Spec.Protocol); protocol {
+	case "tcp", "udp":
+		if trace.Spec.Port == 0 {
+			return "", "", permanentErrorf("spec.port must be set for %s", trace.Spec.Protocol)
+		}
+		port := strconv.Itoa(int(trace.Spec.Port))
+		microflow += fmt.Sprintf(" && %s.dst == %s", protocol, port)
+		flow = protocol + suffix + "," + flow + ",tp_dst=" + port
+	default:
+		// echo request
+		if ipv6 {
+			microflow += " && icmp6.type == 128"
+			flow = "icmp6," + flow + ",icmp_type=128"
+		} else {
+			microflow += " && icmp4.type == 8"
+			flow = "icmp," + flow + ",icmp_type=8"
+		}
+	}
+	return microflow, flow, nil
+}
+
+// dpuNodeOfHost returns the DPU node of the tenant host.
+func (r *OVNKubeConfigReconciler) dpuNodeOfHost(ctx context.Context, namespace, host string) (string, error) {
+	dpuNodes, err := r.dpuNodesByHost(ctx, namespace)
+	if errors.IsNotFound(err) {
+		return "", permanentErrorf("ConfigMap %s not found, the DPU of host %s is unknown", utils.CmNameEnvOverrides, host)
+	} else if err != nil {
+		return "", err
+	}
+	if node, ok := dpuNodes[host]; ok {
+		return node, nil
+	}
+	return "", permanentErrorf("host %s has no DPU in ConfigMap %s", host, utils.CmNameEnvOverrides)
+}
+
+// dpuNodesByHost maps the tenant hosts to their DPU node, from the
+// TENANT_K8S_NODE of the env overrides of ovnkube-node.
+func (r *OVNKubeConfigReconciler) dpuNodesByHost(ctx context.Context, namespace string) (map[string]string, error) {
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: utils.CmNameEnvOverrides}, cm); err != nil {
+		return nil, err
+	}
+	dpuNodes := map[string]string{}
+	for node, env := range cm.Data {
+		for _, line := range strings.Split(env, "\n") {
+			key, value, ok := strings.Cut(strings.TrimPrefix(strings.TrimSpace(line), "export "), "=")
+			if ok && key == "TENANT_K8S_NODE" {
+				dpuNodes[strings.Trim(value, `"'`)] = node
+			}
+		}
+	}
+	return dpuNodes, nil
+}
+
+// parseTraceOutput splits the log of the trace pod into the ovn-trace and
+// the ofproto/trace outputs.
+func parseTraceOutput(out string) (string, string) {
+	ovnTrace, ofprotoTrace := out, ""
+	if i := strings.Index(out, ofprotoTraceMarker+"\n"); i >= 0 {
+		ovnTrace, ofprotoTrace = out[:i], out[i+len(ofprotoTraceMarker)+1:]
+	}
+	ovnTrace = strings.TrimPrefix(ovnTrace, ovnTraceMarker+"\n")
+	return truncateTraceOutput(ovnTrace), truncateTraceOutput(ofprotoTrace)
+}
+
+func truncateTraceOutput(out string) string {
+	if len(out) <= traceOutputLimit {
+		return out
+	}
+	return out[:traceOutputLimit] + "\n... truncated"
+}
+
+func (r *OVNKubeConfigReconciler) updateTraceStatus(ctx context.Context, trace *dpuv1alpha1.DpuTrace) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &dpuv1alpha1.DpuTrace{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: trace.Namespace, Name: trace.Name}, latest); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if equality.Semantic.DeepEqual(latest.Status, trace.Status) {
+			return nil
+		}
+		latest.Status = trace.Status
+		return r.Status().Update(ctx, latest)
+	})
+}
@@ -0,0 +1,342 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+const (
+	// driftTick is how often the OVNKubeConfigs are checked for a due
+	// drift check.
+	driftTick = 1 * time.Minute
+
+	defaultDriftInterval = 24 * time.Hour
+
+	// maxDriftPaths bounds the differing fields reported per object.
+	maxDriftPaths = 3
+
+	eventReasonDriftDetected = "DriftDetected"
+)
+
+var driftedObjects = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "dpu_network_operator_drifted_objects",
+	Help: "Number of live objects differing from their rendering at the last drift check.",
+}, []string{"namespace"})
+
+func init() {
+	metrics.Registry.MustRegister(driftedObjects)
+}
+
+// runDriftDetection checks, until ctx is done, the OVNKubeConfigs with drift
+// detection enabled whose last check is older than their interval. It runs
+// as a manager Runnable, outside of the reconcile loops, since nothing is
+// applied and the checks must also run while changes are held.
+func (r *OVNKubeConfigReconciler) runDriftDetection(ctx context.Context) error {
+	ticker := time.NewTicker(driftTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		cfgList := &dpuv1alpha1.OVNKubeConfigList{}
+		if err := r.List(ctx, cfgList); err != nil {
+			logger.Error(err, "failed to list OVNKubeConfigs for drift detection")
+			continue
+		}
+		for i := range cfgList.Items {
+			cfg := &cfgList.Items[i]
+			if delegated(cfg) || !driftCheckDue(cfg, time.Now()) {
+				continue
+			}
+			if err := r.checkDrift(ctx, cfg); err != nil {
+				logger.Error(err, "failed to report drift", "namespace", cfg.Namespace, "name", cfg.Name)
+			}
+		}
+	}
+}
+
+// driftCheckDue reports whether cfg must be checked at now. A report left
+// over from a disabled drift detection is due to be cleared.
+func driftCheckDue(cfg *dpuv1alpha1.OVNKubeConfig, now time.Time) bool {
+	if cfg.Spec.DriftDetection == nil {
+		return cfg.Status.Drift != nil
+	}
+	if cfg.Status.Drift == nil {
+		return true
+	}
+	interval := cfg.Spec.DriftDetection.Interval.Duration
+	if interval <= 0 {
+		interval = defaultDriftInterval
+	}
+	return !now.Before(cfg.Status.Drift.LastCheckTime.Add(interval))
+}
+
+// checkDrift publishes the drift report of cfg in its status, in the
+// drifted objects metric and, when objects drifted, in a warning event.
+func (r *OVNKubeConfigReconciler) checkDrift(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) error {
+	if cfg.Spec.DriftDetection == nil {
+		driftedObjects.DeleteLabelValues(cfg.Namespace)
+		cfg.Status.Drift = nil
+	} else {
+		drifted, err := r.detectDrift(ctx, cfg)
+		cfg.Status.Drift = &dpuv1alpha1.DriftReport{
+			LastCheckTime: metav1.Now(),
+			Objects:       drifted,
+		}
+		if err != nil {
+			cfg.Status.Drift.Error = err.Error()
+		}
+		driftedObjects.WithLabelValues(cfg.Namespace).Set(float64(len(drifted)))
+		if len(drifted) > 0 && r.Recorder != nil {
+			r.Recorder.Eventf(cfg, corev1.EventTypeWarning, eventReasonDriftDetected,
+				"%d objects differ from their rendering: %s", len(drifted), strings.Join(drifted, "; "))
+		}
+	}
+	return r.updateStatus(ctx, cfg, nil, func(dst, src *dpuv1alpha1.OVNKubeConfigStatus) {
+		dst.Drift = src.Drift
+	})
+}
+
+// detectDrift renders the objects managed for cfg, as the controllers would
+// apply them, and lists the live objects which are missing or differ. The
+// drift found before a rendering error is still returned.
+func (r *OVNKubeConfigReconciler) detectDrift(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) ([]string, error) {
+	desired, err := r.renderDesiredObjects(ctx, cfg)
+	drifted := []string{}
+	for _, obj := range desired {
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(obj.GroupVersionKind())
+		err := r.APIReader.Get(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}, live)
+		if errors.IsNotFound(err) {
+			drifted = append(drifted, fmt.Sprintf("%s %s: missing", obj.GetKind(), obj.GetName()))
+			continue
+		} else if err != nil {
+			return drifted, err
+		}
+		paths := objectDrift(obj.Object, live.Object)
+		if len(paths) == 0 {
+			continue
+		}
+		if len(paths) > maxDriftPaths {
+			paths = append(paths[:maxDriftPaths], "...")
+		}
+		drifted = append(drifted, fmt.Sprintf("%s %s: %s", obj.GetKind(), obj.GetName(), strings.Join(paths, ", ")))
+	}
+	sort.Strings(drifted)
+	return drifted, err
+}
+
+// renderDesiredObjects renders the switchdev MachineConfig, or the host
+// configuration on MicroShift, and unless spec.manageWorkloads is false, the
+// ovnkube-node and VF representor discovery objects. On error, the objects
+// rendered so far are returned.
+func (r *OVNKubeConfigReconciler) renderDesiredObjects(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) ([]*unstructured.Unstructured, error) {
+	desired := []*unstructured.Unstructured{}
+	nodeSelector, err := r.poolNodeSelector(ctx, cfg)
+	if err != nil {
+		return desired, err
+	}
+
+	mc, err := r.renderSwitchdevMachineConfig(ctx, cfg)
+	if err != nil {
+		return desired, err
+	}
+	switch {
+	case r.managesMachineConfig(cfg):
+		obj, err := r.toUnstructured(mc)
+		if err != nil {
+			return desired, err
+		}
+		desired = append(desired, obj)
+	case cfg.Spec.InfraFlavor == dpuv1alpha1.InfraFlavorMicroShift:
+		data, binaryData, err := hostConfigData(mc.Spec.Config.Raw)
+		if err != nil {
+			return desired, dpuerrors.RenderFailed(err)
+		}
+		revision := rolloutRevision(mc.Spec.Config.Raw)
+		obj, err := r.toUnstructured(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        utils.CmNameHostConfig,
+				Namespace:   cfg.Namespace,
+				Annotations: map[string]string{utils.HostConfigRevisionAnnotation: revision},
+			},
+			Data:       data,
+			BinaryData: binaryData,
+		})
+		if err != nil {
+			return desired, err
+		}
+		desired = append(desired, obj)
+		objs, err := r.renderHostConfig(ctx, cfg, revision)
+		if err != nil {
+			return desired, err
+		}
+		for _, obj := range objs {
+			if err := addNodeSelector(obj, nodeSelector); err != nil {
+				return desired, err
+			}
+			desired = append(desired, obj)
+		}
+	}
+	if !managesWorkloads(cfg) {
+		return desired, nil
+	}
+
+	objs, err := r.dataPlane(cfg).render(ctx, cfg)
+	if err != nil {
+		return desired, err
+	} else if objs == nil {
+		return desired, fmt.Errorf("the control plane of the tenant cluster is not found")
+	}
+	for _, obj := range objs {
+		if obj.GetKind() != "DaemonSet" {
+			desired = append(desired, obj)
+			continue
+		}
+		ds, err := ovnkubeNodeDaemonSet(cfg, obj, nodeSelector)
+		if err != nil {
+			return desired, err
+		}
+		for _, shard := range shardDaemonSets(ds, rolloutShards(cfg)) {
+			obj, err := r.toUnstructured(shard)
+			if err != nil {
+				return desired, err
+			}
+			desired = append(desired, obj)
+		}
+	}
+
+	image, err := r.getOvnkubeImage(ctx, cfg)
+	if err != nil {
+		return desired, err
+	}
+	objs, err = r.renderVfRepresentorDiscovery(ctx, cfg, image)
+	if err != nil {
+		return desired, err
+	}
+	for _, obj := range objs {
+		if err := addNodeSelector(obj, nodeSelector); err != nil {
+			return desired, err
+		}
+		desired = append(desired, obj)
+	}
+	return desired, nil
+}
+
+// toUnstructured converts a typed object, keeping its kind.
+func (r *OVNKubeConfigReconciler) toUnstructured(obj client.Object) (*unstructured.Unstructured, error) {
+	gvks, _, err := r.Scheme.ObjectKinds(obj)
+	if err != nil {
+		return nil, err
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{Object: content}
+	u.SetGroupVersionKind(gvks[0])
+	return u, nil
+}
+
+// objectDrift returns the paths of the fields set in the rendered object
+// which differ in the live one. Of the metadata, only the labels and
+// annotations are compared, and the status is ignored.
+func objectDrift(desired, live map[string]interface{}) []string {
+	paths := []string{}
+	for k, v := range desired {
+		switch k {
+		case "apiVersion", "kind", "status":
+			continue
+		case "metadata":
+			dm, _ := v.(map[string]interface{})
+			lm, _ := live[k].(map[string]interface{})
+			for _, f := range []string{"labels", "annotations"} {
+				paths = append(paths, fieldDrift("metadata."+f, dm[f], lm[f])...)
+			}
+		default:
+			paths = append(paths, fieldDrift(k, v, live[k])...)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// fieldDrift compares a rendered field with the live one. Fields left empty
+// in the rendering are ignored, as well as the fields only set in the live
+// object, e.g. by defaulting, but lists must have the same length.
+func fieldDrift(path string, desired, live interface{}) []string {
+	switch d := desired.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		if len(d) == 0 {
+			return nil
+		}
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			return []string{path}
+		}
+		paths := []string{}
+		for k, v := range d {
+			paths = append(paths, fieldDrift(path+"."+k, v, l[k])...)
+		}
+		return paths
+	case []interface{}:
+		if len(d) == 0 {
+			return nil
+		}
+		l, ok := live.([]interface{})
+		if !ok || len(l) != len(d) {
+			return []string{path}
+		}
+		paths := []string{}
+		for i := range d {
+			paths = append(paths, fieldDrift(fmt.Sprintf("%s[%d]", path, i), d[i], l[i])...)
+		}
+		return paths
+	default:
+		// the numbers of the rendered objects are decoded as int64 or
+		// float64 depending on their origin
+		if fmt.Sprint(desired) != fmt.Sprint(live) {
+			return []string{path}
+		}
+		return nil
+	}
+}
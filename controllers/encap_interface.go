@@ -0,0 +1,93 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+// encapMatcher selects the OVN encapsulation address of a node among the
+// interface addresses published by the discovery DaemonSet.
+type encapMatcher struct {
+	pattern string
+	name    *regexp.Regexp
+	subnet  *net.IPNet
+}
+
+func newEncapMatcher(encap *dpuv1alpha1.EncapInterface) (*encapMatcher, error) {
+	name, err := regexp.Compile("^(?:" + encap.Name + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid encap interface %q: %v", encap.Name, err)
+	}
+	m := &encapMatcher{pattern: encap.Name, name: name}
+	if encap.Subnet != "" {
+		if _, m.subnet, err = net.ParseCIDR(encap.Subnet); err != nil {
+			return nil, fmt.Errorf("invalid encap subnet %q: %v", encap.Subnet, err)
+		}
+	}
+	return m, nil
+}
+
+// selectAddress returns the first interface, in name order, matching the
+// encap interface with an address on the subnet, and that address.
+func (m *encapMatcher) selectAddress(annotation string) (string, string, error) {
+	if annotation == "" {
+		return "", "", fmt.Errorf("the interface addresses are not published yet")
+	}
+	addrs := map[string][]string{}
+	if err := json.Unmarshal([]byte(annotation), &addrs); err != nil {
+		return "", "", fmt.Errorf("invalid %s annotation: %v", utils.InterfaceAddressesAnnotation, err)
+	}
+	names := make([]string, 0, len(addrs))
+	for name := range addrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	matched := []string{}
+	for _, name := range names {
+		if !m.name.MatchString(name) {
+			continue
+		}
+		matched = append(matched, name)
+		for _, cidr := range addrs[name] {
+			ip, _, err := net.ParseCIDR(cidr)
+			if err != nil {
+				continue
+			}
+			if m.subnet == nil || m.subnet.Contains(ip) {
+				return name, ip.String(), nil
+			}
+		}
+	}
+	switch {
+	case len(matched) == 0:
+		return "", "", fmt.Errorf("no interface matches %q", m.pattern)
+	case m.subnet == nil:
+		return "", "", fmt.Errorf("%s has no global address", strings.Join(matched, ", "))
+	default:
+		return "", "", fmt.Errorf("%s has no address in %s", strings.Join(matched, ", "), m.subnet)
+	}
+}
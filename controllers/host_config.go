@@ -0,0 +1,245 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/openshift/cluster-network-operator/pkg/render"
+	ctrlcommon "github.com/openshift/machine-config-operator/pkg/controller/common"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+	"github.com/openshift/dpu-network-operator/pkg/tracing"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+const eventReasonIPsecUnsupported = "IPsecUnsupported"
+
+// microshiftIPsecWarning returns a warning, also recorded as an event, when
+// OVN IPsec is enabled in the tenant cluster of a MicroShift infra cluster:
+// its hosts only get the files and units of the switchdev MachineConfig, not
+// the ipsec extension, so the IPsec tunnels of the DPU nodes are only set up
+// if their ostree image ships libreswan.
+func (r *OVNKubeConfigReconciler) microshiftIPsecWarning(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) string {
+	ipsec, err := r.ovnIPsecEnabled(ctx, cfg)
+	if err != nil || !ipsec {
+		return ""
+	}
+	msg := "OVN IPsec is enabled in the tenant cluster, but is not supported on microshift: the ipsec extension is not installed, the ostree image of the DPU hosts must ship libreswan"
+	if r.Recorder != nil {
+		r.Recorder.Event(cfg, corev1.EventTypeWarning, eventReasonIPsecUnsupported, msg)
+	}
+	return msg
+}
+
+// validateInfraFlavor rejects the settings a MicroShift infra cluster, which
+// runs on a single DPU without the machine-config-operator, can't honor.
+func (r *OVNKubeConfigReconciler) validateInfraFlavor(cfg *dpuv1alpha1.OVNKubeConfig) error {
+	if cfg.Spec.InfraFlavor != dpuv1alpha1.InfraFlavorMicroShift {
+		return nil
+	}
+	if r.Platform.Get().MachineConfig {
+		return fmt.Errorf("infraFlavor is microshift, but the infra cluster runs the machine-config-operator")
+	}
+	if cfg.Spec.NodeSelector == nil && cfg.Spec.PoolRef == nil {
+		return fmt.Errorf("nodeSelector or poolRef must be set on microshift")
+	}
+	if rolloutShards(cfg) > 1 {
+		return fmt.Errorf("rollout.shards must be 1 on microshift, which runs a single node")
+	}
+	return nil
+}
+
+// syncHostConfig writes the files and systemd units of the switchdev
+// MachineConfig to the DPU hosts of a MicroShift infra cluster, with the
+// dpu-host-config DaemonSet which reboots the hosts when they change. It
+// returns whether every host runs the current configuration.
+func (r *OVNKubeConfigReconciler) syncHostConfig(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) (bool, error) {
+	mc, err := r.renderSwitchdevMachineConfig(ctx, cfg)
+	if err != nil {
+		return false, err
+	}
+	data, binaryData, err := hostConfigData(mc.Spec.Config.Raw)
+	if err != nil {
+		return false, dpuerrors.RenderFailed(err)
+	}
+	revision := rolloutRevision(mc.Spec.Config.Raw)
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: utils.CmNameHostConfig, Namespace: cfg.Namespace}}
+	err = r.Get(ctx, types.NamespacedName{Namespace: cm.Namespace, Name: cm.Name}, cm)
+	if err == nil && cm.Annotations[utils.HostConfigRevisionAnnotation] != revision {
+		// like a MachineConfig update, the new configuration reboots the
+		// hosts
+		if err = checkMaintenanceWindow(cfg.Spec.MaintenanceWindow, time.Now(), "host configuration update"); err != nil {
+			return false, err
+		}
+		if err = r.runPreflightChecks(ctx, cfg, nil); err != nil {
+			return false, err
+		}
+		if err = r.runRolloutHook(ctx, cfg, hookTargetMachineConfig, hookPhasePre, revision); err != nil {
+			return false, err
+		}
+	} else if err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+	err = withApplyRetry(ctx, "ConfigMap", func() error {
+		_, err := controllerutil.CreateOrUpdate(ctx, r.Client, cm, func() error {
+			if cm.Annotations == nil {
+				cm.Annotations = map[string]string{}
+			}
+			cm.Annotations[utils.HostConfigRevisionAnnotation] = revision
+			cm.Data = data
+			cm.BinaryData = binaryData
+			return ctrl.SetControllerReference(cfg, cm, r.Scheme)
+		})
+		return err
+	})
+	if err != nil {
+		return false, conflictError(fmt.Errorf("failed to apply ConfigMap %s: %w", utils.CmNameHostConfig, err))
+	}
+
+	objs, err := r.renderHostConfig(ctx, cfg, revision)
+	if err != nil {
+		return false, err
+	}
+	nodeSelector, err := r.poolNodeSelector(ctx, cfg)
+	if err != nil {
+		return false, err
+	}
+	if err := r.applyRenderedObjects(ctx, cfg, objs, nodeSelector); err != nil {
+		return false, err
+	}
+
+	ds := &appsv1.DaemonSet{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: cfg.Namespace, Name: utils.CmNameHostConfig}, ds); err != nil {
+		return false, err
+	}
+	return ds.Spec.Template.Annotations[utils.HostConfigRevisionAnnotation] == revision && daemonSetRolledOut(ds), nil
+}
+
+// renderHostConfig renders the dpu-host-config DaemonSet installing the
+// given revision of the host configuration.
+func (r *OVNKubeConfigReconciler) renderHostConfig(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, revision string) ([]*unstructured.Unstructured, error) {
+	image, err := r.getOvnkubeImage(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	priorityClassName, err := r.getPriorityClassName(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	rdata := render.MakeRenderData()
+	rdata.Data["OvnKubeImage"] = image
+	rdata.Data["Namespace"] = cfg.Namespace
+	rdata.Data["PriorityClassName"] = priorityClassName
+	rdata.Data["ImagePullSecrets"] = imagePullSecretNames(cfg)
+	rdata.Data["Revision"] = revision
+	rdata.Data["SecurityContextConstraints"] = r.Platform.Get().SecurityContextConstraints
+	addExtraRenderData(rdata.Data, cfg)
+	_, span := tracing.Start(ctx, "render", "manifests", utils.HostConfigManifestPath)
+	objs, err := renderDir(cfg, utils.HostConfigManifestPath, &rdata)
+	span.RecordError(err)
+	span.End()
+	if err != nil {
+		return nil, dpuerrors.RenderFailed(err)
+	}
+	return objs, nil
+}
+
+// runHostConfigPostRollout runs the post-rollout hook and the connectivity
+// test once every host runs the current configuration.
+func (r *OVNKubeConfigReconciler) runHostConfigPostRollout(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) (ctrl.Result, error) {
+	ds := &appsv1.DaemonSet{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: cfg.Namespace, Name: utils.CmNameHostConfig}, ds); err != nil {
+		return ctrl.Result{}, err
+	}
+	revision := ds.Spec.Template.Annotations[utils.HostConfigRevisionAnnotation]
+	if revision == "" || !daemonSetRolledOut(ds) {
+		return ctrl.Result{}, nil
+	}
+	return r.runPostRollout(ctx, cfg, hookTargetMachineConfig, revision)
+}
+
+// hostConfigData flattens the files and systemd units of an ignition config
+// into ConfigMap entries, listed in the manifest read by the host-config
+// pods.
+func hostConfigData(raw []byte) (map[string]string, map[string][]byte, error) {
+	ign, err := ctrlcommon.ParseAndConvertConfig(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	data := map[string]string{}
+	binaryData := map[string][]byte{}
+	add := func(key string, b []byte) {
+		if utf8.Valid(b) {
+			data[key] = string(b)
+		} else {
+			binaryData[key] = b
+		}
+	}
+	manifest := []string{}
+	for i, f := range ign.Storage.Files {
+		b, err := ctrlcommon.DecodeIgnitionFileContents(f.Contents.Source, f.Contents.Compression)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decode %s: %v", f.Path, err)
+		}
+		mode := 0644
+		if f.Mode != nil {
+			mode = *f.Mode
+		}
+		key := fmt.Sprintf("file-%d", i)
+		add(key, b)
+		manifest = append(manifest, fmt.Sprintf("file %s %04o %s", key, mode, f.Path))
+	}
+	for i, u := range ign.Systemd.Units {
+		key := "-"
+		if u.Contents != nil {
+			key = fmt.Sprintf("unit-%d", i)
+			add(key, []byte(*u.Contents))
+		}
+		state := "-"
+		if u.Enabled != nil && *u.Enabled {
+			state = "enabled"
+		} else if u.Enabled != nil {
+			state = "disabled"
+		}
+		manifest = append(manifest, fmt.Sprintf("unit %s %s %s", key, state, u.Name))
+		for j, d := range u.Dropins {
+			if d.Contents == nil {
+				continue
+			}
+			dkey := fmt.Sprintf("unit-%d-dropin-%d", i, j)
+			add(dkey, []byte(*d.Contents))
+			manifest = append(manifest, fmt.Sprintf("dropin %s %s %s", dkey, u.Name, d.Name))
+		}
+	}
+	data["manifest"] = strings.Join(manifest, "\n") + "\n"
+	return data, binaryData, nil
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+	"github.com/openshift/dpu-network-operator/pkg/registry"
+)
+
+const (
+	// dpuArchitecture is the architecture of the Arm cores of the DPUs.
+	dpuArchitecture = "arm64"
+
+	// imagePlatformsTTL is how long the platforms of an image are cached,
+	// a tag may be pushed again. Failed lookups are retried sooner.
+	imagePlatformsTTL       = 1 * time.Hour
+	imagePlatformsFailedTTL = 10 * time.Minute
+
+	imageArchRequeueInterval = 5 * time.Minute
+)
+
+// imagePlatformsEntry is a cached lookup of the platforms of an image.
+type imagePlatformsEntry struct {
+	platforms []registry.Platform
+	err       error
+	expires   time.Time
+}
+
+var (
+	imagePlatformsMu    sync.Mutex
+	imagePlatformsCache = map[string]imagePlatformsEntry{}
+)
+
+// checkImageArchitectures fails with ImageArchMismatch when an image of ds
+// is not built for linux/arm64, rather than rolling out pods failing with
+// exec format errors on the DPUs, e.g. an amd64-only hotfix build. The
+// platforms are read from the registry with the pull secrets of ds. An image
+// whose manifest cannot be read, e.g. from a registry the operator cannot
+// reach, is not checked. IMAGE_ARCH_CHECK=false on the operator disables
+// the check.
+func (r *OVNKubeConfigReconciler) checkImageArchitectures(ctx context.Context, ds *appsv1.DaemonSet) error {
+	if os.Getenv("IMAGE_ARCH_CHECK") == "false" {
+		return nil
+	}
+	var keychain registry.Keychain
+	mismatched := []string{}
+	for _, image := range podImages(&ds.Spec.Template.Spec) {
+		platforms, err := cachedImagePlatforms(image, func() ([]registry.Platform, error) {
+			if keychain == nil {
+				var err error
+				if keychain, err = r.pullSecretKeychain(ctx, ds.Namespace, ds.Spec.Template.Spec.ImagePullSecrets); err != nil {
+					return nil, err
+				}
+			}
+			return registry.Platforms(ctx, image, keychain)
+		})
+		if err != nil {
+			logger.Info("Cannot read the platforms of the image, skipping its architecture check", "image", image, "reason", err.Error())
+			continue
+		}
+		if !hasPlatform(platforms, "linux", dpuArchitecture) {
+			names := []string{}
+			for _, p := range platforms {
+				names = append(names, p.String())
+			}
+			sort.Strings(names)
+			mismatched = append(mismatched, fmt.Sprintf("%s (%s)", image, strings.Join(names, ", ")))
+		}
+	}
+	if len(mismatched) > 0 {
+		return dpuerrors.ImageArchMismatch(fmt.Errorf("DaemonSet %s runs images not built for linux/%s, the architecture of the DPUs: %s",
+			ds.Name, dpuArchitecture, strings.Join(mismatched, "; ")))
+	}
+	return nil
+}
+
+// cachedImagePlatforms returns the cached platforms of image, or looks them
+// up with lookup.
+func cachedImagePlatforms(image string, lookup func() ([]registry.Platform, error)) ([]registry.Platform, error) {
+	imagePlatformsMu.Lock()
+	entry, ok := imagePlatformsCache[image]
+	imagePlatformsMu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.platforms, entry.err
+	}
+	platforms, err := lookup()
+	ttl := imagePlatformsTTL
+	if err != nil {
+		ttl = imagePlatformsFailedTTL
+	}
+	imagePlatformsMu.Lock()
+	imagePlatformsCache[image] = imagePlatformsEntry{platforms: platforms, err: err, expires: time.Now().Add(ttl)}
+	imagePlatformsMu.Unlock()
+	return platforms, err
+}
+
+func hasPlatform(platforms []registry.Platform, goos, arch string) bool {
+	for _, p := range platforms {
+		if p.OS == goos && p.Architecture == arch {
+			return true
+		}
+	}
+	return false
+}
+
+// pullSecretKeychain returns the registry credentials of the pull secrets
+// of a pod of namespace. The missing Secrets are skipped, like the kubelet
+// does.
+func (r *OVNKubeConfigReconciler) pullSecretKeychain(ctx context.Context, namespace string, refs []corev1.LocalObjectReference) (registry.Keychain, error) {
+	configs := [][]byte{}
+	for _, ref := range refs {
+		s := &corev1.Secret{}
+		err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, s)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if data, ok := s.Data[corev1.DockerConfigJsonKey]; ok {
+			configs = append(configs, data)
+		} else if data, ok := s.Data[corev1.DockerConfigKey]; ok {
+			// the legacy format is the auths of the current one
+			configs = append(configs, []byte(`{"auths":`+string(data)+`}`))
+		}
+	}
+	return registry.DockerConfigKeychain(configs...)
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+)
+
+const (
+	prepullDsName = "ovnkube-image-prepull"
+
+	prepullRequeueInterval = 30 * time.Second
+)
+
+// prepullError is returned while the images of a new ovnkube-node revision
+// are being pulled on the DPU nodes, ahead of the rollout.
+type prepullError struct {
+	images         []string
+	ready, desired int32
+}
+
+func (e *prepullError) Error() string {
+	return fmt.Sprintf("pre-pulling %v on the DPU nodes: %d/%d done", e.images, e.ready, e.desired)
+}
+
+// prepullImages holds the rollout of ds until the images it introduces are
+// cached on every node it runs on, so pulling them over the management link
+// of the DPUs doesn't extend the downtime of the rolling update. The images
+// are pulled by a DaemonSet which is removed once no rollout is pending.
+func (r *OVNKubeConfigReconciler) prepullImages(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, ds *appsv1.DaemonSet) error {
+	dss, err := r.listOvnkubeNodeDaemonSets(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	// without DaemonSets there is nothing to roll, the pods are created
+	// from scratch
+	images := podImages(&ds.Spec.Template.Spec)
+	pending := false
+	for i := range dss {
+		if !equality.Semantic.DeepEqual(images, podImages(&dss[i].Spec.Template.Spec)) {
+			pending = true
+		}
+	}
+	if !pending {
+		return r.deletePrepullDaemonSet(ctx, cfg)
+	}
+
+	prepull := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: prepullDsName, Namespace: cfg.Namespace}}
+	mutate := func() error {
+		labels := map[string]string{"app": prepullDsName}
+		prepull.Spec.Selector = &metav1.LabelSelector{MatchLabels: labels}
+		template := ds.Spec.Template.Spec
+		spec := corev1.PodSpec{
+			NodeSelector:       template.NodeSelector,
+			Affinity:           template.Affinity,
+			Tolerations:        template.Tolerations,
+			PriorityClassName:  template.PriorityClassName,
+			ImagePullSecrets:   template.ImagePullSecrets,
+			ServiceAccountName: template.ServiceAccountName,
+		}
+		// pulling the images is all that matters, the init containers exit
+		// right away and the pod then idles until it is deleted
+		for i, image := range images {
+			spec.InitContainers = append(spec.InitContainers, prepullContainer(fmt.Sprintf("prepull-%d", i), image, "true"))
+		}
+		spec.Containers = []corev1.Container{prepullContainer("wait", images[0], "sleep infinity")}
+		prepull.Spec.Template = corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: labels},
+			Spec:       spec,
+		}
+		return ctrl.SetControllerReference(cfg, prepull, r.Scheme)
+	}
+	err = withApplyRetry(ctx, "DaemonSet", func() error {
+		_, err := controllerutil.CreateOrUpdate(ctx, r.Client, prepull, mutate)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to apply DaemonSet %s: %v", prepullDsName, err)
+	}
+
+	st := prepull.Status
+	if prepull.Generation > 0 && st.ObservedGeneration >= prepull.Generation &&
+		st.UpdatedNumberScheduled == st.DesiredNumberScheduled && st.NumberReady == st.DesiredNumberScheduled {
+		return nil
+	}
+	return &prepullError{images: images, ready: st.NumberReady, desired: st.DesiredNumberScheduled}
+}
+
+func (r *OVNKubeConfigReconciler) deletePrepullDaemonSet(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) error {
+	prepull := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: prepullDsName, Namespace: cfg.Namespace}}
+	return client.IgnoreNotFound(r.Delete(ctx, prepull))
+}
+
+// podImages returns the sorted distinct images of a pod.
+func podImages(spec *corev1.PodSpec) []string {
+	set := map[string]bool{}
+	for _, c := range append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...) {
+		set[c.Image] = true
+	}
+	images := []string{}
+	for image := range set {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+	return images
+}
+
+func prepullContainer(name, image, cmd string) corev1.Container {
+	return corev1.Container{
+		Name:    name,
+		Image:   image,
+		Command: []string{"/bin/bash", "-c", cmd},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1m"),
+				corev1.ResourceMemory: resource.MustParse("10Mi"),
+			},
+		},
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+	}
+}
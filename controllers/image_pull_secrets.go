@@ -0,0 +1,102 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+// imagePullSecretNames returns the names of the pull secrets of the CR, as
+// consumed by the templates.
+func imagePullSecretNames(cfg *dpuv1alpha1.OVNKubeConfig) []string {
+	names := []string{}
+	for _, s := range cfg.Spec.ImagePullSecrets {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+// syncServiceAccountPullSecrets adds the pull secrets of the CR to a rendered
+// ServiceAccount. The apply keeps the imagePullSecrets of the existing
+// ServiceAccount, which hold the dockercfg Secret generated by OpenShift, so
+// they are merged here. The names added by the operator are recorded in an
+// annotation, to remove them once they are dropped from the CR.
+func (r *OVNKubeConfigReconciler) syncServiceAccountPullSecrets(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, namespace, name string) error {
+	sa := &corev1.ServiceAccount{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, sa); err != nil {
+		return err
+	}
+	managed := []string{}
+	if v, ok := sa.Annotations[utils.ManagedPullSecretsAnnotation]; ok {
+		if err := json.Unmarshal([]byte(v), &managed); err != nil {
+			logger.Error(err, "ignoring invalid managed pull secrets annotation", "serviceaccount", name)
+		}
+	}
+	desired := imagePullSecretNames(cfg)
+	if equality.Semantic.DeepEqual(managed, desired) {
+		return nil
+	}
+
+	patch := client.MergeFrom(sa.DeepCopy())
+	removed := map[string]bool{}
+	for _, n := range managed {
+		removed[n] = true
+	}
+	for _, n := range desired {
+		delete(removed, n)
+	}
+	present := map[string]bool{}
+	secrets := []corev1.LocalObjectReference{}
+	for _, s := range sa.ImagePullSecrets {
+		if removed[s.Name] {
+			continue
+		}
+		present[s.Name] = true
+		secrets = append(secrets, s)
+	}
+	for _, n := range desired {
+		if !present[n] {
+			secrets = append(secrets, corev1.LocalObjectReference{Name: n})
+		}
+	}
+	sa.ImagePullSecrets = secrets
+	b, err := json.Marshal(desired)
+	if err != nil {
+		return err
+	}
+	if sa.Annotations == nil {
+		sa.Annotations = map[string]string{}
+	}
+	sa.Annotations[utils.ManagedPullSecretsAnnotation] = string(b)
+	err = withApplyRetry(ctx, "ServiceAccount", func() error {
+		return r.Patch(ctx, sa, patch)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update the pull secrets of ServiceAccount %s: %v", name, err)
+	}
+	return nil
+}
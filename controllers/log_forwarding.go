@@ -0,0 +1,130 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift/cluster-network-operator/pkg/render"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+	"github.com/openshift/dpu-network-operator/pkg/tracing"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+//+kubebuilder:rbac:groups=logging.openshift.io,resources=clusterlogforwarders;clusterloggings,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,resourceNames=collect-application-logs;collect-infrastructure-logs,verbs=bind
+
+const (
+	logForwardingName   = "dpu-data-plane"
+	logCollectorSaName  = "dpu-log-collector"
+	loggingAPIGroup     = "logging.openshift.io"
+	loggingAPIVersion   = "v1"
+	clusterLoggingKind  = "ClusterLogging"
+	logForwarderKind    = "ClusterLogForwarder"
+	collectorRoleFormat = "dpu-log-collector-%s-%s"
+)
+
+// syncLogForwarding deploys the ClusterLogForwarder shipping the logs of the
+// DPU data plane to the logForwarding endpoint, with its collector scheduled
+// on the DPU nodes. The objects are deleted when logForwarding is unset.
+func (r *OVNKubeConfigReconciler) syncLogForwarding(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) error {
+	if cfg.Spec.LogForwarding == nil {
+		return r.deleteLogForwarding(ctx, cfg)
+	}
+	gk := schema.GroupKind{Group: loggingAPIGroup, Kind: logForwarderKind}
+	if err := requireAPI(r.RESTMapper(), gk, loggingAPIVersion, "the OpenShift Logging operator"); err != nil {
+		return err
+	}
+	nodeSelector, err := r.poolNodeSelector(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	objs, err := r.renderLogForwarding(ctx, cfg, nodeSelector)
+	if err != nil {
+		return err
+	}
+	return r.applyRenderedObjects(ctx, cfg, objs, nil)
+}
+
+func (r *OVNKubeConfigReconciler) renderLogForwarding(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, nodeSelector *metav1.LabelSelector) ([]*unstructured.Unstructured, error) {
+	lf := cfg.Spec.LogForwarding
+	data := render.MakeRenderData()
+	data.Data["Namespace"] = cfg.Namespace
+	data.Data["NodeSelector"] = nodeSelector.MatchLabels
+	data.Data["OutputType"] = lf.Type
+	data.Data["OutputURL"] = lf.URL
+	data.Data["OutputSecret"] = ""
+	if lf.Secret != nil {
+		data.Data["OutputSecret"] = lf.Secret.Name
+	}
+	addExtraRenderData(data.Data, cfg)
+
+	_, span := tracing.Start(ctx, "render", "manifests", utils.LogForwardingManifestPath)
+	objs, err := renderDir(cfg, utils.LogForwardingManifestPath, &data)
+	span.RecordError(err)
+	span.End()
+	if err != nil {
+		logger.Error(err, "Fail to render log-forwarding manifests")
+		return nil, dpuerrors.RenderFailed(err)
+	}
+	return objs, nil
+}
+
+// deleteLogForwarding deletes the objects of the log forwarding. The
+// ServiceAccount is deleted last, so its absence means there is nothing left
+// to delete.
+func (r *OVNKubeConfigReconciler) deleteLogForwarding(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) error {
+	sa := &corev1.ServiceAccount{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: cfg.Namespace, Name: logCollectorSaName}, sa)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	objs := []client.Object{}
+	for _, kind := range []string{logForwarderKind, clusterLoggingKind} {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(schema.GroupVersionKind{Group: loggingAPIGroup, Version: loggingAPIVersion, Kind: kind})
+		obj.SetNamespace(cfg.Namespace)
+		obj.SetName(logForwardingName)
+		objs = append(objs, obj)
+	}
+	for _, logs := range []string{"application", "infrastructure"} {
+		objs = append(objs, &rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf(collectorRoleFormat, logs, cfg.Namespace)}})
+	}
+	objs = append(objs, sa)
+	for _, obj := range objs {
+		if err := r.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+			return fmt.Errorf("failed to delete %s: %v", obj.GetName(), err)
+		}
+	}
+	logger.Info("Deleted the log forwarding", "namespace", cfg.Namespace)
+	return nil
+}
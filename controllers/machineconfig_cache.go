@@ -0,0 +1,157 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+)
+
+var machineConfigRenderCacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "dpu_network_operator_machineconfig_render_cache_lookups_total",
+	Help: "Number of lookups of the rendered MachineConfigs cache, by result.",
+}, []string{"result"})
+
+func init() {
+	metrics.Registry.MustRegister(machineConfigRenderCacheLookups)
+}
+
+// renderedMachineConfig is a MachineConfig rendered for a pool and role,
+// with the hash of the templates and inputs it was rendered from.
+type renderedMachineConfig struct {
+	key        string
+	generation int64
+	mc         *mcfgv1.MachineConfig
+}
+
+// machineConfigCache caches the last MachineConfig rendered per
+// OVNKubeConfig, pool and role, so the reconciles triggered by the watched
+// objects don't render and convert the same Ignition config again.
+var machineConfigCache = struct {
+	sync.Mutex
+	byPool map[string]*renderedMachineConfig
+}{byPool: map[string]*renderedMachineConfig{}}
+
+// machineConfigTemplatesHash is the hash of the MachineConfig templates,
+// which don't change while the operator runs.
+var machineConfigTemplatesHash = struct {
+	once sync.Once
+	hash string
+	err  error
+}{}
+
+// resetCachedMachineConfigs drops the MachineConfigs cached for the
+// OVNKubeConfig of the namespace, once it is deleted.
+func resetCachedMachineConfigs(namespace string) {
+	machineConfigCache.Lock()
+	defer machineConfigCache.Unlock()
+	for key := range machineConfigCache.byPool {
+		if strings.HasPrefix(key, namespace+"/") {
+			delete(machineConfigCache.byPool, key)
+		}
+	}
+}
+
+// cachedMachineConfig returns a copy of the MachineConfig rendered for the
+// pool of cfg and role when the templates, name and inputs are unchanged,
+// and calls render otherwise. An entry is dropped when the generation of cfg
+// changes, and failures are never cached.
+func cachedMachineConfig(cfg *dpuv1alpha1.OVNKubeConfig, name, role string, inputs map[string]interface{}, render func() (*mcfgv1.MachineConfig, error)) (*mcfgv1.MachineConfig, error) {
+	key, err := machineConfigCacheKey(name, inputs)
+	if err != nil {
+		// inputs which can't be hashed are always rendered
+		machineConfigRenderCacheLookups.WithLabelValues("uncacheable").Inc()
+		return render()
+	}
+	poolKey := cfg.Namespace + "/" + cfg.Name + "/" + cfgPoolName(cfg) + "/" + role
+
+	machineConfigCache.Lock()
+	cached := machineConfigCache.byPool[poolKey]
+	machineConfigCache.Unlock()
+	if cached != nil && cached.key == key && cached.generation == cfg.Generation {
+		machineConfigRenderCacheLookups.WithLabelValues("hit").Inc()
+		return cached.mc.DeepCopy(), nil
+	}
+	machineConfigRenderCacheLookups.WithLabelValues("miss").Inc()
+
+	mc, err := render()
+	machineConfigCache.Lock()
+	defer machineConfigCache.Unlock()
+	if err != nil {
+		delete(machineConfigCache.byPool, poolKey)
+		return nil, err
+	}
+	machineConfigCache.byPool[poolKey] = &renderedMachineConfig{key: key, generation: cfg.Generation, mc: mc.DeepCopy()}
+	return mc, nil
+}
+
+// machineConfigCacheKey hashes the templates, the name and the inputs of a
+// MachineConfig.
+func machineConfigCacheKey(name string, inputs map[string]interface{}) (string, error) {
+	machineConfigTemplatesHash.once.Do(func() {
+		machineConfigTemplatesHash.hash, machineConfigTemplatesHash.err = hashTemplates(machineConfigTemplates)
+	})
+	if machineConfigTemplatesHash.err != nil {
+		return "", machineConfigTemplatesHash.err
+	}
+	b, err := json.Marshal(inputs)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.New()
+	sum.Write([]byte(machineConfigTemplatesHash.hash))
+	sum.Write([]byte{0})
+	sum.Write([]byte(name))
+	sum.Write([]byte{0})
+	sum.Write(b)
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// hashTemplates hashes the paths and contents of the files under dir, in
+// lexical order.
+func hashTemplates(dir string) (string, error) {
+	sum := sha256.New()
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		sum.Write([]byte(path))
+		sum.Write([]byte{0})
+		sum.Write(b)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
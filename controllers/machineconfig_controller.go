@@ -0,0 +1,247 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/openshift/dpu-network-operator/api"
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+	"github.com/openshift/dpu-network-operator/pkg/tracing"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+// machineConfigConditions are the conditions owned by the machine-config
+// controller.
+var machineConfigConditions = []string{api.McpReady, api.WaitingForPreflight, api.PendingChanges, api.MachineConfigHooks, api.RenderFailed}
+
+// copyMachineConfigStatus copies the status fields owned by the
+// machine-config controller.
+func copyMachineConfigStatus(dst, src *dpuv1alpha1.OVNKubeConfigStatus) {
+	copyVerification(dst, src, hookTargetMachineConfig)
+}
+
+// reconcileMachineConfig syncs the labels of the DPU nodes, the
+// MachineConfigPool and the switchdev MachineConfig. On MicroShift, the
+// switchdev configuration is written to the hosts instead, and only the
+// labels are synced on other clusters without the machine-config-operator,
+// or with spec.manageMachineConfig false.
+func (r *OVNKubeConfigReconciler) reconcileMachineConfig(ctx context.Context, req ctrl.Request) (result ctrl.Result, reterr error) {
+	ctx, span := tracing.Start(ctx, "reconcile machine-config", "namespace", req.Namespace, "name", req.Name)
+	defer func() {
+		span.RecordError(reterr)
+		span.End()
+	}()
+	logger := log.FromContext(ctx).WithValues("reconcile OVNKubeConfig MachineConfig", req.NamespacedName)
+	logger.Info("Reconcile")
+
+	ovnkubeConfig, err := r.getNamespaceConfig(ctx, req.Namespace)
+	if err != nil || ovnkubeConfig == nil {
+		return ctrl.Result{}, err
+	}
+	if !ovnkubeConfig.DeletionTimestamp.IsZero() {
+		_, err := r.syncNodeTaints(ctx, ovnkubeConfig)
+		return ctrl.Result{}, err
+	}
+	if delegated(ovnkubeConfig) {
+		logger.Info("Skip the MachineConfig", "reason", "rendered in spec.targetNamespace")
+		return ctrl.Result{}, nil
+	}
+	start := time.Now()
+	defer func() {
+		recordReconcile(ovnkubeConfig, machineConfigControllerName, start, reterr)
+		setRenderFailedCondition(ovnkubeConfig)
+		if err := r.updateStatus(ctx, ovnkubeConfig, machineConfigConditions, copyReconcileStatus(machineConfigControllerName, copyMachineConfigStatus)); err != nil {
+			logger.Error(err, "unable to update OVNKubeConfig status")
+		}
+	}()
+
+	if ovnkubeConfig.Spec.Hooks == nil {
+		meta.RemoveStatusCondition(&ovnkubeConfig.Status.Conditions, api.MachineConfigHooks)
+	}
+	if cfgPoolName(ovnkubeConfig) == "" {
+		logger.Info("poolName or poolRef is not provided")
+		return ctrl.Result{}, nil
+	}
+	if err := r.syncNamespaceRoleBinding(ctx, ovnkubeConfig); err != nil {
+		return ctrl.Result{}, err
+	}
+	labelsCtx, labelsSpan := tracing.Start(ctx, "sync node labels")
+	err = r.syncNodeLabels(labelsCtx, ovnkubeConfig)
+	labelsSpan.RecordError(err)
+	labelsSpan.End()
+	if err != nil {
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).NotMcpReady().Reason(dpuerrors.Reason(err, api.ReasonFailedCreated)).Msg(err.Error()).Build())
+		return ctrl.Result{}, err
+	}
+	taintsCtx, taintsSpan := tracing.Start(ctx, "sync node taints")
+	notReady, err := r.syncNodeTaints(taintsCtx, ovnkubeConfig)
+	taintsSpan.RecordError(err)
+	taintsSpan.End()
+	if err != nil {
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).NotMcpReady().Reason(dpuerrors.Reason(err, api.ReasonFailedCreated)).Msg(err.Error()).Build())
+		return ctrl.Result{}, err
+	}
+	if notReady > 0 {
+		// the readiness of the tenant hosts is not watched
+		defer func() {
+			if reterr == nil && !result.Requeue && (result.RequeueAfter == 0 || result.RequeueAfter > readinessTaintRequeueInterval) {
+				result.RequeueAfter = readinessTaintRequeueInterval
+			}
+		}()
+	}
+	if err = r.validateInfraFlavor(ovnkubeConfig); err != nil {
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).NotMcpReady().Reason(api.ReasonUnsupportedFlavor).Msg(err.Error()).Build())
+		return ctrl.Result{}, nil
+	}
+	microshift := ovnkubeConfig.Spec.InfraFlavor == dpuv1alpha1.InfraFlavorMicroShift
+	if !r.managesMachineConfig(ovnkubeConfig) && !microshift {
+		// the OS of the DPU nodes is managed out of band
+		meta.RemoveStatusCondition(&ovnkubeConfig.Status.Conditions, api.McpReady)
+		return ctrl.Result{}, nil
+	}
+	hostConfigReady := true
+	mcpCtx, mcpSpan := tracing.Start(ctx, "MCP sync", "pool", cfgPoolName(ovnkubeConfig))
+	if microshift {
+		hostConfigReady, err = r.syncHostConfig(mcpCtx, ovnkubeConfig)
+	} else {
+		err = r.syncMachineConfigObjs(mcpCtx, ovnkubeConfig)
+	}
+	mcpSpan.RecordError(err)
+	mcpSpan.End()
+	if perr, ok := err.(*pendingChangesError); ok {
+		logger.Info("Queue MachineConfig update", "reason", perr.Error())
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).PendingChanges().Reason(api.ReasonOutsideMaintenanceWindow).Msg(perr.Error()).Build())
+		return ctrl.Result{RequeueAfter: perr.nextOpen}, nil
+	}
+	meta.RemoveStatusCondition(&ovnkubeConfig.Status.Conditions, api.PendingChanges)
+	if perr, ok := err.(*preflightError); ok {
+		logger.Info("Hold MachineConfig update", "reason", perr.Error())
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).WaitingForPreflight().Reason(api.ReasonPreflightFailed).Msg(perr.Error()).Build())
+		return ctrl.Result{RequeueAfter: preflightRequeueInterval}, nil
+	}
+	meta.RemoveStatusCondition(&ovnkubeConfig.Status.Conditions, api.WaitingForPreflight)
+	if herr, ok := err.(*hookError); ok {
+		// the hook Job is owned by the OVNKubeConfig, so its completion
+		// triggers a new reconcile
+		logger.Info("Hold MachineConfig update", "reason", herr.Error())
+		return ctrl.Result{}, nil
+	}
+	if err != nil {
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).NotMcpReady().Reason(dpuerrors.Reason(err, api.ReasonFailedCreated)).Msg(err.Error()).Build())
+		return ctrl.Result{}, err
+	}
+	if !hostConfigReady {
+		// the DaemonSet status changes trigger a new reconcile
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).NotMcpReady().Reason(api.ReasonProgressing).Msg("DaemonSet '" + utils.CmNameHostConfig + "' is rolling out").Build())
+		return ctrl.Result{}, nil
+	}
+	msg := ""
+	if microshift {
+		msg = r.microshiftIPsecWarning(ctx, ovnkubeConfig)
+	}
+	meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).McpReady().Reason(api.ReasonCreated).Msg(msg).Build())
+	if microshift {
+		result, err = r.runHostConfigPostRollout(ctx, ovnkubeConfig)
+	} else {
+		result, err = r.runMachineConfigPostRollout(ctx, ovnkubeConfig)
+	}
+	if err != nil {
+		if _, ok := err.(*hookError); !ok {
+			return ctrl.Result{}, err
+		}
+	}
+	return result, nil
+}
+
+// mcpToOVNKubeConfigs maps a MachineConfigPool event to the OVNKubeConfigs
+// managing the pool.
+func (r *OVNKubeConfigReconciler) mcpToOVNKubeConfigs(obj client.Object) []reconcile.Request {
+	cfgList := &dpuv1alpha1.OVNKubeConfigList{}
+	if err := r.List(context.TODO(), cfgList); err != nil {
+		logger.Error(err, "failed to list OVNKubeConfigs")
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, cfg := range cfgList.Items {
+		if cfgPoolName(&cfg) == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name}})
+		}
+	}
+	return requests
+}
+
+// dpuNodePoolToOVNKubeConfigs maps a DpuNodePool event to the OVNKubeConfigs
+// referencing the pool.
+func (r *OVNKubeConfigReconciler) dpuNodePoolToOVNKubeConfigs(obj client.Object) []reconcile.Request {
+	cfgList := &dpuv1alpha1.OVNKubeConfigList{}
+	if err := r.List(context.TODO(), cfgList); err != nil {
+		logger.Error(err, "failed to list OVNKubeConfigs")
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, cfg := range cfgList.Items {
+		if cfg.Spec.PoolRef != nil && cfg.Spec.PoolRef.Name == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name}})
+		}
+	}
+	return requests
+}
+
+func (r *OVNKubeConfigReconciler) setupMachineConfigController(mgr ctrl.Manager) error {
+	trigger := recordTrigger(r.Scheme, machineConfigControllerName)
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named(machineConfigControllerName).
+		WithOptions(workerOptions(r.MaxConcurrentReconciles)).
+		For(&dpuv1alpha1.OVNKubeConfig{}, builder.WithPredicates(configChanged, trigger)).
+		// the ovnkube-config synced from the tenant cluster enables IPsec
+		Owns(&corev1.ConfigMap{}, builder.WithPredicates(ownedDataChanged, trigger)).
+		Owns(&batchv1.Job{}, builder.WithPredicates(trigger)).
+		// the dpu-host-config DaemonSet of MicroShift
+		Owns(&appsv1.DaemonSet{}, builder.WithPredicates(trigger)).
+		Watches(&source.Kind{Type: &corev1.Node{}},
+			handler.EnqueueRequestsFromMapFunc(r.nodeToOVNKubeConfigs),
+			builder.WithPredicates(nodeLabelsChanged, trigger)).
+		Watches(&source.Kind{Type: &dpuv1alpha1.DpuNodePool{}},
+			handler.EnqueueRequestsFromMapFunc(r.dpuNodePoolToOVNKubeConfigs),
+			builder.WithPredicates(trigger)).
+		Watches(platformChanges(r.Platform, &dpuv1alpha1.OVNKubeConfig{}), handler.EnqueueRequestsFromMapFunc(r.allOVNKubeConfigs))
+	c, err := b.Build(isolateReconcile(machineConfigControllerName, r.reconcileMachineConfig))
+	if err != nil {
+		return err
+	}
+	return whenMachineConfigServed(r.Platform, func() error {
+		return c.Watch(&source.Kind{Type: &mcfgv1.MachineConfigPool{}},
+			handler.EnqueueRequestsFromMapFunc(r.mcpToOVNKubeConfigs), trigger)
+	})
+}
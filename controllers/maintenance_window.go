@@ -0,0 +1,146 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+// pendingChangesError is returned when a disruptive change is queued until
+// the next maintenance window opens.
+type pendingChangesError struct {
+	change   string
+	nextOpen time.Duration
+}
+
+func (e *pendingChangesError) Error() string {
+	return fmt.Sprintf("%s is pending until the next maintenance window in %s", e.change, e.nextOpen.Round(time.Minute))
+}
+
+// checkMaintenanceWindow returns a pendingChangesError describing the change
+// if now is outside of the maintenance window. A nil window is always open.
+func checkMaintenanceWindow(w *dpuv1alpha1.MaintenanceWindow, now time.Time, change string) error {
+	if w == nil {
+		return nil
+	}
+	open, next, err := maintenanceWindowState(w, now)
+	if err != nil {
+		return err
+	}
+	if open {
+		return nil
+	}
+	return &pendingChangesError{change: change, nextOpen: next}
+}
+
+// maintenanceWindowState reports whether the window is open at now and, if
+// not, how long until it opens next.
+func maintenanceWindowState(w *dpuv1alpha1.MaintenanceWindow, now time.Time) (bool, time.Duration, error) {
+	start, err := time.Parse("15:04", w.Start)
+	if err != nil {
+		return false, 0, fmt.Errorf("invalid maintenance window start %q: %v", w.Start, err)
+	}
+	if w.Duration.Duration <= 0 {
+		return false, 0, fmt.Errorf("invalid maintenance window duration %s", w.Duration.Duration)
+	}
+	days := map[time.Weekday]bool{}
+	for _, d := range w.Days {
+		wd, ok := weekdays[d]
+		if !ok {
+			return false, 0, fmt.Errorf("invalid maintenance window day %q", d)
+		}
+		days[wd] = true
+	}
+	allowed := func(t time.Time) bool {
+		return len(days) == 0 || days[t.Weekday()]
+	}
+
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), start.Hour(), start.Minute(), 0, 0, time.UTC)
+	// A window may have opened on a previous day and still be open, e.g.
+	// 23:00 for 3h. Look back far enough to cover long windows.
+	for d := -int(w.Duration.Duration/(24*time.Hour)) - 1; d <= 0; d++ {
+		opening := today.AddDate(0, 0, d)
+		if allowed(opening) && !now.Before(opening) && now.Before(opening.Add(w.Duration.Duration)) {
+			return true, 0, nil
+		}
+	}
+	for d := 0; d <= 7; d++ {
+		opening := today.AddDate(0, 0, d)
+		if allowed(opening) && opening.After(now) {
+			return false, opening.Sub(now), nil
+		}
+	}
+	return false, 0, fmt.Errorf("maintenance window never opens")
+}
+
+var weekdays = map[dpuv1alpha1.Weekday]time.Weekday{
+	"Sun": time.Sunday,
+	"Mon": time.Monday,
+	"Tue": time.Tuesday,
+	"Wed": time.Wednesday,
+	"Thu": time.Thursday,
+	"Fri": time.Friday,
+	"Sat": time.Saturday,
+}
+
+// checkDaemonSetRollout holds the apply of the DaemonSet, stamped with the
+// hash of its pod template, when it would restart the ovnkube-node pods
+// outside of the maintenance window, or until the pre-rollout hook succeeds.
+// The shards of a sharded rollout are checked together.
+func (r *OVNKubeConfigReconciler) checkDaemonSetRollout(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, ds *appsv1.DaemonSet) error {
+	hash := ds.Annotations[utils.TemplateHashAnnotation]
+	dss, err := r.listOvnkubeNodeDaemonSets(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	// changing the shards recreates the DaemonSets
+	layout := map[string]bool{}
+	for _, name := range ovnkubeNodeDaemonSetNames(ds.Name, rolloutShards(cfg)) {
+		layout[name] = true
+	}
+	for _, found := range dss {
+		// an adopted DaemonSet has no hash yet
+		if found.Annotations[utils.TemplateHashAnnotation] != hash || !layout[found.Name] {
+			if err := checkMaintenanceWindow(cfg.Spec.MaintenanceWindow, time.Now(), "DaemonSet "+ds.Name+" rollout"); err != nil {
+				return err
+			}
+			return r.runRolloutHook(ctx, cfg, hookTargetDaemonSet, hookPhasePre, hash)
+		}
+	}
+	return nil
+}
+
+func podTemplateHash(template *corev1.PodTemplateSpec) (string, error) {
+	b, err := json.Marshal(template)
+	if err != nil {
+		return "", err
+	}
+	h := fnv.New64a()
+	h.Write(b)
+	return fmt.Sprintf("%x", h.Sum64()), nil
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,resourceNames=dpu-network-operator-namespaced,verbs=bind
+
+const (
+	// namespacedClusterRoleName is the ClusterRole holding the write verbs
+	// of the operator on the ConfigMaps and Secrets, bound in the namespace
+	// of every OVNKubeConfig rather than cluster-wide.
+	namespacedClusterRoleName = "dpu-network-operator-namespaced"
+	namespaceRoleBindingName  = "dpu-network-operator"
+)
+
+// syncNamespaceRoleBinding binds the operator to namespacedClusterRoleName in
+// the namespace of cfg, so it only writes ConfigMaps and Secrets where an
+// OVNKubeConfig lives. When the operator may not bind the role, e.g. when
+// installed with an older ClusterRole granting these verbs cluster-wide, the
+// writes rely on the cluster-wide grants.
+func (r *OVNKubeConfigReconciler) syncNamespaceRoleBinding(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) error {
+	if utils.ServiceAccount == "" || utils.Namespace == "" {
+		return nil
+	}
+	desired := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      namespaceRoleBindingName,
+			Namespace: cfg.Namespace,
+			Labels:    map[string]string{utils.OwnerLabel: cfg.Namespace},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     namespacedClusterRoleName,
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      utils.ServiceAccount,
+			Namespace: utils.Namespace,
+		}},
+	}
+	if err := ctrl.SetControllerReference(cfg, desired, r.Scheme); err != nil {
+		return err
+	}
+	found := &rbacv1.RoleBinding{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}, found)
+	if errors.IsNotFound(err) {
+		err = retryCreate(ctx, r.Client, desired)
+		if errors.IsAlreadyExists(err) {
+			return nil
+		}
+	} else if err == nil {
+		if found.RoleRef == desired.RoleRef && equality.Semantic.DeepEqual(found.Subjects, desired.Subjects) {
+			return nil
+		}
+		if found.RoleRef != desired.RoleRef {
+			// the roleRef of a RoleBinding is immutable
+			if err = r.Delete(ctx, found); err == nil {
+				err = retryCreate(ctx, r.Client, desired)
+			}
+		} else {
+			err = retryUpdate(ctx, r.Client, found, func() {
+				found.Subjects = desired.Subjects
+			})
+		}
+	}
+	if errors.IsForbidden(err) {
+		logger.Info("Cannot bind the namespaced role, relying on the cluster-wide grants", "namespace", cfg.Namespace, "reason", err.Error())
+		return nil
+	}
+	return conflictError(err)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+// syncNodeLabels applies the matchLabels of the NodeSelector to the nodes
+// selected by the discovery rule, and removes the labels previously applied
+// for cfg from the other nodes. The applied labels are recorded in an
+// annotation of each OVNKubeConfig, so labels set by the admin or for another
+// OVNKubeConfig are never removed. The labels are left in place when
+// ManageNodeLabels is unset.
+func (r *OVNKubeConfigReconciler) syncNodeLabels(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) error {
+	if cfg.Spec.ManageNodeLabels == nil {
+		return nil
+	}
+	discovery, err := metav1.LabelSelectorAsSelector(cfg.Spec.ManageNodeLabels.DiscoverySelector)
+	if err != nil {
+		return fmt.Errorf("invalid discoverySelector: %v", err)
+	}
+	poolLabels := map[string]string{}
+	if cfg.Spec.NodeSelector != nil {
+		poolLabels = cfg.Spec.NodeSelector.MatchLabels
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := r.List(ctx, nodes); err != nil {
+		return err
+	}
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		patch := client.MergeFrom(node.DeepCopy())
+		desired, changed, err := applyManagedLabels(node, managedLabelsAnnotation(cfg), discovery.Matches(labels.Set(node.Labels)), poolLabels)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			continue
+		}
+		logger.Info("Update DPU node labels", "node", node.Name, "labels", desired)
+		err = withApplyRetry(ctx, "Node", func() error {
+			return r.Patch(ctx, node, patch)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to label node %s: %v", node.Name, err)
+		}
+	}
+	return nil
+}
+
+// managedLabelsAnnotation returns the annotation recording the labels
+// applied to a node for cfg.
+func managedLabelsAnnotation(cfg *dpuv1alpha1.OVNKubeConfig) string {
+	return utils.ManagedLabelsAnnotationPrefix + cfg.Namespace
+}
+
+// applyManagedLabels sets poolLabels on node when selected, and removes the
+// labels recorded in its annotation key otherwise, then records the labels
+// applied. It returns these labels and whether node changed.
+func applyManagedLabels(node *corev1.Node, key string, selected bool, poolLabels map[string]string) (map[string]string, bool, error) {
+	managed := map[string]string{}
+	if v, ok := node.Annotations[key]; ok {
+		if err := json.Unmarshal([]byte(v), &managed); err != nil {
+			logger.Error(err, "ignoring invalid managed labels annotation", "node", node.Name, "annotation", key)
+		}
+	}
+	// only the labels not already set by hand are managed
+	desired := map[string]string{}
+	if selected {
+		for k, v := range poolLabels {
+			if _, ok := managed[k]; ok || node.Labels[k] != v {
+				desired[k] = v
+			}
+		}
+	}
+	if equality.Semantic.DeepEqual(managed, desired) {
+		return desired, false, nil
+	}
+
+	if node.Labels == nil {
+		node.Labels = map[string]string{}
+	}
+	for k, v := range managed {
+		if _, ok := desired[k]; !ok && node.Labels[k] == v {
+			delete(node.Labels, k)
+		}
+	}
+	for k, v := range desired {
+		node.Labels[k] = v
+	}
+	if len(desired) > 0 {
+		b, err := json.Marshal(desired)
+		if err != nil {
+			return nil, false, err
+		}
+		if node.Annotations == nil {
+			node.Annotations = map[string]string{}
+		}
+		node.Annotations[key] = string(b)
+	} else {
+		delete(node.Annotations, key)
+	}
+	return desired, true, nil
+}
+
+// nodeLabelsChanged filters the node events which may change the pool
+// membership.
+var nodeLabelsChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return !equality.Semantic.DeepEqual(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels())
+	},
+	DeleteFunc: func(e event.DeleteEvent) bool {
+		return false
+	},
+	GenericFunc: func(e event.GenericEvent) bool {
+		return false
+	},
+}
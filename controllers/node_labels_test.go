@@ -0,0 +1,81 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestApplyManagedLabels(t *testing.T) {
+	const keyA, keyB = "managed-labels.dpu.openshift.io/tenant-a", "managed-labels.dpu.openshift.io/tenant-b"
+	poolA := map[string]string{"dpu.openshift.io/pool": "a"}
+	poolB := map[string]string{"dpu.openshift.io/pool": "b", "node-role.kubernetes.io/dpu-worker": ""}
+	labeledForA := func() *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:        "dpu-1",
+			Labels:      map[string]string{"dpu.openshift.io/pool": "a", "kubernetes.io/hostname": "dpu-1"},
+			Annotations: map[string]string{keyA: `{"dpu.openshift.io/pool":"a"}`},
+		}}
+	}
+	for _, tc := range []struct {
+		name            string
+		node            *corev1.Node
+		key             string
+		selected        bool
+		poolLabels      map[string]string
+		wantChanged     bool
+		wantLabels      map[string]string
+		wantAnnotations map[string]string
+	}{
+		{name: "labeled", key: keyA, selected: true, poolLabels: poolA, wantChanged: true,
+			node:            &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "dpu-1", Labels: map[string]string{"kubernetes.io/hostname": "dpu-1"}}},
+			wantLabels:      map[string]string{"dpu.openshift.io/pool": "a", "kubernetes.io/hostname": "dpu-1"},
+			wantAnnotations: map[string]string{keyA: `{"dpu.openshift.io/pool":"a"}`}},
+		{name: "already labeled", key: keyA, selected: true, poolLabels: poolA, node: labeledForA(),
+			wantLabels:      map[string]string{"dpu.openshift.io/pool": "a", "kubernetes.io/hostname": "dpu-1"},
+			wantAnnotations: map[string]string{keyA: `{"dpu.openshift.io/pool":"a"}`}},
+		{name: "not selected by another OVNKubeConfig", key: keyB, poolLabels: poolB, node: labeledForA(),
+			wantLabels:      map[string]string{"dpu.openshift.io/pool": "a", "kubernetes.io/hostname": "dpu-1"},
+			wantAnnotations: map[string]string{keyA: `{"dpu.openshift.io/pool":"a"}`}},
+		{name: "no longer selected", key: keyA, poolLabels: poolA, node: labeledForA(), wantChanged: true,
+			wantLabels:      map[string]string{"kubernetes.io/hostname": "dpu-1"},
+			wantAnnotations: map[string]string{}},
+		{name: "label set by hand", key: keyA, poolLabels: poolA,
+			node:       &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "dpu-1", Labels: map[string]string{"dpu.openshift.io/pool": "a"}}},
+			wantLabels: map[string]string{"dpu.openshift.io/pool": "a"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, changed, err := applyManagedLabels(tc.node, tc.key, tc.selected, tc.poolLabels)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed != tc.wantChanged {
+				t.Errorf("changed = %v, want %v", changed, tc.wantChanged)
+			}
+			if !reflect.DeepEqual(tc.node.Labels, tc.wantLabels) {
+				t.Errorf("labels %v, want %v", tc.node.Labels, tc.wantLabels)
+			}
+			if len(tc.node.Annotations)+len(tc.wantAnnotations) > 0 && !reflect.DeepEqual(tc.node.Annotations, tc.wantAnnotations) {
+				t.Errorf("annotations %v, want %v", tc.node.Annotations, tc.wantAnnotations)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	daemonconsts "github.com/openshift/machine-config-operator/pkg/daemon/constants"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+)
+
+// chassisIDAnnotation is set on the tenant hosts by ovnkube-node once their
+// OVN chassis is registered.
+const chassisIDAnnotation = "k8s.ovn.org/node-chassis-id"
+
+// onboardingCheck reports whether a node reached a step of its onboarding,
+// or else what the step is waiting for.
+type onboardingCheck struct {
+	step  string
+	check func(node *corev1.Node, status *dpuv1alpha1.DpuNodeStatus) (bool, string)
+}
+
+// trackNodeOnboarding updates the onboarding of the nodes still onboarding,
+// carried over from the previous status, statuses[i] being the status of
+// nodes[i]. The steps are checked in order,
+// the phase is the last of the steps reached in a row. A node is no longer
+// checked once it reached the last step.
+func (r *OVNKubeConfigReconciler) trackNodeOnboarding(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, previous []dpuv1alpha1.DpuNodeStatus, nodes []corev1.Node, statuses []dpuv1alpha1.DpuNodeStatus) error {
+	onboarding := map[string]*dpuv1alpha1.DpuNodeOnboarding{}
+	for i := range previous {
+		onboarding[previous[i].Name] = previous[i].Onboarding
+	}
+	pending := map[string]bool{}
+	for i := range statuses {
+		statuses[i].Onboarding = onboarding[statuses[i].Name].DeepCopy()
+		if o := statuses[i].Onboarding; o == nil || o.CompletionTime == nil {
+			pending[statuses[i].Name] = true
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	checks, err := r.onboardingChecks(ctx, cfg, pending)
+	if err != nil {
+		return err
+	}
+	now := metav1.Now()
+	for i := range statuses {
+		if !pending[statuses[i].Name] {
+			continue
+		}
+		statuses[i].Onboarding = advanceOnboarding(statuses[i].Onboarding, checks, &nodes[i], &statuses[i], now)
+	}
+	return nil
+}
+
+// advanceOnboarding runs the checks of a node, recording the time each step
+// is first reached.
+func advanceOnboarding(o *dpuv1alpha1.DpuNodeOnboarding, checks []onboardingCheck, node *corev1.Node, status *dpuv1alpha1.DpuNodeStatus, now metav1.Time) *dpuv1alpha1.DpuNodeOnboarding {
+	if o == nil {
+		o = &dpuv1alpha1.DpuNodeOnboarding{StartTime: now}
+	}
+	o.Phase = dpuv1alpha1.OnboardingPending
+	o.Message = ""
+	for _, c := range checks {
+		done, msg := c.check(node, status)
+		if !done {
+			o.Message = msg
+			return o
+		}
+		o.Phase = c.step
+		if !onboardingStepReached(o, c.step) {
+			o.Steps = append(o.Steps, dpuv1alpha1.DpuNodeOnboardingStep{Name: c.step, Time: now})
+		}
+	}
+	o.CompletionTime = &now
+	return o
+}
+
+func onboardingStepReached(o *dpuv1alpha1.DpuNodeOnboarding, step string) bool {
+	for _, s := range o.Steps {
+		if s.Name == step {
+			return true
+		}
+	}
+	return false
+}
+
+// onboardingChecks returns the checks of the onboarding steps of cfg, with
+// what they need read once for the pending nodes.
+func (r *OVNKubeConfigReconciler) onboardingChecks(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, pending map[string]bool) ([]onboardingCheck, error) {
+	checks := []onboardingCheck{}
+	if r.managesMachineConfig(cfg) {
+		mcChecks, err := r.machineConfigOnboardingChecks(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, mcChecks...)
+	}
+	checks = append(checks, onboardingCheck{step: dpuv1alpha1.OnboardingSwitchdevVerified,
+		check: func(node *corev1.Node, status *dpuv1alpha1.DpuNodeStatus) (bool, string) {
+			return status.SwitchdevError == "", status.SwitchdevError
+		}})
+
+	scheduled := map[string]bool{}
+	dss, err := r.listOvnkubeNodeDaemonSets(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	for i := range dss {
+		pods, err := r.daemonSetPods(ctx, &dss[i])
+		if err != nil {
+			return nil, err
+		}
+		for j := range pods {
+			if name := podNodeName(&pods[j]); name != "" {
+				scheduled[name] = true
+			}
+		}
+	}
+	checks = append(checks, onboardingCheck{step: dpuv1alpha1.OnboardingOvnKubeScheduled,
+		check: func(node *corev1.Node, status *dpuv1alpha1.DpuNodeStatus) (bool, string) {
+			return scheduled[node.Name], "no data plane pod is scheduled on the node yet"
+		}})
+
+	if isOvnDataPlane(cfg) {
+		chassis := r.registeredChassis(ctx, cfg, pending)
+		checks = append(checks, onboardingCheck{step: dpuv1alpha1.OnboardingChassisRegistered,
+			check: func(node *corev1.Node, status *dpuv1alpha1.DpuNodeStatus) (bool, string) {
+				msg, ok := chassis[node.Name]
+				if !ok {
+					msg = "the tenant host of the node is not known yet"
+				}
+				return ok && msg == "", msg
+			}})
+	}
+	return checks, nil
+}
+
+// machineConfigOnboardingChecks returns the checks of the switchdev
+// MachineConfig being applied to a node, then the node rebooted into it,
+// against the rendered configuration targeted by the pool.
+func (r *OVNKubeConfigReconciler) machineConfigOnboardingChecks(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) ([]onboardingCheck, error) {
+	mcp := &mcfgv1.MachineConfigPool{}
+	if err := r.Get(ctx, types.NamespacedName{Name: cfgPoolName(cfg)}, mcp); err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	target := mcp.Spec.Configuration.Name
+	mcName := switchdevMachineConfigName(cfg)
+	waiting := ""
+	if target == "" || !poolTargetsMachineConfig(mcp, mcName) {
+		waiting = fmt.Sprintf("MachineConfigPool %s doesn't render MachineConfig %s yet", cfgPoolName(cfg), mcName)
+	}
+	return []onboardingCheck{
+		{step: dpuv1alpha1.OnboardingMachineConfigApplied,
+			check: func(node *corev1.Node, status *dpuv1alpha1.DpuNodeStatus) (bool, string) {
+				if waiting != "" {
+					return false, waiting
+				}
+				return node.Annotations[daemonconsts.DesiredMachineConfigAnnotationKey] == target,
+					fmt.Sprintf("the machine-config-operator didn't target %s on the node yet", target)
+			}},
+		{step: dpuv1alpha1.OnboardingRebooted,
+			check: func(node *corev1.Node, status *dpuv1alpha1.DpuNodeStatus) (bool, string) {
+				state := node.Annotations[daemonconsts.MachineConfigDaemonStateAnnotationKey]
+				return node.Annotations[daemonconsts.CurrentMachineConfigAnnotationKey] == target && state == daemonconsts.MachineConfigDaemonStateDone,
+					fmt.Sprintf("the node is not rebooted into %s yet, machine-config daemon state %q", target, state)
+			}},
+	}, nil
+}
+
+func poolTargetsMachineConfig(mcp *mcfgv1.MachineConfigPool, name string) bool {
+	for _, source := range mcp.Spec.Configuration.Source {
+		if source.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// registeredChassis maps the pending DPU nodes whose tenant host is known
+// to "" once the host has an OVN chassis, or else to what is waited for.
+// The tenant cluster is only read for the pending nodes, and its errors are
+// reported per node rather than failing the pass.
+func (r *OVNKubeConfigReconciler) registeredChassis(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, pending map[string]bool) map[string]string {
+	chassis := map[string]string{}
+	hosts, err := r.dpuNodesByHost(ctx, cfg.Namespace)
+	if err != nil {
+		return chassis
+	}
+	kubeClient := r.connectedTenantKubeClient(cfg.Namespace)
+	for host, node := range hosts {
+		if !pending[node] {
+			continue
+		}
+		if kubeClient == nil {
+			chassis[node] = "the tenant cluster is not reachable"
+			continue
+		}
+		tenantNode, getErr := kubeClient.CoreV1().Nodes().Get(ctx, host, metav1.GetOptions{})
+		switch {
+		case errors.IsNotFound(getErr):
+			chassis[node] = fmt.Sprintf("the tenant host %s is not found", host)
+		case getErr != nil:
+			chassis[node] = fmt.Sprintf("the tenant host %s could not be read: %v", host, getErr)
+		case tenantNode.Annotations[chassisIDAnnotation] == "":
+			chassis[node] = fmt.Sprintf("the tenant host %s has no OVN chassis yet", host)
+		default:
+			chassis[node] = ""
+		}
+	}
+	return chassis
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openshift/dpu-network-operator/api"
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+)
+
+// NodeSelectorValidationPath is the path the node selector admission
+// webhook is served on.
+const NodeSelectorValidationPath = "/validate-dpu-openshift-io-v1alpha1-ovnkubeconfig"
+
+// NodeSelectorAdmissionMode tells what the admission does with an
+// OVNKubeConfig whose node selector matches no node.
+type NodeSelectorAdmissionMode string
+
+const (
+	// NodeSelectorAdmissionWarn admits the CR with a warning
+	NodeSelectorAdmissionWarn NodeSelectorAdmissionMode = "warn"
+	// NodeSelectorAdmissionReject denies the CR
+	NodeSelectorAdmissionReject NodeSelectorAdmissionMode = "reject"
+)
+
+// checkNodeSelection reports in the ZeroNodesSelected condition whether
+// the selector of the DPU nodes matches no node, e.g. on a typo in
+// spec.nodeSelector, rather than leaving an empty pool and idle DaemonSets.
+func (r *OVNKubeConfigReconciler) checkNodeSelection(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, nodeSelector *metav1.LabelSelector) error {
+	matched, err := nodesMatch(ctx, r.Client, nodeSelector)
+	if err != nil {
+		return err
+	}
+	if matched {
+		meta.SetStatusCondition(&cfg.Status.Conditions, *api.Conditions(cfg).NodesSelected().Reason(api.ReasonNodesSelected).Build())
+		return nil
+	}
+	msg := fmt.Sprintf("Node selector %q matches no node", metav1.FormatLabelSelector(nodeSelector))
+	logger.Info("No DPU node selected", "namespace", cfg.Namespace, "reason", msg)
+	meta.SetStatusCondition(&cfg.Status.Conditions, *api.Conditions(cfg).ZeroNodesSelected().Reason(api.ReasonNoMatchingNodes).Msg(msg).Build())
+	return nil
+}
+
+// nodesMatch returns whether nodeSelector matches at least one node.
+func nodesMatch(ctx context.Context, c client.Reader, nodeSelector *metav1.LabelSelector) (bool, error) {
+	selector, err := metav1.LabelSelectorAsSelector(nodeSelector)
+	if err != nil {
+		return false, err
+	}
+	nodes := &corev1.NodeList{}
+	if err := c.List(ctx, nodes, &client.ListOptions{LabelSelector: selector}); err != nil {
+		return false, err
+	}
+	return len(nodes.Items) > 0, nil
+}
+
+// NodeSelectorValidator is a validating admission webhook of the
+// OVNKubeConfigs, warning about or rejecting a node selector which matches
+// no current node. The selector checked is spec.nodeSelector, or
+// spec.manageNodeLabels.discoverySelector when the operator labels the
+// nodes itself. A CR referencing a DpuNodePool is admitted, so is one whose
+// selector doesn't change on update, and the CR is admitted when the nodes
+// cannot be listed.
+type NodeSelectorValidator struct {
+	Client  client.Reader
+	Mode    NodeSelectorAdmissionMode
+	decoder *admission.Decoder
+}
+
+// SetupWebhookWithManager registers the webhook on the webhook server of
+// the manager.
+func (v *NodeSelectorValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	if v.Mode != NodeSelectorAdmissionWarn && v.Mode != NodeSelectorAdmissionReject {
+		return fmt.Errorf("unknown node selector admission mode %q", v.Mode)
+	}
+	decoder, err := admission.NewDecoder(mgr.GetScheme())
+	if err != nil {
+		return err
+	}
+	v.decoder = decoder
+	mgr.GetWebhookServer().Register(NodeSelectorValidationPath, &webhook.Admission{Handler: v})
+	return nil
+}
+
+// Handle admits or denies an OVNKubeConfig.
+func (v *NodeSelectorValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	cfg := &dpuv1alpha1.OVNKubeConfig{}
+	if err := v.decoder.Decode(req, cfg); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	nodeSelector, field := admittedNodeSelector(cfg)
+	if nodeSelector == nil || cfg.DeletionTimestamp != nil {
+		return admission.Allowed("")
+	}
+	if len(req.OldObject.Raw) > 0 {
+		old := &dpuv1alpha1.OVNKubeConfig{}
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if oldSelector, _ := admittedNodeSelector(old); equality.Semantic.DeepEqual(oldSelector, nodeSelector) {
+			return admission.Allowed("")
+		}
+	}
+	matched, err := nodesMatch(ctx, v.Client, nodeSelector)
+	if err != nil {
+		logger.Error(err, "failed to check the node selector", "namespace", req.Namespace, "name", req.Name)
+		return admission.Allowed("")
+	}
+	if matched {
+		return admission.Allowed("")
+	}
+	msg := fmt.Sprintf("%s %q matches no node", field, metav1.FormatLabelSelector(nodeSelector))
+	if v.Mode == NodeSelectorAdmissionReject {
+		return admission.Denied(msg)
+	}
+	return admission.Allowed("").WithWarnings(msg)
+}
+
+// admittedNodeSelector returns the selector of cfg expected to match the
+// current nodes, and its field, or nil.
+func admittedNodeSelector(cfg *dpuv1alpha1.OVNKubeConfig) (*metav1.LabelSelector, string) {
+	if cfg.Spec.PoolRef != nil {
+		return nil, ""
+	}
+	if cfg.Spec.ManageNodeLabels != nil {
+		return cfg.Spec.ManageNodeLabels.DiscoverySelector, "spec.manageNodeLabels.discoverySelector"
+	}
+	return cfg.Spec.NodeSelector, "spec.nodeSelector"
+}
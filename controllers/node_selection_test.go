@@ -0,0 +1,133 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+)
+
+// nodeLister lists nodes, or fails with err.
+type nodeLister struct {
+	nodes []corev1.Node
+	err   error
+}
+
+func (l *nodeLister) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return errors.New("not implemented")
+}
+
+func (l *nodeLister) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if l.err != nil {
+		return l.err
+	}
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	nodes := list.(*corev1.NodeList)
+	for _, node := range l.nodes {
+		if listOpts.LabelSelector == nil || listOpts.LabelSelector.Matches(labels.Set(node.Labels)) {
+			nodes.Items = append(nodes.Items, node)
+		}
+	}
+	return nil
+}
+
+func TestNodeSelectorValidator(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := dpuv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := []corev1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "dpu-1", Labels: map[string]string{"pool": "dpu"}}}}
+	config := func(spec dpuv1alpha1.OVNKubeConfigSpec) *dpuv1alpha1.OVNKubeConfig {
+		return &dpuv1alpha1.OVNKubeConfig{
+			TypeMeta:   metav1.TypeMeta{APIVersion: dpuv1alpha1.GroupVersion.String(), Kind: "OVNKubeConfig"},
+			ObjectMeta: metav1.ObjectMeta{Name: "ovnkubeconfig", Namespace: "tenant-a"},
+			Spec:       spec,
+		}
+	}
+	selecting := func(pool string) dpuv1alpha1.OVNKubeConfigSpec {
+		return dpuv1alpha1.OVNKubeConfigSpec{NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"pool": pool}}}
+	}
+	discovering := dpuv1alpha1.OVNKubeConfigSpec{
+		NodeSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"node-role.kubernetes.io/dpu-worker": ""}},
+		ManageNodeLabels: &dpuv1alpha1.NodeLabelManagement{DiscoverySelector: &metav1.LabelSelector{MatchLabels: map[string]string{"pool": "dpu"}}},
+	}
+	pooled := selecting("typo")
+	pooled.PoolRef = &dpuv1alpha1.PoolReference{Name: "dpus"}
+
+	for _, tc := range []struct {
+		name        string
+		mode        NodeSelectorAdmissionMode
+		cfg         *dpuv1alpha1.OVNKubeConfig
+		old         *dpuv1alpha1.OVNKubeConfig
+		listErr     error
+		wantAllowed bool
+		wantWarning bool
+	}{
+		{name: "matching selector", mode: NodeSelectorAdmissionReject, cfg: config(selecting("dpu")), wantAllowed: true},
+		{name: "no match rejected", mode: NodeSelectorAdmissionReject, cfg: config(selecting("typo"))},
+		{name: "no match warned", mode: NodeSelectorAdmissionWarn, cfg: config(selecting("typo")), wantAllowed: true, wantWarning: true},
+		{name: "discovery selector checked", mode: NodeSelectorAdmissionReject, cfg: config(discovering), wantAllowed: true},
+		{name: "DpuNodePool admitted", mode: NodeSelectorAdmissionReject, cfg: config(pooled), wantAllowed: true},
+		{name: "unchanged selector admitted", mode: NodeSelectorAdmissionReject,
+			cfg: config(selecting("typo")), old: config(selecting("typo")), wantAllowed: true},
+		{name: "changed selector checked", mode: NodeSelectorAdmissionReject,
+			cfg: config(selecting("typo")), old: config(selecting("dpu"))},
+		{name: "nodes not listed", mode: NodeSelectorAdmissionReject,
+			cfg: config(selecting("typo")), listErr: errors.New("forbidden"), wantAllowed: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			v := &NodeSelectorValidator{Client: &nodeLister{nodes: nodes, err: tc.listErr}, Mode: tc.mode, decoder: decoder}
+			req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Namespace: "tenant-a", Name: "ovnkubeconfig"}}
+			req.Object.Raw = marshal(t, tc.cfg)
+			if tc.old != nil {
+				req.OldObject.Raw = marshal(t, tc.old)
+			}
+			resp := v.Handle(context.TODO(), req)
+			if resp.Allowed != tc.wantAllowed {
+				t.Errorf("allowed = %v, want %v: %v", resp.Allowed, tc.wantAllowed, resp.Result)
+			}
+			if got := len(resp.Warnings) > 0; got != tc.wantWarning {
+				t.Errorf("warnings = %v, want a warning: %v", resp.Warnings, tc.wantWarning)
+			}
+		})
+	}
+}
+
+func marshal(t *testing.T, obj interface{}) []byte {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
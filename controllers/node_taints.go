@@ -0,0 +1,216 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+// syncNodeTaints applies the nodeTaints to the DPU nodes, along with the
+// readiness taint on the nodes whose data plane is not verified yet, and
+// removes the taints previously applied by the operator from the other
+// nodes, or from every node once the CR is deleted. The applied taints are
+// recorded in an annotation, so taints set by the admin are never removed.
+// A finalizer holds the deletion of the CR until its taints are removed. It
+// returns the number of nodes holding the readiness taint.
+func (r *OVNKubeConfigReconciler) syncNodeTaints(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) (int, error) {
+	readiness := cfg.DeletionTimestamp.IsZero() && readinessTaintEnabled(cfg)
+	enabled := cfg.DeletionTimestamp.IsZero() && (len(cfg.Spec.NodeTaints) > 0 || readiness)
+	selector := labels.Nothing()
+	var ready map[string]bool
+	if enabled {
+		if controllerutil.AddFinalizer(cfg, utils.NodeTaintsFinalizer) {
+			if err := r.Update(ctx, cfg); err != nil {
+				return 0, err
+			}
+		}
+		nodeSelector, err := r.nodeTaintsSelector(ctx, cfg)
+		if err != nil {
+			return 0, err
+		}
+		if selector, err = metav1.LabelSelectorAsSelector(nodeSelector); err != nil {
+			return 0, fmt.Errorf("invalid nodeSelector: %v", err)
+		}
+		if readiness {
+			if ready, err = r.readyDataPlaneNodes(ctx, cfg); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := r.List(ctx, nodes); err != nil {
+		return 0, err
+	}
+	notReady := 0
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		// the taints of the nodes of another tenant are left to its CR
+		if owner, ok := node.Annotations[utils.TaintsOwnerAnnotation]; ok && owner != cfg.Namespace {
+			continue
+		}
+		managed := managedTaintsAnnotation(node, utils.ManagedTaintsAnnotation)
+		var desired []corev1.Taint
+		if selector.Matches(labels.Set(node.Labels)) {
+			wanted := cfg.Spec.NodeTaints
+			if readiness && !ready[node.Name] {
+				wanted = append(append([]corev1.Taint{}, wanted...), readinessTaint)
+				notReady++
+			}
+			desired = desiredTaints(node.Spec.Taints, managed, wanted)
+		}
+		if len(managed) == 0 && len(desired) == 0 {
+			continue
+		}
+
+		updated := node.DeepCopy()
+		updated.Spec.Taints = mergeTaints(updated.Spec.Taints, managed, desired)
+		if err := setJSONAnnotation(updated, utils.ManagedTaintsAnnotation, desired, len(desired) > 0); err != nil {
+			return 0, err
+		}
+		if len(desired) > 0 {
+			updated.Annotations[utils.TaintsOwnerAnnotation] = cfg.Namespace
+		} else {
+			delete(updated.Annotations, utils.TaintsOwnerAnnotation)
+		}
+		if equality.Semantic.DeepEqual(node.Spec.Taints, updated.Spec.Taints) && equality.Semantic.DeepEqual(node.Annotations, updated.Annotations) {
+			continue
+		}
+		logger.Info("Update DPU node taints", "node", node.Name, "taints", desired)
+		err := withApplyRetry(ctx, "Node", func() error {
+			return r.Patch(ctx, updated, client.MergeFrom(node))
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to taint node %s: %v", node.Name, err)
+		}
+	}
+
+	if !enabled && controllerutil.RemoveFinalizer(cfg, utils.NodeTaintsFinalizer) {
+		return 0, client.IgnoreNotFound(r.Update(ctx, cfg))
+	}
+	return notReady, nil
+}
+
+// nodeTaintsSelector returns the selector of the nodes to taint. It doesn't
+// require the MachineConfigPool, so the nodes are tainted before it is
+// created.
+func (r *OVNKubeConfigReconciler) nodeTaintsSelector(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) (*metav1.LabelSelector, error) {
+	if cfg.Spec.PoolRef != nil {
+		pool := &dpuv1alpha1.DpuNodePool{}
+		if err := r.Get(ctx, types.NamespacedName{Name: cfg.Spec.PoolRef.Name}, pool); err != nil {
+			return nil, fmt.Errorf("DpuNodePool %s not found: %v", cfg.Spec.PoolRef.Name, err)
+		}
+		return nodePoolSelector(pool), nil
+	}
+	if cfg.Spec.NodeSelector == nil {
+		return nil, fmt.Errorf("nodeSelector must be set with nodeTaints or readinessTaint")
+	}
+	return cfg.Spec.NodeSelector, nil
+}
+
+// managedNodeTaints returns the nodeTaints and the readiness taint.
+func managedNodeTaints(cfg *dpuv1alpha1.OVNKubeConfig) []corev1.Taint {
+	taints := cfg.Spec.NodeTaints
+	if readinessTaintEnabled(cfg) {
+		taints = append(append([]corev1.Taint{}, taints...), readinessTaint)
+	}
+	return taints
+}
+
+// nodeTaintTolerations returns the tolerations of the nodeTaints and of the
+// readiness taint.
+func nodeTaintTolerations(cfg *dpuv1alpha1.OVNKubeConfig) []corev1.Toleration {
+	var tolerations []corev1.Toleration
+	for _, t := range managedNodeTaints(cfg) {
+		tolerations = append(tolerations, corev1.Toleration{
+			Key:      t.Key,
+			Operator: corev1.TolerationOpEqual,
+			Value:    t.Value,
+			Effect:   t.Effect,
+		})
+	}
+	return tolerations
+}
+
+// managedTaintsAnnotation returns the taints recorded in the annotation of
+// the node.
+func managedTaintsAnnotation(node *corev1.Node, key string) []corev1.Taint {
+	managed := []corev1.Taint{}
+	if v, ok := node.Annotations[key]; ok {
+		if err := json.Unmarshal([]byte(v), &managed); err != nil {
+			logger.Error(err, "ignoring invalid managed taints annotation", "node", node.Name)
+		}
+	}
+	return managed
+}
+
+// desiredTaints returns the taints to manage on a node: the wanted ones,
+// except those already set by hand.
+func desiredTaints(nodeTaints, managed, wanted []corev1.Taint) []corev1.Taint {
+	desired := []corev1.Taint{}
+	for _, t := range wanted {
+		if findTaint(managed, t) != nil || findTaint(nodeTaints, t) == nil {
+			desired = append(desired, corev1.Taint{Key: t.Key, Value: t.Value, Effect: t.Effect})
+		}
+	}
+	return desired
+}
+
+// mergeTaints removes the previously managed taints which are no longer
+// desired from the taints of a node, and adds or updates the desired ones.
+func mergeTaints(nodeTaints, managed, desired []corev1.Taint) []corev1.Taint {
+	taints := []corev1.Taint{}
+	for _, t := range nodeTaints {
+		if findTaint(managed, t) == nil || findTaint(desired, t) != nil {
+			taints = append(taints, t)
+		}
+	}
+	for _, t := range desired {
+		if existing := findTaint(taints, t); existing != nil {
+			existing.Value = t.Value
+		} else {
+			taints = append(taints, t)
+		}
+	}
+	if len(taints) == 0 {
+		return nil
+	}
+	return taints
+}
+
+// findTaint returns the taint of taints with the key and effect of t.
+func findTaint(taints []corev1.Taint, t corev1.Taint) *corev1.Taint {
+	for i := range taints {
+		if taints[i].Key == t.Key && taints[i].Effect == t.Effect {
+			return &taints[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+)
+
+//+kubebuilder:rbac:groups=dpu.openshift.io,resources=dpuoperatorconfigs,verbs=get;list;watch
+
+const (
+	// operatorConfigName is the name of the only DpuOperatorConfig used.
+	operatorConfigName = "cluster"
+
+	notificationTimeout   = 10 * time.Second
+	notificationTokenKey  = "token"
+	notificationCAKey     = "ca.crt"
+	cloudEventType        = "io.openshift.dpu.condition.transition"
+	cloudEventContentType = "application/cloudevents+json"
+)
+
+// notificationBackoff retries a failed delivery for about 15s, so a sink
+// restarting doesn't miss the transition.
+var notificationBackoff = wait.Backoff{
+	Steps:    4,
+	Duration: time.Second,
+	Factor:   3,
+	Jitter:   0.1,
+}
+
+var notificationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "dpu_network_operator_notifications_total",
+	Help: "Number of condition transitions notified to the sinks, by sink and result.",
+}, []string{"sink", "result"})
+
+func init() {
+	metrics.Registry.MustRegister(notificationsTotal)
+}
+
+// conditionTransition is the body of a notification, or the data of its
+// CloudEvent.
+type conditionTransition struct {
+	Namespace      string                 `json:"namespace"`
+	Name           string                 `json:"name"`
+	Type           string                 `json:"type"`
+	Status         metav1.ConditionStatus `json:"status"`
+	PreviousStatus metav1.ConditionStatus `json:"previousStatus,omitempty"`
+	Reason         string                 `json:"reason"`
+	PreviousReason string                 `json:"previousReason,omitempty"`
+	Message        string                 `json:"message,omitempty"`
+	Time           metav1.Time            `json:"time"`
+}
+
+// cloudEvent is a CloudEvent 1.0 in the structured content mode.
+type cloudEvent struct {
+	SpecVersion     string              `json:"specversion"`
+	ID              string              `json:"id"`
+	Source          string              `json:"source"`
+	Type            string              `json:"type"`
+	Subject         string              `json:"subject"`
+	Time            metav1.Time         `json:"time"`
+	DataContentType string              `json:"datacontenttype"`
+	Data            conditionTransition `json:"data"`
+}
+
+// conditionTransitions returns the conditions of cfg whose status or reason
+// changed from old to new. Removed conditions are not notified.
+func conditionTransitions(cfg *dpuv1alpha1.OVNKubeConfig, old, new []metav1.Condition) []conditionTransition {
+	transitions := []conditionTransition{}
+	for _, c := range new {
+		t := conditionTransition{
+			Namespace: cfg.Namespace,
+			Name:      cfg.Name,
+			Type:      c.Type,
+			Status:    c.Status,
+			Reason:    c.Reason,
+			Message:   c.Message,
+			Time:      c.LastTransitionTime,
+		}
+		if prev := meta.FindStatusCondition(old, c.Type); prev != nil {
+			if prev.Status == c.Status && prev.Reason == c.Reason {
+				continue
+			}
+			t.PreviousStatus = prev.Status
+			t.PreviousReason = prev.Reason
+		}
+		transitions = append(transitions, t)
+	}
+	return transitions
+}
+
+// notifyTransitions posts the transitions to the notification sinks of the
+// DpuOperatorConfig in the background, so a slow sink doesn't hold the
+// reconciles. Failed deliveries are retried, then only logged and counted.
+func (r *OVNKubeConfigReconciler) notifyTransitions(ctx context.Context, transitions []conditionTransition) {
+	if len(transitions) == 0 {
+		return
+	}
+	config := &dpuv1alpha1.DpuOperatorConfig{}
+	if err := r.Get(ctx, types.NamespacedName{Name: operatorConfigName}, config); err != nil {
+		if !errors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+			logger.Error(err, "failed to read the DpuOperatorConfig")
+		}
+		return
+	}
+	for _, sink := range config.Spec.Notifications {
+		selected := filterTransitions(transitions, sink.Conditions)
+		if len(selected) == 0 {
+			continue
+		}
+		client, token, err := r.notificationClient(ctx, &sink)
+		if err != nil {
+			logger.Error(err, "failed to configure the notification sink", "sink", sink.Name)
+			notificationsTotal.WithLabelValues(sink.Name, "failed").Add(float64(len(selected)))
+			continue
+		}
+		go deliverTransitions(sink, client, token, selected)
+	}
+}
+
+// filterTransitions returns the transitions of the condition types, or all
+// of them when types is empty.
+func filterTransitions(transitions []conditionTransition, types []string) []conditionTransition {
+	if len(types) == 0 {
+		return transitions
+	}
+	selected := []conditionTransition{}
+	for _, t := range transitions {
+		for _, cndType := range types {
+			if t.Type == cndType {
+				selected = append(selected, t)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// notificationClient returns the HTTP client and the bearer token of the
+// sink, from its Secret if set.
+func (r *OVNKubeConfigReconciler) notificationClient(ctx context.Context, sink *dpuv1alpha1.NotificationSink) (*http.Client, string, error) {
+	client := &http.Client{Timeout: notificationTimeout}
+	if sink.Secret == nil {
+		return client, "", nil
+	}
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: sink.Secret.Namespace, Name: sink.Secret.Name}, secret); err != nil {
+		return nil, "", err
+	}
+	if ca, ok := secret.Data[notificationCAKey]; ok {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, "", fmt.Errorf("no certificate found in the %s key of Secret %s/%s", notificationCAKey, secret.Namespace, secret.Name)
+		}
+		client.Transport = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+		}
+	}
+	return client, string(secret.Data[notificationTokenKey]), nil
+}
+
+// deliverTransitions posts the transitions to the sink in order, each one
+// retried with notificationBackoff.
+func deliverTransitions(sink dpuv1alpha1.NotificationSink, client *http.Client, token string, transitions []conditionTransition) {
+	for _, t := range transitions {
+		body, contentType, err := notificationBody(sink.Format, t)
+		if err != nil {
+			logger.Error(err, "failed to encode the notification", "sink", sink.Name)
+			notificationsTotal.WithLabelValues(sink.Name, "failed").Inc()
+			continue
+		}
+		backoff := notificationBackoff
+		for {
+			err = postNotification(client, sink.URL, token, contentType, body)
+			if err == nil || backoff.Steps <= 1 {
+				break
+			}
+			time.Sleep(backoff.Step())
+		}
+		if err != nil {
+			logger.Error(err, "failed to notify the condition transition", "sink", sink.Name,
+				"namespace", t.Namespace, "name", t.Name, "type", t.Type, "status", t.Status)
+			notificationsTotal.WithLabelValues(sink.Name, "failed").Inc()
+			continue
+		}
+		notificationsTotal.WithLabelValues(sink.Name, "delivered").Inc()
+	}
+}
+
+// notificationBody encodes the transition in the format of the sink.
+func notificationBody(format string, t conditionTransition) ([]byte, string, error) {
+	if format != dpuv1alpha1.NotificationFormatCloudEvents {
+		body, err := json.Marshal(t)
+		return body, "application/json", err
+	}
+	body, err := json.Marshal(cloudEvent{
+		SpecVersion:     "1.0",
+		ID:              string(uuid.NewUUID()),
+		Source:          fmt.Sprintf("/apis/%s/namespaces/%s/ovnkubeconfigs/%s", dpuv1alpha1.GroupVersion, t.Namespace, t.Name),
+		Type:            cloudEventType,
+		Subject:         t.Type,
+		Time:            t.Time,
+		DataContentType: "application/json",
+		Data:            t,
+	})
+	return body, cloudEventContentType, err
+}
+
+func postNotification(client *http.Client, url, token, contentType string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notification sink %s returned %s", url, resp.Status)
+	}
+	return nil
+}
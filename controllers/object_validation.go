@@ -0,0 +1,73 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+)
+
+// dryRunFieldOwner is the field manager of the server-side apply dry runs.
+// Nothing is persisted, so it never owns a field.
+const dryRunFieldOwner = "dpu-network-operator-validation"
+
+// validateRenderedObjects checks every rendered object before any of them
+// is applied, so an invalid one doesn't leave the others half applied. The
+// objects of a kind known to the scheme are decoded strictly, rejecting the
+// unknown and mistyped fields, then every object goes through a server-side
+// apply dry run, which runs the structural schema of the CRDs and the
+// admission of the API server.
+func (r *OVNKubeConfigReconciler) validateRenderedObjects(ctx context.Context, objs []*unstructured.Unstructured) error {
+	for _, obj := range objs {
+		if err := r.validateObjectSchema(obj); err != nil {
+			return dpuerrors.InvalidObject(fmt.Errorf("%s %s is not valid: %w", obj.GetKind(), obj.GetName(), err))
+		}
+	}
+	for _, obj := range objs {
+		err := r.Patch(ctx, obj.DeepCopy(), client.Apply, client.DryRunAll, client.ForceOwnership, client.FieldOwner(dryRunFieldOwner))
+		if errors.IsInvalid(err) || errors.IsBadRequest(err) {
+			return dpuerrors.InvalidObject(fmt.Errorf("%s %s was rejected by the API server: %w", obj.GetKind(), obj.GetName(), err))
+		}
+		// the namespace of the object may be created by the apply, or the
+		// kind be missing until its operator is installed; the apply
+		// reports them
+		if err != nil && !errors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateObjectSchema decodes obj strictly into its type, when the kind
+// is known to the scheme.
+func (r *OVNKubeConfigReconciler) validateObjectSchema(obj *unstructured.Unstructured) error {
+	typed, err := r.Scheme.New(obj.GroupVersionKind())
+	if runtime.IsNotRegisteredError(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructuredWithValidation(obj.Object, typed, true)
+}
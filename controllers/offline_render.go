@@ -0,0 +1,237 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	ctrlcommon "github.com/openshift/machine-config-operator/pkg/controller/common"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+// OfflineRenderOptions are the inputs RenderOffline can't discover without
+// a cluster.
+type OfflineRenderOptions struct {
+	// Platform holds the OpenShift APIs of the infra cluster.
+	Platform utils.Platform
+	// OvnkubeImage is the ovnkube image, unless set in the spec.
+	OvnkubeImage string
+	// IPsec is set when OVN IPsec is enabled in the tenant cluster.
+	IPsec bool
+}
+
+// RenderOffline renders the objects the operator creates for cfg in the
+// infra cluster without connecting to a cluster, e.g. to bake them into the
+// day-0 installation manifests: the MachineConfigPool and the switchdev
+// MachineConfig when the operator manages them, and the ovnkube-node
+// objects. The features of spec.ovn.features are taken as enabled in the
+// tenant cluster, and spec.ovn.externalDbEndpoints must be set since the
+// ovnkube-master pods can't be discovered. The objects are marked so the
+// operator takes them over once the OVNKubeConfig is created.
+func RenderOffline(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, opts OfflineRenderOptions) ([]*unstructured.Unstructured, error) {
+	if cfg.Namespace == "" {
+		return nil, fmt.Errorf("the OVNKubeConfig has no namespace")
+	}
+	if err := validateUplinks(cfg); err != nil {
+		return nil, err
+	}
+	eps := cfg.Spec.Ovn.ExternalDbEndpoints
+	if eps == nil {
+		return nil, fmt.Errorf("spec.ovn.externalDbEndpoints must be set, the ovnkube-master pods of the tenant cluster can't be discovered offline")
+	}
+	manageMachineConfig := offlineManageMachineConfig(cfg, opts)
+	nodeSelector, err := offlineNodeSelector(cfg, manageMachineConfig)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := RenderOfflineMachineConfigs(cfg, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	image, source := specOvnkubeImage(cfg), dpuv1alpha1.OvnKubeImageSourceSpec
+	if image == "" {
+		image, source = opts.OvnkubeImage, "--ovnkube-image"
+	}
+	if image == "" {
+		return nil, fmt.Errorf("the ovnkube image must be set in spec.images.ovnKube or with --ovnkube-image")
+	}
+	if err := validateImageReference(image, source); err != nil {
+		return nil, err
+	}
+	ovnControllerImage, err := getOvnControllerImage(ctx, cfg, image)
+	if err != nil {
+		return nil, err
+	}
+	ovsDaemonsImage, err := getOvsDaemonsImage(ctx, cfg, image)
+	if err != nil {
+		return nil, err
+	}
+	tenantFeatures := map[string]string{}
+	for _, f := range ovnFeatures {
+		tenantFeatures[f.option] = "true"
+	}
+	featureFlags, err := ovnFeatureFlags(cfg.Spec.Ovn.Features, tenantFeatures)
+	if err != nil {
+		return nil, err
+	}
+	priorityClassName := cfg.Spec.PriorityClassName
+	if priorityClassName == "" {
+		priorityClassName = defaultPriorityClassName
+	}
+	nodeObjs, err := renderOvnkubeNodeObjects(ctx, cfg, ovnkubeNodeInputs{
+		image:                      image,
+		ovnControllerImage:         ovnControllerImage,
+		ovsDaemonsImage:            ovsDaemonsImage,
+		priorityClassName:          priorityClassName,
+		nbDbList:                   externalDbList(eps.Nb, OVN_NB_PORT),
+		sbDbList:                   externalDbList(eps.Sb, OVN_SB_PORT),
+		featureFlags:               featureFlags,
+		ipsec:                      opts.IPsec,
+		privileged:                 cfg.Spec.Privileged || !manageMachineConfig,
+		securityContextConstraints: opts.Platform.SecurityContextConstraints,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range nodeObjs {
+		// the empty documents of the templates, e.g. around a conditional
+		if len(obj.Object) == 0 {
+			continue
+		}
+		// the operator adopts the DaemonSets and ConfigMaps it didn't
+		// create only when marked
+		switch obj.GetKind() {
+		case "DaemonSet":
+			ds, err := ovnkubeNodeDaemonSet(cfg, obj, nodeSelector)
+			if err != nil {
+				return nil, err
+			}
+			if obj, err = toUnstructured(ds, appsv1.SchemeGroupVersion.WithKind("DaemonSet")); err != nil {
+				return nil, err
+			}
+			annotations := obj.GetAnnotations()
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			annotations[utils.AdoptAnnotation] = "true"
+			obj.SetAnnotations(annotations)
+		case "ConfigMap":
+			markManaged(obj)
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// RenderOfflineMachineConfigs renders the MachineConfigPool and the switchdev
+// MachineConfig of cfg, none when the operator doesn't manage them, e.g. for
+// the manifests directory of openshift-install.
+func RenderOfflineMachineConfigs(cfg *dpuv1alpha1.OVNKubeConfig, opts OfflineRenderOptions) ([]*unstructured.Unstructured, error) {
+	if err := validateUplinks(cfg); err != nil {
+		return nil, err
+	}
+	objs := []*unstructured.Unstructured{}
+	if !offlineManageMachineConfig(cfg, opts) {
+		return objs, nil
+	}
+	if !workerPoolAllowed(cfg) {
+		nodeSelector, err := offlineNodeSelector(cfg, true)
+		if err != nil {
+			return nil, err
+		}
+		mcp, err := desiredMachineConfigPool(cfgPoolName(cfg), nodeSelector)
+		if err != nil {
+			return nil, err
+		}
+		mcp.Labels = map[string]string{utils.OwnerLabel: cfg.Namespace}
+		obj, err := toUnstructured(mcp, mcfgv1.SchemeGroupVersion.WithKind("MachineConfigPool"))
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+	mc, err := switchdevMachineConfig(cfg, opts.IPsec)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := toUnstructured(mc, mcfgv1.SchemeGroupVersion.WithKind("MachineConfig"))
+	if err != nil {
+		return nil, err
+	}
+	return append(objs, obj), nil
+}
+
+// RenderIgnition renders the switchdev MachineConfig of cfg as an Ignition
+// config, to merge into the ignition of the DPU nodes joining at install
+// time, so they boot in switchdev mode instead of being rebooted by the
+// machine-config-operator once installed. The RHCOS extensions of the
+// MachineConfig, such as ipsec, are not part of an Ignition config.
+func RenderIgnition(cfg *dpuv1alpha1.OVNKubeConfig, opts OfflineRenderOptions) ([]byte, error) {
+	if err := validateUplinks(cfg); err != nil {
+		return nil, err
+	}
+	mc, err := switchdevMachineConfig(cfg, opts.IPsec)
+	if err != nil {
+		return nil, err
+	}
+	ign, err := ctrlcommon.ParseAndConvertConfig(mc.Spec.Config.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the ignition config of MachineConfig %s: %v", mc.Name, err)
+	}
+	return json.MarshalIndent(ign, "", "  ")
+}
+
+func offlineManageMachineConfig(cfg *dpuv1alpha1.OVNKubeConfig, opts OfflineRenderOptions) bool {
+	return opts.Platform.MachineConfig && (cfg.Spec.ManageMachineConfig == nil || *cfg.Spec.ManageMachineConfig)
+}
+
+// offlineNodeSelector returns the selector of the DPU nodes of cfg, which
+// can't be read from a MachineConfigPool or a DpuNodePool offline.
+func offlineNodeSelector(cfg *dpuv1alpha1.OVNKubeConfig, manageMachineConfig bool) (*metav1.LabelSelector, error) {
+	if cfg.Spec.NodeSelector != nil {
+		return cfg.Spec.NodeSelector, nil
+	}
+	if manageMachineConfig && workerPoolAllowed(cfg) {
+		return &metav1.LabelSelector{MatchLabels: map[string]string{"node-role.kubernetes.io/worker": ""}}, nil
+	}
+	return nil, fmt.Errorf("spec.nodeSelector must be set, the nodes of pool %s can't be read offline", cfgPoolName(cfg))
+}
+
+// toUnstructured converts a typed object, which doesn't carry its TypeMeta,
+// into an object of the kind, without the fields set by the API server.
+func toUnstructured(obj runtime.Object, gvk schema.GroupVersionKind) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{Object: content}
+	u.SetGroupVersionKind(gvk)
+	unstructured.RemoveNestedField(u.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(u.Object, "status")
+	return u, nil
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+)
+
+// requireAPI fails with APINotInstalled when the API server behind mapper
+// doesn't serve kind, e.g. when the operator shipping its CRD is not
+// installed, so the optional feature using it is reported as disabled
+// rather than failing. The mapper rediscovers the APIs on a miss, the
+// feature is enabled on a later reconcile once the CRD is installed.
+func requireAPI(mapper meta.RESTMapper, gk schema.GroupKind, version, operator string) error {
+	_, err := mapper.RESTMapping(gk, version)
+	if meta.IsNoMatchError(err) {
+		return dpuerrors.APINotInstalled(fmt.Errorf("%s is not installed, %s.%s/%s is not served", operator, gk.Kind, gk.Group, version))
+	}
+	return err
+}
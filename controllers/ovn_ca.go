@@ -0,0 +1,199 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/openshift/dpu-network-operator/api"
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+// getOvnCABundle returns the CA bundle mounted into ovnkube-node, from the
+// Secret referenced by spec.ovn.caSecretRef if set, or else from the ovn-ca
+// ConfigMap synced from the tenant cluster.
+func (r *OVNKubeConfigReconciler) getOvnCABundle(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) ([]byte, error) {
+	if ref := cfg.Spec.Ovn.CASecretRef; ref != nil {
+		s := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: cfg.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, fmt.Errorf("failed to get the OVN CA Secret %s: %v", ref.Name, err)
+		}
+		bundle, ok := s.Data[utils.OvnCABundleKey]
+		if !ok {
+			return nil, fmt.Errorf("OVN CA Secret %s has no %s key", ref.Name, utils.OvnCABundleKey)
+		}
+		return bundle, nil
+	}
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: cfg.Namespace, Name: utils.CmNameOvnCa}, cm); err != nil {
+		return nil, fmt.Errorf("failed to get the OVN CA ConfigMap: %v", err)
+	}
+	bundle, ok := cm.Data[utils.OvnCABundleKey]
+	if !ok {
+		return nil, fmt.Errorf("OVN CA ConfigMap has no %s key", utils.OvnCABundleKey)
+	}
+	return []byte(bundle), nil
+}
+
+// validateOvnCertChain verifies that the ovn-cert certificate mounted into
+// ovnkube-node chains to the OVN CA, so a mismatched custom CA is reported
+// instead of failing the SSL handshakes with the OVN databases.
+func (r *OVNKubeConfigReconciler) validateOvnCertChain(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) error {
+	bundle, err := r.getOvnCABundle(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(bundle) {
+		return fmt.Errorf("OVN CA bundle holds no valid certificate")
+	}
+
+	s := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: cfg.Namespace, Name: utils.SecretNameOvnCert}, s); err != nil {
+		return fmt.Errorf("failed to get the OVN certificate: %v", err)
+	}
+	certs, err := parseCertificates(s.Data[corev1.TLSCertKey])
+	if err != nil {
+		return fmt.Errorf("invalid OVN certificate: %v", err)
+	}
+	// any certificate following the leaf is an intermediate
+	intermediates := x509.NewCertPool()
+	for _, c := range certs[1:] {
+		intermediates.AddCert(c)
+	}
+	_, err = certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return fmt.Errorf("OVN certificate %q doesn't chain to the OVN CA: %v", certs[0].Subject.CommonName, err)
+	}
+	return nil
+}
+
+// checkOvnCertIdentities reports in the CertInvalidForNodes condition the
+// DPU nodes whose identity, spec.ovn.certIdentity, is neither the common
+// name nor a subject alternative name of the ovn-cert certificate, rather
+// than leaving ovn-controller to fail its TLS handshakes on them.
+func (r *OVNKubeConfigReconciler) checkOvnCertIdentities(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) error {
+	pattern := cfg.Spec.Ovn.CertIdentity
+	if pattern == "" {
+		meta.RemoveStatusCondition(&cfg.Status.Conditions, api.CertInvalidForNodes)
+		return nil
+	}
+	s := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: cfg.Namespace, Name: utils.SecretNameOvnCert}, s); err != nil {
+		return fmt.Errorf("failed to get the OVN certificate: %v", err)
+	}
+	certs, err := parseCertificates(s.Data[corev1.TLSCertKey])
+	if err != nil {
+		return fmt.Errorf("invalid OVN certificate: %v", err)
+	}
+	missing := []string{}
+	for _, node := range cfg.Status.Nodes {
+		identity := strings.ReplaceAll(pattern, "{node}", node.Name)
+		if !certHoldsIdentity(certs[0], identity) {
+			missing = append(missing, fmt.Sprintf("%s (%s)", node.Name, identity))
+		}
+	}
+	if len(missing) == 0 {
+		meta.SetStatusCondition(&cfg.Status.Conditions, *api.Conditions(cfg).CertValidForNodes().Reason(api.ReasonIdentitiesCovered).Build())
+		return nil
+	}
+	sort.Strings(missing)
+	list := missing
+	if len(list) > maxPodIssueNodes {
+		list = append(list[:maxPodIssueNodes:maxPodIssueNodes], fmt.Sprintf("%d more", len(missing)-maxPodIssueNodes))
+	}
+	msg := fmt.Sprintf("OVN certificate %q doesn't hold the identity of %d DPU nodes: %s",
+		certs[0].Subject.CommonName, len(missing), strings.Join(list, ", "))
+	logger.Info("OVN certificate invalid for nodes", "namespace", cfg.Namespace, "reason", msg)
+	meta.SetStatusCondition(&cfg.Status.Conditions, *api.Conditions(cfg).CertInvalidForNodes().Reason(api.ReasonMissingIdentities).Msg(msg).Build())
+	return nil
+}
+
+// certHoldsIdentity returns whether identity is the common name of cert,
+// one of its DNS names, wildcards included, IP addresses, URIs or email
+// addresses.
+func certHoldsIdentity(cert *x509.Certificate, identity string) bool {
+	if cert.Subject.CommonName == identity {
+		return true
+	}
+	if ip := net.ParseIP(identity); ip != nil {
+		for _, a := range cert.IPAddresses {
+			if a.Equal(ip) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, name := range cert.DNSNames {
+		if strings.EqualFold(name, identity) {
+			return true
+		}
+	}
+	// VerifyHostname matches the wildcards of the DNS names
+	if cert.VerifyHostname(identity) == nil {
+		return true
+	}
+	for _, u := range cert.URIs {
+		if u.String() == identity {
+			return true
+		}
+	}
+	for _, email := range cert.EmailAddresses {
+		if email == identity {
+			return true
+		}
+	}
+	return false
+}
+
+func parseCertificates(data []byte) ([]*x509.Certificate, error) {
+	certs := []*x509.Certificate{}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, c)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificate found")
+	}
+	return certs, nil
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+)
+
+// splitDbEndpoint splits a host or host:port endpoint, falling back to
+// defaultPort when the port is omitted.
+func splitDbEndpoint(endpoint, defaultPort string) (string, string) {
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		// no port, or a bare IPv6 address
+		return strings.Trim(endpoint, "[]"), defaultPort
+	}
+	return host, port
+}
+
+// externalDbList renders the endpoints into the comma separated list of
+// ssl addresses expected by ovnkube.
+func externalDbList(endpoints []string, defaultPort string) string {
+	addrs := make([]string, len(endpoints))
+	for i, ep := range endpoints {
+		host, port := splitDbEndpoint(ep, defaultPort)
+		addrs[i] = "ssl:" + net.JoinHostPort(host, port)
+	}
+	return strings.Join(addrs, ",")
+}
+
+// validateDbEndpoints makes sure every hostname of the external endpoints
+// resolves, so a typo surfaces in the CR status rather than in ovn-controller.
+func validateDbEndpoints(ctx context.Context, eps *dpuv1alpha1.ExternalDbEndpoints) error {
+	for _, ep := range append(append([]string{}, eps.Nb...), eps.Sb...) {
+		host, _ := splitDbEndpoint(ep, "")
+		if host == "" {
+			return fmt.Errorf("invalid OVN DB endpoint %q", ep)
+		}
+		if net.ParseIP(host) != nil {
+			continue
+		}
+		if _, err := net.DefaultResolver.LookupHost(ctx, host); err != nil {
+			return fmt.Errorf("failed to resolve OVN DB endpoint %q: %v", ep, err)
+		}
+	}
+	return nil
+}
+
+// getOvnDbLists returns the NB and SB DB lists rendered into ovnkube-node,
+// from the external endpoints if set, or else from the ovnkube-master pods
+// of the tenant cluster.
+func (r *OVNKubeConfigReconciler) getOvnDbLists(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) (string, string, error) {
+	if eps := cfg.Spec.Ovn.ExternalDbEndpoints; eps != nil {
+		if err := validateDbEndpoints(ctx, eps); err != nil {
+			return "", "", err
+		}
+		return externalDbList(eps.Nb, OVN_NB_PORT), externalDbList(eps.Sb, OVN_SB_PORT), nil
+	}
+	masterIPs, err := r.getTenantClusterMasterIPs(ctx, cfg)
+	if err != nil {
+		return "", "", err
+	}
+	return dbList(masterIPs, OVN_NB_PORT), dbList(masterIPs, OVN_SB_PORT), nil
+}
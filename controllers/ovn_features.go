@@ -0,0 +1,64 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+)
+
+// ovnFeatures maps the feature toggles to the ovnkube-node flags, and to the
+// options enabling them in the [ovnkubernetesfeature] section of the tenant
+// ovnkube.conf.
+var ovnFeatures = []struct {
+	name    string
+	enabled func(*dpuv1alpha1.OvnFeatures) bool
+	flag    string
+	option  string
+}{
+	{"egressIP", func(f *dpuv1alpha1.OvnFeatures) bool { return f.EgressIP }, "--enable-egress-ip", "enable-egress-ip"},
+	{"egressFirewall", func(f *dpuv1alpha1.OvnFeatures) bool { return f.EgressFirewall }, "--enable-egress-firewall", "enable-egress-firewall"},
+	{"multicastSnooping", func(f *dpuv1alpha1.OvnFeatures) bool { return f.MulticastSnooping }, "--enable-multicast", "enable-multicast"},
+}
+
+// ovnFeatureFlags returns the ovnkube-node flags of the enabled features. It
+// fails with a FeatureMismatch when a feature is not enabled in the tenant
+// cluster, since the DPU data plane would diverge from its control plane.
+func ovnFeatureFlags(features *dpuv1alpha1.OvnFeatures, tenantFeatures map[string]string) ([]string, error) {
+	if features == nil {
+		return nil, nil
+	}
+	flags := []string{}
+	mismatches := []string{}
+	for _, f := range ovnFeatures {
+		if !f.enabled(features) {
+			continue
+		}
+		if tenantFeatures[f.option] != "true" {
+			mismatches = append(mismatches, f.name)
+			continue
+		}
+		flags = append(flags, f.flag)
+	}
+	if len(mismatches) > 0 {
+		return nil, dpuerrors.FeatureMismatch(fmt.Errorf("%s enabled in spec.ovn.features but not in the tenant cluster", strings.Join(mismatches, ", ")))
+	}
+	return flags, nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+// ovnIPsecEnabled reports whether OVN IPsec is enabled in the ovnkube-config
+// synced from the tenant cluster. It fails with NotFound until the ConfigMap
+// is synced.
+func (r *OVNKubeConfigReconciler) ovnIPsecEnabled(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) (bool, error) {
+	features, err := r.tenantOvnkubeFeatures(ctx, cfg)
+	if err != nil {
+		return false, err
+	}
+	return features["enable-ipsec"] == "true", nil
+}
+
+// tenantOvnkubeFeatures returns the options of the [ovnkubernetesfeature]
+// section of the ovnkube-config synced from the tenant cluster.
+func (r *OVNKubeConfigReconciler) tenantOvnkubeFeatures(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) (map[string]string, error) {
+	if tenantKubeconfigName(cfg) == "" {
+		return nil, nil
+	}
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: cfg.Namespace, Name: utils.CmNameOvnkubeConfig}, cm); err != nil {
+		return nil, fmt.Errorf("failed to get the %s ConfigMap of the tenant cluster: %w", utils.CmNameOvnkubeConfig, err)
+	}
+	return parseOvnkubeConf(cm.Data[utils.OvnkubeConfKey])["ovnkubernetesfeature"], nil
+}
+
+// checkSignerCaSynced verifies that the CA signing the IPsec certificates
+// has been synced from the tenant cluster.
+func (r *OVNKubeConfigReconciler) checkSignerCaSynced(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) error {
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: cfg.Namespace, Name: utils.CmNameSignerCa}, cm); err != nil {
+		return fmt.Errorf("OVN IPsec is enabled but the %s ConfigMap of the tenant cluster is not synced: %w", utils.CmNameSignerCa, err)
+	}
+	if cm.Data[utils.OvnCABundleKey] == "" {
+		return fmt.Errorf("ConfigMap %s has no %s", utils.CmNameSignerCa, utils.OvnCABundleKey)
+	}
+	return nil
+}
+
+// parseOvnkubeConf returns the options of an ovnkube configuration file,
+// keyed by section and name.
+func parseOvnkubeConf(conf string) map[string]map[string]string {
+	sections := map[string]map[string]string{}
+	section := ""
+	scanner := bufio.NewScanner(strings.NewReader(conf))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			section = strings.ToLower(strings.TrimSpace(line[1 : len(line)-1]))
+		default:
+			name, value, _ := strings.Cut(line, "=")
+			if sections[section] == nil {
+				sections[section] = map[string]string{}
+			}
+			sections[section][strings.TrimSpace(name)] = strings.Trim(strings.TrimSpace(value), "\"")
+		}
+	}
+	return sections
+}
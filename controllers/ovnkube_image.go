@@ -0,0 +1,230 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+// imageReferenceRegexp matches the image references of the distribution
+// grammar: an optional registry host, a repository path, and a tag and/or a
+// sha256 digest.
+var imageReferenceRegexp = regexp.MustCompile(`^(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)*(?::[0-9]+)?/)?[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*(?::[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127})?(?:@sha256:[a-f0-9]{64})?$`)
+
+// validateImageReference checks that image is a well-formed reference, so a
+// typo is reported up front rather than as an ImagePullBackOff on every DPU.
+func validateImageReference(image, source string) error {
+	if !imageReferenceRegexp.MatchString(image) {
+		return dpuerrors.InvalidImage(fmt.Errorf("invalid image %q (source: %s)", image, source))
+	}
+	return nil
+}
+
+// getOvnkubeImage returns the ovnkube image rendered for cfg.
+func (r *OVNKubeConfigReconciler) getOvnkubeImage(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) (string, error) {
+	image, _, err := r.resolveOvnkubeImage(ctx, cfg)
+	return image, err
+}
+
+// resolveOvnkubeImage returns the ovnkube image and where it comes from, by
+// precedence: spec.images.ovnKube, spec.ovnKubeNode.image, the OVNKUBE_IMAGE
+// env of the operator,
+// the ovnkube-master image of the tenant cluster, or else the image of the
+// ovnkube-node DaemonSet of the infra cluster.
+func (r *OVNKubeConfigReconciler) resolveOvnkubeImage(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) (string, string, error) {
+	image, source, err := r.lookupOvnkubeImage(ctx, cfg)
+	if err != nil {
+		return "", "", err
+	}
+	if err := validateImageReference(image, source); err != nil {
+		return "", "", err
+	}
+	if os.Getenv("OVNKUBE_IMAGE_RESOLVE") == "true" {
+		if err := resolveImageRegistry(ctx, image, source); err != nil {
+			return "", "", err
+		}
+	}
+	return image, source, nil
+}
+
+// specOvnkubeImage returns the ovnkube image set in the spec,
+// spec.images.ovnKube or else spec.ovnKubeNode.image, "" if none.
+func specOvnkubeImage(cfg *dpuv1alpha1.OVNKubeConfig) string {
+	if cfg.Spec.Images != nil && cfg.Spec.Images.OvnKube != "" {
+		return cfg.Spec.Images.OvnKube
+	}
+	if cfg.Spec.OvnKubeNode != nil {
+		return cfg.Spec.OvnKubeNode.Image
+	}
+	return ""
+}
+
+func (r *OVNKubeConfigReconciler) lookupOvnkubeImage(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) (string, string, error) {
+	if image := specOvnkubeImage(cfg); image != "" {
+		return image, dpuv1alpha1.OvnKubeImageSourceSpec, nil
+	}
+	if image := os.Getenv("OVNKUBE_IMAGE"); image != "" {
+		return image, dpuv1alpha1.OvnKubeImageSourceEnv, nil
+	}
+	image, tenantErr := r.getTenantOvnkubeImage(ctx, cfg)
+	if tenantErr == nil {
+		return image, dpuv1alpha1.OvnKubeImageSourceTenant, nil
+	}
+	image, found, err := r.getLocalOvnkubeImage(ctx)
+	if err != nil {
+		return "", "", err
+	} else if !found {
+		return "", "", dpuerrors.InvalidImage(fmt.Errorf("no ovnkube image: OVNKUBE_IMAGE is not set, the image of the tenant cluster cannot be used (%v) and the infra cluster doesn't run OVN-Kubernetes", tenantErr))
+	}
+	return image, dpuv1alpha1.OvnKubeImageSourceLocal, nil
+}
+
+// getOvnControllerImage returns the ovn-controller image rendered for cfg,
+// by precedence: spec.images.ovnController, the OVN_CONTROLLER_IMAGE env of
+// the operator, or else ovnkubeImage.
+func getOvnControllerImage(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, ovnkubeImage string) (string, error) {
+	image := ""
+	if cfg.Spec.Images != nil {
+		image = cfg.Spec.Images.OvnController
+	}
+	return resolveComponentImage(ctx, image, "spec.images.ovnController", "OVN_CONTROLLER_IMAGE", ovnkubeImage)
+}
+
+// getOvsDaemonsImage returns the image of the containers running Open
+// vSwitch daemons, by precedence: spec.images.ovsDaemons, the
+// OVS_DAEMONS_IMAGE env of the operator, or else ovnkubeImage.
+func getOvsDaemonsImage(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, ovnkubeImage string) (string, error) {
+	image := ""
+	if cfg.Spec.Images != nil {
+		image = cfg.Spec.Images.OvsDaemons
+	}
+	return resolveComponentImage(ctx, image, "spec.images.ovsDaemons", "OVS_DAEMONS_IMAGE", ovnkubeImage)
+}
+
+// getNodeAgentImage returns the image of the helper DaemonSets publishing
+// the state of the DPU nodes, by precedence: spec.images.nodeAgent, the
+// NODE_AGENT_IMAGE env of the operator, or else ovnkubeImage.
+func getNodeAgentImage(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, ovnkubeImage string) (string, error) {
+	image := ""
+	if cfg.Spec.Images != nil {
+		image = cfg.Spec.Images.NodeAgent
+	}
+	return resolveComponentImage(ctx, image, "spec.images.nodeAgent", "NODE_AGENT_IMAGE", ovnkubeImage)
+}
+
+// resolveComponentImage returns specImage, or else the image of the env
+// variable, or else the ovnkube image, already checked, as a default. The
+// image set is checked like the ovnkube image.
+func resolveComponentImage(ctx context.Context, specImage, specField, env, ovnkubeImage string) (string, error) {
+	image, source := specImage, specField
+	if image == "" {
+		image, source = os.Getenv(env), env
+	}
+	if image == "" {
+		return ovnkubeImage, nil
+	}
+	if err := validateImageReference(image, source); err != nil {
+		return "", err
+	}
+	if os.Getenv("OVNKUBE_IMAGE_RESOLVE") == "true" {
+		if err := resolveImageRegistry(ctx, image, source); err != nil {
+			return "", err
+		}
+	}
+	return image, nil
+}
+
+// getTenantOvnkubeImage returns the image of the ovnkube-master containers
+// of the tenant cluster.
+func (r *OVNKubeConfigReconciler) getTenantOvnkubeImage(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) (string, error) {
+	if r.connectedTenantConfig(cfg.Namespace) == nil {
+		return "", fmt.Errorf("the tenant cluster is not configured yet")
+	}
+	ns, err := r.tenantOvnNamespace(ctx, cfg)
+	if err != nil {
+		return "", err
+	}
+	pods, err := r.listTenantOvnkubeMasterPods(ctx, cfg, ns)
+	if err != nil {
+		return "", err
+	}
+	for _, pod := range pods.Items {
+		for _, c := range pod.Spec.Containers {
+			if c.Name == "ovnkube-master" {
+				return c.Image, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no ovnkube-master pod found in namespace %s of the tenant cluster", ns)
+}
+
+// resolveImageRegistry checks that the registry host of image resolves, so
+// an image from an unknown or air-gapped registry is reported before the
+// DPUs try to pull it. Images without a registry host are pulled from the
+// default registry of the container runtime and are not checked.
+func resolveImageRegistry(ctx context.Context, image, source string) error {
+	i := strings.Index(image, "/")
+	if i < 0 {
+		return nil
+	}
+	host := image[:i]
+	if !strings.ContainsAny(host, ".:") && host != "localhost" {
+		return nil
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if _, err := net.DefaultResolver.LookupHost(ctx, host); err != nil {
+		return dpuerrors.InvalidImage(fmt.Errorf("registry of image %q (source: %s) cannot be resolved: %v", image, source, err))
+	}
+	return nil
+}
+
+// getLocalOvnkubeImage returns the image of the ovnkube-node DaemonSet of
+// the infra cluster, and whether the DaemonSet exists.
+func (r *OVNKubeConfigReconciler) getLocalOvnkubeImage(ctx context.Context) (string, bool, error) {
+	ds := &appsv1.DaemonSet{}
+	name := types.NamespacedName{Namespace: utils.LocalOvnkbueNamespace, Name: utils.LocalOvnkbueNodeDsName}
+	err := r.Get(ctx, name, ds)
+	if errors.IsNotFound(err) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, fmt.Errorf("DaemonSet %s could not be read: %v", name, err)
+	}
+	for _, c := range ds.Spec.Template.Spec.Containers {
+		if c.Name == utils.LocalOvnkbueNodeDsName {
+			return c.Image, true, nil
+		}
+	}
+	if len(ds.Spec.Template.Spec.Containers) == 0 {
+		return "", false, dpuerrors.InvalidImage(fmt.Errorf("DaemonSet %s has no container", name))
+	}
+	return ds.Spec.Template.Spec.Containers[0].Image, true, nil
+}
@@ -24,36 +24,51 @@ import (
 	"os"
 	"reflect"
 	"strings"
+	"time"
 
-	"github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/apply"
 	"github.com/openshift/cluster-network-operator/pkg/render"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
 
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/client-go/kubernetes/scheme"
-	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	mcrender "github.com/k8snetworkplumbingwg/sriov-network-operator/pkg/render"
 	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
 
-	"github.com/openshift/dpu-network-operator/api"
 	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
-	syncer "github.com/openshift/dpu-network-operator/pkg/ovnkube-syncer"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+	"github.com/openshift/dpu-network-operator/pkg/tracing"
 	"github.com/openshift/dpu-network-operator/pkg/utils"
 )
 
 const (
 	dpuMcRole = "dpu-worker"
+	// workerPool is the MachineConfigPool of every worker, allowed as the
+	// pool of the DPU nodes with spec.allowWorkerPool
+	workerPool = "worker"
+	// machineConfigTemplates holds the templates of the switchdev
+	// MachineConfig
+	machineConfigTemplates = "bindata/machine-config"
+	// defaultMcNamePrefix orders the switchdev MachineConfig first
+	defaultMcNamePrefix = "00"
+
+	eventReasonWorkerPoolAllowed = "WorkerPoolAllowed"
 )
 
 var logger = log.Log.WithName("controller_ovnkubeconfig")
@@ -61,292 +76,477 @@ var logger = log.Log.WithName("controller_ovnkubeconfig")
 const (
 	OVN_NB_PORT = "9641"
 	OVN_SB_PORT = "9642"
+
+	preflightRequeueInterval = 1 * time.Minute
+
+	defaultPriorityClassName = "system-node-critical"
 )
 
 // OVNKubeConfigReconciler reconciles a OVNKubeConfig object
 type OVNKubeConfigReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
-	syncer *syncer.OvnkubeSyncer
-	stopCh chan struct{}
+	// APIReader reads the objects which are not worth caching, such as
+	// the pods of the DaemonSets.
+	APIReader client.Reader
+	// SecretReader reads the tenant kubeconfig Secrets of other namespaces,
+	// see NewKubeconfigSecretReader.
+	SecretReader client.Reader
+	Scheme       *runtime.Scheme
+	// Platform holds the OpenShift APIs available in the infra cluster.
+	Platform *utils.DynamicPlatform
+	// Recorder publishes the drift reports as events.
+	Recorder record.EventRecorder
+	// syncers are the running tenant syncers, by OVNKubeConfig namespace.
+	syncers tenantSyncers
+	// tenantTriggers queues the re-renders triggered by the tenant cluster.
+	tenantTriggers *tenantTriggers
+	// kubeClient reads the logs of the trace and capture pods.
+	kubeClient kubernetes.Interface
+	// MaxConcurrentReconciles is the number of OVNKubeConfigs each
+	// controller reconciles in parallel, DefaultMaxConcurrentReconciles when
+	// not set. The state of a CR is kept in syncers, by namespace, or in the
+	// caches guarded by their lock.
+	MaxConcurrentReconciles int
 }
 
 //+kubebuilder:rbac:groups=dpu.openshift.io,resources=ovnkubeconfigs,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=dpu.openshift.io,resources=ovnkubeconfigs/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=dpu.openshift.io,resources=ovnkubeconfigs/finalizers,verbs=update
 //+kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
-//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
-//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=list;watch
 //+kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=machineconfiguration.openshift.io,resources=machineconfigpools,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=machineconfiguration.openshift.io,resources=machineconfigs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=scheduling.k8s.io,resources=priorityclasses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=security.openshift.io,resources=securitycontextconstraints,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=security.openshift.io,resources=securitycontextconstraints,resourceNames=anyuid;hostnetwork,verbs=use
 
-// Reconcile is part of the main kubernetes reconciliation loop which aims to
-// move the current state of the cluster closer to the desired state.
-// TODO(user): Modify the Reconcile function to compare the state specified by
-// the OVNKubeConfig object against the actual cluster state, and then
-// perform operations to make the cluster state reflect the state specified by
-// the user.
-//
-// For more details, check Reconcile and its Result here:
-// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.9.2/pkg/reconcile
-func (r *OVNKubeConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	var err error
-	logger := log.FromContext(ctx).WithValues("reconcile OVNKubeConfig", req.NamespacedName)
-	logger.Info("Reconcile")
-	ovnkubeConfig := &dpuv1alpha1.OVNKubeConfig{}
+// SetupWithManager sets up the machine-config, tenant-sync and workload
+// controllers with the Manager. They reconcile the same OVNKubeConfig, each
+// with its own watches and conditions, so a failure in one area doesn't
+// block the others. The drift detection, the chassis verification and the
+// DpuTrace and DpuPacketCapture controllers run alongside them.
+func (r *OVNKubeConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.tenantTriggers = newTenantTriggers()
+	if err := r.setupMachineConfigController(mgr); err != nil {
+		return err
+	}
+	if err := r.setupTenantSyncController(mgr); err != nil {
+		return err
+	}
+	if err := r.setupWorkloadController(mgr); err != nil {
+		return err
+	}
+	if err := r.setupDelegationController(mgr); err != nil {
+		return err
+	}
+	if err := r.setupTraceController(mgr); err != nil {
+		return err
+	}
+	if err := r.setupCaptureController(mgr); err != nil {
+		return err
+	}
+	if err := mgr.Add(manager.RunnableFunc(r.runDriftDetection)); err != nil {
+		return err
+	}
+	return mgr.Add(manager.RunnableFunc(r.runChassisVerification))
+}
 
+// getNamespaceConfig returns the OVNKubeConfig of the namespace, or nil if
+// there is none. Only one OVNKubeConfig is supported per namespace.
+func (r *OVNKubeConfigReconciler) getNamespaceConfig(ctx context.Context, namespace string) (*dpuv1alpha1.OVNKubeConfig, error) {
 	cfgList := &dpuv1alpha1.OVNKubeConfigList{}
-	err = r.List(ctx, cfgList, &client.ListOptions{Namespace: req.Namespace})
-	if err != nil {
-		return ctrl.Result{}, err
+	if err := r.List(ctx, cfgList, &client.ListOptions{Namespace: namespace}); err != nil {
+		return nil, err
 	}
 	if len(cfgList.Items) > 1 {
-		logger.Error(fmt.Errorf("more than one OVNKubeConfig CR is found in"), "namespace", req.Namespace)
-		return ctrl.Result{}, err
-	} else if len(cfgList.Items) == 1 {
-		ovnkubeConfig = &cfgList.Items[0]
-
-		defer func() {
-			if err := r.Status().Update(context.TODO(), ovnkubeConfig); err != nil {
-				logger.Error(err, "unable to update OVNKubeConfig status")
-			}
-		}()
-
-		if ovnkubeConfig.Spec.PoolName == "" {
-			logger.Info("poolName is not provided")
-			return ctrl.Result{}, nil
-		} else {
-			err = r.syncMachineConfigObjs(ovnkubeConfig.Spec)
-			if err != nil {
-				meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions().NotMcpReady().Reason(api.ReasonFailedCreated).Msg(err.Error()).Build())
-				return ctrl.Result{}, err
-			}
-			meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions().McpReady().Reason(api.ReasonCreated).Build())
-		}
+		return nil, fmt.Errorf("more than one OVNKubeConfig CR is found in namespace %s", namespace)
+	} else if len(cfgList.Items) == 0 {
+		return nil, nil
+	}
+	return &cfgList.Items[0], nil
+}
 
-		if ovnkubeConfig.Spec.KubeConfigFile == "" {
-			logger.Info("kubeconfig of tenant cluster is not provided")
-			return ctrl.Result{}, nil
+// updateStatus copies the conditions of the given types, and the fields
+// copied by copyFields, from cfg onto the latest OVNKubeConfig, so the
+// controllers sharing the CR don't overwrite each other's status. Ready is
+// recomputed from the merged conditions. The condition transitions are then
+// notified to the sinks of the DpuOperatorConfig, and the conditions
+// exported as metrics.
+func (r *OVNKubeConfigReconciler) updateStatus(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, conditionTypes []string, copyFields func(dst, src *dpuv1alpha1.OVNKubeConfigStatus)) error {
+	var transitions []conditionTransition
+	var conditions []metav1.Condition
+	deleted := false
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		transitions = nil
+		latest := &dpuv1alpha1.OVNKubeConfig{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name}, latest); err != nil {
+			deleted = errors.IsNotFound(err)
+			return client.IgnoreNotFound(err)
 		}
-		if r.syncer == nil {
-			logger.Info("Create the tenant syncer")
-			r.stopCh = make(chan struct{})
-			if err = r.startTenantSyncer(ctx, ovnkubeConfig); err != nil {
-				meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions().NotTenantObjsSynced().Reason(api.ReasonFailedStart).Msg(err.Error()).Build())
-				return ctrl.Result{}, err
-			}
-			if err := r.isTenantObjsSynced(ctx, req.Namespace); err != nil {
-				meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions().NotTenantObjsSynced().Reason(api.ReasonNotFound).Msg(err.Error()).Build())
+		status := latest.Status.DeepCopy()
+		for _, t := range conditionTypes {
+			if c := meta.FindStatusCondition(cfg.Status.Conditions, t); c != nil {
+				meta.SetStatusCondition(&status.Conditions, *c)
 			} else {
-				meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions().TenantObjsSynced().Reason(api.ReasonCreated).Build())
+				meta.RemoveStatusCondition(&status.Conditions, t)
 			}
 		}
-		if err = r.syncOvnkubeDaemonSet(ctx, ovnkubeConfig); err != nil {
-			logger.Info("Sync DaemonSet ovnkube-node")
-			meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions().NotOvnKubeReady().Reason(api.ReasonFailedCreated).Msg(err.Error()).Build())
-			return ctrl.Result{}, err
+		if copyFields != nil {
+			copyFields(status, &cfg.Status)
 		}
-		ds := appsv1.DaemonSet{}
-		if err = r.Get(ctx, types.NamespacedName{Namespace: req.Namespace, Name: "ovnkube-node"}, &ds); err != nil {
-			meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions().NotOvnKubeReady().Reason(api.ReasonNotFound).Msg(err.Error()).Build())
-			return ctrl.Result{}, err
+		// Ready is owned by no controller, it follows the merged conditions
+		meta.SetStatusCondition(&status.Conditions, *readyCondition(latest, status.Conditions))
+		conditions = status.Conditions
+		if equality.Semantic.DeepEqual(&latest.Status, status) {
+			return nil
 		}
-		if ds.Status.DesiredNumberScheduled == ds.Status.NumberReady {
-			meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions().OvnKubeReady().Reason(api.ReasonCreated).Build())
+		transitions = conditionTransitions(latest, latest.Status.Conditions, status.Conditions)
+		latest.Status = *status
+		return r.Status().Update(ctx, latest)
+	})
+	if err == nil {
+		r.notifyTransitions(ctx, transitions)
+		if deleted {
+			resetConditionMetrics(cfg.Namespace)
+			resetRenderFailures(cfg.Namespace)
+			resetCachedMachineConfigs(cfg.Namespace)
 		} else {
-			meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions().NotOvnKubeReady().Reason(api.ReasonProgressing).Msg("DaemonSet 'ovnkube-node' is rolling out").Build())
-		}
-	} else if len(cfgList.Items) == 0 {
-		if r.syncer != nil {
-			logger.Info("Stop the ovnkube syncer")
-			close(r.stopCh)
-			r.syncer = nil
+			recordConditionMetrics(cfg.Namespace, conditions)
 		}
 	}
-
-	return ctrl.Result{}, nil
+	return err
 }
 
-// SetupWithManager sets up the controller with the Manager.
-func (r *OVNKubeConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&dpuv1alpha1.OVNKubeConfig{}).
-		Owns(&corev1.ConfigMap{}).
-		Owns(&corev1.Secret{}).
-		Owns(&appsv1.DaemonSet{}).
-		Complete(r)
-}
-
-func (r *OVNKubeConfigReconciler) startTenantSyncer(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) error {
-	logger.Info("Start the tenant syncer")
+func (r *OVNKubeConfigReconciler) syncOvnkubeDaemonSet(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) error {
+	logger.Info("Start to sync ovnkube daemonset")
 	var err error
-	s := &corev1.Secret{}
-
-	err = r.Client.Get(ctx, types.NamespacedName{Name: cfg.Spec.KubeConfigFile, Namespace: cfg.Namespace}, s)
+	nodeSelector, err := r.poolNodeSelector(ctx, cfg)
 	if err != nil {
 		return err
 	}
-	bytes, ok := s.Data["config"]
-	if !ok {
-		return fmt.Errorf("key 'config' cannot be found in secret %s", cfg.Spec.KubeConfigFile)
+	if err := r.checkNodeSelection(ctx, cfg, nodeSelector); err != nil {
+		return err
+	}
+	if !r.Platform.Get().SecurityContextConstraints {
+		if err := r.ensurePodSecurityLabels(ctx, cfg.Namespace); err != nil {
+			return err
+		}
 	}
 
-	utils.TenantRestConfig, err = clientcmd.RESTConfigFromKubeConfig(bytes)
-	if err != nil {
+	if err := r.adoptDaemonSets(ctx, cfg); err != nil {
 		return err
 	}
-
-	r.syncer, err = syncer.New(syncer.SyncerConfig{
-		// LocalClusterID:   cfg.Namespace,
-		LocalRestConfig:  ctrl.GetConfigOrDie(),
-		LocalNamespace:   cfg.Namespace,
-		TenantRestConfig: utils.TenantRestConfig,
-		TenantNamespace:  utils.TenantNamespace}, cfg, r.Scheme)
-	if err != nil {
+	objs, err := r.dataPlane(cfg).render(ctx, cfg)
+	if err != nil || objs == nil {
 		return err
 	}
-	go func() {
-		if err = r.syncer.Start(r.stopCh); err != nil {
-			logger.Error(err, "Error running the ovnkube syncer")
-		}
-	}()
-	if err != nil {
+	if err := r.validateRenderedObjects(ctx, objs); err != nil {
 		return err
 	}
-
-	return nil
-}
-
-func (r *OVNKubeConfigReconciler) syncOvnkubeDaemonSet(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) error {
-	logger.Info("Start to sync ovnkube daemonset")
-	var err error
-	mcp := &mcfgv1.MachineConfigPool{}
-	err = r.Get(context.TODO(), types.NamespacedName{Name: cfg.Spec.PoolName}, mcp)
+	// the helper DaemonSets run the ovnkube image, which ships kubectl
+	image, err := r.getOvnkubeImage(ctx, cfg)
 	if err != nil {
-		if errors.IsNotFound(err) {
-			return fmt.Errorf("MachineConfigPool %s not found: %v", cfg.Spec.PoolName, err)
+		return err
+	}
+	// Sync DaemonSets. A failure rolls back the objects changed before it,
+	// a hold keeps them
+	tx := newApplyTransaction(r.Client)
+	var held error
+	err = r.applyOvnkubeNodeObjects(ctx, tx, cfg, objs, nodeSelector)
+	if serr, ok := err.(*shardRolloutError); ok {
+		held = serr
+	} else if err != nil {
+		if !rolloutHeld(err) {
+			tx.rollback(ctx)
 		}
+		return err
 	}
-
-	masterIPs, err := r.getTenantClusterMasterIPs(ctx)
-	if err != nil {
-		logger.Error(err, "failed to get the ovnkube master IPs")
-		return nil
+	if err := r.syncSwitchdevCheck(ctx, cfg, image, nodeSelector); err != nil {
+		return err
 	}
-
-	image := os.Getenv("OVNKUBE_IMAGE")
-	if image == "" {
-		image, err = r.getLocalOvnkubeImage()
-		if err != nil {
-			return err
-		}
+	if err := r.syncVfRepresentorDiscovery(ctx, cfg, image, nodeSelector); err != nil {
+		return err
 	}
+	return held
+}
 
-	data := render.MakeRenderData()
-	data.Data["OvnKubeImage"] = image
-	data.Data["Namespace"] = cfg.Namespace
-	data.Data["TenantKubeconfig"] = cfg.Spec.KubeConfigFile
-	data.Data["OVN_NB_DB_LIST"] = dbList(masterIPs, OVN_NB_PORT)
-	data.Data["OVN_SB_DB_LIST"] = dbList(masterIPs, OVN_SB_PORT)
-
-	objs, err := render.RenderDir(utils.OvnkubeNodeManifestPath, &data)
-	if err != nil {
-		logger.Error(err, "Fail to render ovnkube-node daemon manifests")
-		return err
+// rolloutHeld reports whether err holds the rollout of the DaemonSets until
+// a later reconcile, rather than failing it.
+func rolloutHeld(err error) bool {
+	switch err.(type) {
+	case *pendingChangesError, *prepullError, *hookError:
+		return true
 	}
-	// Sync DaemonSets
+	return false
+}
+
+// applyOvnkubeNodeObjects applies objs in tx. A shard waiting for the
+// previous ones doesn't hold the other objects: its hold is returned once
+// they are applied.
+func (r *OVNKubeConfigReconciler) applyOvnkubeNodeObjects(ctx context.Context, tx *applyTransaction, cfg *dpuv1alpha1.OVNKubeConfig, objs []*unstructured.Unstructured, nodeSelector *metav1.LabelSelector) error {
+	var held error
 	for _, obj := range objs {
 		switch obj.GetKind() {
 		case "DaemonSet":
-			scheme := scheme.Scheme
-			ds := &appsv1.DaemonSet{}
-			err = scheme.Convert(obj, ds, nil)
+			ds, err := ovnkubeNodeDaemonSet(cfg, obj, nodeSelector)
 			if err != nil {
-				logger.Error(err, "Fail to convert to DaemonSet")
 				return err
 			}
-			for k, v := range mcp.Spec.NodeSelector.MatchLabels {
-				ds.Spec.Template.Spec.NodeSelector[k] = v
+			if err = r.checkImageArchitectures(ctx, ds); err != nil {
+				return err
+			}
+			if err = r.prepullImages(ctx, cfg, ds); err != nil {
+				return err
 			}
-			err = scheme.Convert(ds, obj, nil)
-			if err != nil {
-				logger.Error(err, "Fail to convert to Unstructured")
+			if err = r.checkDaemonSetRollout(ctx, cfg, ds); err != nil {
 				return err
 			}
-			if err := ctrl.SetControllerReference(cfg, obj, r.Scheme); err != nil {
+			err = r.applyOvnkubeNodeDaemonSets(ctx, tx, cfg, ds, nodeSelector)
+			if serr, ok := err.(*shardRolloutError); ok {
+				held = serr
+			} else if err != nil {
 				return err
 			}
+			continue
 		default:
+			// cluster-scoped objects cannot be owned by a namespaced CR
+			if obj.GetNamespace() == "" {
+				if err := r.ownClusterObject(ctx, cfg, obj); err != nil {
+					return err
+				}
+				break
+			}
 			if err := ctrl.SetControllerReference(cfg, obj, r.Scheme); err != nil {
 				return err
 			}
 		}
-		if err := apply.ApplyObject(context.TODO(), r.Client, obj); err != nil {
-			return fmt.Errorf("failed to apply object %v with err: %v", obj, err)
+		_, span := tracing.Start(ctx, "apply", "kind", obj.GetKind(), "name", obj.GetName())
+		err := tx.apply(ctx, obj)
+		span.RecordError(err)
+		span.End()
+		if err != nil {
+			return conflictError(fmt.Errorf("failed to apply object %v with err: %w", obj, err))
+		}
+		if obj.GetKind() == "ServiceAccount" {
+			if err := r.syncServiceAccountPullSecrets(ctx, cfg, obj.GetNamespace(), obj.GetName()); err != nil {
+				return err
+			}
 		}
 	}
-	return nil
+	return held
 }
 
-func (r *OVNKubeConfigReconciler) getLocalOvnkubeImage() (string, error) {
-	ds := &appsv1.DaemonSet{}
-	name := types.NamespacedName{Namespace: utils.LocalOvnkbueNamespace, Name: utils.LocalOvnkbueNodeDsName}
-	err := r.Get(context.TODO(), name, ds)
+// renderOvnkubeNode renders the ovnkube-node objects. No objects are
+// returned while the ovnkube-master pods of the tenant cluster are not found.
+func (r *OVNKubeConfigReconciler) renderOvnkubeNode(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, image string) ([]*unstructured.Unstructured, error) {
+	discoveryCtx, span := tracing.Start(ctx, "tenant discovery")
+	nbDbList, sbDbList, err := r.getOvnDbLists(discoveryCtx, cfg)
+	span.RecordError(err)
+	span.End()
 	if err != nil {
-		return "", err
+		if cfg.Spec.Ovn.ExternalDbEndpoints != nil {
+			return nil, dpuerrors.TenantUnreachable(err)
+		}
+		logger.Error(err, "failed to get the ovnkube master IPs")
+		return nil, nil
 	}
-	return ds.Spec.Template.Spec.Containers[0].Image, nil
-}
+	cfg.Status.NbEndpoints = strings.Split(nbDbList, ",")
+	cfg.Status.SbEndpoints = strings.Split(sbDbList, ",")
 
-func (r *OVNKubeConfigReconciler) syncMachineConfigObjs(cs dpuv1alpha1.OVNKubeConfigSpec) error {
-	var err error
-	foundMc := &mcfgv1.MachineConfig{}
-	foundMcp := &mcfgv1.MachineConfigPool{}
-	mcp := &mcfgv1.MachineConfigPool{}
-	mcp.Name = cs.PoolName
-	mcSelector, err := metav1.ParseToLabelSelector(fmt.Sprintf("%s in (worker,%s)", mcfgv1.MachineConfigRoleLabelKey, dpuMcRole))
+	priorityClassName, err := r.getPriorityClassName(ctx, cfg)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	mcp.Spec = mcfgv1.MachineConfigPoolSpec{
-		MachineConfigSelector: mcSelector,
-		NodeSelector:          cs.NodeSelector,
+
+	tenantFeatures, err := r.tenantOvnkubeFeatures(ctx, cfg)
+	if err != nil {
+		return nil, err
 	}
-	if cs.PoolName == "master" || cs.PoolName == "worker" {
-		return fmt.Errorf("%s pools is not allowed", cs.PoolName)
+	featureFlags, err := ovnFeatureFlags(cfg.Spec.Ovn.Features, tenantFeatures)
+	if err != nil {
+		return nil, err
+	}
+	ipsec := tenantFeatures["enable-ipsec"] == "true"
+	if ipsec {
+		if err := r.checkSignerCaSynced(ctx, cfg); err != nil {
+			return nil, err
+		}
 	}
 
-	err = r.Get(context.TODO(), types.NamespacedName{Name: cs.PoolName}, foundMcp)
+	ovnControllerImage, err := getOvnControllerImage(ctx, cfg, image)
 	if err != nil {
-		if errors.IsNotFound(err) {
+		return nil, err
+	}
+	ovsDaemonsImage, err := getOvsDaemonsImage(ctx, cfg, image)
+	if err != nil {
+		return nil, err
+	}
+	return renderOvnkubeNodeObjects(ctx, cfg, ovnkubeNodeInputs{
+		image:              image,
+		ovnControllerImage: ovnControllerImage,
+		ovsDaemonsImage:    ovsDaemonsImage,
+		priorityClassName:  priorityClassName,
+		nbDbList:           nbDbList,
+		sbDbList:           sbDbList,
+		featureFlags:       featureFlags,
+		ipsec:              ipsec,
+		// the SELinux policy and seccomp profile are installed by the
+		// MachineConfig
+		privileged:                 cfg.Spec.Privileged || !r.managesMachineConfig(cfg),
+		securityContextConstraints: r.Platform.Get().SecurityContextConstraints,
+	})
+}
 
-			err = r.Create(context.TODO(), mcp)
-			if err != nil {
-				return fmt.Errorf("couldn't create MachineConfigPool: %v", err)
+// ovnkubeNodeInputs are the inputs of the ovnkube-node objects discovered
+// in the infra and tenant clusters.
+type ovnkubeNodeInputs struct {
+	image              string
+	ovnControllerImage string
+	ovsDaemonsImage    string
+	priorityClassName  string
+	nbDbList           string
+	sbDbList           string
+	featureFlags       []string
+	ipsec              bool
+	privileged         bool
+	// securityContextConstraints is set when the infra cluster admits the
+	// pods with SCCs
+	securityContextConstraints bool
+}
+
+// renderOvnkubeNodeObjects renders the ovnkube-node objects of cfg from
+// the discovered inputs.
+func renderOvnkubeNodeObjects(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, in ovnkubeNodeInputs) ([]*unstructured.Unstructured, error) {
+	data := render.MakeRenderData()
+	data.Data["OvnKubeImage"] = in.image
+	data.Data["OvnControllerImage"] = in.ovnControllerImage
+	data.Data["OvsDaemonsImage"] = in.ovsDaemonsImage
+	data.Data["Namespace"] = cfg.Namespace
+	data.Data["PriorityClassName"] = in.priorityClassName
+	data.Data["ImagePullSecrets"] = imagePullSecretNames(cfg)
+	data.Data["Privileged"] = in.privileged
+	data.Data["SecurityContextConstraints"] = in.securityContextConstraints
+	data.Data["TenantKubeconfig"], data.Data["TenantKubeconfigKey"] = tenantKubeconfigMount(cfg)
+	data.Data["OVN_NB_DB_LIST"] = in.nbDbList
+	data.Data["OVN_SB_DB_LIST"] = in.sbDbList
+	data.Data["ConfigName"] = cfg.Name
+	data.Data["PoolName"] = cfgPoolName(cfg)
+	data.Data["OvnCASecret"] = ""
+	if cfg.Spec.Ovn.CASecretRef != nil {
+		data.Data["OvnCASecret"] = cfg.Spec.Ovn.CASecretRef.Name
+	}
+	data.Data["EncapInterface"] = cfg.Spec.Ovn.EncapInterface != nil
+	data.Data["EncapIPsConfigMap"] = utils.CmNameEncapIPs
+	data.Data["OvnFeatureFlags"] = in.featureFlags
+	data.Data["IPsec"] = in.ipsec
+	data.Data["SignerCAConfigMap"] = utils.CmNameSignerCa
+	data.Data["OvnLogLevelConfigMap"] = utils.CmNameOvnLogLevel
+	data.Data["OvnLogLevel"] = "info"
+	if cfg.Spec.Ovn.OvnLogLevel != "" {
+		data.Data["OvnLogLevel"] = cfg.Spec.Ovn.OvnLogLevel
+	}
+	data.Data["OvnKubeLogLevel"] = int32(4)
+	if cfg.Spec.OvnKubeNode != nil && cfg.Spec.OvnKubeNode.LogLevel != nil {
+		data.Data["OvnKubeLogLevel"] = *cfg.Spec.OvnKubeNode.LogLevel
+	}
+	data.Data["HostNetwork"] = true
+	if cfg.Spec.OvnKubeNode != nil && cfg.Spec.OvnKubeNode.HostNetwork != nil {
+		data.Data["HostNetwork"] = *cfg.Spec.OvnKubeNode.HostNetwork
+	}
+	data.Funcs["scopedName"] = func(name string) string {
+		return scopedName(cfg, name)
+	}
+
+	addExtraRenderData(data.Data, cfg)
+	_, span := tracing.Start(ctx, "render", "manifests", utils.OvnkubeNodeManifestPath)
+	objs, err := renderDir(cfg, utils.OvnkubeNodeManifestPath, &data)
+	span.RecordError(err)
+	span.End()
+	if err != nil {
+		logger.Error(err, "Fail to render ovnkube-node daemon manifests")
+		return nil, dpuerrors.RenderFailed(err)
+	}
+	return objs, nil
+}
+
+// ovnkubeNodeDaemonSet converts the rendered ovnkube-node DaemonSet, runs
+// the mutations of cfg on it: the pool node selector, the tolerations of the
+// managed taints, the env and the images of spec.ovnKubeNode, and stamps the
+// hash of its pod template.
+func ovnkubeNodeDaemonSet(cfg *dpuv1alpha1.OVNKubeConfig, obj *unstructured.Unstructured, nodeSelector *metav1.LabelSelector) (*appsv1.DaemonSet, error) {
+	mutations := []daemonSetMutation{mergeNodeSelector(nodeSelector), tolerateTaints(managedNodeTaints(cfg))}
+	if node := cfg.Spec.OvnKubeNode; node != nil {
+		for name, image := range node.ContainerImages {
+			if err := validateImageReference(image, "spec.ovnKubeNode.containerImages."+name); err != nil {
+				return nil, err
 			}
-			logger.Info("Created MachineConfigPool:", "name", cs.PoolName)
+		}
+		mutations = append(mutations, setEnv(node.Env), overrideImages(node.ContainerImages))
+	}
+	ds, err := mutateDaemonSet(obj, mutations...)
+	if err != nil {
+		return nil, dpuerrors.InvalidObject(err)
+	}
+	hash, err := podTemplateHash(&ds.Spec.Template)
+	if err != nil {
+		return nil, err
+	}
+	if ds.Annotations == nil {
+		ds.Annotations = map[string]string{}
+	}
+	ds.Annotations[utils.TemplateHashAnnotation] = hash
+	return ds, nil
+}
+
+// getPriorityClassName returns the priority class of the rendered pods,
+// making sure it exists since the pods would be rejected otherwise.
+func (r *OVNKubeConfigReconciler) getPriorityClassName(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) (string, error) {
+	name := cfg.Spec.PriorityClassName
+	if name == "" {
+		name = defaultPriorityClassName
+	}
+	pc := &schedulingv1.PriorityClass{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name}, pc); err != nil {
+		return "", fmt.Errorf("failed to get PriorityClass %s: %v", name, err)
+	}
+	if err := r.checkCriticalPriorityQuota(ctx, cfg.Namespace, name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+func (r *OVNKubeConfigReconciler) syncMachineConfigObjs(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) error {
+	var err error
+	cs := cfg.Spec
+	poolName := cfgPoolName(cfg)
+	foundMc := &mcfgv1.MachineConfig{}
+	foundMcp := &mcfgv1.MachineConfigPool{}
+	if cs.PoolRef != nil {
+		// the MachineConfigPool is managed by the DpuNodePool
+		if err = r.Get(ctx, types.NamespacedName{Name: poolName}, foundMcp); err != nil {
+			return fmt.Errorf("MachineConfigPool %s of the DpuNodePool is not found: %v", poolName, err)
+		}
+	} else if workerPoolAllowed(cfg) {
+		// the worker pool belongs to the cluster, it is used as it is
+		r.warnWorkerPool(cfg)
+		if err = r.Get(ctx, types.NamespacedName{Name: poolName}, foundMcp); err != nil {
+			return fmt.Errorf("MachineConfigPool %s is not found: %v", poolName, err)
 		}
 	} else {
-		if !(equality.Semantic.DeepEqual(foundMcp.Spec.MachineConfigSelector, mcSelector) && equality.Semantic.DeepEqual(foundMcp.Spec.NodeSelector, cs.NodeSelector)) {
-			logger.Info("MachineConfigPool already exists, updating")
-			foundMcp.Spec = mcp.Spec
-			err = r.Update(context.TODO(), foundMcp)
-			if err != nil {
-				return fmt.Errorf("couldn't update MachineConfigPool: %v", err)
-			}
-		} else {
-			logger.Info("No content change, skip updating MCP")
+		mcp, err := desiredMachineConfigPool(poolName, cs.NodeSelector)
+		if err != nil {
+			return err
+		}
+		mcp.Labels = map[string]string{utils.OwnerLabel: cfg.Namespace}
+		if foundMcp, err = syncMachineConfigPool(ctx, r.Client, mcp); err != nil {
+			return err
 		}
 	}
 
-	mcName := "00-" + cs.PoolName + "-" + "bluefield-switchdev"
-
-	data := mcrender.MakeRenderData()
-	pfRepName := os.Getenv("PF_REP_NAME")
-	data.Data["PfRepName"] = pfRepName
-	mc, err := mcrender.GenerateMachineConfig("bindata/machine-config", mcName, dpuMcRole, true, &data)
+	mcName := switchdevMachineConfigName(cfg)
+	mc, err := r.renderSwitchdevMachineConfig(ctx, cfg)
 	if err != nil {
 		return err
 	}
@@ -354,15 +554,27 @@ func (r *OVNKubeConfigReconciler) syncMachineConfigObjs(cs dpuv1alpha1.OVNKubeCo
 	err = r.Get(context.TODO(), types.NamespacedName{Name: mcName}, foundMc)
 	if err != nil {
 		if errors.IsNotFound(err) {
-			err = r.Create(context.TODO(), mc)
-			if err != nil {
+			err = retryCreate(ctx, r.Client, mc)
+			if errors.IsAlreadyExists(err) {
+				// created by another OVNKubeConfig of the pool meanwhile,
+				// its owner is checked on the next pass
+				return dpuerrors.ApplyConflict(fmt.Errorf("MachineConfig %s was created concurrently", mcName))
+			} else if err != nil {
 				return fmt.Errorf("couldn't create MachineConfig: %v", err)
 			}
-			logger.Info("Created MachineConfig CR in MachineConfigPool", mcName, cs.PoolName)
+			logger.Info("Created MachineConfig CR in MachineConfigPool", mcName, poolName)
 		} else {
 			return fmt.Errorf("failed to get MachineConfig: %v", err)
 		}
 	} else {
+		if err := checkOwner("MachineConfig", foundMc, mc); err != nil {
+			return err
+		}
+		// the MachineConfigs rendered before the owner label, under the
+		// default name, and the ones annotated for adoption are adopted
+		if _, ok := foundMc.Labels[utils.OwnerLabel]; !ok && !adoptable(foundMc) && mcName != defaultMcNamePrefix+switchdevMachineConfigSuffix(poolName) {
+			return dpuerrors.Conflict(fmt.Errorf("MachineConfig %s already exists and is not managed by the operator, change spec.machineConfig.namePrefix or annotate it with %s=true", mcName, utils.AdoptAnnotation))
+		}
 		var foundIgn, renderedIgn interface{}
 		// The Raw config JSON string may have the fields reordered.
 		// For example the "path" field may come before the "contents"
@@ -373,49 +585,336 @@ func (r *OVNKubeConfigReconciler) syncMachineConfigObjs(cs dpuv1alpha1.OVNKubeCo
 		json.Unmarshal(foundMc.Spec.Config.Raw, &foundIgn)
 		json.Unmarshal(mc.Spec.Config.Raw, &renderedIgn)
 		if !reflect.DeepEqual(foundIgn, renderedIgn) {
+			// Updating the MachineConfig reboots every node of the pool
+			if err = checkMaintenanceWindow(cfg.Spec.MaintenanceWindow, time.Now(), "MachineConfig "+mcName+" update"); err != nil {
+				return err
+			}
+			if err = r.runPreflightChecks(ctx, cfg, foundMcp); err != nil {
+				return err
+			}
+			if err = r.runRolloutHook(ctx, cfg, hookTargetMachineConfig, hookPhasePre, rolloutRevision(mc.Spec.Config.Raw)); err != nil {
+				return err
+			}
 			logger.Info("MachineConfig already exists, updating")
-			foundMc.Spec.Config.Raw = mc.Spec.Config.Raw
-			mc.SetResourceVersion(foundMc.GetResourceVersion())
-			err = r.Update(context.TODO(), mc)
+			err = retryUpdate(ctx, r.Client, foundMc, func() {
+				foundMc.Labels = mc.Labels
+				foundMc.Spec = mc.Spec
+			})
+			if err != nil {
+				return conflictError(fmt.Errorf("couldn't update MachineConfig: %w", err))
+			}
+		} else if foundMc.Labels[utils.OwnerLabel] != cfg.Namespace {
+			// adopt the MachineConfig created before the owner label
+			err = retryUpdate(ctx, r.Client, foundMc, func() {
+				foundMc.Labels = mc.Labels
+			})
 			if err != nil {
-				return fmt.Errorf("couldn't update MachineConfig: %v", err)
+				return conflictError(fmt.Errorf("couldn't label MachineConfig: %w", err))
 			}
 		} else {
 			logger.Info("No content change, skip updating MachineConfig")
 		}
 	}
+	if err := r.deleteStaleMachineConfigs(ctx, cfg); err != nil {
+		return err
+	}
+	if c := mcfgv1.GetMachineConfigPoolCondition(foundMcp.Status, mcfgv1.MachineConfigPoolDegraded); c != nil && c.Status == corev1.ConditionTrue {
+		return dpuerrors.McDegraded(fmt.Errorf("MachineConfigPool %s is degraded: %s", poolName, c.Message))
+	}
 	return nil
 }
 
-func (r *OVNKubeConfigReconciler) getTenantClusterMasterIPs(ctx context.Context) ([]string, error) {
-	c, err := client.New(utils.TenantRestConfig, client.Options{})
+// desiredMachineConfigPool returns the MachineConfigPool of the DPU nodes
+// selected by nodeSelector, which applies the worker and dpu-worker
+// MachineConfigs.
+func desiredMachineConfigPool(name string, nodeSelector *metav1.LabelSelector) (*mcfgv1.MachineConfigPool, error) {
+	if name == "master" {
+		return nil, fmt.Errorf("%s pools is not allowed", name)
+	}
+	if name == workerPool {
+		return nil, fmt.Errorf("%s pools is not allowed without allowWorkerPool", name)
+	}
+	mcSelector, err := metav1.ParseToLabelSelector(fmt.Sprintf("%s in (worker,%s)", mcfgv1.MachineConfigRoleLabelKey, dpuMcRole))
+	if err != nil {
+		return nil, err
+	}
+	mcp := &mcfgv1.MachineConfigPool{}
+	mcp.Name = name
+	mcp.Spec = mcfgv1.MachineConfigPoolSpec{
+		MachineConfigSelector: mcSelector,
+		NodeSelector:          nodeSelector,
+	}
+	return mcp, nil
+}
+
+// syncMachineConfigPool creates the MachineConfigPool, or updates its
+// selectors, and returns the pool found in the cluster.
+func syncMachineConfigPool(ctx context.Context, c client.Client, mcp *mcfgv1.MachineConfigPool) (*mcfgv1.MachineConfigPool, error) {
+	foundMcp := &mcfgv1.MachineConfigPool{}
+	err := c.Get(ctx, types.NamespacedName{Name: mcp.Name}, foundMcp)
+	if errors.IsNotFound(err) {
+		err = retryCreate(ctx, c, mcp)
+		if errors.IsAlreadyExists(err) {
+			// created by another owner meanwhile, its owner is checked on
+			// the next pass
+			return nil, dpuerrors.ApplyConflict(fmt.Errorf("MachineConfigPool %s was created concurrently", mcp.Name))
+		} else if err != nil {
+			return nil, fmt.Errorf("couldn't create MachineConfigPool: %v", err)
+		}
+		logger.Info("Created MachineConfigPool:", "name", mcp.Name)
+		return mcp, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := checkOwner("MachineConfigPool", foundMcp, mcp); err != nil {
+		return nil, err
+	}
+	// a pool without owner is adopted when it was rendered by a previous
+	// version of the operator, or annotated for adoption
+	if _, ok := foundMcp.Labels[utils.OwnerLabel]; !ok && metav1.GetControllerOf(foundMcp) == nil && !adoptable(foundMcp) &&
+		!equality.Semantic.DeepEqual(foundMcp.Spec.MachineConfigSelector, mcp.Spec.MachineConfigSelector) {
+		return nil, dpuerrors.Conflict(fmt.Errorf("MachineConfigPool %s was not created by the operator, annotate it with %s=true to take it over", mcp.Name, utils.AdoptAnnotation))
+	}
+	adopt := foundMcp.Labels[utils.OwnerLabel] != mcp.Labels[utils.OwnerLabel] ||
+		(metav1.GetControllerOf(foundMcp) == nil && metav1.GetControllerOf(mcp) != nil)
+	if !adopt && equality.Semantic.DeepEqual(foundMcp.Spec.MachineConfigSelector, mcp.Spec.MachineConfigSelector) && equality.Semantic.DeepEqual(foundMcp.Spec.NodeSelector, mcp.Spec.NodeSelector) {
+		logger.Info("No content change, skip updating MCP")
+		return foundMcp, nil
+	}
+	logger.Info("MachineConfigPool already exists, updating")
+	err = retryUpdate(ctx, c, foundMcp, func() {
+		foundMcp.Spec.MachineConfigSelector = mcp.Spec.MachineConfigSelector
+		foundMcp.Spec.NodeSelector = mcp.Spec.NodeSelector
+		if owner, ok := mcp.Labels[utils.OwnerLabel]; ok {
+			if foundMcp.Labels == nil {
+				foundMcp.Labels = map[string]string{}
+			}
+			foundMcp.Labels[utils.OwnerLabel] = owner
+		}
+		if metav1.GetControllerOf(foundMcp) == nil && metav1.GetControllerOf(mcp) != nil {
+			foundMcp.OwnerReferences = append(foundMcp.OwnerReferences, *metav1.GetControllerOf(mcp))
+		}
+	})
+	if err != nil {
+		return nil, conflictError(fmt.Errorf("couldn't update MachineConfigPool: %w", err))
+	}
+	return foundMcp, nil
+}
+
+// checkOwner refuses to adopt found, the live object of desired, when it is
+// owned by another OVNKubeConfig, as told by the owner label, or controlled
+// by another DpuNodePool. Objects without owner are adopted.
+func checkOwner(kind string, found, desired metav1.Object) error {
+	if owner := found.GetLabels()[utils.OwnerLabel]; owner != "" && owner != desired.GetLabels()[utils.OwnerLabel] {
+		return dpuerrors.Conflict(fmt.Errorf("%s %s is owned by the OVNKubeConfig of namespace %s", kind, found.GetName(), owner))
+	}
+	if ref := metav1.GetControllerOf(found); ref != nil {
+		if want := metav1.GetControllerOf(desired); want == nil || want.UID != ref.UID {
+			return dpuerrors.Conflict(fmt.Errorf("%s %s is managed by %s %s", kind, found.GetName(), ref.Kind, ref.Name))
+		}
+	}
+	return nil
+}
+
+// renderSwitchdevMachineConfig renders the MachineConfig configuring the
+// DPU hosts in switchdev mode.
+func (r *OVNKubeConfigReconciler) renderSwitchdevMachineConfig(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) (*mcfgv1.MachineConfig, error) {
+	if err := validateUplinks(cfg); err != nil {
+		return nil, err
+	}
+	ipsec, err := r.ovnIPsecEnabled(ctx, cfg)
+	if errors.IsNotFound(err) {
+		// the MachineConfig doesn't wait for the tenant sync: it keeps the
+		// IPsec setting it was last rendered with, and is rendered again
+		// once the ovnkube-config is synced
+		if ipsec, err = r.renderedIPsec(ctx, cfg); err != nil {
+			return nil, err
+		}
+		logger.Info("The ovnkube-config of the tenant cluster is not synced, keep the last IPsec setting", "namespace", cfg.Namespace, "ipsec", ipsec)
+	} else if err != nil {
+		return nil, err
+	}
+	return switchdevMachineConfig(cfg, ipsec)
+}
+
+// renderedIPsec reports whether the switchdev MachineConfig of cfg in the
+// cluster enables OVN IPsec, false when it is not created yet.
+func (r *OVNKubeConfigReconciler) renderedIPsec(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) (bool, error) {
+	mc := &mcfgv1.MachineConfig{}
+	err := r.Get(ctx, types.NamespacedName{Name: switchdevMachineConfigName(cfg)}, mc)
+	if errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	for _, ext := range mc.Spec.Extensions {
+		if ext == "ipsec" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// switchdevMachineConfig renders the switchdev MachineConfig of cfg, with
+// the OVN IPsec configuration when ipsec is set. The uplinks must be valid.
+func switchdevMachineConfig(cfg *dpuv1alpha1.OVNKubeConfig, ipsec bool) (*mcfgv1.MachineConfig, error) {
+	data := mcrender.MakeRenderData()
+	pfRepName := os.Getenv("PF_REP_NAME")
+	data.Data["PfRepName"] = pfRepName
+	if err := addUplinkRenderData(data.Data, cfg); err != nil {
+		return nil, err
+	}
+	data.Data["IPsec"] = ipsec
+	addExtraRenderData(data.Data, cfg)
+	name := switchdevMachineConfigName(cfg)
+	role := machineConfigRole(cfg)
+	mc, err := cachedMachineConfig(cfg, name, role, data.Data, func() (*mcfgv1.MachineConfig, error) {
+		var mc *mcfgv1.MachineConfig
+		err := guardRender(cfg, machineConfigTemplates, data.Data, func() error {
+			var err error
+			mc, err = mcrender.GenerateMachineConfig(machineConfigTemplates, name, role, true, &data)
+			return err
+		})
+		return mc, err
+	})
+	if err != nil {
+		return nil, dpuerrors.RenderFailed(err)
+	}
+	if ipsec {
+		// libreswan is shipped as an RHCOS extension
+		mc.Spec.Extensions = []string{"ipsec"}
+	}
+	if mc.Labels == nil {
+		mc.Labels = map[string]string{}
+	}
+	mc.Labels[utils.OwnerLabel] = cfg.Namespace
+	return mc, nil
+}
+
+// workerPoolAllowed reports whether the DPU nodes of cfg are the worker
+// pool, allowed by spec.allowWorkerPool.
+func workerPoolAllowed(cfg *dpuv1alpha1.OVNKubeConfig) bool {
+	return cfg.Spec.PoolRef == nil && cfg.Spec.PoolName == workerPool && cfg.Spec.AllowWorkerPool
+}
+
+// machineConfigRole returns the role of the switchdev MachineConfig: the
+// one selected by the worker pool when it is the pool of the DPU nodes,
+// dpu-worker otherwise.
+func machineConfigRole(cfg *dpuv1alpha1.OVNKubeConfig) string {
+	if workerPoolAllowed(cfg) {
+		return workerPool
+	}
+	return dpuMcRole
+}
+
+// warnWorkerPool warns on every pass that the switchdev configuration is
+// applied to every worker of the cluster, a node without DPU included.
+func (r *OVNKubeConfigReconciler) warnWorkerPool(cfg *dpuv1alpha1.OVNKubeConfig) {
+	const msg = "allowWorkerPool is set: the switchdev MachineConfig is applied to every worker of the cluster, " +
+		"a worker without DPU loses its network. Use a dedicated pool unless every worker has a DPU"
+	logger.Info("WARNING: "+msg, "namespace", cfg.Namespace, "name", cfg.Name)
+	if r.Recorder != nil {
+		r.Recorder.Event(cfg, corev1.EventTypeWarning, eventReasonWorkerPoolAllowed, msg)
+	}
+}
+
+// conflictError classifies err as an ApplyConflict when the object was
+// modified concurrently.
+func conflictError(err error) error {
+	if errors.IsConflict(err) {
+		return dpuerrors.ApplyConflict(err)
+	}
+	return err
+}
+
+// switchdevMachineConfigName returns the name of the MachineConfig rendered
+// for the pool.
+func switchdevMachineConfigName(cfg *dpuv1alpha1.OVNKubeConfig) string {
+	prefix := defaultMcNamePrefix
+	if cfg.Spec.MachineConfig != nil && cfg.Spec.MachineConfig.NamePrefix != "" {
+		prefix = cfg.Spec.MachineConfig.NamePrefix
+	}
+	return prefix + switchdevMachineConfigSuffix(cfgPoolName(cfg))
+}
+
+func switchdevMachineConfigSuffix(poolName string) string {
+	return "-" + poolName + "-" + "bluefield-switchdev"
+}
+
+// deleteStaleMachineConfigs deletes the MachineConfigs rendered for the pool
+// under another name prefix, once the current one is applied.
+func (r *OVNKubeConfigReconciler) deleteStaleMachineConfigs(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) error {
+	mcs := &mcfgv1.MachineConfigList{}
+	if err := r.List(ctx, mcs, client.MatchingLabels{utils.OwnerLabel: cfg.Namespace}); err != nil {
+		return err
+	}
+	current := switchdevMachineConfigName(cfg)
+	for i := range mcs.Items {
+		mc := &mcs.Items[i]
+		if mc.Name == current || !strings.HasSuffix(mc.Name, switchdevMachineConfigSuffix(cfgPoolName(cfg))) {
+			continue
+		}
+		logger.Info("Delete MachineConfig of the previous name prefix", "name", mc.Name)
+		if err := r.Delete(ctx, mc); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *OVNKubeConfigReconciler) getTenantClusterMasterIPs(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) ([]string, error) {
+	tenantNamespace, err := r.tenantOvnNamespace(ctx, cfg)
 	if err != nil {
-		logger.Error(err, "Fail to create client for the tenant cluster")
 		return []string{}, err
 	}
-	ovnkubeMasterPods := corev1.PodList{}
-	labelSelector := labels.SelectorFromSet(map[string]string{"app": "ovnkube-master"})
-	listOps := &client.ListOptions{LabelSelector: labelSelector}
-	err = c.List(ctx, &ovnkubeMasterPods, listOps)
+	ovnkubeMasterPods, err := r.listTenantOvnkubeMasterPods(ctx, cfg, tenantNamespace)
 	if err != nil {
-		logger.Error(err, "Fail to get the ovnkube-master pods of the tenant cluster")
 		return []string{}, err
 	}
 	masterIPs := []string{}
 	for _, pod := range ovnkubeMasterPods.Items {
-		masterIPs = append(masterIPs, pod.Status.PodIP)
+		if pod.Status.PodIP != "" {
+			masterIPs = append(masterIPs, pod.Status.PodIP)
+		}
+	}
+	// rendering empty DB lists would disconnect every ovn-controller
+	if len(masterIPs) == 0 {
+		return masterIPs, fmt.Errorf("no ovnkube-master pod with an IP in namespace %s of the tenant cluster", tenantNamespace)
 	}
 	return masterIPs, nil
 }
 
-func (r *OVNKubeConfigReconciler) isTenantObjsSynced(ctx context.Context, namespace string) error {
-	cm := corev1.ConfigMap{}
-	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: utils.CmNameOvnCa}, &cm); err != nil {
-		return err
+// listTenantOvnkubeMasterPods lists the ovnkube-master pods in namespace of
+// the tenant cluster of cfg.
+func (r *OVNKubeConfigReconciler) listTenantOvnkubeMasterPods(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, namespace string) (*corev1.PodList, error) {
+	c := r.connectedTenantClient(cfg.Namespace)
+	if c == nil {
+		return nil, fmt.Errorf("the tenant cluster is not connected yet")
+	}
+	ovnkubeMasterPods := &corev1.PodList{}
+	labelSelector := labels.SelectorFromSet(map[string]string{"app": "ovnkube-master"})
+	// the terminated pods have no DB to serve
+	fieldSelector := fields.AndSelectors(fields.OneTermNotEqualSelector("status.phase", string(corev1.PodSucceeded)),
+		fields.OneTermNotEqualSelector("status.phase", string(corev1.PodFailed)))
+	listOps := &client.ListOptions{LabelSelector: labelSelector, FieldSelector: fieldSelector, Namespace: namespace}
+	if err := utils.ListAll(ctx, c, ovnkubeMasterPods, listOps); err != nil {
+		logger.Error(err, "Fail to get the ovnkube-master pods of the tenant cluster")
+		return nil, err
 	}
+	return ovnkubeMasterPods, nil
+}
 
-	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: utils.CmNameOvnkubeConfig}, &cm); err != nil {
-		return err
+func (r *OVNKubeConfigReconciler) isTenantObjsSynced(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) error {
+	namespace := cfg.Namespace
+	// the syncer doesn't overwrite a ConfigMap managed by another controller
+	for _, name := range []string{utils.CmNameOvnCa, utils.CmNameOvnkubeConfig} {
+		cm := corev1.ConfigMap{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &cm); err != nil {
+			return err
+		}
+		if err := checkConfigMapWriter(&cm, cfg.UID); err != nil {
+			return err
+		}
 	}
 
 	s := corev1.Secret{}
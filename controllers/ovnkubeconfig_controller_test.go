@@ -0,0 +1,48 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+)
+
+func TestWorkerPoolAllowed(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		spec     dpuv1alpha1.OVNKubeConfigSpec
+		want     bool
+		wantRole string
+	}{
+		{name: "dedicated pool", spec: dpuv1alpha1.OVNKubeConfigSpec{PoolName: "dpu", AllowWorkerPool: true}, wantRole: dpuMcRole},
+		{name: "worker pool not allowed", spec: dpuv1alpha1.OVNKubeConfigSpec{PoolName: workerPool}, wantRole: dpuMcRole},
+		{name: "worker pool allowed", spec: dpuv1alpha1.OVNKubeConfigSpec{PoolName: workerPool, AllowWorkerPool: true}, want: true, wantRole: workerPool},
+		{name: "DpuNodePool", wantRole: dpuMcRole, spec: dpuv1alpha1.OVNKubeConfigSpec{
+			PoolName: workerPool, AllowWorkerPool: true, PoolRef: &dpuv1alpha1.PoolReference{Name: "dpus"}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &dpuv1alpha1.OVNKubeConfig{Spec: tc.spec}
+			if got := workerPoolAllowed(cfg); got != tc.want {
+				t.Errorf("workerPoolAllowed() = %v, want %v", got, tc.want)
+			}
+			if got := machineConfigRole(cfg); got != tc.wantRole {
+				t.Errorf("machineConfigRole() = %q, want %q", got, tc.wantRole)
+			}
+		})
+	}
+}
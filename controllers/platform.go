@@ -0,0 +1,179 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;patch
+
+// podSecurityLabels admit the host network and host path pods of the data
+// plane under the Pod Security Admission.
+var podSecurityLabels = map[string]string{
+	"pod-security.kubernetes.io/enforce": "privileged",
+	"pod-security.kubernetes.io/audit":   "privileged",
+	"pod-security.kubernetes.io/warn":    "privileged",
+}
+
+// whenMachineConfigServed calls watch once the machine-config-operator runs
+// in the infra cluster: at once, or when a later detection of the platform
+// finds it, so its objects are watched without restarting the operator. The
+// watches are kept if it is removed.
+func whenMachineConfigServed(platform *utils.DynamicPlatform, watch func() error) error {
+	if platform.Get().MachineConfig {
+		return watch()
+	}
+	var once sync.Once
+	platform.OnChange(func(p utils.Platform) {
+		if !p.MachineConfig {
+			return
+		}
+		once.Do(func() {
+			if err := watch(); err != nil {
+				logger.Error(err, "failed to watch the objects of the machine-config-operator")
+			}
+		})
+	})
+	return nil
+}
+
+// platformChanges returns a source emitting an event of obj when the
+// platform of the infra cluster changes, for the controller to reconcile
+// every object. Changes are coalesced until the controller reads them.
+func platformChanges(platform *utils.DynamicPlatform, obj client.Object) source.Source {
+	events := make(chan event.GenericEvent, 1)
+	platform.OnChange(func(utils.Platform) {
+		select {
+		case events <- event.GenericEvent{Object: obj}:
+		default:
+		}
+	})
+	return &source.Channel{Source: events}
+}
+
+// allOVNKubeConfigs maps an event to every OVNKubeConfig.
+func (r *OVNKubeConfigReconciler) allOVNKubeConfigs(client.Object) []reconcile.Request {
+	cfgList := &dpuv1alpha1.OVNKubeConfigList{}
+	if err := r.List(context.TODO(), cfgList); err != nil {
+		logger.Error(err, "failed to list OVNKubeConfigs")
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, cfg := range cfgList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name}})
+	}
+	return requests
+}
+
+// cfgPoolName returns the name of the pool of the DPU nodes, the one of the
+// referenced DpuNodePool if set.
+func cfgPoolName(cfg *dpuv1alpha1.OVNKubeConfig) string {
+	if cfg.Spec.PoolRef != nil {
+		return cfg.Spec.PoolRef.Name
+	}
+	return cfg.Spec.PoolName
+}
+
+// managesMachineConfig reports whether the operator manages the
+// MachineConfigPool and the switchdev MachineConfig of cfg: the
+// machine-config-operator runs and spec.manageMachineConfig isn't false.
+func (r *OVNKubeConfigReconciler) managesMachineConfig(cfg *dpuv1alpha1.OVNKubeConfig) bool {
+	return r.Platform.Get().MachineConfig && (cfg.Spec.ManageMachineConfig == nil || *cfg.Spec.ManageMachineConfig)
+}
+
+// managesWorkloads reports whether the operator deploys the ovnkube
+// workload of cfg: spec.manageWorkloads isn't false.
+func managesWorkloads(cfg *dpuv1alpha1.OVNKubeConfig) bool {
+	return cfg.Spec.ManageWorkloads == nil || *cfg.Spec.ManageWorkloads
+}
+
+// poolNodeSelector returns the selector of the DPU nodes: the one of the
+// MachineConfigPool, or when the operator doesn't manage it, the one of the
+// referenced DpuNodePool or the nodeSelector of the CR. A MachineConfigPool
+// managed by the user is read when neither is set.
+func (r *OVNKubeConfigReconciler) poolNodeSelector(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) (*metav1.LabelSelector, error) {
+	poolName := cfgPoolName(cfg)
+	if !r.managesMachineConfig(cfg) {
+		if cfg.Spec.PoolRef != nil {
+			pool := &dpuv1alpha1.DpuNodePool{}
+			if err := r.Get(ctx, types.NamespacedName{Name: poolName}, pool); err != nil {
+				return nil, fmt.Errorf("DpuNodePool %s not found: %v", poolName, err)
+			}
+			return nodePoolSelector(pool), nil
+		}
+		if cfg.Spec.NodeSelector != nil {
+			return cfg.Spec.NodeSelector, nil
+		}
+		if !r.Platform.Get().MachineConfig {
+			return nil, fmt.Errorf("nodeSelector must be set without the machine-config-operator")
+		}
+	}
+	mcp := &mcfgv1.MachineConfigPool{}
+	err := r.Get(ctx, types.NamespacedName{Name: poolName}, mcp)
+	if errors.IsNotFound(err) {
+		return nil, fmt.Errorf("MachineConfigPool %s not found: %v", poolName, err)
+	} else if err != nil {
+		return nil, err
+	}
+	if mcp.Spec.NodeSelector == nil {
+		return nil, fmt.Errorf("MachineConfigPool %s has no nodeSelector", poolName)
+	}
+	return mcp.Spec.NodeSelector, nil
+}
+
+// ensurePodSecurityLabels labels the namespace so the Pod Security
+// Admission admits the rendered pods. On OpenShift the SCCs take care of it.
+func (r *OVNKubeConfigReconciler) ensurePodSecurityLabels(ctx context.Context, namespace string) error {
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return err
+	}
+	patch := client.MergeFrom(ns.DeepCopy())
+	changed := false
+	for k, v := range podSecurityLabels {
+		if ns.Labels[k] != v {
+			if ns.Labels == nil {
+				ns.Labels = map[string]string{}
+			}
+			ns.Labels[k] = v
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	if err := r.Patch(ctx, ns, patch); err != nil {
+		return fmt.Errorf("failed to label namespace %s for the Pod Security Admission: %v", namespace, err)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+// inventoryResource returns whether the allocatable resource is reported
+// in the inventory: cpu, memory and the hugepages of every size.
+func inventoryResource(name corev1.ResourceName) bool {
+	return name == corev1.ResourceCPU || name == corev1.ResourceMemory ||
+		strings.HasPrefix(string(name), corev1.ResourceHugePagesPrefix)
+}
+
+// poolInventory aggregates the inventory of the nodes of a pool, nil when
+// it has none. The OS and resources come from the node status, the NIC
+// firmware versions from the annotation of the VF representor discovery.
+func poolInventory(nodes []*corev1.Node) *dpuv1alpha1.DpuPoolInventory {
+	if len(nodes) == 0 {
+		return nil
+	}
+	inventory := &dpuv1alpha1.DpuPoolInventory{Allocatable: corev1.ResourceList{}}
+	kernels := map[string]int32{}
+	images := map[string]int32{}
+	firmwares := map[string]int32{}
+	for _, node := range nodes {
+		n := dpuv1alpha1.DpuNodeInventory{
+			Name:          node.Name,
+			Architecture:  node.Status.NodeInfo.Architecture,
+			KernelVersion: node.Status.NodeInfo.KernelVersion,
+			OSImage:       node.Status.NodeInfo.OSImage,
+		}
+		for name, quantity := range node.Status.Allocatable {
+			if !inventoryResource(name) || quantity.IsZero() {
+				continue
+			}
+			if n.Allocatable == nil {
+				n.Allocatable = corev1.ResourceList{}
+			}
+			n.Allocatable[name] = quantity.DeepCopy()
+			total := inventory.Allocatable[name]
+			total.Add(quantity)
+			inventory.Allocatable[name] = total
+		}
+		if v, ok := node.Annotations[utils.NicFirmwareAnnotation]; ok {
+			if err := json.Unmarshal([]byte(v), &n.NicFirmware); err != nil {
+				logger.Error(err, "ignoring invalid NIC firmware annotation", "node", node.Name)
+				n.NicFirmware = nil
+			}
+		}
+		if n.KernelVersion != "" {
+			kernels[n.KernelVersion]++
+		}
+		if n.OSImage != "" {
+			images[n.OSImage]++
+		}
+		for _, version := range n.NicFirmware {
+			firmwares[version]++
+		}
+		inventory.Nodes = append(inventory.Nodes, n)
+	}
+	sort.Slice(inventory.Nodes, func(i, j int) bool { return inventory.Nodes[i].Name < inventory.Nodes[j].Name })
+	inventory.KernelVersions = versionCounts(kernels)
+	inventory.OSImages = versionCounts(images)
+	inventory.NicFirmwareVersions = versionCounts(firmwares)
+	return inventory
+}
+
+// versionCounts returns the counts sorted by version.
+func versionCounts(counts map[string]int32) []dpuv1alpha1.VersionCount {
+	if len(counts) == 0 {
+		return nil
+	}
+	versions := make([]dpuv1alpha1.VersionCount, 0, len(counts))
+	for version, count := range counts {
+		versions = append(versions, dpuv1alpha1.VersionCount{Version: version, Count: count})
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+	return versions
+}
+
+// nodeInventoryChanged filters the node events which change the inventory
+// of their pool: the OS, the allocatable resources or the NIC firmware.
+var nodeInventoryChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldNode, oldOk := e.ObjectOld.(*corev1.Node)
+		newNode, newOk := e.ObjectNew.(*corev1.Node)
+		if !oldOk || !newOk {
+			return false
+		}
+		return oldNode.Status.NodeInfo.KernelVersion != newNode.Status.NodeInfo.KernelVersion ||
+			oldNode.Status.NodeInfo.OSImage != newNode.Status.NodeInfo.OSImage ||
+			oldNode.Status.NodeInfo.Architecture != newNode.Status.NodeInfo.Architecture ||
+			!equality.Semantic.DeepEqual(oldNode.Status.Allocatable, newNode.Status.Allocatable) ||
+			oldNode.Annotations[utils.NicFirmwareAnnotation] != newNode.Annotations[utils.NicFirmwareAnnotation]
+	},
+	GenericFunc: func(e event.GenericEvent) bool {
+		return false
+	},
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+)
+
+// preflightError is returned when a disruptive MachineConfig update is held
+// because the cluster is not in a state where rebooting the DPUs is safe.
+type preflightError struct {
+	failures []string
+}
+
+func (e *preflightError) Error() string {
+	return "pre-flight checks failed: " + strings.Join(e.failures, "; ")
+}
+
+// runPreflightChecks verifies that pushing a new MachineConfig, which reboots
+// every node of the pool, won't compound an ongoing outage: the ovnkube-node
+// pods must be ready, the pool must not be degraded and the tenant cluster
+// must be reachable.
+func (r *OVNKubeConfigReconciler) runPreflightChecks(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, mcp *mcfgv1.MachineConfigPool) error {
+	failures := []string{}
+
+	dss, err := r.listOvnkubeNodeDaemonSets(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	var ready, desired int32
+	for _, ds := range dss {
+		ready += ds.Status.NumberReady
+		desired += ds.Status.DesiredNumberScheduled
+	}
+	if ready != desired {
+		failures = append(failures, fmt.Sprintf("%d/%d ovnkube-node pods are ready", ready, desired))
+	}
+
+	if mcp != nil && mcfgv1.IsMachineConfigPoolConditionTrue(mcp.Status.Conditions, mcfgv1.MachineConfigPoolDegraded) {
+		failures = append(failures, fmt.Sprintf("MachineConfigPool %s is degraded", mcp.Name))
+	}
+
+	if running := r.tenantSyncer(cfg.Namespace); running != nil {
+		if _, err := running.discovery.ServerVersion(); err != nil {
+			failures = append(failures, fmt.Sprintf("tenant cluster is not reachable: %v", err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return &preflightError{failures: failures}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+)
+
+//+kubebuilder:rbac:groups="",resources=resourcequotas,verbs=list
+
+// criticalPriorityClasses are the priority classes whose pods the API
+// server only admits out of kube-system when a ResourceQuota of the
+// namespace covers them.
+var criticalPriorityClasses = map[string]bool{
+	"system-node-critical":    true,
+	"system-cluster-critical": true,
+}
+
+// checkCriticalPriorityQuota checks that a ResourceQuota of the namespace
+// has a PriorityClass scope selecting the critical priority class name,
+// since the pods of the DaemonSets would be rejected otherwise, leaving
+// them with no pod and no error to show. The quotas are read from the API
+// server, they are not worth caching.
+func (r *OVNKubeConfigReconciler) checkCriticalPriorityQuota(ctx context.Context, namespace, name string) error {
+	if !criticalPriorityClasses[name] || namespace == metav1.NamespaceSystem {
+		return nil
+	}
+	quotas := &corev1.ResourceQuotaList{}
+	if err := r.APIReader.List(ctx, quotas, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list the ResourceQuotas of namespace %s: %w", namespace, err)
+	}
+	for i := range quotas.Items {
+		if quotaAdmitsPriorityClass(&quotas.Items[i], name) {
+			return nil
+		}
+	}
+	return dpuerrors.PriorityClassNotAdmitted(fmt.Errorf(
+		"no ResourceQuota of namespace %s has a PriorityClass scope selecting %s, the pods of this priority class are rejected; create one or set spec.priorityClassName",
+		namespace, name))
+}
+
+// quotaAdmitsPriorityClass reports whether the scope selector of the quota
+// selects the pods of the priority class name.
+func quotaAdmitsPriorityClass(quota *corev1.ResourceQuota, name string) bool {
+	if quota.Spec.ScopeSelector == nil {
+		return false
+	}
+	for _, expr := range quota.Spec.ScopeSelector.MatchExpressions {
+		if expr.ScopeName != corev1.ResourceQuotaScopePriorityClass {
+			continue
+		}
+		switch expr.Operator {
+		case corev1.ScopeSelectorOpExists:
+			return true
+		case corev1.ScopeSelectorOpIn:
+			for _, v := range expr.Values {
+				if v == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+// readinessTaintRequeueInterval is how often the nodes tainted by
+// spec.readinessTaint are checked again, the tenant hosts are not watched.
+const readinessTaintRequeueInterval = 30 * time.Second
+
+// readinessTaint keeps the general workloads off a DPU node until its data
+// plane is verified.
+var readinessTaint = corev1.Taint{Key: utils.ReadinessTaintKey, Effect: corev1.TaintEffectNoSchedule}
+
+// readinessTaintEnabled reports whether the DPU nodes of cfg are tainted
+// until their data plane is verified. Without the workloads, the data plane
+// never becomes ready.
+func readinessTaintEnabled(cfg *dpuv1alpha1.OVNKubeConfig) bool {
+	return cfg.Spec.ReadinessTaint && managesWorkloads(cfg)
+}
+
+// readyDataPlaneNodes returns the DPU nodes whose data plane is verified:
+// the data plane pod of the node is ready and the tenant host it serves, if
+// known, is Ready with its network available. The hosts are not checked
+// while the tenant cluster cannot be read, so an outage of its API server
+// doesn't taint every DPU node.
+func (r *OVNKubeConfigReconciler) readyDataPlaneNodes(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) (map[string]bool, error) {
+	dss, err := r.listOvnkubeNodeDaemonSets(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	ready := map[string]bool{}
+	for i := range dss {
+		pods, err := r.daemonSetPods(ctx, &dss[i])
+		if err != nil {
+			return nil, err
+		}
+		for j := range pods {
+			if podReady(&pods[j]) {
+				ready[podNodeName(&pods[j])] = true
+			}
+		}
+	}
+	kubeClient := r.connectedTenantKubeClient(cfg.Namespace)
+	if len(ready) == 0 || kubeClient == nil {
+		return ready, nil
+	}
+	hosts, err := r.dpuNodesByHost(ctx, cfg.Namespace)
+	if errors.IsNotFound(err) {
+		return ready, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for host, node := range hosts {
+		if !ready[node] {
+			continue
+		}
+		tenantNode, err := kubeClient.CoreV1().Nodes().Get(ctx, host, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			delete(ready, node)
+			continue
+		} else if err != nil {
+			logger.Error(err, "failed to check the tenant hosts of the DPU nodes")
+			return ready, nil
+		}
+		if !tenantHostNetworkReady(tenantNode) {
+			delete(ready, node)
+		}
+	}
+	return ready, nil
+}
+
+// tenantHostNetworkReady reports whether the tenant host is Ready and its
+// network is available.
+func tenantHostNetworkReady(node *corev1.Node) bool {
+	ready := false
+	for _, c := range node.Status.Conditions {
+		switch c.Type {
+		case corev1.NodeReady:
+			ready = c.Status == corev1.ConditionTrue
+		case corev1.NodeNetworkUnavailable:
+			if c.Status == corev1.ConditionTrue {
+				return false
+			}
+		}
+	}
+	return ready
+}
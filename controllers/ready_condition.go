@@ -0,0 +1,74 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/dpu-network-operator/api"
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+)
+
+// readyComponents are the conditions aggregated by the Ready condition.
+var readyComponents = []string{api.McpReady, api.TenantObjsSynced, api.OvnKubeReady}
+
+// readyCondition aggregates the readyComponents of the conditions of cfg.
+// Ready is False when one of them is False, with the reason of the first
+// one, Unknown while one of them is not reported yet, and True otherwise. An
+// ovnkube workload not managed by the operator doesn't hold Ready. Ready is
+// stamped with the oldest generation observed by its components, so it only
+// holds for a generation once all of them reconciled it. A CR failing to
+// delegate to its spec.targetNamespace is not Ready.
+func readyCondition(cfg *dpuv1alpha1.OVNKubeConfig, conditions []metav1.Condition) *metav1.Condition {
+	if c := meta.FindStatusCondition(conditions, api.Delegated); c != nil && c.Status == metav1.ConditionFalse {
+		return api.Conditions(cfg).NotReady().Reason(c.Reason).Msg(fmt.Sprintf("%s: %s", api.Delegated, c.Message)).Build()
+	}
+	generation := cfg.Generation
+	var notReady, unknown []string
+	reason := ""
+	for _, t := range readyComponents {
+		c := meta.FindStatusCondition(conditions, t)
+		if c == nil || c.Status == metav1.ConditionUnknown {
+			unknown = append(unknown, t)
+			continue
+		}
+		if c.ObservedGeneration < generation {
+			generation = c.ObservedGeneration
+		}
+		if c.Status == metav1.ConditionTrue || c.Reason == api.ReasonWorkloadsNotManaged {
+			continue
+		}
+		if reason == "" {
+			reason = c.Reason
+		}
+		notReady = append(notReady, fmt.Sprintf("%s: %s", t, c.Message))
+	}
+	b := api.Conditions(cfg).ObservedGeneration(generation)
+	switch {
+	case len(notReady) > 0:
+		b = b.NotReady().Reason(reason).Msg(strings.Join(notReady, "; "))
+	case len(unknown) > 0:
+		b = b.UnknownReady().Reason(api.ReasonProgressing).Msg(fmt.Sprintf("waiting for %s", strings.Join(unknown, ", ")))
+	default:
+		b = b.Ready().Reason(api.ReasonComponentsReady).Msg(fmt.Sprintf("%s are True", strings.Join(readyComponents, ", ")))
+	}
+	return b.Build()
+}
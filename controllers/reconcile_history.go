@@ -0,0 +1,159 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+)
+
+// reconcileHistoryLength is the number of passes kept in the reconcile
+// history of the status.
+const reconcileHistoryLength = 10
+
+var reconcileTriggers = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "dpu_network_operator_reconcile_triggers_total",
+	Help: "Events queuing an OVNKubeConfig reconcile, by namespace, controller and kind of the object. The namespace is empty for cluster-scoped objects.",
+}, []string{"namespace", "controller", "kind"})
+
+func init() {
+	metrics.Registry.MustRegister(reconcileTriggers)
+}
+
+// reconcileTrigger is an event which queued a reconcile.
+type reconcileTrigger struct {
+	object string
+	time   time.Time
+}
+
+// triggerTracker remembers the last event queuing each controller. There is
+// one OVNKubeConfig per namespace, so the events of namespaced objects are
+// kept per namespace, and the ones of cluster-scoped objects, e.g. nodes,
+// for every namespace.
+type triggerTracker struct {
+	mu         sync.Mutex
+	namespaced map[string]reconcileTrigger
+	cluster    map[string]reconcileTrigger
+	// taken is the time of the last trigger taken by a pass of a
+	// controller in a namespace
+	taken map[string]time.Time
+}
+
+var triggers = &triggerTracker{
+	namespaced: map[string]reconcileTrigger{},
+	cluster:    map[string]reconcileTrigger{},
+	taken:      map[string]time.Time{},
+}
+
+func (t *triggerTracker) record(controller, namespace, object string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	trigger := reconcileTrigger{object: object, time: time.Now()}
+	if namespace == "" {
+		t.cluster[controller] = trigger
+	} else {
+		t.namespaced[controller+"/"+namespace] = trigger
+	}
+}
+
+// take returns the last event queuing the pass of the controller in the
+// namespace which started at start, or an empty string when the pass was
+// requeued.
+func (t *triggerTracker) take(controller, namespace string, start time.Time) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := controller + "/" + namespace
+	var last reconcileTrigger
+	for _, trigger := range []reconcileTrigger{t.namespaced[key], t.cluster[controller]} {
+		if trigger.time.After(t.taken[key]) && !trigger.time.After(start) && trigger.time.After(last.time) {
+			last = trigger
+		}
+	}
+	if last.object == "" {
+		return ""
+	}
+	t.taken[key] = last.time
+	return last.object
+}
+
+// recordTrigger returns the predicate remembering the events queuing the
+// controller. It must be the last predicate of a watch, so the filtered out
+// events are ignored.
+func recordTrigger(scheme *runtime.Scheme, controller string) predicate.Predicate {
+	record := func(obj client.Object) bool {
+		kind := fmt.Sprintf("%T", obj)
+		if gvk, err := apiutil.GVKForObject(obj, scheme); err == nil {
+			kind = gvk.Kind
+		}
+		reconcileTriggers.WithLabelValues(obj.GetNamespace(), controller, kind).Inc()
+		object := kind + " " + obj.GetName()
+		if obj.GetNamespace() != "" {
+			object = kind + " " + obj.GetNamespace() + "/" + obj.GetName()
+		}
+		triggers.record(controller, obj.GetNamespace(), object)
+		return true
+	}
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return record(e.Object) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return record(e.ObjectNew) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return record(e.Object) },
+		GenericFunc: func(e event.GenericEvent) bool { return record(e.Object) },
+	}
+}
+
+// addReconcileEvent appends a pass of a controller to the reconcile history,
+// dropping the oldest passes.
+func addReconcileEvent(s *dpuv1alpha1.OVNKubeConfigStatus, e dpuv1alpha1.ReconcileEvent) {
+	s.ReconcileHistory = append(s.ReconcileHistory, e)
+	if n := len(s.ReconcileHistory); n > reconcileHistoryLength {
+		s.ReconcileHistory = s.ReconcileHistory[n-reconcileHistoryLength:]
+	}
+}
+
+// lastReconcileEvent returns the last pass of the controller in the history.
+func lastReconcileEvent(s *dpuv1alpha1.OVNKubeConfigStatus, controller string) *dpuv1alpha1.ReconcileEvent {
+	for i := len(s.ReconcileHistory) - 1; i >= 0; i-- {
+		if s.ReconcileHistory[i].Controller == controller {
+			return &s.ReconcileHistory[i]
+		}
+	}
+	return nil
+}
+
+func newReconcileEvent(controller, trigger, outcome string, now time.Time) dpuv1alpha1.ReconcileEvent {
+	if trigger == "" {
+		trigger = "requeue"
+	}
+	return dpuv1alpha1.ReconcileEvent{
+		Time:       metav1.NewTime(now),
+		Controller: controller,
+		Trigger:    trigger,
+		Outcome:    outcome,
+	}
+}
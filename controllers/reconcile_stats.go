@@ -0,0 +1,126 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+)
+
+// The names of the controllers reconciling the OVNKubeConfigs.
+const (
+	delegationControllerName    = "ovnkubeconfig-delegation"
+	machineConfigControllerName = "ovnkubeconfig-machineconfig"
+	tenantSyncControllerName    = "ovnkubeconfig-tenantsync"
+	workloadControllerName      = "ovnkubeconfig-workload"
+)
+
+var reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "dpu_network_operator_reconcile_duration_seconds",
+	Help:    "Duration of the OVNKubeConfig reconciles, by namespace, controller and outcome.",
+	Buckets: prometheus.ExponentialBuckets(0.01, 2, 12),
+}, []string{"namespace", "controller", "outcome"})
+
+func init() {
+	metrics.Registry.MustRegister(reconcileDuration)
+}
+
+// recordReconcile reports the duration and the error of a pass of the
+// controller in the status of cfg and in the reconcile duration metric.
+func recordReconcile(cfg *dpuv1alpha1.OVNKubeConfig, controller string, start time.Time, err error) {
+	now := time.Now()
+	outcome := "success"
+	status := dpuv1alpha1.ReconcileStatus{
+		Controller:            controller,
+		LastReconcileTime:     metav1.NewTime(now),
+		LastReconcileDuration: metav1.Duration{Duration: now.Sub(start).Round(time.Millisecond)},
+	}
+	if err != nil {
+		outcome = "error"
+		status.LastError = err.Error()
+	}
+	reconcileDuration.WithLabelValues(cfg.Namespace, controller, outcome).Observe(now.Sub(start).Seconds())
+	setReconcileStatus(&cfg.Status, status)
+	addReconcileEvent(&cfg.Status, newReconcileEvent(controller, triggers.take(controller, cfg.Namespace, start), outcome, now))
+}
+
+func setReconcileStatus(s *dpuv1alpha1.OVNKubeConfigStatus, status dpuv1alpha1.ReconcileStatus) {
+	for i := range s.Reconciles {
+		if s.Reconciles[i].Controller == status.Controller {
+			s.Reconciles[i] = status
+			return
+		}
+	}
+	s.Reconciles = append(s.Reconciles, status)
+}
+
+// copyReconcileStatus returns the copyFields of updateStatus copying the
+// last pass of the controller and adding it to the history, along with the
+// fields copied by copyFields.
+func copyReconcileStatus(controller string, copyFields func(dst, src *dpuv1alpha1.OVNKubeConfigStatus)) func(dst, src *dpuv1alpha1.OVNKubeConfigStatus) {
+	return func(dst, src *dpuv1alpha1.OVNKubeConfigStatus) {
+		if copyFields != nil {
+			copyFields(dst, src)
+		}
+		for _, status := range src.Reconciles {
+			if status.Controller == controller {
+				setReconcileStatus(dst, status)
+			}
+		}
+		if e := lastReconcileEvent(src, controller); e != nil {
+			addReconcileEvent(dst, *e)
+		}
+	}
+}
+
+// reconcileStatusChanged filters out the OVNKubeConfig updates which only
+// report the last passes of the controllers or their history, which would
+// otherwise trigger a new pass each.
+var reconcileStatusChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldCfg, ok := e.ObjectOld.(*dpuv1alpha1.OVNKubeConfig)
+		if !ok {
+			return true
+		}
+		newCfg, ok := e.ObjectNew.(*dpuv1alpha1.OVNKubeConfig)
+		if !ok {
+			return true
+		}
+		if oldCfg.Generation != newCfg.Generation ||
+			!equality.Semantic.DeepEqual(oldCfg.Labels, newCfg.Labels) ||
+			!equality.Semantic.DeepEqual(oldCfg.Annotations, newCfg.Annotations) ||
+			!equality.Semantic.DeepEqual(oldCfg.Finalizers, newCfg.Finalizers) ||
+			!oldCfg.DeletionTimestamp.Equal(newCfg.DeletionTimestamp) {
+			return true
+		}
+		oldStatus := oldCfg.Status.DeepCopy()
+		newStatus := newCfg.Status.DeepCopy()
+		oldStatus.Reconciles = nil
+		newStatus.Reconciles = nil
+		oldStatus.ReconcileHistory = nil
+		newStatus.ReconcileHistory = nil
+		return !equality.Semantic.DeepEqual(oldStatus, newStatus)
+	},
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// DefaultMaxConcurrentReconciles is the number of CRs a controller
+// reconciles in parallel when not set.
+const DefaultMaxConcurrentReconciles = 4
+
+var (
+	reconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dpu_network_operator_reconcile_errors_total",
+		Help: "Number of failed reconciles, panics included, by controller and CR.",
+	}, []string{"controller", "namespace", "name"})
+	reconcileConsecutiveErrors = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "dpu_network_operator_reconcile_consecutive_errors",
+		Help: "Number of reconciles of the CR failed in a row, by controller. The series is dropped once a reconcile succeeds.",
+	}, []string{"controller", "namespace", "name"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(reconcileErrors, reconcileConsecutiveErrors)
+}
+
+// workerOptions runs up to workers reconciles of the controller in
+// parallel. The workqueue never hands the same CR to two workers, so the
+// reconciles of a CR stay serialized while a CR wedged on a slow tenant API
+// server or a degraded MachineConfigPool doesn't hold the others, and each
+// CR failing is retried with its own backoff.
+func workerOptions(workers int) controller.Options {
+	if workers <= 0 {
+		workers = DefaultMaxConcurrentReconciles
+	}
+	return controller.Options{MaxConcurrentReconciles: workers}
+}
+
+// isolateReconcile wraps the reconcile of a controller so the failures of
+// a CR are counted against it, and a panic fails only the reconcile of that
+// CR.
+func isolateReconcile(controllerName string, fn reconcile.Func) reconcile.Func {
+	return func(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				logger.Error(fmt.Errorf("%v", p), "reconcile panicked", "controller", controllerName, "request", req.NamespacedName, "stack", string(debug.Stack()))
+				result, err = ctrl.Result{}, fmt.Errorf("reconcile panicked: %v", p)
+			}
+			if err != nil {
+				reconcileErrors.WithLabelValues(controllerName, req.Namespace, req.Name).Inc()
+				reconcileConsecutiveErrors.WithLabelValues(controllerName, req.Namespace, req.Name).Inc()
+				return
+			}
+			reconcileConsecutiveErrors.DeleteLabelValues(controllerName, req.Namespace, req.Name)
+		}()
+		return fn(ctx, req)
+	}
+}
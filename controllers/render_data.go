@@ -0,0 +1,39 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+)
+
+// addExtraRenderData adds the extraRenderData of the CR to the render data
+// of the manifests. The variables set by the operator take precedence, so a
+// user value never changes how the operator's own objects are rendered.
+func addExtraRenderData(data map[string]interface{}, cfg *dpuv1alpha1.OVNKubeConfig) {
+	for k, v := range cfg.Spec.ExtraRenderData {
+		if _, ok := data[k]; !ok {
+			data[k] = v
+		}
+	}
+}
+
+// scopedName suffixes name with the namespace of cfg, so the cluster-scoped
+// objects rendered for different tenant clusters don't collide. It is the
+// scopedName function of the ovnkube-node templates.
+func scopedName(cfg *dpuv1alpha1.OVNKubeConfig, name string) string {
+	return name + "-" + cfg.Namespace
+}
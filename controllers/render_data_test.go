@@ -0,0 +1,64 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/openshift/cluster-network-operator/pkg/render"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+)
+
+func TestScopedName(t *testing.T) {
+	for _, tc := range []struct {
+		namespace string
+		name      string
+		want      string
+	}{
+		{namespace: "tenant-a", name: "dpu-ovnkube-node", want: "dpu-ovnkube-node-tenant-a"},
+		{namespace: "tenant-b", name: "dpu-ovnkube-node", want: "dpu-ovnkube-node-tenant-b"},
+	} {
+		cfg := &dpuv1alpha1.OVNKubeConfig{ObjectMeta: metav1.ObjectMeta{Name: "ovnkubeconfig", Namespace: tc.namespace}}
+		if got := scopedName(cfg, tc.name); got != tc.want {
+			t.Errorf("scopedName(%s, %q) = %q, want %q", tc.namespace, tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestScopedNameTemplate(t *testing.T) {
+	cfg := &dpuv1alpha1.OVNKubeConfig{ObjectMeta: metav1.ObjectMeta{Name: "ovnkubeconfig", Namespace: "tenant-a"}}
+	data := render.MakeRenderData()
+	data.Data["SecurityContextConstraints"] = true
+	data.Data["Privileged"] = false
+	data.Data["Namespace"] = cfg.Namespace
+	data.Funcs["scopedName"] = func(name string) string { return scopedName(cfg, name) }
+	objs, err := render.RenderTemplate("../bindata/ovnkube-node/scc.yaml", &data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, obj := range objs {
+		if obj.GetKind() == "SecurityContextConstraints" {
+			names = append(names, obj.GetName())
+		}
+	}
+	if len(names) != 1 || names[0] != "dpu-ovnkube-node-tenant-a" {
+		t.Errorf("unexpected SecurityContextConstraints %v", names)
+	}
+}
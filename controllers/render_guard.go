@@ -0,0 +1,170 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openshift/cluster-network-operator/pkg/render"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/openshift/dpu-network-operator/api"
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+)
+
+const (
+	renderBackoffBase = 5 * time.Second
+	renderBackoffMax  = 5 * time.Minute
+)
+
+var (
+	// templateErrorPattern matches the location in a text/template error,
+	// e.g. template: ./bindata/ovnkube-node/ovnkube-node.yaml:12:5: ...
+	templateErrorPattern = regexp.MustCompile(`template: ([^:\s]+):(\d+)`)
+	// yamlErrorPattern matches the location in the error of a rendered
+	// manifest which is not valid YAML.
+	yamlErrorPattern = regexp.MustCompile(`manifest (\S+): .*yaml: line (\d+)`)
+)
+
+// renderFailure is the last failure rendering a template directory for an
+// OVNKubeConfig.
+type renderFailure struct {
+	inputs   string
+	err      error
+	failures int
+	retryAt  time.Time
+}
+
+// renderFailures caches the render failures by OVNKubeConfig and template
+// directory. A directory failing again for the same inputs isn't rendered
+// until its backoff expires, so the events of the watched objects don't
+// make the reconciles hot loop on it.
+var renderFailures = struct {
+	sync.Mutex
+	byKey map[string]*renderFailure
+}{byKey: map[string]*renderFailure{}}
+
+func renderFailureKey(cfg *dpuv1alpha1.OVNKubeConfig, templates string) string {
+	return cfg.Namespace + "/" + cfg.Name + "/" + templates
+}
+
+// resetRenderFailures drops the render failures of the OVNKubeConfig of the
+// namespace, once it is deleted.
+func resetRenderFailures(namespace string) {
+	renderFailures.Lock()
+	defer renderFailures.Unlock()
+	for key := range renderFailures.byKey {
+		if strings.HasPrefix(key, namespace+"/") {
+			delete(renderFailures.byKey, key)
+		}
+	}
+}
+
+// renderDir renders the manifests of the template directory, guarded by
+// guardRender.
+func renderDir(cfg *dpuv1alpha1.OVNKubeConfig, manifestDir string, data *render.RenderData) ([]*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+	err := guardRender(cfg, manifestDir, data.Data, func() error {
+		var err error
+		objs, err = render.RenderDir(manifestDir, data)
+		return err
+	})
+	return objs, err
+}
+
+// guardRender runs fn, unless it failed for the same inputs and its
+// backoff has not expired, in which case the last error is returned. The
+// backoff doubles with every identical failure, from renderBackoffBase to
+// renderBackoffMax. The errors locate the failing template and line.
+func guardRender(cfg *dpuv1alpha1.OVNKubeConfig, templates string, inputs map[string]interface{}, fn func() error) error {
+	key := renderFailureKey(cfg, templates)
+	// inputs which can't be hashed are always rendered
+	hash := ""
+	if b, err := json.Marshal(inputs); err == nil {
+		sum := sha256.Sum256(b)
+		hash = hex.EncodeToString(sum[:])
+	}
+
+	renderFailures.Lock()
+	last := renderFailures.byKey[key]
+	renderFailures.Unlock()
+	if last != nil && hash != "" && last.inputs == hash && time.Now().Before(last.retryAt) {
+		return fmt.Errorf("%w (failed %d times for the same inputs, not retried before %s)",
+			last.err, last.failures, last.retryAt.UTC().Format(time.RFC3339))
+	}
+
+	err := fn()
+	renderFailures.Lock()
+	defer renderFailures.Unlock()
+	if err == nil {
+		delete(renderFailures.byKey, key)
+		return nil
+	}
+	err = locateRenderError(err)
+	failures := 1
+	if last != nil && last.inputs == hash {
+		failures = last.failures + 1
+	}
+	backoff := renderBackoffMax
+	if failures <= 6 {
+		backoff = renderBackoffBase << (failures - 1)
+	}
+	renderFailures.byKey[key] = &renderFailure{inputs: hash, err: err, failures: failures, retryAt: time.Now().Add(backoff)}
+	return err
+}
+
+// locateRenderError prefixes err with the template and line it failed at,
+// when found in the error.
+func locateRenderError(err error) error {
+	for _, pattern := range []*regexp.Regexp{templateErrorPattern, yamlErrorPattern} {
+		if m := pattern.FindStringSubmatch(err.Error()); m != nil {
+			template := strings.TrimPrefix(strings.TrimPrefix(m[1], "./"), "bindata/")
+			return fmt.Errorf("template %s line %s: %w", template, m[2], err)
+		}
+	}
+	return err
+}
+
+// setRenderFailedCondition sets the RenderFailed condition of cfg while one
+// of its templates is failing, and removes it otherwise. The failures are
+// shared by the controllers, so each one reports all of them.
+func setRenderFailedCondition(cfg *dpuv1alpha1.OVNKubeConfig) {
+	prefix := renderFailureKey(cfg, "")
+	messages := []string{}
+	renderFailures.Lock()
+	for key, failure := range renderFailures.byKey {
+		if strings.HasPrefix(key, prefix) {
+			messages = append(messages, failure.err.Error())
+		}
+	}
+	renderFailures.Unlock()
+	if len(messages) == 0 {
+		meta.RemoveStatusCondition(&cfg.Status.Conditions, api.RenderFailed)
+		return
+	}
+	sort.Strings(messages)
+	meta.SetStatusCondition(&cfg.Status.Conditions, *api.Conditions(cfg).RenderFailed().Reason(api.ReasonRenderFailed).Msg(strings.Join(messages, "; ")).Build())
+}
@@ -0,0 +1,306 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/dpu-network-operator/api"
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+
+const (
+	hookTargetDaemonSet     = "daemonset"
+	hookTargetMachineConfig = "machineconfig"
+	hookPhasePre            = "pre-rollout"
+	hookPhasePost           = "post-rollout"
+
+	defaultHookTimeoutSeconds = 600
+	hookScriptMountPath       = "/hook"
+)
+
+// hookError is returned while a rollout hook Job runs, or when it failed.
+type hookError struct {
+	phase  string
+	job    string
+	failed bool
+}
+
+func (e *hookError) Error() string {
+	if e.failed {
+		return fmt.Sprintf("%s hook Job %s failed", e.phase, e.job)
+	}
+	return fmt.Sprintf("waiting for %s hook Job %s", e.phase, e.job)
+}
+
+func (e *hookError) reason() string {
+	if e.failed {
+		return api.ReasonHookFailed
+	}
+	return api.ReasonHookRunning
+}
+
+// runRolloutHook runs the hook of the phase for a revision of the target, and
+// records its result in the hooks condition of the target. The Job is named
+// after the revision, so the hook runs once per change; deleting a failed Job
+// runs the hook again. It returns nil once the Job succeeded or if there is
+// no hook, and a hookError otherwise.
+func (r *OVNKubeConfigReconciler) runRolloutHook(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, target, phase, revision string) error {
+	hook := rolloutHook(cfg, phase)
+	if hook == nil {
+		return nil
+	}
+	if len(revision) > 10 {
+		revision = revision[:10]
+	}
+	name := target + "-" + phase + "-" + revision
+
+	job := &batchv1.Job{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: cfg.Namespace, Name: name}, job)
+	if errors.IsNotFound(err) {
+		job, err = r.renderHookJob(ctx, cfg, hook, target, phase, name)
+		if err != nil {
+			setHookCondition(cfg, target, false, api.ReasonHookFailed, err.Error())
+			return err
+		}
+		// only the Job of the latest change is kept
+		if err := r.deleteHookJobs(ctx, cfg.Namespace, target+"-"+phase); err != nil {
+			return err
+		}
+		logger.Info("Run rollout hook", "job", name)
+		if err := retryCreate(ctx, r.Client, job); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	herr := &hookError{phase: phase, job: name}
+	switch {
+	case jobConditionTrue(job, batchv1.JobComplete):
+		setHookCondition(cfg, target, true, api.ReasonHookSucceeded, fmt.Sprintf("%s hook Job %s succeeded", phase, name))
+		return nil
+	case jobConditionTrue(job, batchv1.JobFailed):
+		herr.failed = true
+	}
+	setHookCondition(cfg, target, false, herr.reason(), herr.Error())
+	return herr
+}
+
+func rolloutHook(cfg *dpuv1alpha1.OVNKubeConfig, phase string) *dpuv1alpha1.RolloutHook {
+	if cfg.Spec.Hooks == nil {
+		return nil
+	}
+	if phase == hookPhasePre {
+		return cfg.Spec.Hooks.PreRollout
+	}
+	return cfg.Spec.Hooks.PostRollout
+}
+
+// renderHookJob returns the Job running the script of the hook in the
+// ovnkube image, or the spec of its Job template.
+func (r *OVNKubeConfigReconciler) renderHookJob(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, hook *dpuv1alpha1.RolloutHook, target, phase, name string) (*batchv1.Job, error) {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cfg.Namespace,
+			Labels:    map[string]string{utils.RolloutHookLabel: target + "-" + phase},
+		},
+	}
+	switch {
+	case hook.Script != nil && hook.JobTemplate != nil:
+		return nil, fmt.Errorf("%s hook must set only one of script and jobTemplate", phase)
+	case hook.Script != nil:
+		image, err := r.getOvnkubeImage(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		job.Spec.Template.Spec = corev1.PodSpec{
+			RestartPolicy:    corev1.RestartPolicyNever,
+			ImagePullSecrets: cfg.Spec.ImagePullSecrets,
+			Tolerations:      nodeTaintTolerations(cfg),
+			Containers: []corev1.Container{{
+				Name:    "hook",
+				Image:   image,
+				Command: []string{"/bin/bash", hookScriptMountPath + "/" + hook.Script.Key},
+				VolumeMounts: []corev1.VolumeMount{{
+					Name:      "script",
+					MountPath: hookScriptMountPath,
+					ReadOnly:  true,
+				}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: "script",
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: hook.Script.LocalObjectReference,
+						Items:                []corev1.KeyToPath{{Key: hook.Script.Key, Path: hook.Script.Key}},
+					},
+				},
+			}},
+		}
+	case hook.JobTemplate != nil:
+		template := &batchv1.Job{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: cfg.Namespace, Name: hook.JobTemplate.Name}, template); err != nil {
+			return nil, fmt.Errorf("failed to get the %s hook Job template %s: %v", phase, hook.JobTemplate.Name, err)
+		}
+		job.Spec.Template = *template.Spec.Template.DeepCopy()
+		job.Spec.BackoffLimit = template.Spec.BackoffLimit
+		// the selector of the template is generated from its own uid
+		delete(job.Spec.Template.Labels, "controller-uid")
+		delete(job.Spec.Template.Labels, "job-name")
+	default:
+		return nil, fmt.Errorf("%s hook must set script or jobTemplate", phase)
+	}
+
+	for i := range job.Spec.Template.Spec.Containers {
+		c := &job.Spec.Template.Spec.Containers[i]
+		c.Env = append(c.Env,
+			corev1.EnvVar{Name: "ROLLOUT_TARGET", Value: target},
+			corev1.EnvVar{Name: "ROLLOUT_PHASE", Value: phase})
+	}
+	if job.Spec.BackoffLimit == nil {
+		backoffLimit := int32(0)
+		job.Spec.BackoffLimit = &backoffLimit
+	}
+	timeout := hook.TimeoutSeconds
+	if timeout == 0 {
+		timeout = defaultHookTimeoutSeconds
+	}
+	job.Spec.ActiveDeadlineSeconds = &timeout
+	if err := ctrl.SetControllerReference(cfg, job, r.Scheme); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (r *OVNKubeConfigReconciler) deleteHookJobs(ctx context.Context, namespace, hook string) error {
+	jobs := &batchv1.JobList{}
+	if err := r.List(ctx, jobs, client.InNamespace(namespace), client.MatchingLabels{utils.RolloutHookLabel: hook}); err != nil {
+		return err
+	}
+	for i := range jobs.Items {
+		if err := r.Delete(ctx, &jobs.Items[i], client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runPostRollout runs the post-rollout hook of the rolled out revision of
+// the target, then, once the hook succeeded, the connectivity test.
+func (r *OVNKubeConfigReconciler) runPostRollout(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, target, revision string) (ctrl.Result, error) {
+	if err := r.runRolloutHook(ctx, cfg, target, hookPhasePost, revision); err != nil {
+		return ctrl.Result{}, err
+	}
+	return r.runRolloutVerification(ctx, cfg, target, revision)
+}
+
+// runDaemonSetPostRollout runs the post-rollout hook and the connectivity
+// test once the ovnkube-node DaemonSets are rolled out to every DPU node.
+func (r *OVNKubeConfigReconciler) runDaemonSetPostRollout(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, dss []appsv1.DaemonSet) (ctrl.Result, error) {
+	hash := ""
+	for i := range dss {
+		h := dss[i].Annotations[utils.TemplateHashAnnotation]
+		if h == "" || (hash != "" && h != hash) || !daemonSetRolledOut(&dss[i]) {
+			return ctrl.Result{}, nil
+		}
+		hash = h
+	}
+	if hash == "" {
+		return ctrl.Result{}, nil
+	}
+	return r.runPostRollout(ctx, cfg, hookTargetDaemonSet, hash)
+}
+
+// runMachineConfigPostRollout runs the post-rollout hook and the
+// connectivity test once every node of the pool runs the switchdev
+// MachineConfig.
+func (r *OVNKubeConfigReconciler) runMachineConfigPostRollout(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) (ctrl.Result, error) {
+	if rolloutHook(cfg, hookPhasePost) == nil && !verificationEnabled(cfg) {
+		return ctrl.Result{}, nil
+	}
+	mcp := &mcfgv1.MachineConfigPool{}
+	if err := r.Get(ctx, types.NamespacedName{Name: cfgPoolName(cfg)}, mcp); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	mc := &mcfgv1.MachineConfig{}
+	if err := r.Get(ctx, types.NamespacedName{Name: switchdevMachineConfigName(cfg)}, mc); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if mcp.Status.ObservedGeneration < mcp.Generation ||
+		!mcfgv1.IsMachineConfigPoolConditionTrue(mcp.Status.Conditions, mcfgv1.MachineConfigPoolUpdated) ||
+		!poolRendersMachineConfig(mcp, mc.Name) {
+		return ctrl.Result{}, nil
+	}
+	return r.runPostRollout(ctx, cfg, hookTargetMachineConfig, rolloutRevision(mc.Spec.Config.Raw))
+}
+
+func poolRendersMachineConfig(mcp *mcfgv1.MachineConfigPool, name string) bool {
+	for _, source := range mcp.Status.Configuration.Source {
+		if source.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func rolloutRevision(b []byte) string {
+	h := fnv.New64a()
+	h.Write(b)
+	return fmt.Sprintf("%x", h.Sum64())
+}
+
+func jobConditionTrue(job *batchv1.Job, t batchv1.JobConditionType) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == t && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+func setHookCondition(cfg *dpuv1alpha1.OVNKubeConfig, target string, succeeded bool, reason, msg string) {
+	c := api.Conditions(cfg)
+	switch {
+	case target == hookTargetDaemonSet && succeeded:
+		c.DaemonSetHooks()
+	case target == hookTargetDaemonSet:
+		c.NotDaemonSetHooks()
+	case succeeded:
+		c.MachineConfigHooks()
+	default:
+		c.NotMachineConfigHooks()
+	}
+	meta.SetStatusCondition(&cfg.Status.Conditions, *c.Reason(reason).Msg(msg).Build())
+}
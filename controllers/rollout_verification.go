@@ -0,0 +1,347 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+const (
+	defaultVerificationNamespace      = "default"
+	defaultVerificationTimeoutSeconds = 300
+	// the test pods of the tenant cluster are polled, not watched
+	verificationRequeueInterval = 10 * time.Second
+	verificationServerPort      = 8080
+	verificationCheckMarker     = "### check"
+	// verificationMessageLimit bounds the message of a failed check
+	verificationMessageLimit = 512
+
+	eventReasonVerificationFailed = "VerificationFailed"
+)
+
+// verificationServerScript serves HTTP on the port of the test.
+const verificationServerScript = `exec python3 -m http.server "${SERVER_PORT}"`
+
+// verificationClientScript runs the checks from a host other than the
+// server's, when there is one, printing a marker line per check.
+const verificationClientScript = `
+rc=0
+check() {
+  name="$1"
+  shift
+  if [[ "$1" == "skip" ]]; then
+    echo "` + verificationCheckMarker + ` ${name} ` + dpuv1alpha1.VerificationCheckSkipped + `"
+    return
+  fi
+  if out=$("$@" 2>&1); then
+    echo "` + verificationCheckMarker + ` ${name} ` + dpuv1alpha1.VerificationCheckPassed + `"
+  else
+    echo "` + verificationCheckMarker + ` ${name} ` + dpuv1alpha1.VerificationCheckFailed + ` $(echo ${out} | tr '\n' ' ')"
+    rc=1
+  fi
+}
+check pod-to-pod curl -sS --max-time 5 -o /dev/null "http://${SERVER_IP}:${SERVER_PORT}/"
+check dns getent hosts kubernetes.default.svc
+if [[ -n "${EGRESS_URL}" ]]; then
+  check egress curl -sS --max-time 10 -o /dev/null "${EGRESS_URL}"
+else
+  check egress skip
+fi
+exit ${rc}
+`
+
+func verificationEnabled(cfg *dpuv1alpha1.OVNKubeConfig) bool {
+	return cfg.Spec.Verification != nil && cfg.Spec.Verification.Enabled
+}
+
+func verificationNamespace(cfg *dpuv1alpha1.OVNKubeConfig) string {
+	if cfg.Spec.Verification != nil && cfg.Spec.Verification.Namespace != "" {
+		return cfg.Spec.Verification.Namespace
+	}
+	return defaultVerificationNamespace
+}
+
+func verificationTimeout(cfg *dpuv1alpha1.OVNKubeConfig) time.Duration {
+	if cfg.Spec.Verification.TimeoutSeconds > 0 {
+		return time.Duration(cfg.Spec.Verification.TimeoutSeconds) * time.Second
+	}
+	return defaultVerificationTimeoutSeconds * time.Second
+}
+
+// verificationPodName returns the name of the server or client test pod of
+// the target. The workload and machine-config controllers each test their
+// own rollouts.
+func verificationPodName(cfg *dpuv1alpha1.OVNKubeConfig, target, role string) string {
+	return "dpu-verification-" + cfg.Namespace + "-" + target + "-" + role
+}
+
+// copyVerification copies the last verification of src when it tests a
+// rollout of target, and the verified revision of target, so the
+// controllers don't overwrite each other's.
+func copyVerification(dst, src *dpuv1alpha1.OVNKubeConfigStatus, target string) {
+	if src.LastVerification != nil && src.LastVerification.Target == target {
+		dst.LastVerification = src.LastVerification
+	}
+	if revision, ok := src.VerifiedRevisions[target]; ok {
+		if dst.VerifiedRevisions == nil {
+			dst.VerifiedRevisions = map[string]string{}
+		}
+		dst.VerifiedRevisions[target] = revision
+	}
+}
+
+// runRolloutVerification runs the connectivity test of the revision of the
+// target once it is rolled out, and records it in status.lastVerification.
+// A server pod is started on a host served by a DPU, then a client pod on
+// another one checks the pod-to-pod traffic to the server, the cluster DNS
+// and, if set, the egress URL. The test runs once per revision, and waits
+// for the test of the other target to complete; the pods are polled until
+// it completes, then deleted.
+func (r *OVNKubeConfigReconciler) runRolloutVerification(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, target, revision string) (ctrl.Result, error) {
+	if !verificationEnabled(cfg) {
+		return ctrl.Result{}, nil
+	}
+	report := cfg.Status.LastVerification
+	running := report != nil && report.Phase == dpuv1alpha1.VerificationRunning
+	if running && report.Target != target {
+		return ctrl.Result{RequeueAfter: verificationRequeueInterval}, nil
+	}
+	current := running && report.Revision == revision
+	if !current && cfg.Status.VerifiedRevisions[target] == revision {
+		return ctrl.Result{}, nil
+	}
+	kubeClient := r.connectedTenantKubeClient(cfg.Namespace)
+	if kubeClient == nil {
+		// the tenant syncer is not started yet
+		return ctrl.Result{RequeueAfter: verificationRequeueInterval}, nil
+	}
+	hosts, err := r.verificationHosts(ctx, cfg)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	pods := kubeClient.CoreV1().Pods(verificationNamespace(cfg))
+	serverName := verificationPodName(cfg, target, "server")
+	clientName := verificationPodName(cfg, target, "client")
+
+	if !current {
+		logger.Info("Verify the rollout", "target", target, "revision", revision)
+		if err := r.deleteVerificationPods(ctx, kubeClient, cfg, target); err != nil {
+			return ctrl.Result{}, err
+		}
+		cfg.Status.LastVerification = &dpuv1alpha1.VerificationReport{
+			Target:    target,
+			Revision:  revision,
+			Phase:     dpuv1alpha1.VerificationRunning,
+			StartTime: metav1.Now(),
+		}
+		if len(hosts) == 0 {
+			return ctrl.Result{}, r.completeVerification(ctx, kubeClient, cfg, dpuv1alpha1.VerificationFailed, nil,
+				fmt.Sprintf("no host of the tenant cluster is served by a DPU in ConfigMap %s", utils.CmNameEnvOverrides))
+		}
+		env := []corev1.EnvVar{{Name: "SERVER_PORT", Value: fmt.Sprint(verificationServerPort)}}
+		server, err := r.renderVerificationPod(ctx, cfg, serverName, hosts[0], verificationServerScript, env)
+		if err != nil {
+			return ctrl.Result{}, r.completeVerification(ctx, kubeClient, cfg, dpuv1alpha1.VerificationFailed, nil, err.Error())
+		}
+		if _, err := pods.Create(ctx, server, metav1.CreateOptions{}); err != nil {
+			return ctrl.Result{}, dpuerrors.TenantUnreachable(err)
+		}
+		return ctrl.Result{RequeueAfter: verificationRequeueInterval}, nil
+	}
+
+	if time.Since(report.StartTime.Time) > verificationTimeout(cfg) {
+		return ctrl.Result{}, r.completeVerification(ctx, kubeClient, cfg, dpuv1alpha1.VerificationFailed, nil,
+			fmt.Sprintf("the test didn't complete in %s", verificationTimeout(cfg)))
+	}
+	server, err := pods.Get(ctx, serverName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return ctrl.Result{}, r.completeVerification(ctx, kubeClient, cfg, dpuv1alpha1.VerificationFailed, nil,
+			fmt.Sprintf("the server pod %s was deleted", serverName))
+	} else if err != nil {
+		return ctrl.Result{}, dpuerrors.TenantUnreachable(err)
+	}
+	if server.Status.Phase != corev1.PodRunning || server.Status.PodIP == "" {
+		return ctrl.Result{RequeueAfter: verificationRequeueInterval}, nil
+	}
+	client, err := pods.Get(ctx, clientName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		env := []corev1.EnvVar{
+			{Name: "SERVER_IP", Value: server.Status.PodIP},
+			{Name: "SERVER_PORT", Value: fmt.Sprint(verificationServerPort)},
+			{Name: "EGRESS_URL", Value: cfg.Spec.Verification.EgressURL},
+		}
+		client, err = r.renderVerificationPod(ctx, cfg, clientName, hosts[len(hosts)-1], verificationClientScript, env)
+		if err != nil {
+			return ctrl.Result{}, r.completeVerification(ctx, kubeClient, cfg, dpuv1alpha1.VerificationFailed, nil, err.Error())
+		}
+		if _, err := pods.Create(ctx, client, metav1.CreateOptions{}); err != nil {
+			return ctrl.Result{}, dpuerrors.TenantUnreachable(err)
+		}
+		return ctrl.Result{RequeueAfter: verificationRequeueInterval}, nil
+	} else if err != nil {
+		return ctrl.Result{}, dpuerrors.TenantUnreachable(err)
+	}
+	if client.Status.Phase != corev1.PodSucceeded && client.Status.Phase != corev1.PodFailed {
+		return ctrl.Result{RequeueAfter: verificationRequeueInterval}, nil
+	}
+	out, err := pods.GetLogs(clientName, &corev1.PodLogOptions{Container: "test"}).DoRaw(ctx)
+	if err != nil {
+		return ctrl.Result{}, dpuerrors.TenantUnreachable(fmt.Errorf("couldn't read the logs of the test pod %s: %v", clientName, err))
+	}
+	checks := parseVerificationChecks(string(out))
+	phase := dpuv1alpha1.VerificationSucceeded
+	msg := ""
+	if client.Status.Phase == corev1.PodFailed || len(checks) == 0 {
+		phase = dpuv1alpha1.VerificationFailed
+	}
+	if len(checks) == 0 {
+		msg = "the test pod reported no check"
+	}
+	return ctrl.Result{}, r.completeVerification(ctx, kubeClient, cfg, phase, checks, msg)
+}
+
+// completeVerification records the outcome of the running test, deletes
+// its pods and publishes a failure in a warning event.
+func (r *OVNKubeConfigReconciler) completeVerification(ctx context.Context, kubeClient kubernetes.Interface, cfg *dpuv1alpha1.OVNKubeConfig, phase dpuv1alpha1.VerificationPhase, checks []dpuv1alpha1.VerificationCheck, msg string) error {
+	report := cfg.Status.LastVerification
+	now := metav1.Now()
+	if cfg.Status.VerifiedRevisions == nil {
+		cfg.Status.VerifiedRevisions = map[string]string{}
+	}
+	cfg.Status.VerifiedRevisions[report.Target] = report.Revision
+	report.Phase = phase
+	report.CompletionTime = &now
+	report.Checks = checks
+	report.Message = msg
+	logger.Info("Verified the rollout", "target", report.Target, "revision", report.Revision, "phase", phase, "message", msg)
+	if phase == dpuv1alpha1.VerificationFailed && r.Recorder != nil {
+		failed := []string{}
+		for _, c := range checks {
+			if c.Result == dpuv1alpha1.VerificationCheckFailed {
+				failed = append(failed, c.Name+": "+c.Message)
+			}
+		}
+		if msg != "" {
+			failed = append(failed, msg)
+		}
+		r.Recorder.Eventf(cfg, corev1.EventTypeWarning, eventReasonVerificationFailed,
+			"The verification of the %s rollout %s failed: %s", report.Target, report.Revision, strings.Join(failed, "; "))
+	}
+	return r.deleteVerificationPods(ctx, kubeClient, cfg, report.Target)
+}
+
+// deleteVerificationPods deletes the test pods of the target from the
+// tenant cluster.
+func (r *OVNKubeConfigReconciler) deleteVerificationPods(ctx context.Context, kubeClient kubernetes.Interface, cfg *dpuv1alpha1.OVNKubeConfig, target string) error {
+	pods := kubeClient.CoreV1().Pods(verificationNamespace(cfg))
+	for _, role := range []string{"server", "client"} {
+		err := pods.Delete(ctx, verificationPodName(cfg, target, role), metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return dpuerrors.TenantUnreachable(err)
+		}
+	}
+	return nil
+}
+
+// verificationHosts returns the hosts of the tenant cluster served by the
+// DPUs of cfg, sorted.
+func (r *OVNKubeConfigReconciler) verificationHosts(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) ([]string, error) {
+	dpuNodes, err := r.dpuNodesByHost(ctx, cfg.Namespace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	hosts := make([]string, 0, len(dpuNodes))
+	for host := range dpuNodes {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts, nil
+}
+
+// renderVerificationPod returns a test pod of the tenant cluster running
+// the script on the host. It is labeled with the namespace of cfg, so it is
+// removed with cfg.
+func (r *OVNKubeConfigReconciler) renderVerificationPod(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, name, host, script string, env []corev1.EnvVar) (*corev1.Pod, error) {
+	image := cfg.Spec.Verification.Image
+	if image == "" {
+		var err error
+		if image, err = r.getOvnkubeImage(ctx, cfg); err != nil {
+			return nil, err
+		}
+	}
+	timeout := int64(verificationTimeout(cfg).Seconds())
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: verificationNamespace(cfg),
+			Labels:    map[string]string{utils.OwnerLabel: cfg.Namespace},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:              host,
+			RestartPolicy:         corev1.RestartPolicyNever,
+			Tolerations:           []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			ActiveDeadlineSeconds: &timeout,
+			Containers: []corev1.Container{{
+				Name:    "test",
+				Image:   image,
+				Command: []string{"/bin/bash", "-c", script},
+				Env:     env,
+			}},
+		},
+	}, nil
+}
+
+// parseVerificationChecks returns the checks reported by the client test
+// pod.
+func parseVerificationChecks(out string) []dpuv1alpha1.VerificationCheck {
+	checks := []dpuv1alpha1.VerificationCheck{}
+	for _, line := range strings.Split(out, "\n") {
+		if !strings.HasPrefix(line, verificationCheckMarker+" ") {
+			continue
+		}
+		cols := strings.SplitN(strings.TrimPrefix(line, verificationCheckMarker+" "), " ", 3)
+		if len(cols) < 2 {
+			continue
+		}
+		check := dpuv1alpha1.VerificationCheck{Name: cols[0], Result: cols[1]}
+		if len(cols) == 3 {
+			check.Message = strings.TrimSpace(cols[2])
+			if len(check.Message) > verificationMessageLimit {
+				check.Message = check.Message[:verificationMessageLimit] + "..."
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openshift/cluster-network-operator/pkg/render"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/openshift/dpu-network-operator/api"
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+	"github.com/openshift/dpu-network-operator/pkg/tracing"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+const (
+	eswitchModeSwitchdev = "switchdev"
+	// switchdevPending is the SwitchdevError of the nodes whose
+	// switchdev-check pod didn't report yet
+	switchdevPending = "the eswitch modes are not reported yet"
+)
+
+// syncSwitchdevCheck deploys the DaemonSet which annotates every DPU node
+// with the eswitch mode of its NICs, since the switchdev configuration may
+// apply while the firmware keeps a NIC in legacy mode.
+func (r *OVNKubeConfigReconciler) syncSwitchdevCheck(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, image string, nodeSelector *metav1.LabelSelector) error {
+	objs, err := r.renderSwitchdevCheck(ctx, cfg, image)
+	if err != nil {
+		return err
+	}
+	return r.applyRenderedObjects(ctx, cfg, objs, nodeSelector)
+}
+
+func (r *OVNKubeConfigReconciler) renderSwitchdevCheck(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, ovnkubeImage string) ([]*unstructured.Unstructured, error) {
+	image, err := getNodeAgentImage(ctx, cfg, ovnkubeImage)
+	if err != nil {
+		return nil, err
+	}
+	priorityClassName, err := r.getPriorityClassName(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	data := render.MakeRenderData()
+	data.Data["OvnKubeImage"] = ovnkubeImage
+	data.Data["NodeAgentImage"] = image
+	data.Data["Namespace"] = cfg.Namespace
+	data.Data["PriorityClassName"] = priorityClassName
+	data.Data["ImagePullSecrets"] = imagePullSecretNames(cfg)
+	data.Data["EswitchModesAnnotation"] = utils.EswitchModesAnnotation
+
+	addExtraRenderData(data.Data, cfg)
+	_, span := tracing.Start(ctx, "render", "manifests", utils.SwitchdevCheckManifestPath)
+	objs, err := renderDir(cfg, utils.SwitchdevCheckManifestPath, &data)
+	span.RecordError(err)
+	span.End()
+	if err != nil {
+		logger.Error(err, "Fail to render switchdev-check manifests")
+		return nil, dpuerrors.RenderFailed(err)
+	}
+	return objs, nil
+}
+
+// setNodeSwitchdevStatus reports in status the eswitch modes published in
+// annotation by the switchdev-check pod of the node. When uplinks are set,
+// only their PFs are checked, and the ones not found are reported.
+func setNodeSwitchdevStatus(status *dpuv1alpha1.DpuNodeStatus, annotation string, published bool, uplinks []dpuv1alpha1.Uplink) {
+	if !published {
+		status.SwitchdevError = switchdevPending
+		return
+	}
+	modes := map[string]string{}
+	if err := json.Unmarshal([]byte(annotation), &modes); err != nil {
+		status.SwitchdevError = fmt.Sprintf("invalid %s annotation: %v", utils.EswitchModesAnnotation, err)
+		return
+	}
+	status.EswitchModes = modes
+	if len(modes) == 0 {
+		status.SwitchdevError = "no NIC with an eswitch found"
+		return
+	}
+	devices := uplinkDevices(uplinks)
+	legacy := []string{}
+	for dev, mode := range modes {
+		if _, ok := devices[dev]; (ok || len(devices) == 0) && mode != eswitchModeSwitchdev {
+			legacy = append(legacy, fmt.Sprintf("%s is in %s mode", dev, mode))
+		}
+	}
+	for dev, name := range devices {
+		if _, ok := modes[dev]; !ok {
+			status.MissingUplinks = append(status.MissingUplinks, name)
+			legacy = append(legacy, fmt.Sprintf("uplink %s is not found at %s", name, dev))
+		}
+	}
+	sort.Strings(status.MissingUplinks)
+	sort.Strings(legacy)
+	status.SwitchdevError = strings.Join(legacy, ", ")
+}
+
+// switchdevCondition aggregates the switchdev status of the nodes of cfg
+// into the SwitchdevReady condition. The nodes under maintenance are left
+// out.
+func switchdevCondition(cfg *dpuv1alpha1.OVNKubeConfig) *metav1.Condition {
+	nodes := cfg.Status.Nodes
+	if len(nodes) == 0 {
+		return api.Conditions(cfg).NotSwitchdevReady().Reason(api.ReasonProgressing).Msg("No DPU node in the pool").Build()
+	}
+	notReady := []string{}
+	reason := api.ReasonProgressing
+	for _, node := range nodes {
+		if node.SwitchdevError == "" || node.UnderMaintenance {
+			continue
+		}
+		notReady = append(notReady, fmt.Sprintf("%s: %s", node.Name, node.SwitchdevError))
+		if len(node.MissingUplinks) > 0 {
+			reason = api.ReasonUplinkNotFound
+		} else if node.SwitchdevError != switchdevPending && reason != api.ReasonUplinkNotFound {
+			reason = api.ReasonLegacyMode
+		}
+	}
+	if len(notReady) > 0 {
+		return api.Conditions(cfg).NotSwitchdevReady().Reason(reason).Msg(strings.Join(notReady, "; ")).Build()
+	}
+	return api.Conditions(cfg).SwitchdevReady().Reason(api.ReasonSwitchdev).Build()
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+const (
+	// defaultTenantCleanupTimeout is how long the deletion of an
+	// OVNKubeConfig retries the cleanup of the unreachable tenant cluster.
+	defaultTenantCleanupTimeout = 5 * time.Minute
+
+	eventReasonTenantCleanupTimedOut = "TenantCleanupTimedOut"
+)
+
+// tenantManagedObject is a kind the operator creates in a namespace of the
+// tenant cluster, labeled with the namespace of its OVNKubeConfig.
+type tenantManagedObject struct {
+	gvk       schema.GroupVersionKind
+	namespace string
+}
+
+// tenantManagedObjects returns the kinds and namespaces the operator writes
+// to in the tenant cluster for cfg: the pods of the rollout verification
+// and the ConfigMap of the device plugin. Nothing else is cleaned up, so
+// that objects labeled by anyone else are left alone.
+func tenantManagedObjects(cfg *dpuv1alpha1.OVNKubeConfig) []tenantManagedObject {
+	return []tenantManagedObject{
+		{gvk: corev1.SchemeGroupVersion.WithKind("Pod"), namespace: verificationNamespace(cfg)},
+		{gvk: corev1.SchemeGroupVersion.WithKind("ConfigMap"), namespace: devicePluginNamespace(cfg)},
+	}
+}
+
+// tenantCleanupTimeout returns the spec.tenant.cleanupTimeout of cfg, or
+// its default.
+func tenantCleanupTimeout(cfg *dpuv1alpha1.OVNKubeConfig) time.Duration {
+	if cfg.Spec.Tenant != nil && cfg.Spec.Tenant.CleanupTimeout != nil {
+		return cfg.Spec.Tenant.CleanupTimeout.Duration
+	}
+	return defaultTenantCleanupTimeout
+}
+
+// addTenantCleanupFinalizer holds the deletion of cfg until the objects it
+// created in the tenant cluster are removed.
+func (r *OVNKubeConfigReconciler) addTenantCleanupFinalizer(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) error {
+	if !controllerutil.AddFinalizer(cfg, utils.TenantCleanupFinalizer) {
+		return nil
+	}
+	return r.Update(ctx, cfg)
+}
+
+// finalizeTenantObjects removes the objects created in the tenant cluster
+// by the deleted cfg, then its finalizer. While the tenant cluster is
+// unreachable, the cleanup is retried; once the cleanupTimeout expired, the
+// objects are left behind with forceCleanup, otherwise a warning event asks
+// for it.
+func (r *OVNKubeConfigReconciler) finalizeTenantObjects(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(cfg, utils.TenantCleanupFinalizer) {
+		return ctrl.Result{}, nil
+	}
+	// without a kubeconfig anymore, there is no tenant cluster to clean
+	if tenantKubeconfigName(cfg) != "" {
+		if err := r.cleanupTenantObjects(ctx, cfg); err != nil {
+			if time.Since(cfg.DeletionTimestamp.Time) < tenantCleanupTimeout(cfg) {
+				logger.Error(err, "failed to clean up the tenant cluster, retrying", "namespace", cfg.Namespace)
+				return ctrl.Result{RequeueAfter: tenantReachabilityRequeueInterval}, nil
+			}
+			if cfg.Spec.Tenant == nil || !cfg.Spec.Tenant.ForceCleanup {
+				if r.Recorder != nil {
+					r.Recorder.Eventf(cfg, corev1.EventTypeWarning, eventReasonTenantCleanupTimedOut,
+						"The objects of the tenant cluster could not be removed in %s, set spec.tenant.forceCleanup to leave them: %v",
+						tenantCleanupTimeout(cfg), err)
+				}
+				return ctrl.Result{RequeueAfter: tenantReachabilityRequeueInterval}, nil
+			}
+			logger.Error(err, "cleanup timed out, leaving the objects in the tenant cluster", "namespace", cfg.Namespace)
+		}
+	}
+	controllerutil.RemoveFinalizer(cfg, utils.TenantCleanupFinalizer)
+	return ctrl.Result{}, client.IgnoreNotFound(r.Update(ctx, cfg))
+}
+
+// cleanupTenantObjects deletes the tenantManagedObjects labeled with the
+// namespace of cfg from the tenant cluster. Nothing is left to clean once
+// the kubeconfig Secret is gone, e.g. deleted first with the namespace, and
+// a kind the tenant identity may not list or delete is one it could not
+// have created either.
+func (r *OVNKubeConfigReconciler) cleanupTenantObjects(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) error {
+	config, _, err := r.tenantRestConfig(ctx, cfg)
+	if errors.IsNotFound(err) {
+		logger.Info("The tenant kubeconfig is gone, leaving the tenant cluster as is", "namespace", cfg.Namespace)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	config = rest.CopyConfig(config)
+	config.Timeout = tenantReachabilityTimeout
+	c, err := client.New(config, client.Options{})
+	if err != nil {
+		return err
+	}
+	selector := labels.SelectorFromSet(labels.Set{utils.OwnerLabel: cfg.Namespace})
+	for _, managed := range tenantManagedObjects(cfg) {
+		gvk := managed.gvk
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		err := utils.ListAll(ctx, c, list, client.InNamespace(managed.namespace),
+			client.MatchingLabelsSelector{Selector: selector})
+		if errors.IsForbidden(err) {
+			logger.Info("The tenant identity may not list the objects, none to clean up", "kind", gvk.Kind, "namespace", managed.namespace)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to list the %s objects of the tenant cluster in %s: %w", gvk.Kind, managed.namespace, err)
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			logger.Info("Delete tenant object", "kind", gvk.Kind, "namespace", obj.GetNamespace(), "name", obj.GetName())
+			err := c.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground))
+			if errors.IsForbidden(err) {
+				logger.Info("The tenant identity may not delete the object, leaving it", "kind", gvk.Kind, "namespace", obj.GetNamespace(), "name", obj.GetName())
+				continue
+			}
+			if client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to delete %s %s/%s of the tenant cluster: %w", gvk.Kind, obj.GetNamespace(), obj.GetName(), err)
+			}
+		}
+	}
+	return nil
+}
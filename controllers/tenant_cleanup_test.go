@@ -0,0 +1,179 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+func TestTenantManagedObjects(t *testing.T) {
+	pod := corev1.SchemeGroupVersion.WithKind("Pod")
+	configMap := corev1.SchemeGroupVersion.WithKind("ConfigMap")
+	for _, tc := range []struct {
+		name string
+		spec dpuv1alpha1.OVNKubeConfigSpec
+		want []tenantManagedObject
+	}{
+		{name: "defaults",
+			want: []tenantManagedObject{{gvk: pod, namespace: "default"}, {gvk: configMap, namespace: "kube-system"}}},
+		{name: "custom namespaces",
+			spec: dpuv1alpha1.OVNKubeConfigSpec{
+				Verification: &dpuv1alpha1.VerificationSpec{Namespace: "dpu-verification"},
+				Tenant:       &dpuv1alpha1.TenantSpec{DevicePlugin: &dpuv1alpha1.DevicePluginSpec{Namespace: "sriov"}},
+			},
+			want: []tenantManagedObject{{gvk: pod, namespace: "dpu-verification"}, {gvk: configMap, namespace: "sriov"}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &dpuv1alpha1.OVNKubeConfig{Spec: tc.spec}
+			if got := tenantManagedObjects(cfg); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// kubeconfigClient serves the tenant kubeconfig Secret, if any, and records
+// the updates of the OVNKubeConfig.
+type kubeconfigClient struct {
+	client.Client
+	secret  *corev1.Secret
+	updated bool
+}
+
+func (c *kubeconfigClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if c.secret == nil {
+		return errors.NewNotFound(corev1.Resource("secrets"), key.Name)
+	}
+	c.secret.DeepCopyInto(obj.(*corev1.Secret))
+	return nil
+}
+
+func (c *kubeconfigClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.updated = true
+	return nil
+}
+
+// tenantAPIServer serves the discovery of the core API, and answers the
+// lists of pods with a labeled pod and the other requests with status.
+func tenantAPIServer(t *testing.T, listStatus, deleteStatus int) *httptest.Server {
+	write := func(w http.ResponseWriter, code int, obj interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(obj)
+	}
+	status := func(w http.ResponseWriter, code int) {
+		reason := metav1.StatusReasonServiceUnavailable
+		if code == http.StatusForbidden {
+			reason = metav1.StatusReasonForbidden
+		}
+		write(w, code, &metav1.Status{TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}, Status: metav1.StatusFailure,
+			Code: int32(code), Reason: reason, Message: http.StatusText(code)})
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.URL.Path == "/api":
+			write(w, http.StatusOK, &metav1.APIVersions{TypeMeta: metav1.TypeMeta{Kind: "APIVersions"}, Versions: []string{"v1"}})
+		case req.URL.Path == "/apis":
+			write(w, http.StatusOK, &metav1.APIGroupList{TypeMeta: metav1.TypeMeta{Kind: "APIGroupList", APIVersion: "v1"}})
+		case req.URL.Path == "/api/v1":
+			write(w, http.StatusOK, &metav1.APIResourceList{GroupVersion: "v1", APIResources: []metav1.APIResource{
+				{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: []string{"list", "delete"}},
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"list", "delete"}},
+			}})
+		case req.Method == http.MethodGet && listStatus != http.StatusOK:
+			status(w, listStatus)
+		case req.Method == http.MethodGet && req.URL.Path == "/api/v1/namespaces/default/pods":
+			write(w, http.StatusOK, &corev1.PodList{TypeMeta: metav1.TypeMeta{Kind: "PodList", APIVersion: "v1"}, Items: []corev1.Pod{{
+				ObjectMeta: metav1.ObjectMeta{Name: "verification", Namespace: "default", Labels: map[string]string{utils.OwnerLabel: "tenant-a"}}}}})
+		case req.Method == http.MethodGet:
+			write(w, http.StatusOK, &corev1.ConfigMapList{TypeMeta: metav1.TypeMeta{Kind: "ConfigMapList", APIVersion: "v1"}})
+		case req.Method == http.MethodDelete && deleteStatus != http.StatusOK:
+			status(w, deleteStatus)
+		case req.Method == http.MethodDelete:
+			write(w, http.StatusOK, &metav1.Status{TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}, Status: metav1.StatusSuccess})
+		default:
+			t.Errorf("unexpected request %s %s", req.Method, req.URL)
+		}
+	}))
+}
+
+func TestFinalizeTenantObjects(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		noKubeconfig bool
+		listStatus   int
+		deleteStatus int
+		wantReleased bool
+	}{
+		{name: "objects deleted", listStatus: http.StatusOK, deleteStatus: http.StatusOK, wantReleased: true},
+		{name: "kubeconfig Secret gone", noKubeconfig: true, wantReleased: true},
+		{name: "lists forbidden", listStatus: http.StatusForbidden, wantReleased: true},
+		{name: "deletes forbidden", listStatus: http.StatusOK, deleteStatus: http.StatusForbidden, wantReleased: true},
+		{name: "tenant cluster unavailable", listStatus: http.StatusServiceUnavailable},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			server := tenantAPIServer(t, tc.listStatus, tc.deleteStatus)
+			defer server.Close()
+			c := &kubeconfigClient{}
+			if !tc.noKubeconfig {
+				kubeconfig := fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters: [{name: tenant, cluster: {server: %q}}]
+users: [{name: tenant, user: {token: secret}}]
+contexts: [{name: tenant, context: {cluster: tenant, user: tenant}}]
+current-context: tenant
+`, server.URL)
+				c.secret = &corev1.Secret{Data: map[string][]byte{defaultTenantKubeconfigKey: []byte(kubeconfig)}}
+			}
+			r := &OVNKubeConfigReconciler{Client: c, Scheme: runtime.NewScheme()}
+			cfg := &dpuv1alpha1.OVNKubeConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "ovnkubeconfig", Namespace: "tenant-a",
+					DeletionTimestamp: &metav1.Time{Time: time.Now()}, Finalizers: []string{utils.TenantCleanupFinalizer}},
+				Spec: dpuv1alpha1.OVNKubeConfigSpec{KubeConfigFile: "tenant-kubeconfig"},
+			}
+
+			result, err := r.finalizeTenantObjects(context.TODO(), cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			released := !controllerutil.ContainsFinalizer(cfg, utils.TenantCleanupFinalizer) && c.updated
+			if released != tc.wantReleased {
+				t.Errorf("finalizer released = %v, want %v", released, tc.wantReleased)
+			}
+			if retried := result.RequeueAfter > 0; retried == tc.wantReleased {
+				t.Errorf("cleanup retried = %v, want %v", retried, !tc.wantReleased)
+			}
+		})
+	}
+}
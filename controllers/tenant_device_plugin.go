@@ -0,0 +1,185 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+const (
+	defaultDevicePluginNamespace    = "kube-system"
+	defaultDevicePluginResourceName = "dpu_vfs"
+	// devicePluginConfigKey is the key of the configuration file of the
+	// device plugin in its ConfigMap.
+	devicePluginConfigKey = "config.json"
+	// devicePluginPodSelector selects the pods of the upstream device plugin
+	// DaemonSet, which read their configuration at start.
+	devicePluginPodSelector = "app=sriovdp"
+	// mellanoxVendorID is the PCI vendor ID of the DPUs.
+	mellanoxVendorID = "15b3"
+)
+
+// defaultDevicePluginDevices are the PCI device IDs of the VFs of the
+// BlueField-2 and BlueField-3 DPUs.
+var defaultDevicePluginDevices = []string{"101e"}
+
+// devicePluginConfig is the configuration file of the SR-IOV network device
+// plugin.
+type devicePluginConfig struct {
+	ResourceList []devicePluginResource `json:"resourceList"`
+}
+
+type devicePluginResource struct {
+	ResourceName   string                `json:"resourceName"`
+	ResourcePrefix string                `json:"resourcePrefix,omitempty"`
+	Selectors      devicePluginSelectors `json:"selectors"`
+}
+
+type devicePluginSelectors struct {
+	Vendors []string `json:"vendors"`
+	Devices []string `json:"devices"`
+	PfNames []string `json:"pfNames,omitempty"`
+}
+
+// devicePluginNamespace returns the namespace of the device plugin of the
+// tenant cluster.
+func devicePluginNamespace(cfg *dpuv1alpha1.OVNKubeConfig) string {
+	if cfg.Spec.Tenant != nil && cfg.Spec.Tenant.DevicePlugin != nil && cfg.Spec.Tenant.DevicePlugin.Namespace != "" {
+		return cfg.Spec.Tenant.DevicePlugin.Namespace
+	}
+	return defaultDevicePluginNamespace
+}
+
+// renderDevicePluginConfig renders the configuration of the device plugin
+// with the resource pool of the DPU VFs.
+func renderDevicePluginConfig(cfg *dpuv1alpha1.OVNKubeConfig) (string, error) {
+	resource := devicePluginResource{
+		ResourceName: defaultDevicePluginResourceName,
+		Selectors: devicePluginSelectors{
+			Vendors: []string{mellanoxVendorID},
+			Devices: defaultDevicePluginDevices,
+		},
+	}
+	if spec := cfg.Spec.Tenant.DevicePlugin; spec != nil {
+		if spec.ResourceName != "" {
+			resource.ResourceName = spec.ResourceName
+		}
+		resource.ResourcePrefix = spec.ResourcePrefix
+		if len(spec.Devices) > 0 {
+			resource.Selectors.Devices = spec.Devices
+		}
+		resource.Selectors.PfNames = spec.PfNames
+	}
+	config, err := json.MarshalIndent(devicePluginConfig{ResourceList: []devicePluginResource{resource}}, "", "  ")
+	if err != nil {
+		return "", dpuerrors.RenderFailed(err)
+	}
+	return string(config), nil
+}
+
+// syncTenantDevicePlugin writes the sriovdp-config ConfigMap of the SR-IOV
+// network device plugin of the tenant cluster with spec.tenant.
+// manageDevicePlugin, labeled with the namespace of cfg, and restarts the
+// device plugin pods when it changes, since they read it at start. A
+// ConfigMap not created by the operator is only taken over once annotated
+// for adoption. The ConfigMap is deleted once the setting is reset.
+func (r *OVNKubeConfigReconciler) syncTenantDevicePlugin(ctx context.Context, config *rest.Config, cfg *dpuv1alpha1.OVNKubeConfig) error {
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return dpuerrors.TenantUnreachable(err)
+	}
+	namespace := devicePluginNamespace(cfg)
+	configMaps := kubeClient.CoreV1().ConfigMaps(namespace)
+	found, err := configMaps.Get(ctx, utils.CmNameSriovDevicePlugin, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		found = nil
+	} else if err != nil {
+		return dpuerrors.TenantUnreachable(err)
+	}
+	owned := found != nil && found.Labels[utils.OwnerLabel] == cfg.Namespace
+
+	if cfg.Spec.Tenant == nil || !cfg.Spec.Tenant.ManageDevicePlugin {
+		if !owned {
+			return nil
+		}
+		logger.Info("Delete the device plugin configuration of the tenant cluster", "namespace", namespace)
+		if err := configMaps.Delete(ctx, found.Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return dpuerrors.TenantUnreachable(err)
+		}
+		return nil
+	}
+
+	data, err := renderDevicePluginConfig(cfg)
+	if err != nil {
+		return err
+	}
+	desired := map[string]string{devicePluginConfigKey: data}
+	switch {
+	case found == nil:
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name:      utils.CmNameSriovDevicePlugin,
+			Namespace: namespace,
+			Labels:    map[string]string{utils.OwnerLabel: cfg.Namespace},
+		}, Data: desired}
+		if _, err := configMaps.Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+			return dpuerrors.TenantUnreachable(err)
+		}
+		logger.Info("Created the device plugin configuration of the tenant cluster", "namespace", namespace)
+	case found.Labels[utils.OwnerLabel] != "" && !owned:
+		return dpuerrors.Conflict(fmt.Errorf("ConfigMap %s/%s of the tenant cluster is owned by the OVNKubeConfig of namespace %s",
+			namespace, found.Name, found.Labels[utils.OwnerLabel]))
+	case !owned && !adoptable(found):
+		return dpuerrors.Conflict(fmt.Errorf("ConfigMap %s/%s of the tenant cluster was not created by the operator, annotate it with %s=true to take it over",
+			namespace, found.Name, utils.AdoptAnnotation))
+	case owned && equality.Semantic.DeepEqual(found.Data, desired):
+		return nil
+	default:
+		updated := found.DeepCopy()
+		if updated.Labels == nil {
+			updated.Labels = map[string]string{}
+		}
+		updated.Labels[utils.OwnerLabel] = cfg.Namespace
+		updated.Data = desired
+		if _, err := configMaps.Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
+			if errors.IsConflict(err) {
+				return dpuerrors.ApplyConflict(err)
+			}
+			return dpuerrors.TenantUnreachable(err)
+		}
+		logger.Info("Updated the device plugin configuration of the tenant cluster", "namespace", namespace)
+	}
+
+	// the DaemonSet recreates the pods with the new configuration
+	err = kubeClient.CoreV1().Pods(namespace).DeleteCollection(ctx, metav1.DeleteOptions{}, metav1.ListOptions{LabelSelector: devicePluginPodSelector})
+	if err != nil && !errors.IsNotFound(err) {
+		return dpuerrors.TenantUnreachable(fmt.Errorf("failed to restart the device plugin pods of the tenant cluster: %w", err))
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+)
+
+// checkTenantEndpoint checks the API server of the tenant kubeconfig step
+// by step from the operator pod, so a misrouted management network is
+// reported before the informers of the syncer slowly time out: the DNS
+// resolution of its host, a TCP connection, the TLS handshake, then the
+// /version endpoint. Through a proxy, only /version is checked. The outcome
+// is recorded in status.
+func checkTenantEndpoint(ctx context.Context, config *rest.Config, status *dpuv1alpha1.OVNKubeConfigStatus) error {
+	endpoint := &dpuv1alpha1.TenantEndpointStatus{Server: config.Host, LastCheckTime: metav1.Now()}
+	status.TenantEndpoint = endpoint
+	fail := func(step string, err error) error {
+		endpoint.Error = step + ": " + err.Error()
+		return dpuerrors.TenantUnreachable(fmt.Errorf("tenant API server %s: %s failed: %w", config.Host, step, err))
+	}
+
+	server := config.Host
+	if !strings.Contains(server, "://") {
+		server = "https://" + server
+	}
+	u, err := url.Parse(server)
+	if err != nil || u.Hostname() == "" {
+		return dpuerrors.InvalidKubeconfig(fmt.Errorf("invalid server %q in the tenant kubeconfig: %v", config.Host, err))
+	}
+	if !tenantEndpointProxied(config, u) {
+		if err := dialTenantEndpoint(ctx, config, u, endpoint, fail); err != nil {
+			return err
+		}
+	}
+
+	versionConfig := rest.CopyConfig(config)
+	versionConfig.Timeout = tenantReachabilityTimeout
+	dc, err := discovery.NewDiscoveryClientForConfig(versionConfig)
+	if err != nil {
+		return dpuerrors.InvalidKubeconfig(err)
+	}
+	start := time.Now()
+	_, err = dc.ServerVersion()
+	endpoint.VersionLatency = latencySince(start)
+	if err != nil {
+		return fail("version", err)
+	}
+	endpoint.Reachable = true
+	return nil
+}
+
+// dialTenantEndpoint resolves the host of u, connects to its first address
+// and runs the TLS handshake of an https server.
+func dialTenantEndpoint(ctx context.Context, config *rest.Config, u *url.URL, endpoint *dpuv1alpha1.TenantEndpointStatus, fail func(string, error) error) error {
+	host, port := u.Hostname(), u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	addresses := []string{host}
+	if net.ParseIP(host) == nil {
+		resolveCtx, cancel := context.WithTimeout(ctx, tenantReachabilityTimeout)
+		start := time.Now()
+		resolved, err := net.DefaultResolver.LookupHost(resolveCtx, host)
+		cancel()
+		endpoint.ResolveLatency = latencySince(start)
+		if err != nil {
+			return fail("resolve", err)
+		}
+		addresses = resolved
+	}
+	endpoint.Addresses = addresses
+
+	dialer := &net.Dialer{Timeout: tenantReachabilityTimeout}
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(addresses[0], port))
+	endpoint.DialLatency = latencySince(start)
+	if err != nil {
+		return fail("dial", err)
+	}
+	defer conn.Close()
+	if u.Scheme != "https" {
+		return nil
+	}
+
+	tlsConfig, err := rest.TLSConfigFor(config)
+	if err != nil {
+		return dpuerrors.InvalidKubeconfig(err)
+	}
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	} else {
+		tlsConfig = tlsConfig.Clone()
+	}
+	// the connection is to the resolved address
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = host
+	}
+	handshakeCtx, cancel := context.WithTimeout(ctx, tenantReachabilityTimeout)
+	defer cancel()
+	start = time.Now()
+	err = tls.Client(conn, tlsConfig).HandshakeContext(handshakeCtx)
+	endpoint.TLSLatency = latencySince(start)
+	if err != nil {
+		return fail("tls", err)
+	}
+	return nil
+}
+
+// tenantEndpointProxied returns whether the requests to u go through a
+// proxy, set in the kubeconfig or in the environment of the operator.
+func tenantEndpointProxied(config *rest.Config, u *url.URL) bool {
+	proxy := config.Proxy
+	if proxy == nil {
+		proxy = http.ProxyFromEnvironment
+	}
+	proxyURL, err := proxy(&http.Request{URL: u})
+	return err == nil && proxyURL != nil
+}
+
+func latencySince(start time.Time) *metav1.Duration {
+	return &metav1.Duration{Duration: time.Since(start).Round(time.Millisecond)}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+// tenantAccessVerbs are the verbs the syncer needs on the OVN-Kubernetes
+// ConfigMaps and Secrets of the tenant cluster.
+var tenantAccessVerbs = []string{"get", "list", "watch"}
+
+// tenantSyncedObjects are the resources and names of the objects the syncer
+// reads in the OVN-Kubernetes namespace. Each is reviewed by name, so an
+// identity only allowed them by resourceNames passes the review.
+var tenantSyncedObjects = []struct {
+	resource string
+	name     string
+}{
+	{resource: "configmaps", name: utils.CmNameOvnCa},
+	{resource: "configmaps", name: utils.CmNameOvnkubeConfig},
+	{resource: "configmaps", name: utils.CmNameSignerCa},
+	{resource: "secrets", name: utils.SecretNameOvnCert},
+}
+
+// tenantImpersonation returns the impersonation of the tenant client set in
+// the spec, empty if none.
+func tenantImpersonation(cfg *dpuv1alpha1.OVNKubeConfig) rest.ImpersonationConfig {
+	if cfg.Spec.Tenant == nil || cfg.Spec.Tenant.ImpersonateUser == "" {
+		return rest.ImpersonationConfig{}
+	}
+	return rest.ImpersonationConfig{
+		UserName: cfg.Spec.Tenant.ImpersonateUser,
+		Groups:   cfg.Spec.Tenant.ImpersonateGroups,
+	}
+}
+
+// applyTenantImpersonation makes the clients built from config impersonate
+// the user and groups of the spec.
+func applyTenantImpersonation(config *rest.Config, cfg *dpuv1alpha1.OVNKubeConfig) error {
+	if cfg.Spec.Tenant != nil && cfg.Spec.Tenant.ImpersonateUser == "" && len(cfg.Spec.Tenant.ImpersonateGroups) > 0 {
+		return dpuerrors.InvalidKubeconfig(fmt.Errorf("spec.tenant.impersonateGroups requires spec.tenant.impersonateUser"))
+	}
+	config.Impersonate = tenantImpersonation(cfg)
+	return nil
+}
+
+// checkTenantImpersonation checks that the kubeconfig identity may
+// impersonate the user and groups of config, by creating an access review
+// as them. Nothing is checked without impersonation.
+func checkTenantImpersonation(ctx context.Context, config *rest.Config) error {
+	if config.Impersonate.UserName == "" {
+		return nil
+	}
+	_, err := tenantAccessReview(ctx, config, "", "get", "configmaps", "")
+	return err
+}
+
+// checkTenantAccess checks with SelfSubjectAccessReviews that the
+// impersonated identity may read the ConfigMaps and Secrets the syncer
+// reads in the OVN-Kubernetes namespace. Nothing is checked without impersonation, a
+// kubeconfig lacking an access fails on its first request instead.
+func checkTenantAccess(ctx context.Context, config *rest.Config, namespace string) error {
+	if config.Impersonate.UserName == "" {
+		return nil
+	}
+	denied := []string{}
+	for _, obj := range tenantSyncedObjects {
+		for _, verb := range tenantAccessVerbs {
+			allowed, err := tenantAccessReview(ctx, config, namespace, verb, obj.resource, obj.name)
+			if err != nil {
+				return err
+			}
+			if !allowed {
+				denied = append(denied, verb+" "+obj.resource+"/"+obj.name)
+			}
+		}
+	}
+	if len(denied) > 0 {
+		return dpuerrors.InsufficientPermissions(fmt.Errorf("user %s may not %s in namespace %s of the tenant cluster",
+			config.Impersonate.UserName, strings.Join(denied, ", "), namespace))
+	}
+	return nil
+}
+
+// tenantAccessReview returns whether the tenant identity of config may use
+// the verb on the resource, on the object name if not "". The review being forbidden means the kubeconfig
+// identity may not impersonate it.
+func tenantAccessReview(ctx context.Context, config *rest.Config, namespace, verb, resource, name string) (bool, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return false, dpuerrors.TenantUnreachable(err)
+	}
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      verb,
+				Resource:  resource,
+				Name:      name,
+			},
+		},
+	}
+	review, err = clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		if errors.IsForbidden(err) {
+			return false, dpuerrors.InsufficientPermissions(fmt.Errorf("the tenant kubeconfig may not impersonate user %s: %w",
+				config.Impersonate.UserName, err))
+		}
+		return false, dpuerrors.TenantUnreachable(err)
+	}
+	return review.Status.Allowed, nil
+}
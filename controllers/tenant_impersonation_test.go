@@ -0,0 +1,105 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/openshift/dpu-network-operator/api"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+// accessReviewServer answers the SelfSubjectAccessReviews as the RBAC
+// authorizer would with rules bound to the reviewing user.
+func accessReviewServer(t *testing.T, rules []rbacv1.PolicyRule) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		review := &authorizationv1.SelfSubjectAccessReview{}
+		if err := json.NewDecoder(req.Body).Decode(review); err != nil {
+			t.Errorf("unexpected request %s: %v", req.URL, err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		attrs := review.Spec.ResourceAttributes
+		for _, rule := range rules {
+			if contains(rule.Verbs, attrs.Verb) && contains(rule.Resources, attrs.Resource) &&
+				(len(rule.ResourceNames) == 0 || contains(rule.ResourceNames, attrs.Name)) {
+				review.Status.Allowed = true
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(review)
+	}))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCheckTenantAccess(t *testing.T) {
+	verbs := []string{"get", "list", "watch"}
+	// the Role of gen-tenant-kubeconfig
+	namedRules := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: verbs,
+			ResourceNames: []string{utils.CmNameOvnCa, utils.CmNameOvnkubeConfig, utils.CmNameSignerCa}},
+		{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: verbs,
+			ResourceNames: []string{utils.SecretNameOvnCert}},
+	}
+	for _, tc := range []struct {
+		name  string
+		rules []rbacv1.PolicyRule
+		want  string
+	}{
+		{name: "resourceNames only", rules: namedRules},
+		{name: "whole resources", rules: []rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"configmaps", "secrets"}, Verbs: verbs}}},
+		{name: "missing object", rules: namedRules[:1],
+			want: "may not get secrets/ovn-cert, list secrets/ovn-cert, watch secrets/ovn-cert"},
+		{name: "other names", want: "get configmaps/ovn-ca", rules: []rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"configmaps", "secrets"}, Verbs: verbs, ResourceNames: []string{"other"}}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			server := accessReviewServer(t, tc.rules)
+			defer server.Close()
+			config := &rest.Config{Host: server.URL, Impersonate: rest.ImpersonationConfig{UserName: "dpu-operator"}}
+			err := checkTenantAccess(context.TODO(), config, "openshift-ovn-kubernetes")
+			if tc.want == "" {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.want) || dpuerrors.Reason(err, "") != api.ReasonInsufficientPermissions {
+				t.Errorf("got error %v, want InsufficientPermissions %q", err, tc.want)
+			}
+		})
+	}
+}
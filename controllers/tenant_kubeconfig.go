@@ -0,0 +1,226 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+const (
+	// defaultTenantKubeconfigKey is the data key of the tenant kubeconfig in
+	// its Secret, and the file the rendered pods read it from.
+	defaultTenantKubeconfigKey = "config"
+	// tenantKubeconfigCopyName is the copy of a tenant kubeconfig Secret of
+	// another namespace, mounted by the rendered pods.
+	tenantKubeconfigCopyName = "tenant-kubeconfig"
+	// KubeconfigReaderUser is the user the operator impersonates to read a
+	// tenant kubeconfig Secret of another namespace. It holds no
+	// cluster-wide grant, so the read is only allowed in the namespaces
+	// binding it a Role.
+	KubeconfigReaderUser = "dpu-network-operator-kubeconfig-reader"
+)
+
+//+kubebuilder:rbac:groups="",resources=users,resourceNames=dpu-network-operator-kubeconfig-reader,verbs=impersonate
+
+// NewKubeconfigSecretReader returns the client reading the tenant kubeconfig
+// Secrets of other namespaces as KubeconfigReaderUser, rather than with the
+// cluster-wide read of the operator.
+func NewKubeconfigSecretReader(config *rest.Config, scheme *runtime.Scheme, mapper meta.RESTMapper) (client.Reader, error) {
+	config = rest.CopyConfig(config)
+	config.Impersonate = rest.ImpersonationConfig{UserName: KubeconfigReaderUser}
+	return client.New(config, client.Options{Scheme: scheme, Mapper: mapper})
+}
+
+// tenantKubeconfigSecret returns the name and data key of the Secret of the
+// tenant kubeconfig of cfg, "" when not set.
+func tenantKubeconfigSecret(cfg *dpuv1alpha1.OVNKubeConfig) (string, string) {
+	if ref := cfg.Spec.KubeConfigSecretRef; ref != nil {
+		if ref.Key != "" {
+			return ref.Name, ref.Key
+		}
+		return ref.Name, defaultTenantKubeconfigKey
+	}
+	return cfg.Spec.KubeConfigFile, defaultTenantKubeconfigKey
+}
+
+// tenantKubeconfigName returns the name of the Secret of the tenant
+// kubeconfig of cfg, "" when not set.
+func tenantKubeconfigName(cfg *dpuv1alpha1.OVNKubeConfig) string {
+	name, _ := tenantKubeconfigSecret(cfg)
+	return name
+}
+
+// tenantKubeconfigNamespace returns the namespace of the Secret of the
+// tenant kubeconfig of cfg.
+func tenantKubeconfigNamespace(cfg *dpuv1alpha1.OVNKubeConfig) string {
+	if ref := cfg.Spec.KubeConfigSecretRef; ref != nil && ref.Namespace != "" {
+		return ref.Namespace
+	}
+	return cfg.Namespace
+}
+
+// tenantKubeconfigMount returns the name and data key of the Secret the
+// rendered pods mount the tenant kubeconfig from: the copy of a Secret of
+// another namespace, the Secret itself otherwise.
+func tenantKubeconfigMount(cfg *dpuv1alpha1.OVNKubeConfig) (string, string) {
+	if tenantKubeconfigNamespace(cfg) != cfg.Namespace {
+		return tenantKubeconfigCopyName, defaultTenantKubeconfigKey
+	}
+	return tenantKubeconfigSecret(cfg)
+}
+
+// tenantKubeconfig reads the tenant kubeconfig of cfg and the version of
+// its data: the versionAnnotation of the Secret when set by its secret
+// store, the hash of the kubeconfig otherwise. A Secret of another
+// namespace is read as KubeconfigReaderUser, and only used once shared
+// with the namespace of cfg, since its copy is readable there.
+func (r *OVNKubeConfigReconciler) tenantKubeconfig(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) ([]byte, string, error) {
+	name, key := tenantKubeconfigSecret(cfg)
+	namespace := tenantKubeconfigNamespace(cfg)
+	s := &corev1.Secret{}
+	if namespace != cfg.Namespace {
+		if err := r.SecretReader.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, s); errors.IsForbidden(err) {
+			return nil, "", dpuerrors.SecretReadForbidden(fmt.Errorf("the operator may not read Secret %s/%s: "+
+				"bind a Role granting get on secrets in namespace %s to user %s: %w",
+				namespace, name, namespace, KubeconfigReaderUser, err))
+		} else if err != nil {
+			return nil, "", dpuerrors.InvalidKubeconfig(err)
+		}
+		if !secretSharedWith(s, cfg.Namespace) {
+			return nil, "", dpuerrors.SecretNotShared(fmt.Errorf("Secret %s/%s is not shared with namespace %s: "+
+				"add it to the %s annotation of the Secret", namespace, name, cfg.Namespace, utils.SharedWithAnnotation))
+		}
+	} else if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, s); err != nil {
+		return nil, "", dpuerrors.InvalidKubeconfig(err)
+	}
+	kubeconfig, ok := s.Data[key]
+	if !ok || len(kubeconfig) == 0 {
+		return nil, "", dpuerrors.InvalidKubeconfig(fmt.Errorf("key '%s' cannot be found in secret %s/%s", key, namespace, name))
+	}
+	if ref := cfg.Spec.KubeConfigSecretRef; ref != nil && ref.VersionAnnotation != "" {
+		if version := s.Annotations[ref.VersionAnnotation]; version != "" {
+			return kubeconfig, version, nil
+		}
+	}
+	sum := sha256.Sum256(kubeconfig)
+	return kubeconfig, "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// secretSharedWith reports whether the owner of the Secret listed namespace
+// in its SharedWithAnnotation.
+func secretSharedWith(s *corev1.Secret, namespace string) bool {
+	for _, ns := range strings.Split(s.Annotations[utils.SharedWithAnnotation], ",") {
+		if strings.TrimSpace(ns) == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// tenantRestConfig returns the client configuration of the tenant cluster
+// of cfg, impersonating the tenant identity, and the version of the
+// kubeconfig.
+func (r *OVNKubeConfigReconciler) tenantRestConfig(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) (*rest.Config, string, error) {
+	kubeconfig, version, err := r.tenantKubeconfig(ctx, cfg)
+	if err != nil {
+		return nil, "", err
+	}
+	config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, "", dpuerrors.InvalidKubeconfig(err)
+	}
+	if err := applyTenantImpersonation(config, cfg); err != nil {
+		return nil, "", err
+	}
+	instrumentTenantConfig(config)
+	return config, version, nil
+}
+
+// syncTenantKubeconfigCopy copies a tenant kubeconfig Secret of another
+// namespace into the namespace of cfg, where the rendered pods mount it, or
+// deletes the copy once the Secret is in the namespace of cfg.
+func (r *OVNKubeConfigReconciler) syncTenantKubeconfigCopy(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) error {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: cfg.Namespace, Name: tenantKubeconfigCopyName}}
+	if tenantKubeconfigNamespace(cfg) == cfg.Namespace {
+		if err := r.Get(ctx, client.ObjectKeyFromObject(secret), secret); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if !metav1.IsControlledBy(secret, cfg) {
+			return nil
+		}
+		logger.Info("Delete the copy of the tenant kubeconfig", "namespace", secret.Namespace, "name", secret.Name)
+		return client.IgnoreNotFound(r.Delete(ctx, secret))
+	}
+	kubeconfig, _, err := r.tenantKubeconfig(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	return withApplyRetry(ctx, "Secret", func() error {
+		_, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+			if secret.CreationTimestamp.IsZero() || metav1.IsControlledBy(secret, cfg) {
+				if secret.Labels == nil {
+					secret.Labels = map[string]string{}
+				}
+				secret.Labels[utils.OwnerLabel] = cfg.Namespace
+				secret.Data = map[string][]byte{defaultTenantKubeconfigKey: kubeconfig}
+				return ctrl.SetControllerReference(cfg, secret, r.Scheme)
+			}
+			return dpuerrors.Conflict(fmt.Errorf("Secret %s/%s was not created by the operator", secret.Namespace, secret.Name))
+		})
+		return err
+	})
+}
+
+// secretToOVNKubeConfigs maps a Secret to the OVNKubeConfigs using it as
+// tenant kubeconfig, in its namespace or from another one, so its rotation
+// restarts the syncer and refreshes the copy.
+func (r *OVNKubeConfigReconciler) secretToOVNKubeConfigs(obj client.Object) []reconcile.Request {
+	cfgList := &dpuv1alpha1.OVNKubeConfigList{}
+	if err := r.List(context.TODO(), cfgList); err != nil {
+		logger.Error(err, "failed to list the OVNKubeConfigs")
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for i := range cfgList.Items {
+		cfg := &cfgList.Items[i]
+		if tenantKubeconfigNamespace(cfg) == obj.GetNamespace() && tenantKubeconfigName(cfg) == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name}})
+		}
+	}
+	return requests
+}
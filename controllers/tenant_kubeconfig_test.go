@@ -0,0 +1,125 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openshift/dpu-network-operator/api"
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// secretReader serves a Secret, or the error of its read.
+type secretReader struct {
+	secret *corev1.Secret
+	err    error
+}
+
+func (r *secretReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.secret.DeepCopyInto(obj.(*corev1.Secret))
+	return nil
+}
+
+func (r *secretReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return nil
+}
+
+func TestSecretSharedWith(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        bool
+	}{
+		{name: "no annotation"},
+		{name: "empty", annotations: map[string]string{utils.SharedWithAnnotation: ""}},
+		{name: "listed", annotations: map[string]string{utils.SharedWithAnnotation: "tenant-a"}, want: true},
+		{name: "listed among others", annotations: map[string]string{utils.SharedWithAnnotation: "tenant-b, tenant-a ,tenant-c"}, want: true},
+		{name: "prefix only", annotations: map[string]string{utils.SharedWithAnnotation: "tenant-ab"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			if got := secretSharedWith(s, "tenant-a"); got != tt.want {
+				t.Errorf("secretSharedWith() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTenantKubeconfigOtherNamespace(t *testing.T) {
+	secret := func(sharedWith string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "kubeconfig", Namespace: "kubeconfigs",
+				Annotations: map[string]string{utils.SharedWithAnnotation: sharedWith}},
+			Data: map[string][]byte{defaultTenantKubeconfigKey: []byte("kubeconfig")},
+		}
+	}
+	tests := []struct {
+		name       string
+		reader     *secretReader
+		wantReason string
+	}{
+		{
+			name:       "forbidden",
+			reader:     &secretReader{err: errors.NewForbidden(corev1.Resource("secrets"), "kubeconfig", nil)},
+			wantReason: api.ReasonSecretReadForbidden,
+		},
+		{
+			name:       "not shared",
+			reader:     &secretReader{secret: secret("tenant-b")},
+			wantReason: api.ReasonSecretNotShared,
+		},
+		{
+			name:   "shared",
+			reader: &secretReader{secret: secret("tenant-b,tenant-a")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &dpuv1alpha1.OVNKubeConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "ovnkubeconfig", Namespace: "tenant-a"},
+				Spec: dpuv1alpha1.OVNKubeConfigSpec{
+					KubeConfigSecretRef: &dpuv1alpha1.KubeConfigSecretReference{Name: "kubeconfig", Namespace: "kubeconfigs"},
+				},
+			}
+			r := &OVNKubeConfigReconciler{SecretReader: tt.reader}
+			kubeconfig, _, err := r.tenantKubeconfig(context.Background(), cfg)
+			if tt.wantReason != "" {
+				if got := dpuerrors.Reason(err, ""); got != tt.wantReason {
+					t.Fatalf("reason = %q, want %q (error %v)", got, tt.wantReason, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("tenantKubeconfig() error = %v", err)
+			}
+			if string(kubeconfig) != "kubeconfig" {
+				t.Errorf("tenantKubeconfig() = %q, want the Secret data", kubeconfig)
+			}
+		})
+	}
+}
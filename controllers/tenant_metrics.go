@@ -0,0 +1,82 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	tenantRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dpu_network_operator_tenant_request_duration_seconds",
+		Help:    "Latency of the requests to the API server of the tenant cluster, watches excluded, by verb and status code.",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 12),
+	}, []string{"verb", "code"})
+	tenantRequestErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dpu_network_operator_tenant_request_errors_total",
+		Help: "Number of requests to the API server of the tenant cluster which failed, by verb and reason.",
+	}, []string{"verb", "reason"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(tenantRequestDuration, tenantRequestErrors)
+}
+
+// instrumentTenantConfig makes the clients built from config report their
+// requests in the tenant request metrics. The client-go metrics don't tell
+// the tenant cluster from the infra one.
+func instrumentTenantConfig(config *rest.Config) {
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &tenantMetricsRoundTripper{rt: rt}
+	})
+}
+
+type tenantMetricsRoundTripper struct {
+	rt http.RoundTripper
+}
+
+func (t *tenantMetricsRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.rt.RoundTrip(req)
+	verb := req.Method
+	if req.URL.Query().Get("watch") == "true" {
+		verb = "WATCH"
+	}
+	code := "<error>"
+	switch {
+	case err != nil:
+		tenantRequestErrors.WithLabelValues(verb, "transport").Inc()
+	case resp.StatusCode == http.StatusTooManyRequests:
+		tenantRequestErrors.WithLabelValues(verb, "throttled").Inc()
+	case resp.StatusCode >= http.StatusInternalServerError:
+		tenantRequestErrors.WithLabelValues(verb, "server").Inc()
+	}
+	if resp != nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	// a watch lasts until it times out
+	if verb != "WATCH" {
+		tenantRequestDuration.WithLabelValues(verb, code).Observe(time.Since(start).Seconds())
+	}
+	return resp, err
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+// defaultTenantOvnNamespaces are the namespaces OVN-Kubernetes is usually
+// deployed in, searched after TENANT_NAMESPACE.
+var defaultTenantOvnNamespaces = []string{"openshift-ovn-kubernetes", "ovn-kubernetes"}
+
+// tenantOvnNamespace returns the namespace of OVN-Kubernetes in the tenant
+// cluster: the one set in the spec, else the one synced by the running
+// tenant syncer, else the one last detected by the tenant-sync controller,
+// else it is detected.
+func (r *OVNKubeConfigReconciler) tenantOvnNamespace(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) (string, error) {
+	if ns := specTenantOvnNamespace(cfg); ns != "" {
+		return ns, nil
+	}
+	if running := r.tenantSyncer(cfg.Namespace); running != nil {
+		return running.tenantNamespace, nil
+	}
+	if cfg.Status.TenantOvnNamespace != "" {
+		return cfg.Status.TenantOvnNamespace, nil
+	}
+	tenantConfig := r.connectedTenantConfig(cfg.Namespace)
+	if tenantConfig == nil {
+		return "", fmt.Errorf("the tenant cluster is not connected yet")
+	}
+	return r.detectTenantOvnNamespace(ctx, tenantConfig, cfg)
+}
+
+// detectTenantOvnNamespace validates the namespace set in the spec, or
+// searches the candidate namespaces, by looking up the ovnkube-config
+// ConfigMap in the tenant cluster. Namespaces the tenant kubeconfig may not
+// read are skipped, since it is usually scoped to a single namespace.
+func (r *OVNKubeConfigReconciler) detectTenantOvnNamespace(ctx context.Context, config *rest.Config, cfg *dpuv1alpha1.OVNKubeConfig) (string, error) {
+	c, err := client.New(config, client.Options{})
+	if err != nil {
+		return "", dpuerrors.TenantUnreachable(err)
+	}
+	candidates := tenantOvnNamespaceCandidates(cfg)
+	for _, ns := range candidates {
+		cm := &corev1.ConfigMap{}
+		err := c.Get(ctx, types.NamespacedName{Namespace: ns, Name: utils.CmNameOvnkubeConfig}, cm)
+		if err == nil {
+			return ns, nil
+		} else if !errors.IsNotFound(err) && !errors.IsForbidden(err) {
+			return "", dpuerrors.TenantUnreachable(err)
+		}
+	}
+	return "", fmt.Errorf("ConfigMap %s is not found in namespaces %s of the tenant cluster", utils.CmNameOvnkubeConfig, strings.Join(candidates, ", "))
+}
+
+// specTenantOvnNamespace returns the namespace of OVN-Kubernetes set in the
+// spec of cfg, "" when it is detected.
+func specTenantOvnNamespace(cfg *dpuv1alpha1.OVNKubeConfig) string {
+	if cfg.Spec.Tenant == nil {
+		return ""
+	}
+	return cfg.Spec.Tenant.OvnNamespace
+}
+
+// tenantOvnNamespaceCandidates lists the namespaces searched for
+// OVN-Kubernetes, or only the one set in the spec.
+func tenantOvnNamespaceCandidates(cfg *dpuv1alpha1.OVNKubeConfig) []string {
+	if ns := specTenantOvnNamespace(cfg); ns != "" {
+		return []string{ns}
+	}
+	candidates := []string{}
+	if utils.TenantNamespace != "" {
+		candidates = append(candidates, utils.TenantNamespace)
+	}
+	for _, ns := range defaultTenantOvnNamespaces {
+		if ns != utils.TenantNamespace {
+			candidates = append(candidates, ns)
+		}
+	}
+	return candidates
+}
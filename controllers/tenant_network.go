@@ -0,0 +1,84 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+)
+
+const (
+	ovnKubernetesNetworkType = "OVNKubernetes"
+
+	// tenantNetworkRequeueInterval is how often the network type of the
+	// tenant cluster is checked again while it is not supported, since its
+	// changes are not watched.
+	tenantNetworkRequeueInterval = 5 * time.Minute
+)
+
+var networkConfigGVK = schema.GroupVersionKind{Group: "config.openshift.io", Version: "v1", Kind: "Network"}
+
+// checkTenantNetworkType records the network plugin of the tenant cluster in
+// the status, and fails if it is not the one of the data plane provider,
+// rather than rendering a data plane with no control plane to connect to.
+// Tenants without the config.openshift.io API, or a kubeconfig which may not
+// read it, are assumed to match.
+func (r *OVNKubeConfigReconciler) checkTenantNetworkType(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) error {
+	networkType, err := tenantNetworkType(ctx, r.connectedTenantClient(cfg.Namespace))
+	if err != nil {
+		return err
+	}
+	cfg.Status.TenantNetworkType = networkType
+	if want := r.dataPlane(cfg).networkType(); networkType != "" && networkType != want {
+		return dpuerrors.UnsupportedNetworkType(fmt.Errorf("the tenant cluster runs the %s network plugin, the data plane of the DPUs requires %s", networkType, want))
+	}
+	return nil
+}
+
+// tenantNetworkType returns the network type of the cluster Network config
+// read with the tenant client c, or "" if it cannot be read or c is nil.
+func tenantNetworkType(ctx context.Context, c client.Client) (string, error) {
+	if c == nil {
+		return "", nil
+	}
+	network := &unstructured.Unstructured{}
+	network.SetGroupVersionKind(networkConfigGVK)
+	err := c.Get(ctx, types.NamespacedName{Name: "cluster"}, network)
+	if meta.IsNoMatchError(err) || errors.IsNotFound(err) || errors.IsForbidden(err) {
+		return "", nil
+	} else if err != nil {
+		return "", dpuerrors.TenantUnreachable(err)
+	}
+	// the status reports the plugin actually deployed, the spec the one
+	// requested, e.g. during a migration
+	if networkType, _, _ := unstructured.NestedString(network.Object, "status", "networkType"); networkType != "" {
+		return networkType, nil
+	}
+	networkType, _, _ := unstructured.NestedString(network.Object, "spec", "networkType")
+	return networkType, nil
+}
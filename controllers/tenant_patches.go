@@ -0,0 +1,53 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	syncer "github.com/openshift/dpu-network-operator/pkg/ovnkube-syncer"
+)
+
+// tenantObjectPatches returns the TenantObjectPatches of the OVNKubeConfig
+// of the namespace, read from the cache when the syncer transforms a tenant
+// object.
+func (r *OVNKubeConfigReconciler) tenantObjectPatches(namespace string) func() ([]dpuv1alpha1.TenantObjectPatch, error) {
+	return func() ([]dpuv1alpha1.TenantObjectPatch, error) {
+		cfg, err := r.getNamespaceConfig(context.Background(), namespace)
+		if err != nil {
+			return nil, err
+		}
+		if cfg == nil {
+			return nil, fmt.Errorf("no OVNKubeConfig in namespace %s", namespace)
+		}
+		return cfg.Spec.TenantObjectPatches, nil
+	}
+}
+
+// validateTenantObjectPatches checks that every TenantObjectPatch is a
+// well-formed JSON Patch. Patches failing to apply are only reported in the
+// operator logs, since they depend on the content of the tenant objects.
+func validateTenantObjectPatches(cfg *dpuv1alpha1.OVNKubeConfig) error {
+	for i, p := range cfg.Spec.TenantObjectPatches {
+		if err := syncer.ValidateJSONPatch(p.Patch); err != nil {
+			return fmt.Errorf("tenantObjectPatches[%d] of %s %s is not a valid JSON Patch: %v", i, p.Kind, p.Name, err)
+		}
+	}
+	return nil
+}
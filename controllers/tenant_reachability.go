@@ -0,0 +1,45 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+)
+
+const (
+	// tenantReachabilityRequeueInterval is how often the tenant API server is
+	// probed while it is unreachable.
+	tenantReachabilityRequeueInterval = 30 * time.Second
+	tenantReachabilityTimeout         = 10 * time.Second
+)
+
+// checkTenantReachable probes the tenant API server of cfg. It returns false
+// if its tenant syncer is not started yet, as there is nothing to probe.
+func (r *OVNKubeConfigReconciler) checkTenantReachable(cfg *dpuv1alpha1.OVNKubeConfig) (bool, error) {
+	running := r.tenantSyncer(cfg.Namespace)
+	if running == nil {
+		return false, nil
+	}
+	if _, err := running.discovery.ServerVersion(); err != nil {
+		return true, dpuerrors.TenantUnreachable(fmt.Errorf("tenant cluster is not reachable: %v", err))
+	}
+	return true, nil
+}
@@ -0,0 +1,307 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+	syncer "github.com/openshift/dpu-network-operator/pkg/ovnkube-syncer"
+)
+
+// tenantSyncerStartTimeout bounds the wait for the informers of a new
+// syncer to sync, before it replaces the running one.
+const tenantSyncerStartTimeout = time.Minute
+
+// runningSyncer is a started tenant syncer and the tenant kubeconfig it
+// runs with.
+type runningSyncer struct {
+	syncer *syncer.OvnkubeSyncer
+	stopCh chan struct{}
+	config *rest.Config
+	// tenantClient and kubeClient read the tenant cluster with config, for
+	// as long as the syncer runs, and discovery probes its API server.
+	tenantClient client.Client
+	kubeClient   kubernetes.Interface
+	discovery    discovery.DiscoveryInterface
+	// secretName, as namespace/name, and secretKey identify the tenant
+	// kubeconfig.
+	secretName string
+	secretKey  string
+	// version is the version of the tenant kubeconfig.
+	version       string
+	impersonation rest.ImpersonationConfig
+	// ovnNamespace is the namespace of OVN-Kubernetes set in the spec when
+	// the syncer started, "" when it was detected, and tenantNamespace the
+	// one it syncs.
+	ovnNamespace    string
+	tenantNamespace string
+}
+
+func (s *runningSyncer) stop() {
+	close(s.stopCh)
+}
+
+// tenantSyncers holds the running tenant syncer of each OVNKubeConfig,
+// keyed by its namespace. A syncer is published and replaced as a whole,
+// so the readers, e.g. the parallel workers of the other controllers, get
+// a consistent snapshot of the syncer and of its config.
+type tenantSyncers struct {
+	lock    sync.RWMutex
+	running map[string]*runningSyncer
+}
+
+// get returns the running syncer of namespace, nil if none.
+func (s *tenantSyncers) get(namespace string) *runningSyncer {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.running[namespace]
+}
+
+// swap publishes next as the syncer of namespace, or removes it when next
+// is nil, and returns the syncer it replaces, nil if none.
+func (s *tenantSyncers) swap(namespace string, next *runningSyncer) *runningSyncer {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	previous := s.running[namespace]
+	if next == nil {
+		delete(s.running, namespace)
+	} else {
+		if s.running == nil {
+			s.running = map[string]*runningSyncer{}
+		}
+		s.running[namespace] = next
+	}
+	return previous
+}
+
+// tenantSyncer returns the running tenant syncer of the OVNKubeConfig of
+// namespace, nil until it is started.
+func (r *OVNKubeConfigReconciler) tenantSyncer(namespace string) *runningSyncer {
+	return r.syncers.get(namespace)
+}
+
+// connectedTenantConfig returns the config of the tenant cluster of the
+// OVNKubeConfig of namespace, the one its running syncer was started with,
+// nil until it is started.
+func (r *OVNKubeConfigReconciler) connectedTenantConfig(namespace string) *rest.Config {
+	if running := r.tenantSyncer(namespace); running != nil {
+		return running.config
+	}
+	return nil
+}
+
+// connectedTenantClient returns the client of the running syncer of the
+// OVNKubeConfig of namespace, nil until it is started.
+func (r *OVNKubeConfigReconciler) connectedTenantClient(namespace string) client.Client {
+	if running := r.tenantSyncer(namespace); running != nil {
+		return running.tenantClient
+	}
+	return nil
+}
+
+// connectedTenantKubeClient returns the clientset of the running syncer of
+// the OVNKubeConfig of namespace, nil until it is started.
+func (r *OVNKubeConfigReconciler) connectedTenantKubeClient(namespace string) kubernetes.Interface {
+	if running := r.tenantSyncer(namespace); running != nil {
+		return running.kubeClient
+	}
+	return nil
+}
+
+// TenantRestConfigForNode returns the config of the tenant cluster of the
+// OVNKubeConfig whose DPU nodes include node, nil when none does or its
+// syncer doesn't run.
+func (r *OVNKubeConfigReconciler) TenantRestConfigForNode(ctx context.Context, node *corev1.Node) *rest.Config {
+	cfgList := &dpuv1alpha1.OVNKubeConfigList{}
+	if err := r.List(ctx, cfgList); err != nil {
+		logger.Error(err, "failed to list OVNKubeConfigs")
+		return nil
+	}
+	for i := range cfgList.Items {
+		cfg := &cfgList.Items[i]
+		config := r.connectedTenantConfig(cfg.Namespace)
+		if config == nil {
+			continue
+		}
+		nodeSelector, err := r.poolNodeSelector(ctx, cfg)
+		if err != nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(nodeSelector)
+		if err == nil && selector.Matches(labels.Set(node.Labels)) {
+			return config
+		}
+	}
+	return nil
+}
+
+// tenantSyncerOutdated returns why the running syncer doesn't match the
+// spec of cfg and the tenant kubeconfig anymore, "" while it does. A Secret
+// missing or invalid while its secret store rotates it keeps the running
+// syncer.
+func (r *OVNKubeConfigReconciler) tenantSyncerOutdated(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, running *runningSyncer) string {
+	name, key := tenantKubeconfigSecret(cfg)
+	name = tenantKubeconfigNamespace(cfg) + "/" + name
+	if name != running.secretName || key != running.secretKey {
+		return fmt.Sprintf("the tenant kubeconfig moved from %s/%s to %s/%s", running.secretName, running.secretKey, name, key)
+	}
+	if !equality.Semantic.DeepEqual(running.impersonation, tenantImpersonation(cfg)) {
+		return "the tenant impersonation changed"
+	}
+	if ns := specTenantOvnNamespace(cfg); ns != running.ovnNamespace {
+		return fmt.Sprintf("the OVN namespace of the tenant moved from %q to %q", running.ovnNamespace, ns)
+	}
+	_, version, err := r.tenantKubeconfig(ctx, cfg)
+	if err != nil {
+		logger.Error(err, "failed to read the tenant kubeconfig, keep the running syncer")
+		return ""
+	}
+	if version != running.version {
+		return "the tenant kubeconfig changed"
+	}
+	return ""
+}
+
+// startTenantSyncer builds a syncer from the tenant kubeconfig of cfg and
+// starts it, returning once its informers are synced. The running syncer
+// is left untouched, so it keeps syncing when the new one fails.
+func (r *OVNKubeConfigReconciler) startTenantSyncer(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) (*runningSyncer, error) {
+	logger.Info("Start the tenant syncer")
+	tenantConfig, version, err := r.tenantRestConfig(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	// the outcome is reported even when the check fails
+	if err := checkTenantEndpoint(ctx, tenantConfig, &cfg.Status); err != nil {
+		return nil, err
+	}
+	if err := checkTenantImpersonation(ctx, tenantConfig); err != nil {
+		return nil, err
+	}
+
+	tenantNamespace, err := r.detectTenantOvnNamespace(ctx, tenantConfig, cfg)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Found OVN-Kubernetes in the tenant cluster", "namespace", tenantNamespace)
+	if err := checkTenantAccess(ctx, tenantConfig, tenantNamespace); err != nil {
+		return nil, err
+	}
+
+	tenantClient, err := client.New(tenantConfig, client.Options{})
+	if err != nil {
+		return nil, dpuerrors.TenantUnreachable(err)
+	}
+	kubeClient, err := kubernetes.NewForConfig(tenantConfig)
+	if err != nil {
+		return nil, dpuerrors.TenantUnreachable(err)
+	}
+	probeConfig := rest.CopyConfig(tenantConfig)
+	probeConfig.Timeout = tenantReachabilityTimeout
+	dc, err := discovery.NewDiscoveryClientForConfig(probeConfig)
+	if err != nil {
+		return nil, dpuerrors.InvalidKubeconfig(err)
+	}
+
+	s, err := syncer.New(syncer.SyncerConfig{
+		// LocalClusterID:   cfg.Namespace,
+		LocalRestConfig:  ctrl.GetConfigOrDie(),
+		LocalNamespace:   cfg.Namespace,
+		TenantRestConfig: tenantConfig,
+		TenantNamespace:  tenantNamespace,
+		Transforms:       []syncer.ObjectTransform{syncer.JSONPatchTransform(r.tenantObjectPatches(cfg.Namespace))}}, cfg, r.Scheme)
+	if err != nil {
+		return nil, dpuerrors.TenantUnreachable(err)
+	}
+	name, key := tenantKubeconfigSecret(cfg)
+	running := &runningSyncer{
+		syncer:          s,
+		stopCh:          make(chan struct{}),
+		config:          tenantConfig,
+		tenantClient:    tenantClient,
+		kubeClient:      kubeClient,
+		discovery:       dc,
+		secretName:      tenantKubeconfigNamespace(cfg) + "/" + name,
+		secretKey:       key,
+		version:         version,
+		impersonation:   tenantConfig.Impersonate,
+		ovnNamespace:    specTenantOvnNamespace(cfg),
+		tenantNamespace: tenantNamespace,
+	}
+	// Start returns once the informers are synced, or with an error once
+	// stopCh is closed
+	started := make(chan error, 1)
+	go func() {
+		started <- s.Start(ctx, running.stopCh)
+	}()
+	select {
+	case err = <-started:
+	case <-time.After(tenantSyncerStartTimeout):
+		err = fmt.Errorf("the informers are not synced after %s", tenantSyncerStartTimeout)
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		running.stop()
+		return nil, dpuerrors.TenantUnreachable(fmt.Errorf("failed to start the tenant syncer: %w", err))
+	}
+	r.watchTenantMasterPods(ctx, tenantConfig, tenantNamespace, cfg, running.stopCh)
+	// written by the deferred status update of the tenant-sync controller
+	cfg.Status.TenantOvnNamespace = tenantNamespace
+	cfg.Status.TenantKubeconfigVersion = version
+	return running, nil
+}
+
+// swapTenantSyncer cuts over to the started syncer next of namespace, then
+// stops the previous one. Both run side by side until then, so the synced
+// objects are never left without a syncer; a late write of the previous
+// syncer is overwritten by the next resync of next. The syncer and its
+// config are published together, so a reader of the registry never sees
+// the config of one with the other.
+func (r *OVNKubeConfigReconciler) swapTenantSyncer(namespace string, next *runningSyncer) {
+	previous := r.syncers.swap(namespace, next)
+	if previous != nil {
+		logger.Info("Stop the previous ovnkube syncer", "namespace", namespace)
+		previous.stop()
+	}
+}
+
+// stopTenantSyncer unpublishes the running syncer of namespace, if any,
+// then stops it.
+func (r *OVNKubeConfigReconciler) stopTenantSyncer(namespace, reason string) {
+	running := r.syncers.swap(namespace, nil)
+	if running == nil {
+		return
+	}
+	logger.Info("Stop the ovnkube syncer", "namespace", namespace, "reason", reason)
+	running.stop()
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+)
+
+func TestTenantSyncersSwap(t *testing.T) {
+	first, second := &runningSyncer{version: "1"}, &runningSyncer{version: "2"}
+	syncers := tenantSyncers{}
+	for _, step := range []struct {
+		namespace string
+		next      *runningSyncer
+		previous  *runningSyncer
+	}{
+		{namespace: "tenant-a", next: first},
+		{namespace: "tenant-a", next: second, previous: first},
+		{namespace: "tenant-b", next: first},
+		{namespace: "tenant-a", previous: second},
+		{namespace: "tenant-a"},
+	} {
+		if previous := syncers.swap(step.namespace, step.next); previous != step.previous {
+			t.Errorf("swap(%s, %v) replaced %v, want %v", step.namespace, step.next, previous, step.previous)
+		}
+		if got := syncers.get(step.namespace); got != step.next {
+			t.Errorf("get(%s) = %v, want %v", step.namespace, got, step.next)
+		}
+	}
+	if got := syncers.get("tenant-b"); got != first {
+		t.Errorf("get(tenant-b) = %v, want %v", got, first)
+	}
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+)
+
+const (
+	// tenantTriggerBuffer bounds the OVNKubeConfigs waiting for the
+	// controller to read their trigger.
+	tenantTriggerBuffer = 64
+	// tenantTriggerDelay is the window the triggers of an OVNKubeConfig are
+	// coalesced in, e.g. during a rollout of the ovnkube-master pods.
+	tenantTriggerDelay = 2 * time.Second
+)
+
+var tenantTriggersTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "dpu_network_operator_tenant_triggers_total",
+	Help: "Number of tenant events triggering a re-render, by result: queued, coalesced with a pending one, or dropped on a full queue.",
+}, []string{"result"})
+
+func init() {
+	metrics.Registry.MustRegister(tenantTriggersTotal)
+}
+
+// tenantTriggers funnels the events of the tenant cluster into the
+// workqueue of the workload controller through a bounded channel, so a
+// burst, e.g. of ovnkube-master pod churn, re-renders each OVNKubeConfig once
+// instead of once per event. An OVNKubeConfig already waiting in the channel
+// or in the delay of the workqueue is not queued again.
+type tenantTriggers struct {
+	events chan event.GenericEvent
+
+	lock    sync.Mutex
+	pending map[types.NamespacedName]bool
+}
+
+func newTenantTriggers() *tenantTriggers {
+	return &tenantTriggers{
+		events:  make(chan event.GenericEvent, tenantTriggerBuffer),
+		pending: map[types.NamespacedName]bool{},
+	}
+}
+
+// trigger queues a re-render of the OVNKubeConfig key. It never blocks the
+// caller, e.g. an informer of the tenant cluster: a trigger is dropped on a
+// full channel, the periodic reconciles catch up.
+func (t *tenantTriggers) trigger(key types.NamespacedName) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.pending[key] {
+		tenantTriggersTotal.WithLabelValues("coalesced").Inc()
+		return
+	}
+	cfg := &dpuv1alpha1.OVNKubeConfig{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}
+	select {
+	case t.events <- event.GenericEvent{Object: cfg}:
+		t.pending[key] = true
+		tenantTriggersTotal.WithLabelValues("queued").Inc()
+	default:
+		tenantTriggersTotal.WithLabelValues("dropped").Inc()
+	}
+}
+
+// source returns the source of the triggers, to be watched with handler.
+func (t *tenantTriggers) source() source.Source {
+	return &source.Channel{Source: t.events}
+}
+
+// handler adds the OVNKubeConfig of a trigger to the workqueue after
+// tenantTriggerDelay, the next triggers of the window being merged into it
+// by the workqueue.
+func (t *tenantTriggers) handler() handler.EventHandler {
+	return handler.Funcs{
+		GenericFunc: func(e event.GenericEvent, q workqueue.RateLimitingInterface) {
+			key := types.NamespacedName{Namespace: e.Object.GetNamespace(), Name: e.Object.GetName()}
+			t.lock.Lock()
+			delete(t.pending, key)
+			t.lock.Unlock()
+			q.AddAfter(reconcile.Request{NamespacedName: key}, tenantTriggerDelay)
+		},
+	}
+}
+
+// watchTenantMasterPods triggers a re-render of cfg when an ovnkube-master
+// pod of the tenant cluster comes, goes or changes its address or image,
+// which the rendered DB endpoints and image follow, until stopCh is closed.
+// The watch is skipped when the tenant kubeconfig may not watch the pods,
+// the periodic reconciles still pick the changes up.
+func (r *OVNKubeConfigReconciler) watchTenantMasterPods(ctx context.Context, config *rest.Config, namespace string, cfg *dpuv1alpha1.OVNKubeConfig, stopCh <-chan struct{}) {
+	if allowed, err := tenantAccessReview(ctx, config, namespace, "watch", "pods", ""); err != nil || !allowed {
+		logger.Info("The tenant kubeconfig may not watch the ovnkube-master pods, relying on the periodic reconciles", "namespace", namespace, "error", err)
+		return
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		logger.Error(err, "failed to watch the ovnkube-master pods of the tenant cluster")
+		return
+	}
+	key := types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name}
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0, informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = "app=ovnkube-master"
+		}))
+	factory.Core().V1().Pods().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(interface{}) { r.tenantTriggers.trigger(key) },
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldPod, ok1 := oldObj.(*corev1.Pod)
+			newPod, ok2 := newObj.(*corev1.Pod)
+			if !ok1 || !ok2 || masterPodChanged(oldPod, newPod) {
+				r.tenantTriggers.trigger(key)
+			}
+		},
+		DeleteFunc: func(interface{}) { r.tenantTriggers.trigger(key) },
+	})
+	factory.Start(stopCh)
+}
+
+// masterPodChanged reports whether the update of an ovnkube-master pod
+// changes what is rendered from it.
+func masterPodChanged(oldPod, newPod *corev1.Pod) bool {
+	if oldPod.Status.PodIP != newPod.Status.PodIP || oldPod.Status.Phase != newPod.Status.Phase {
+		return true
+	}
+	if (oldPod.DeletionTimestamp == nil) != (newPod.DeletionTimestamp == nil) {
+		return true
+	}
+	return !equality.Semantic.DeepEqual(oldPod.Spec.Containers, newPod.Spec.Containers)
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/openshift/dpu-network-operator/api"
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+	"github.com/openshift/dpu-network-operator/pkg/tracing"
+)
+
+// tenantSyncConditions are the conditions owned by the tenant-sync
+// controller.
+var tenantSyncConditions = []string{api.TenantObjsSynced}
+
+// copyTenantSyncStatus copies the status fields owned by the tenant-sync
+// controller.
+func copyTenantSyncStatus(dst, src *dpuv1alpha1.OVNKubeConfigStatus) {
+	if src.TenantOvnNamespace != "" {
+		dst.TenantOvnNamespace = src.TenantOvnNamespace
+	}
+	if src.TenantEndpoint != nil {
+		dst.TenantEndpoint = src.TenantEndpoint
+	}
+	if src.TenantKubeconfigVersion != "" {
+		dst.TenantKubeconfigVersion = src.TenantKubeconfigVersion
+	}
+}
+
+// reconcileTenantSync runs the syncer copying the ovnkube ConfigMaps and
+// Secrets of the tenant cluster, and stops it once the OVNKubeConfig is
+// deleted, after removing the objects created in the tenant cluster.
+func (r *OVNKubeConfigReconciler) reconcileTenantSync(ctx context.Context, req ctrl.Request) (result ctrl.Result, reterr error) {
+	ctx, span := tracing.Start(ctx, "reconcile tenant-sync", "namespace", req.Namespace, "name", req.Name)
+	defer func() {
+		span.RecordError(reterr)
+		span.End()
+	}()
+	logger := log.FromContext(ctx).WithValues("reconcile OVNKubeConfig tenant sync", req.NamespacedName)
+	logger.Info("Reconcile")
+
+	ovnkubeConfig, err := r.getNamespaceConfig(ctx, req.Namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if ovnkubeConfig == nil {
+		r.stopTenantSyncer(req.Namespace, "the OVNKubeConfig is not found")
+		return ctrl.Result{}, nil
+	}
+	if delegated(ovnkubeConfig) {
+		// the syncer runs for the managed copy; the tenant objects created
+		// before spec.targetNamespace was set are still removed
+		if !ovnkubeConfig.DeletionTimestamp.IsZero() {
+			return r.finalizeTenantObjects(ctx, ovnkubeConfig)
+		}
+		logger.Info("Skip the tenant sync", "reason", "rendered in spec.targetNamespace")
+		r.stopTenantSyncer(ovnkubeConfig.Namespace, "rendered in spec.targetNamespace")
+		return ctrl.Result{}, nil
+	}
+	start := time.Now()
+	defer func() {
+		recordReconcile(ovnkubeConfig, tenantSyncControllerName, start, reterr)
+		if err := r.updateStatus(ctx, ovnkubeConfig, tenantSyncConditions, copyReconcileStatus(tenantSyncControllerName, copyTenantSyncStatus)); err != nil {
+			logger.Error(err, "unable to update OVNKubeConfig status")
+		}
+	}()
+
+	if !ovnkubeConfig.DeletionTimestamp.IsZero() {
+		r.stopTenantSyncer(ovnkubeConfig.Namespace, "the OVNKubeConfig is deleted")
+		return r.finalizeTenantObjects(ctx, ovnkubeConfig)
+	}
+	if tenantKubeconfigName(ovnkubeConfig) == "" {
+		logger.Info("kubeconfig of tenant cluster is not provided")
+		return ctrl.Result{}, nil
+	}
+	if err := r.addTenantCleanupFinalizer(ctx, ovnkubeConfig); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.syncNamespaceRoleBinding(ctx, ovnkubeConfig); err != nil {
+		return ctrl.Result{}, err
+	}
+	running := r.tenantSyncer(ovnkubeConfig.Namespace)
+	reason := "no syncer is running"
+	if running != nil {
+		reason = r.tenantSyncerOutdated(ctx, ovnkubeConfig, running)
+	}
+	if reason != "" {
+		logger.Info("Create the tenant syncer", "reason", reason)
+		syncerCtx, syncerSpan := tracing.Start(ctx, "start tenant syncer")
+		next, err := r.startTenantSyncer(syncerCtx, ovnkubeConfig)
+		syncerSpan.RecordError(err)
+		syncerSpan.End()
+		if err != nil {
+			if running != nil {
+				logger.Error(err, "failed to start the new tenant syncer, keep the running syncer")
+			}
+			meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).NotTenantObjsSynced().Reason(dpuerrors.Reason(err, api.ReasonFailedStart)).Msg(err.Error()).Build())
+			return ctrl.Result{}, err
+		}
+		r.swapTenantSyncer(ovnkubeConfig.Namespace, next)
+		running = next
+	}
+	if err := validateTenantObjectPatches(ovnkubeConfig); err != nil {
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).NotTenantObjsSynced().Reason(api.ReasonInvalidPatch).Msg(err.Error()).Build())
+		return ctrl.Result{}, nil
+	}
+	if err := r.syncTenantDevicePlugin(ctx, running.config, ovnkubeConfig); err != nil {
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).NotTenantObjsSynced().Reason(dpuerrors.Reason(err, api.ReasonFailedCreated)).Msg(err.Error()).Build())
+		return ctrl.Result{}, err
+	}
+	// the synced objects are owned by the OVNKubeConfig, so their creation
+	// triggers a new reconcile
+	if err := r.isTenantObjsSynced(ctx, ovnkubeConfig); err != nil {
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).NotTenantObjsSynced().Reason(dpuerrors.Reason(err, api.ReasonNotFound)).Msg(err.Error()).Build())
+	} else {
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).TenantObjsSynced().Reason(api.ReasonCreated).Build())
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *OVNKubeConfigReconciler) setupTenantSyncController(mgr ctrl.Manager) error {
+	trigger := recordTrigger(r.Scheme, tenantSyncControllerName)
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(tenantSyncControllerName).
+		// each OVNKubeConfig has its own syncer, and the workers never
+		// reconcile the same OVNKubeConfig at once
+		WithOptions(workerOptions(r.MaxConcurrentReconciles)).
+		For(&dpuv1alpha1.OVNKubeConfig{}, builder.WithPredicates(configChanged, trigger)).
+		Owns(&corev1.ConfigMap{}, builder.WithPredicates(ownedDataChanged, trigger)).
+		Owns(&corev1.Secret{}, builder.WithPredicates(ownedDataChanged, trigger)).
+		Watches(&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(r.secretToOVNKubeConfigs),
+			builder.WithPredicates(trigger)).
+		Complete(isolateReconcile(tenantSyncControllerName, r.reconcileTenantSync))
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+)
+
+const defaultGatewayUplink = "p0"
+
+// sriovInterface is an entry of /etc/sriov_config.json, read by the
+// switchdev configuration of the DPU nodes.
+type sriovInterface struct {
+	PCIAddress  string `json:"pciAddress"`
+	Name        string `json:"name"`
+	ESwitchMode string `json:"eSwitchMode"`
+}
+
+// defaultSriovInterfaces is the first PF of a BlueField-2, used when
+// spec.uplinks is not set.
+var defaultSriovInterfaces = []sriovInterface{{PCIAddress: "0000:03:00.0", Name: "enp3s0f0", ESwitchMode: eswitchModeSwitchdev}}
+
+// uplinkRole returns the role of the uplink, Secondary if not set.
+func uplinkRole(uplink dpuv1alpha1.Uplink) string {
+	if uplink.Role == "" {
+		return dpuv1alpha1.UplinkRoleSecondary
+	}
+	return uplink.Role
+}
+
+// validateUplinks checks that the uplinks are unique and that the gateway
+// is a single uplink, or the bonded ones.
+func validateUplinks(cfg *dpuv1alpha1.OVNKubeConfig) error {
+	if len(cfg.Spec.Uplinks) == 0 {
+		return nil
+	}
+	names := map[string]bool{}
+	addresses := map[string]bool{}
+	gateways := []string{}
+	for _, uplink := range cfg.Spec.Uplinks {
+		if names[uplink.Name] {
+			return dpuerrors.InvalidUplinks(fmt.Errorf("uplink %s is listed twice in spec.uplinks", uplink.Name))
+		}
+		address := strings.ToLower(uplink.PCIAddress)
+		if addresses[address] {
+			return dpuerrors.InvalidUplinks(fmt.Errorf("PCI address %s is listed twice in spec.uplinks", uplink.PCIAddress))
+		}
+		names[uplink.Name] = true
+		addresses[address] = true
+		if uplinkRole(uplink) == dpuv1alpha1.UplinkRoleGateway {
+			gateways = append(gateways, uplink.Name)
+		}
+	}
+	if cfg.Spec.UplinkBond == nil {
+		if len(gateways) != 1 {
+			return dpuerrors.InvalidUplinks(fmt.Errorf("spec.uplinks must have one Gateway uplink, found %d", len(gateways)))
+		}
+		return nil
+	}
+	bonded := append([]string{}, cfg.Spec.UplinkBond.Interfaces...)
+	sort.Strings(bonded)
+	sort.Strings(gateways)
+	if strings.Join(bonded, " ") != strings.Join(gateways, " ") {
+		return dpuerrors.InvalidUplinks(fmt.Errorf("the Gateway uplinks of spec.uplinks [%s] must be the interfaces of spec.uplinkBond [%s]",
+			strings.Join(gateways, " "), strings.Join(bonded, " ")))
+	}
+	return nil
+}
+
+// addUplinkRenderData adds the uplinks to the render data of the
+// MachineConfig: the PFs put in switchdev mode, the gateway first, the
+// uplink of br-ex and the secondary uplinks getting a bridge of their own.
+func addUplinkRenderData(data map[string]interface{}, cfg *dpuv1alpha1.OVNKubeConfig) error {
+	interfaces := defaultSriovInterfaces
+	gateway := defaultGatewayUplink
+	secondaries := []string{}
+	if len(cfg.Spec.Uplinks) > 0 {
+		interfaces = []sriovInterface{}
+		for _, uplink := range cfg.Spec.Uplinks {
+			iface := sriovInterface{PCIAddress: strings.ToLower(uplink.PCIAddress), Name: uplink.Name, ESwitchMode: eswitchModeSwitchdev}
+			if uplinkRole(uplink) == dpuv1alpha1.UplinkRoleGateway {
+				// ovs-add-pf.sh attaches the PF representor of the first
+				// interface to br-ex
+				interfaces = append([]sriovInterface{iface}, interfaces...)
+				gateway = uplink.Name
+			} else {
+				interfaces = append(interfaces, iface)
+				secondaries = append(secondaries, uplink.Name)
+			}
+		}
+	}
+	sriovConfig, err := json.Marshal(map[string][]sriovInterface{"interfaces": interfaces})
+	if err != nil {
+		return dpuerrors.RenderFailed(err)
+	}
+	data["SriovConfig"] = string(sriovConfig)
+	data["GatewayUplink"] = gateway
+	data["SecondaryUplinks"] = strings.Join(secondaries, " ")
+	data["UplinkBondPorts"] = ""
+	if cfg.Spec.UplinkBond != nil {
+		data["UplinkBondPorts"] = strings.Join(cfg.Spec.UplinkBond.Interfaces, " ")
+	}
+	return nil
+}
+
+// uplinkDevices maps the devlink devices of the PFs of spec.uplinks, as
+// published by the switchdev-check pods, to their uplink.
+func uplinkDevices(uplinks []dpuv1alpha1.Uplink) map[string]string {
+	devices := map[string]string{}
+	for _, uplink := range uplinks {
+		devices["pci/"+strings.ToLower(uplink.PCIAddress)] = uplink.Name
+	}
+	return devices
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/dpu-network-operator/api"
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/version"
+)
+
+// openshiftVersion is the major.minor OpenShift version of a component.
+type openshiftVersion struct {
+	major, minor int
+}
+
+func (v openshiftVersion) String() string {
+	return fmt.Sprintf("%d.%d", v.major, v.minor)
+}
+
+// maxTenantMinorSkew is the number of minor releases the tenant OVN control
+// plane may be ahead of ovnkube-node. The OVN databases are upgraded before
+// ovn-controller and remain readable by the previous release, but an older
+// database schema is not supported by a newer ovn-controller.
+const maxTenantMinorSkew = 1
+
+// compatibleVersions is the compatibility matrix between the ovnkube-node
+// image and the tenant OVN control plane.
+func compatibleVersions(node, tenant openshiftVersion) bool {
+	if node.major != tenant.major {
+		return false
+	}
+	skew := tenant.minor - node.minor
+	return skew >= 0 && skew <= maxTenantMinorSkew
+}
+
+var (
+	releaseVersionRegexp = regexp.MustCompile(`^v?([0-9]+)\.([0-9]+)`)
+	imageVersionRegexp   = regexp.MustCompile(`:v?([0-9]+)\.([0-9]+)[^/]*$`)
+)
+
+var clusterVersionGVK = schema.GroupVersionKind{Group: "config.openshift.io", Version: "v1", Kind: "ClusterVersion"}
+
+// imageVersion parses the OpenShift version out of an image tag, e.g.
+// quay.io/openshift/origin-ovn-kubernetes:4.12. Digests carry no version.
+func imageVersion(image string) (openshiftVersion, bool) {
+	m := imageVersionRegexp.FindStringSubmatch(image)
+	if m == nil {
+		return openshiftVersion{}, false
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	return openshiftVersion{major: major, minor: minor}, true
+}
+
+// clusterOpenShiftVersion reads the OpenShift release the cluster read by c
+// is upgrading to, or runs, from the desired version of its ClusterVersion.
+// A cluster without the config.openshift.io API, or a client which may not
+// read it, has no known version.
+func clusterOpenShiftVersion(ctx context.Context, c client.Reader) (openshiftVersion, bool) {
+	if c == nil {
+		return openshiftVersion{}, false
+	}
+	cv := &unstructured.Unstructured{}
+	cv.SetGroupVersionKind(clusterVersionGVK)
+	if err := c.Get(ctx, types.NamespacedName{Name: "version"}, cv); err != nil {
+		return openshiftVersion{}, false
+	}
+	desired, _, _ := unstructured.NestedString(cv.Object, "status", "desired", "version")
+	m := releaseVersionRegexp.FindStringSubmatch(desired)
+	if m == nil {
+		return openshiftVersion{}, false
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	return openshiftVersion{major: major, minor: minor}, true
+}
+
+//+kubebuilder:rbac:groups=config.openshift.io,resources=clusterversions,verbs=get
+
+// checkVersionSkew records the versions of the operator, of the rendered
+// ovnkube image and of the tenant OVN control plane in the status, and
+// flags incompatible combinations with the VersionSkew condition, rather
+// than leaving them to surface as ovsdb schema errors in ovn-controller.
+func (r *OVNKubeConfigReconciler) checkVersionSkew(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig) {
+	build := version.GetInfo()
+	versions := &dpuv1alpha1.ComponentVersions{Operator: build.Version, OperatorCommit: build.GitCommit}
+	cfg.Status.Versions = versions
+
+	image, source, err := r.resolveOvnkubeImage(ctx, cfg)
+	if err != nil {
+		meta.SetStatusCondition(&cfg.Status.Conditions, *api.Conditions(cfg).UnknownVersionSkew().Reason(api.ReasonVersionUnknown).Msg(err.Error()).Build())
+		return
+	}
+	versions.OvnKubeImage = image
+	versions.OvnKubeImageSource = source
+	// an invalid image is reported by the reconcile of the DaemonSets
+	versions.OvnControllerImage, _ = getOvnControllerImage(ctx, cfg, image)
+	versions.OvsDaemonsImage, _ = getOvsDaemonsImage(ctx, cfg, image)
+	// The image of the local DaemonSet is the one of the infra cluster
+	var nodeVersion openshiftVersion
+	var nodeKnown bool
+	if source == dpuv1alpha1.OvnKubeImageSourceLocal {
+		nodeVersion, nodeKnown = clusterOpenShiftVersion(ctx, r.APIReader)
+	} else {
+		nodeVersion, nodeKnown = imageVersion(image)
+	}
+	if nodeKnown {
+		versions.OvnKubeVersion = nodeVersion.String()
+	}
+
+	if ns, err := r.tenantOvnNamespace(ctx, cfg); err != nil {
+		logger.Error(err, "failed to find OVN-Kubernetes in the tenant cluster")
+	} else if pods, err := r.listTenantOvnkubeMasterPods(ctx, cfg, ns); err == nil {
+		for _, pod := range pods.Items {
+			for _, c := range pod.Spec.Containers {
+				if c.Name == "ovnkube-master" {
+					versions.TenantOvnKubeImage = c.Image
+				}
+			}
+		}
+	}
+	// the tag of the ovnkube-master image is the version of a tenant
+	// cluster whose ClusterVersion cannot be read
+	tenantVersion, tenantKnown := clusterOpenShiftVersion(ctx, r.connectedTenantClient(cfg.Namespace))
+	if !tenantKnown && versions.TenantOvnKubeImage != "" {
+		tenantVersion, tenantKnown = imageVersion(versions.TenantOvnKubeImage)
+	}
+	if tenantKnown {
+		versions.TenantVersion = tenantVersion.String()
+	}
+
+	switch {
+	case versions.TenantOvnKubeImage != "" && versions.TenantOvnKubeImage == image:
+		meta.SetStatusCondition(&cfg.Status.Conditions, *api.Conditions(cfg).NoVersionSkew().Reason(api.ReasonCompatible).Msg("ovnkube-node runs the ovnkube image of the tenant cluster").Build())
+	case !nodeKnown || !tenantKnown:
+		meta.SetStatusCondition(&cfg.Status.Conditions, *api.Conditions(cfg).UnknownVersionSkew().Reason(api.ReasonVersionUnknown).Msg("Cannot determine the versions of ovnkube-node and of the tenant cluster").Build())
+	case compatibleVersions(nodeVersion, tenantVersion):
+		meta.SetStatusCondition(&cfg.Status.Conditions, *api.Conditions(cfg).NoVersionSkew().Reason(api.ReasonCompatible).Msg(fmt.Sprintf("ovnkube-node %s is compatible with tenant cluster %s", nodeVersion, tenantVersion)).Build())
+	default:
+		msg := fmt.Sprintf("ovnkube-node %s is not compatible with tenant cluster %s, the tenant may be at most %d minor release ahead", nodeVersion, tenantVersion, maxTenantMinorSkew)
+		logger.Info("Version skew detected", "reason", msg)
+		meta.SetStatusCondition(&cfg.Status.Conditions, *api.Conditions(cfg).VersionSkew().Reason(api.ReasonIncompatible).Msg(msg).Build())
+	}
+}
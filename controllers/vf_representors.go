@@ -0,0 +1,289 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/openshift/cluster-network-operator/pkg/render"
+	daemonconsts "github.com/openshift/machine-config-operator/pkg/daemon/constants"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+	"github.com/openshift/dpu-network-operator/pkg/tracing"
+	"github.com/openshift/dpu-network-operator/pkg/utils"
+)
+
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings,verbs=get;list;watch;create;update;patch;delete
+
+// syncVfRepresentorDiscovery deploys the DaemonSet which annotates every DPU
+// node with its VF representor names, and publishes the mapping of all the
+// nodes of the pool in a ConfigMap consumed by the CNI shim.
+func (r *OVNKubeConfigReconciler) syncVfRepresentorDiscovery(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, image string, nodeSelector *metav1.LabelSelector) error {
+	objs, err := r.renderVfRepresentorDiscovery(ctx, cfg, image)
+	if err != nil {
+		return err
+	}
+	if err := r.applyRenderedObjects(ctx, cfg, objs, nodeSelector); err != nil {
+		return err
+	}
+	return r.publishVfRepresentors(ctx, cfg, nodeSelector)
+}
+
+func (r *OVNKubeConfigReconciler) renderVfRepresentorDiscovery(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, ovnkubeImage string) ([]*unstructured.Unstructured, error) {
+	image, err := getNodeAgentImage(ctx, cfg, ovnkubeImage)
+	if err != nil {
+		return nil, err
+	}
+	priorityClassName, err := r.getPriorityClassName(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	data := render.MakeRenderData()
+	data.Data["OvnKubeImage"] = ovnkubeImage
+	data.Data["NodeAgentImage"] = image
+	data.Data["Namespace"] = cfg.Namespace
+	data.Data["PriorityClassName"] = priorityClassName
+	data.Data["ImagePullSecrets"] = imagePullSecretNames(cfg)
+	data.Data["VfRepresentorsAnnotation"] = utils.VfRepresentorsAnnotation
+	data.Data["ActiveUplinkAnnotation"] = utils.ActiveUplinkAnnotation
+	data.Data["InterfaceAddressesAnnotation"] = utils.InterfaceAddressesAnnotation
+	data.Data["NicFirmwareAnnotation"] = utils.NicFirmwareAnnotation
+
+	addExtraRenderData(data.Data, cfg)
+	_, span := tracing.Start(ctx, "render", "manifests", utils.VfRepresentorsManifestPath)
+	objs, err := renderDir(cfg, utils.VfRepresentorsManifestPath, &data)
+	span.RecordError(err)
+	span.End()
+	if err != nil {
+		logger.Error(err, "Fail to render vf-representor-discovery manifests")
+		return nil, dpuerrors.RenderFailed(err)
+	}
+	return objs, nil
+}
+
+// applyRenderedObjects applies the objects rendered for the DPU nodes,
+// owned by the OVNKubeConfig. The matchLabels of nodeSelector are added to
+// the node selector of the DaemonSets. The objects are applied all or none:
+// a failure rolls back the ones changed before it.
+func (r *OVNKubeConfigReconciler) applyRenderedObjects(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, objs []*unstructured.Unstructured, nodeSelector *metav1.LabelSelector) error {
+	for _, obj := range objs {
+		if err := addNodeSelector(obj, nodeSelector); err != nil {
+			return err
+		}
+		// cluster-scoped objects cannot be owned by a namespaced CR
+		if obj.GetNamespace() == "" {
+			if err := r.ownClusterObject(ctx, cfg, obj); err != nil {
+				return err
+			}
+		} else if err := ctrl.SetControllerReference(cfg, obj, r.Scheme); err != nil {
+			return err
+		}
+	}
+	if err := r.validateRenderedObjects(ctx, objs); err != nil {
+		return err
+	}
+	tx := newApplyTransaction(r.Client)
+	for _, obj := range objs {
+		_, span := tracing.Start(ctx, "apply", "kind", obj.GetKind(), "name", obj.GetName())
+		err := tx.apply(ctx, obj)
+		span.RecordError(err)
+		span.End()
+		if err != nil {
+			tx.rollback(ctx)
+			return conflictError(fmt.Errorf("failed to apply object %v with err: %w", obj, err))
+		}
+		if obj.GetKind() == "ServiceAccount" {
+			if err := r.syncServiceAccountPullSecrets(ctx, cfg, obj.GetNamespace(), obj.GetName()); err != nil {
+				tx.rollback(ctx)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addNodeSelector adds the matchLabels of nodeSelector to the node selector
+// of a rendered DaemonSet. Other objects are left unchanged.
+func addNodeSelector(obj *unstructured.Unstructured, nodeSelector *metav1.LabelSelector) error {
+	if obj.GetKind() != "DaemonSet" || nodeSelector == nil {
+		return nil
+	}
+	sel, _, err := unstructured.NestedStringMap(obj.Object, "spec", "template", "spec", "nodeSelector")
+	if err != nil {
+		return err
+	}
+	if sel == nil {
+		sel = map[string]string{}
+	}
+	for k, v := range nodeSelector.MatchLabels {
+		sel[k] = v
+	}
+	return unstructured.SetNestedStringMap(obj.Object, sel, "spec", "template", "spec", "nodeSelector")
+}
+
+// publishVfRepresentors collects the representor annotations of the pool
+// nodes into the vf-representors ConfigMap, keyed by node name, and reports
+// the active uplink and the maintenance of each node in the status. When an
+// encap interface is set, the encap IP of every node is validated and
+// published in the ovnkube-encap-ips ConfigMap.
+func (r *OVNKubeConfigReconciler) publishVfRepresentors(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, nodeSelector *metav1.LabelSelector) error {
+	selector, err := metav1.LabelSelectorAsSelector(nodeSelector)
+	if err != nil {
+		return err
+	}
+	nodes := &corev1.NodeList{}
+	if err := r.List(ctx, nodes, &client.ListOptions{LabelSelector: selector}); err != nil {
+		return err
+	}
+	var encap *encapMatcher
+	var encapErr error
+	if cfg.Spec.Ovn.EncapInterface != nil {
+		encap, encapErr = newEncapMatcher(cfg.Spec.Ovn.EncapInterface)
+	}
+
+	mapping := map[string]string{}
+	encapIPs := map[string]string{}
+	nodeStatuses := []dpuv1alpha1.DpuNodeStatus{}
+	for _, node := range nodes.Items {
+		if v, ok := node.Annotations[utils.VfRepresentorsAnnotation]; ok {
+			mapping[node.Name] = v
+		}
+		status := dpuv1alpha1.DpuNodeStatus{
+			Name:             node.Name,
+			ActiveUplink:     node.Annotations[utils.ActiveUplinkAnnotation],
+			UnderMaintenance: node.Spec.Unschedulable,
+		}
+		modes, published := node.Annotations[utils.EswitchModesAnnotation]
+		setNodeSwitchdevStatus(&status, modes, published, cfg.Spec.Uplinks)
+		if cfg.Spec.Ovn.EncapInterface != nil {
+			err := encapErr
+			if err == nil {
+				status.EncapInterface, status.EncapIP, err = encap.selectAddress(node.Annotations[utils.InterfaceAddressesAnnotation])
+			}
+			if err != nil {
+				status.EncapError = err.Error()
+			} else {
+				encapIPs[node.Name] = status.EncapIP
+			}
+		}
+		nodeStatuses = append(nodeStatuses, status)
+	}
+	// the onboarding is informational, it doesn't hold the publication
+	if err := r.trackNodeOnboarding(ctx, cfg, cfg.Status.Nodes, nodes.Items, nodeStatuses); err != nil {
+		logger.Error(err, "failed to track the onboarding of the DPU nodes", "namespace", cfg.Namespace)
+	}
+	sort.Slice(nodeStatuses, func(i, j int) bool { return nodeStatuses[i].Name < nodeStatuses[j].Name })
+	// written by the deferred status update of Reconcile
+	cfg.Status.Nodes = nodeStatuses
+
+	if err := r.publishNodeConfigMap(ctx, cfg, utils.CmNameVfRepresentors, mapping); err != nil {
+		return err
+	}
+	return r.publishNodeConfigMap(ctx, cfg, utils.CmNameEncapIPs, encapIPs)
+}
+
+// publishNodeConfigMap creates or updates a ConfigMap of per-node values
+// owned by the OVNKubeConfig.
+func (r *OVNKubeConfigReconciler) publishNodeConfigMap(ctx context.Context, cfg *dpuv1alpha1.OVNKubeConfig, name string, data map[string]string) error {
+	return withApplyRetry(ctx, "ConfigMap", func() error {
+		cm := &corev1.ConfigMap{}
+		err := r.Get(ctx, types.NamespacedName{Namespace: cfg.Namespace, Name: name}, cm)
+		if errors.IsNotFound(err) {
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: cfg.Namespace},
+				Data:       data,
+			}
+			if err := ctrl.SetControllerReference(cfg, cm, r.Scheme); err != nil {
+				return err
+			}
+			markManaged(cm)
+			return r.Create(ctx, cm)
+		} else if err != nil {
+			return err
+		}
+		if err := checkConfigMapWriter(cm, cfg.UID); err != nil {
+			return err
+		}
+		if equality.Semantic.DeepEqual(cm.Data, data) && metav1.IsControlledBy(cm, cfg) &&
+			cm.Annotations[utils.ManagedByAnnotation] == utils.ManagedByOperator {
+			return nil
+		}
+		// an adopted ConfigMap has no controller yet
+		if metav1.GetControllerOf(cm) == nil {
+			if err := ctrl.SetControllerReference(cfg, cm, r.Scheme); err != nil {
+				return err
+			}
+		}
+		cm.Data = data
+		markManaged(cm)
+		return r.Update(ctx, cm)
+	})
+}
+
+// nodeToOVNKubeConfigs maps a node event to every OVNKubeConfig, since any of
+// them may select the node.
+func (r *OVNKubeConfigReconciler) nodeToOVNKubeConfigs(obj client.Object) []reconcile.Request {
+	cfgList := &dpuv1alpha1.OVNKubeConfigList{}
+	if err := r.List(context.TODO(), cfgList); err != nil {
+		logger.Error(err, "failed to list OVNKubeConfigs")
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, cfg := range cfgList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: cfg.Namespace, Name: cfg.Name}})
+	}
+	return requests
+}
+
+// vfRepresentorsChanged filters the node events which change the published
+// representors, the active uplink, the interface addresses or the eswitch
+// modes, cordon the node, or progress its MachineConfig for its onboarding.
+var vfRepresentorsChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldNode, oldOk := e.ObjectOld.(*corev1.Node)
+		newNode, newOk := e.ObjectNew.(*corev1.Node)
+		if oldOk && newOk && oldNode.Spec.Unschedulable != newNode.Spec.Unschedulable {
+			return true
+		}
+		for _, a := range []string{utils.VfRepresentorsAnnotation, utils.ActiveUplinkAnnotation, utils.InterfaceAddressesAnnotation, utils.EswitchModesAnnotation,
+			daemonconsts.DesiredMachineConfigAnnotationKey, daemonconsts.CurrentMachineConfigAnnotationKey, daemonconsts.MachineConfigDaemonStateAnnotationKey} {
+			if e.ObjectOld.GetAnnotations()[a] != e.ObjectNew.GetAnnotations()[a] {
+				return true
+			}
+		}
+		return false
+	},
+	GenericFunc: func(e event.GenericEvent) bool {
+		return false
+	},
+}
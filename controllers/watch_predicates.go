@@ -0,0 +1,99 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+)
+
+// configChanged filters the OVNKubeConfig updates down to the changes of
+// its spec and metadata, and of the status fields the tenant-sync
+// controller hands over to the others. The rest of the status is written by
+// the controllers themselves, e.g. their conditions and check times, and
+// would otherwise trigger a new pass of every controller after each write.
+var configChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldCfg, ok := e.ObjectOld.(*dpuv1alpha1.OVNKubeConfig)
+		if !ok {
+			return true
+		}
+		newCfg, ok := e.ObjectNew.(*dpuv1alpha1.OVNKubeConfig)
+		if !ok {
+			return true
+		}
+		return metadataChanged(oldCfg, newCfg) ||
+			oldCfg.Status.TenantOvnNamespace != newCfg.Status.TenantOvnNamespace ||
+			oldCfg.Status.TenantKubeconfigVersion != newCfg.Status.TenantKubeconfigVersion
+	},
+}
+
+// ownedDataChanged filters the updates of the owned ConfigMaps and Secrets
+// down to the changes of their content and metadata, ignoring the ones of
+// the managed fields alone. The other kinds are passed, their status is
+// read by the controllers.
+var ownedDataChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		switch oldObj := e.ObjectOld.(type) {
+		case *corev1.ConfigMap:
+			newObj, ok := e.ObjectNew.(*corev1.ConfigMap)
+			return !ok || metadataChanged(oldObj, newObj) ||
+				!equality.Semantic.DeepEqual(oldObj.Data, newObj.Data) ||
+				!equality.Semantic.DeepEqual(oldObj.BinaryData, newObj.BinaryData)
+		case *corev1.Secret:
+			newObj, ok := e.ObjectNew.(*corev1.Secret)
+			return !ok || metadataChanged(oldObj, newObj) ||
+				oldObj.Type != newObj.Type ||
+				!equality.Semantic.DeepEqual(oldObj.Data, newObj.Data)
+		}
+		return true
+	},
+}
+
+// podIssueChanged filters the updates of the data plane pods down to the
+// changes of their readiness or of the podIssue keeping them from being
+// ready, e.g. a pod entering CrashLoopBackOff.
+var podIssueChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldPod, ok := e.ObjectOld.(*corev1.Pod)
+		if !ok {
+			return true
+		}
+		newPod, ok := e.ObjectNew.(*corev1.Pod)
+		if !ok {
+			return true
+		}
+		return podReady(oldPod) != podReady(newPod) || podIssue(oldPod) != podIssue(newPod)
+	},
+}
+
+// metadataChanged reports whether the generation, the labels, the
+// annotations, the owners, the finalizers or the deletion of the object
+// changed.
+func metadataChanged(oldObj, newObj client.Object) bool {
+	return oldObj.GetGeneration() != newObj.GetGeneration() ||
+		!equality.Semantic.DeepEqual(oldObj.GetLabels(), newObj.GetLabels()) ||
+		!equality.Semantic.DeepEqual(oldObj.GetAnnotations(), newObj.GetAnnotations()) ||
+		!equality.Semantic.DeepEqual(oldObj.GetOwnerReferences(), newObj.GetOwnerReferences()) ||
+		!equality.Semantic.DeepEqual(oldObj.GetFinalizers(), newObj.GetFinalizers()) ||
+		!oldObj.GetDeletionTimestamp().Equal(newObj.GetDeletionTimestamp())
+}
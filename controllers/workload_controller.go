@@ -0,0 +1,287 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/openshift/dpu-network-operator/api"
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/dpuerrors"
+	"github.com/openshift/dpu-network-operator/pkg/tracing"
+)
+
+// workloadConditions are the conditions owned by the workload controller.
+var workloadConditions = []string{api.OvnKubeReady, api.PendingRollout, api.VersionSkew, api.DaemonSetHooks, api.TenantClusterReachable, api.LogForwarding, api.UnsupportedTenantNetwork, api.SwitchdevReady, api.RenderFailed, api.CertInvalidForNodes, api.ImageArchMismatch, api.ZeroNodesSelected}
+
+// copyWorkloadStatus copies the status fields owned by the workload
+// controller.
+func copyWorkloadStatus(dst, src *dpuv1alpha1.OVNKubeConfigStatus) {
+	dst.Nodes = src.Nodes
+	dst.Versions = src.Versions
+	dst.TenantNetworkType = src.TenantNetworkType
+	dst.NbEndpoints = src.NbEndpoints
+	dst.SbEndpoints = src.SbEndpoints
+	copyVerification(dst, src, hookTargetDaemonSet)
+}
+
+// reconcileWorkload renders the ovnkube-node DaemonSet and the VF representor
+// discovery, and reports their readiness.
+func (r *OVNKubeConfigReconciler) reconcileWorkload(ctx context.Context, req ctrl.Request) (result ctrl.Result, reterr error) {
+	ctx, span := tracing.Start(ctx, "reconcile workload", "namespace", req.Namespace, "name", req.Name)
+	defer func() {
+		span.RecordError(reterr)
+		span.End()
+	}()
+	logger := log.FromContext(ctx).WithValues("reconcile OVNKubeConfig workload", req.NamespacedName)
+	logger.Info("Reconcile")
+
+	ovnkubeConfig, err := r.getNamespaceConfig(ctx, req.Namespace)
+	if err != nil || ovnkubeConfig == nil {
+		if err == nil {
+			resetConditionMetrics(req.Namespace)
+			resetRenderFailures(req.Namespace)
+			resetCachedMachineConfigs(req.Namespace)
+		}
+		return ctrl.Result{}, err
+	}
+	if !ovnkubeConfig.DeletionTimestamp.IsZero() {
+		return r.finalizeClusterObjects(ctx, ovnkubeConfig)
+	}
+	if delegated(ovnkubeConfig) {
+		logger.Info("Skip the workload", "reason", "rendered in spec.targetNamespace")
+		return ctrl.Result{}, nil
+	}
+	start := time.Now()
+	defer func() {
+		recordReconcile(ovnkubeConfig, workloadControllerName, start, reterr)
+		setRenderFailedCondition(ovnkubeConfig)
+		if err := r.updateStatus(ctx, ovnkubeConfig, workloadConditions, copyReconcileStatus(workloadControllerName, copyWorkloadStatus)); err != nil {
+			logger.Error(err, "unable to update OVNKubeConfig status")
+		}
+	}()
+
+	if ovnkubeConfig.Spec.Hooks == nil {
+		meta.RemoveStatusCondition(&ovnkubeConfig.Status.Conditions, api.DaemonSetHooks)
+	}
+	if cfgPoolName(ovnkubeConfig) == "" || tenantKubeconfigName(ovnkubeConfig) == "" {
+		logger.Info("pool or kubeconfig of tenant cluster is not provided")
+		return ctrl.Result{}, nil
+	}
+	if err := r.syncNamespaceRoleBinding(ctx, ovnkubeConfig); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.syncTenantKubeconfigCopy(ctx, ovnkubeConfig); err != nil {
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).NotOvnKubeReady().Reason(dpuerrors.Reason(err, api.ReasonFailedCreated)).Msg(err.Error()).Build())
+		return ctrl.Result{}, err
+	}
+	if !managesWorkloads(ovnkubeConfig) {
+		// only the node preparation is in scope, the conditions of the
+		// workload would be stale
+		logger.Info("Skip the ovnkube workload", "reason", "spec.manageWorkloads is false")
+		for _, cndType := range workloadConditions {
+			meta.RemoveStatusCondition(&ovnkubeConfig.Status.Conditions, cndType)
+		}
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).NotOvnKubeReady().Reason(api.ReasonWorkloadsNotManaged).Msg("spec.manageWorkloads is false, the ovnkube workload is not deployed").Build())
+		return ctrl.Result{}, nil
+	}
+	// the logs matter most during outages, so a failure is reported without
+	// holding the data plane, and retried once the rest is synced
+	lfErr := r.syncLogForwarding(ctx, ovnkubeConfig)
+	if lfErr != nil {
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).NotLogForwarding().Reason(dpuerrors.Reason(lfErr, api.ReasonFailedCreated)).Msg(lfErr.Error()).Build())
+	} else if ovnkubeConfig.Spec.LogForwarding != nil {
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).LogForwarding().Reason(api.ReasonCreated).Build())
+	} else {
+		meta.RemoveStatusCondition(&ovnkubeConfig.Status.Conditions, api.LogForwarding)
+	}
+	// Hold the last known good objects while the tenant cluster is down,
+	// rather than rendering them from a partial view of its OVN control
+	// plane.
+	probed, err := r.checkTenantReachable(ovnkubeConfig)
+	if err != nil {
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).NotTenantClusterReachable().Reason(dpuerrors.Reason(err, api.ReasonTenantUnreachable)).Msg(err.Error()).Build())
+		logger.Info("Hold the ovnkube-node DaemonSet", "reason", err.Error())
+		return ctrl.Result{RequeueAfter: tenantReachabilityRequeueInterval}, nil
+	}
+	if probed {
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).TenantClusterReachable().Reason(api.ReasonReachable).Build())
+	}
+	if err := r.checkTenantNetworkType(ctx, ovnkubeConfig); err != nil {
+		if dpuerrors.Reason(err, "") != api.ReasonUnsupportedNetworkType {
+			return ctrl.Result{}, err
+		}
+		logger.Info("Skip the ovnkube-node DaemonSet", "reason", err.Error())
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).UnsupportedTenantNetwork().Reason(api.ReasonUnsupportedNetworkType).Msg(err.Error()).Build())
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).NotOvnKubeReady().Reason(api.ReasonUnsupportedNetworkType).Msg(err.Error()).Build())
+		return ctrl.Result{RequeueAfter: tenantNetworkRequeueInterval}, nil
+	}
+	meta.RemoveStatusCondition(&ovnkubeConfig.Status.Conditions, api.UnsupportedTenantNetwork)
+	err = r.syncOvnkubeDaemonSet(ctx, ovnkubeConfig)
+	if dpuerrors.Reason(err, "") == api.ReasonImageArchMismatch {
+		// the pods would fail with exec format errors on the DPUs
+		logger.Info("Hold DaemonSet ovnkube-node rollout", "reason", err.Error())
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).ImageArchMismatch().Reason(api.ReasonImageArchMismatch).Msg(err.Error()).Build())
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).NotOvnKubeReady().Reason(api.ReasonImageArchMismatch).Msg(err.Error()).Build())
+		return ctrl.Result{RequeueAfter: imageArchRequeueInterval}, nil
+	}
+	meta.RemoveStatusCondition(&ovnkubeConfig.Status.Conditions, api.ImageArchMismatch)
+	if perr, ok := err.(*pendingChangesError); ok {
+		logger.Info("Queue DaemonSet ovnkube-node rollout", "reason", perr.Error())
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).PendingRollout().Reason(api.ReasonOutsideMaintenanceWindow).Msg(perr.Error()).Build())
+		return ctrl.Result{RequeueAfter: perr.nextOpen}, nil
+	}
+	if perr, ok := err.(*prepullError); ok {
+		logger.Info("Hold DaemonSet ovnkube-node rollout", "reason", perr.Error())
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).PendingRollout().Reason(api.ReasonPrepullingImages).Msg(perr.Error()).Build())
+		return ctrl.Result{RequeueAfter: prepullRequeueInterval}, nil
+	}
+	if serr, ok := err.(*shardRolloutError); ok {
+		// the DaemonSet status changes trigger a new reconcile
+		logger.Info("Hold DaemonSet ovnkube-node rollout", "reason", serr.Error())
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).PendingRollout().Reason(api.ReasonWaitingForShard).Msg(serr.Error()).Build())
+		err = nil
+	} else {
+		meta.RemoveStatusCondition(&ovnkubeConfig.Status.Conditions, api.PendingRollout)
+	}
+	if herr, ok := err.(*hookError); ok {
+		// the hook Job is owned by the OVNKubeConfig, so its completion
+		// triggers a new reconcile
+		logger.Info("Hold DaemonSet ovnkube-node rollout", "reason", herr.Error())
+		return ctrl.Result{}, nil
+	}
+	if err != nil {
+		logger.Info("Sync DaemonSet ovnkube-node")
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).NotOvnKubeReady().Reason(dpuerrors.Reason(err, api.ReasonFailedCreated)).Msg(err.Error()).Build())
+		return ctrl.Result{}, err
+	}
+	meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *switchdevCondition(ovnkubeConfig))
+	if isOvnDataPlane(ovnkubeConfig) {
+		r.checkVersionSkew(ctx, ovnkubeConfig)
+	} else {
+		ovnkubeConfig.Status.Versions = nil
+		meta.RemoveStatusCondition(&ovnkubeConfig.Status.Conditions, api.VersionSkew)
+	}
+	dss, err := r.listOvnkubeNodeDaemonSets(ctx, ovnkubeConfig)
+	if err == nil && len(dss) == 0 {
+		err = errors.NewNotFound(appsv1.Resource("daemonsets"), r.dataPlane(ovnkubeConfig).daemonSetName())
+	}
+	if err != nil {
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).NotOvnKubeReady().Reason(api.ReasonNotFound).Msg(err.Error()).Build())
+		return ctrl.Result{}, err
+	}
+	if isOvnDataPlane(ovnkubeConfig) {
+		if err = r.validateOvnCertChain(ctx, ovnkubeConfig); err != nil {
+			meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).NotOvnKubeReady().Reason(api.ReasonInvalidCertificate).Msg(err.Error()).Build())
+			return ctrl.Result{}, err
+		}
+		if err = r.checkOvnCertIdentities(ctx, ovnkubeConfig); err != nil {
+			return ctrl.Result{}, err
+		}
+	} else {
+		meta.RemoveStatusCondition(&ovnkubeConfig.Status.Conditions, api.CertInvalidForNodes)
+	}
+	maintenance := nodesUnderMaintenance(ovnkubeConfig.Status.Nodes)
+	resetDaemonSetUpdateMetrics(req.Namespace)
+	// a DaemonSet whose pods are ready but not updated yet is reported
+	// apart from the unhealthy ones
+	notReady := []appsv1.DaemonSet{}
+	pending := []string{}
+	for i := range dss {
+		msg, err := r.daemonSetUpdatePending(ctx, &dss[i], maintenance)
+		if err != nil {
+			logger.Error(err, "failed to inspect the pods of the DaemonSet", "name", dss[i].Name)
+		} else if msg != "" {
+			pending = append(pending, msg)
+		}
+		if dss[i].Status.DesiredNumberScheduled == dss[i].Status.NumberReady {
+			continue
+		}
+		if len(maintenance) > 0 {
+			ready, err := r.readyOutsideMaintenance(ctx, &dss[i], maintenance)
+			if err != nil {
+				logger.Error(err, "failed to inspect the pods of the DaemonSet", "name", dss[i].Name)
+			} else if ready {
+				continue
+			}
+		}
+		notReady = append(notReady, dss[i])
+	}
+	switch {
+	case len(notReady) > 0:
+		msg, failing := r.daemonSetRolloutMessage(ctx, notReady, maintenance)
+		reason := api.ReasonProgressing
+		if failing {
+			reason = api.ReasonPodsFailing
+		}
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).NotOvnKubeReady().Reason(reason).Msg(msg).Build())
+	case len(pending) > 0:
+		resetPodIssues(req.Namespace)
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).NotOvnKubeReady().Reason(api.ReasonUpdatePending).Msg(strings.Join(pending, "; ")).Build())
+	default:
+		resetPodIssues(req.Namespace)
+		meta.SetStatusCondition(&ovnkubeConfig.Status.Conditions, *api.Conditions(ovnkubeConfig).OvnKubeReady().Reason(api.ReasonCreated).Msg(maintenanceMessage(maintenance)).Build())
+	}
+	if result, err = r.runDaemonSetPostRollout(ctx, ovnkubeConfig, dss); err != nil {
+		if _, ok := err.(*hookError); !ok {
+			return ctrl.Result{}, err
+		}
+	}
+	return result, lfErr
+}
+
+func (r *OVNKubeConfigReconciler) setupWorkloadController(mgr ctrl.Manager) error {
+	trigger := recordTrigger(r.Scheme, workloadControllerName)
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(workloadControllerName).
+		WithOptions(workerOptions(r.MaxConcurrentReconciles)).
+		For(&dpuv1alpha1.OVNKubeConfig{}, builder.WithPredicates(configChanged, trigger)).
+		Owns(&corev1.ConfigMap{}, builder.WithPredicates(ownedDataChanged, trigger)).
+		Owns(&corev1.Secret{}, builder.WithPredicates(ownedDataChanged, trigger)).
+		Owns(&appsv1.DaemonSet{}, builder.WithPredicates(trigger)).
+		Owns(&batchv1.Job{}, builder.WithPredicates(trigger)).
+		// the pods are owned by the DaemonSets, whose status doesn't change
+		// when a pod starts crashing
+		Watches(&source.Kind{Type: &corev1.Pod{}},
+			handler.EnqueueRequestsFromMapFunc(r.podToOVNKubeConfig),
+			builder.WithPredicates(podIssueChanged, trigger)).
+		Watches(&source.Kind{Type: &corev1.Node{}},
+			handler.EnqueueRequestsFromMapFunc(r.nodeToOVNKubeConfigs),
+			builder.WithPredicates(vfRepresentorsChanged, trigger)).
+		// the tenant kubeconfig of another namespace is copied
+		Watches(&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(r.secretToOVNKubeConfigs),
+			builder.WithPredicates(trigger)).
+		// the pods are rendered for the APIs of the infra cluster
+		Watches(platformChanges(r.Platform, &dpuv1alpha1.OVNKubeConfig{}), handler.EnqueueRequestsFromMapFunc(r.allOVNKubeConfigs)).
+		// e.g. the ovnkube-master pods of the tenant cluster moved
+		Watches(r.tenantTriggers.source(), r.tenantTriggers.handler(), builder.WithPredicates(trigger)).
+		Complete(isolateReconcile(workloadControllerName, r.reconcileWorkload))
+}
@@ -18,6 +18,7 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"github.com/kelseyhightower/envconfig"
 	nmoapiv1beta1 "github.com/medik8s/node-maintenance-operator/api/v1beta1"
 	"github.com/openshift/dpu-network-operator/pkg/utils"
@@ -28,16 +29,22 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	configv1 "github.com/openshift/api/config/v1"
 	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
 	"github.com/openshift/dpu-network-operator/controllers"
+	"github.com/openshift/dpu-network-operator/pkg/debug"
+	"github.com/openshift/dpu-network-operator/pkg/tracing"
+	"github.com/openshift/dpu-network-operator/pkg/version"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -55,6 +62,7 @@ func init() {
 
 	utilruntime.Must(dpuv1alpha1.AddToScheme(scheme))
 	utilruntime.Must(mcfgv1.AddToScheme(scheme))
+	utilruntime.Must(configv1.Install(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -62,11 +70,29 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var publishClusterOperator bool
+	var platformName string
+	var maxConcurrentReconciles int
+	var pprofAddr string
+	var runtimeMetrics bool
+	var nodeSelectorAdmission string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":49555", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":49556", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&publishClusterOperator, "publish-cluster-operator", false,
+		"Publish a ClusterOperator aggregating the health of all OVNKubeConfigs.")
+	flag.StringVar(&platformName, "platform", "auto",
+		"The platform of the infra cluster: openshift, kubernetes, or auto to detect the OpenShift APIs it serves.")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", controllers.DefaultMaxConcurrentReconciles,
+		"The number of OVNKubeConfigs and DpuNodePools reconciled in parallel by each controller.")
+	flag.StringVar(&pprofAddr, "pprof-bind-address", "",
+		"The address the pprof and expvar debug endpoints bind to, disabled when empty.")
+	flag.BoolVar(&runtimeMetrics, "runtime-metrics", false,
+		"Export every Go runtime metric on the metric endpoint, e.g. the GC pauses and scheduler latencies.")
+	flag.StringVar(&nodeSelectorAdmission, "node-selector-admission", "",
+		"Serve the admission webhook checking that the node selector of an OVNKubeConfig matches a node: warn or reject, disabled when empty.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -80,12 +106,34 @@ func main() {
 	}
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	build := version.GetInfo()
+	setupLog.Info("build", "version", build.Version, "gitCommit", build.GitCommit,
+		"gitTreeDirty", build.GitTreeDirty, "goVersion", build.GoVersion, "platform", build.Platform)
 	err = nmoapiv1beta1.AddToScheme(scheme)
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	platform, err := utils.NewDynamicPlatform(platformName, ctrl.GetConfigOrDie())
+	if err != nil {
+		setupLog.Error(err, "unable to determine the platform")
+		os.Exit(1)
+	}
+	detected := platform.Get()
+	setupLog.Info("platform", "machineConfig", detected.MachineConfig,
+		"securityContextConstraints", detected.SecurityContextConstraints, "clusterOperator", detected.ClusterOperator)
+	if publishClusterOperator && !detected.ClusterOperator {
+		setupLog.Error(fmt.Errorf("config.openshift.io is not served"), "unable to publish the ClusterOperator, publishing is disabled")
+		publishClusterOperator = false
+	}
+
+	// only the pods of the data plane are watched, not every pod of the cluster
+	dataPlanePods, err := controllers.DataPlanePodSelector()
+	if err != nil {
+		setupLog.Error(err, "invalid selector of the data plane pods")
+		os.Exit(1)
+	}
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
@@ -93,17 +141,53 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "d02fb12e.openshift.io",
+		MapperProvider:         utils.NewRESTMapper,
+		NewCache: cache.BuilderWithOptions(cache.Options{SelectorsByObject: cache.SelectorsByObject{
+			&corev1.Pod{}: {Label: dataPlanePods},
+		}}),
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
+	if err := mgr.Add(platform); err != nil {
+		setupLog.Error(err, "unable to set up the platform detection")
+		os.Exit(1)
+	}
 
-	if err = (&controllers.OVNKubeConfigReconciler{
+	secretReader, err := controllers.NewKubeconfigSecretReader(mgr.GetConfig(), mgr.GetScheme(), mgr.GetRESTMapper())
+	if err != nil {
+		setupLog.Error(err, "unable to create the kubeconfig Secret reader")
+		os.Exit(1)
+	}
+	ovnkubeConfigReconciler := &controllers.OVNKubeConfigReconciler{
+		Client:                  mgr.GetClient(),
+		APIReader:               mgr.GetAPIReader(),
+		SecretReader:            secretReader,
+		Scheme:                  mgr.GetScheme(),
+		Platform:                platform,
+		Recorder:                mgr.GetEventRecorderFor("dpu-network-operator"),
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+	}
+	if err = ovnkubeConfigReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "OVNKubeConfig")
+		os.Exit(1)
+	}
+
+	if err = (&controllers.DpuFleetPolicyReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "OVNKubeConfig")
+		setupLog.Error(err, "unable to create controller", "controller", "DpuFleetPolicy")
+		os.Exit(1)
+	}
+	if err = (&controllers.DpuNodePoolReconciler{
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		Platform:                platform,
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "DpuNodePool")
 		os.Exit(1)
 	}
 
@@ -113,12 +197,47 @@ func main() {
 		Log:       logrus.New(),
 		Config:    &Options.NodeController,
 		Namespace: utils.Namespace,
+		// the tenant clusters are the ones of the running syncers
+		TenantRestConfigs: ovnkubeConfigReconciler.TenantRestConfigForNode,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "DpuController")
 		os.Exit(1)
 	}
+	if publishClusterOperator {
+		if err = (&controllers.ClusterOperatorReconciler{
+			Client: mgr.GetClient(),
+			Scheme: mgr.GetScheme(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ClusterOperator")
+			os.Exit(1)
+		}
+	}
+	if nodeSelectorAdmission != "" {
+		if err = (&controllers.NodeSelectorValidator{
+			Client: mgr.GetClient(),
+			Mode:   controllers.NodeSelectorAdmissionMode(nodeSelectorAdmission),
+		}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "NodeSelector")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
+	if pprofAddr != "" {
+		if err := mgr.Add(&debug.Server{Addr: pprofAddr}); err != nil {
+			setupLog.Error(err, "unable to set up the debug endpoints")
+			os.Exit(1)
+		}
+	}
+	if runtimeMetrics {
+		debug.EnableRuntimeMetrics()
+	}
+
+	if err := mgr.AddMetricsExtraHandler("/version", version.Handler()); err != nil {
+		setupLog.Error(err, "unable to set up the version endpoint")
+		os.Exit(1)
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -128,8 +247,14 @@ func main() {
 		os.Exit(1)
 	}
 
+	ctx := ctrl.SetupSignalHandler()
+	if err := tracing.Setup(ctx); err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
@@ -0,0 +1,89 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package debug serves the pprof profiles and the runtime statistics of the
+// operator, to investigate its memory and CPU usage in the field, e.g. large
+// tenant informer caches. Nothing is served unless enabled.
+package debug
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// shutdownTimeout bounds the wait for the running requests, e.g. a CPU
+// profile, when the operator stops.
+const shutdownTimeout = 5 * time.Second
+
+// Server serves the pprof profiles under /debug/pprof/ and the expvar
+// runtime statistics under /debug/vars on its address. It runs on every
+// replica, leader or not.
+type Server struct {
+	Addr string
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Start serves the endpoints until ctx is done.
+func (s *Server) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	log.FromContext(ctx).Info("Serving the debug endpoints", "address", listener.Addr().String())
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// EnableRuntimeMetrics replaces the Go collector of the metrics endpoint
+// with one exporting every runtime/metrics metric, e.g. the GC pauses, the
+// scheduler latencies and the heap by size class, on top of the memstats
+// ones.
+func EnableRuntimeMetrics() {
+	metrics.Registry.Unregister(collectors.NewGoCollector())
+	metrics.Registry.MustRegister(collectors.NewGoCollector(
+		collectors.WithGoCollectorRuntimeMetrics(collectors.MetricsAll),
+	))
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dpuerrors classifies the errors of the reconcilers by the Reason of
+// the condition reporting them, so automation can branch on the Reason.
+package dpuerrors
+
+import (
+	"errors"
+
+	"github.com/openshift/dpu-network-operator/api"
+)
+
+// Error is an error classified by the condition Reason it is reported with.
+type Error struct {
+	Reason string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Reason returns the Reason of the first classified error of the chain, or
+// fallback if the error is not classified.
+func Reason(err error, fallback string) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Reason
+	}
+	return fallback
+}
+
+// TenantUnreachable classifies an error reaching the tenant API server.
+func TenantUnreachable(err error) error {
+	return wrap(api.ReasonTenantUnreachable, err)
+}
+
+// InvalidKubeconfig classifies an error loading the tenant kubeconfig.
+func InvalidKubeconfig(err error) error {
+	return wrap(api.ReasonInvalidKubeconfig, err)
+}
+
+// RenderFailed classifies an error rendering manifests or MachineConfigs.
+func RenderFailed(err error) error {
+	return wrap(api.ReasonRenderFailed, err)
+}
+
+// InvalidObject classifies a rendered object rejected by its validation
+// before the apply.
+func InvalidObject(err error) error {
+	return wrap(api.ReasonInvalidObject, err)
+}
+
+// ApplyConflict classifies an update rejected because the object was
+// modified concurrently.
+func ApplyConflict(err error) error {
+	return wrap(api.ReasonApplyConflict, err)
+}
+
+// McDegraded classifies a degraded MachineConfigPool.
+func McDegraded(err error) error {
+	return wrap(api.ReasonMcDegraded, err)
+}
+
+// FeatureMismatch classifies an OVN feature enabled on the DPUs but not in
+// the tenant cluster.
+func FeatureMismatch(err error) error {
+	return wrap(api.ReasonFeatureMismatch, err)
+}
+
+// InvalidImage classifies a missing or malformed ovnkube image.
+func InvalidImage(err error) error {
+	return wrap(api.ReasonInvalidImage, err)
+}
+
+// ImageArchMismatch classifies an image not built for the architecture of
+// the DPUs.
+func ImageArchMismatch(err error) error {
+	return wrap(api.ReasonImageArchMismatch, err)
+}
+
+// APINotInstalled classifies a missing CRD of an optional integration.
+func APINotInstalled(err error) error {
+	return wrap(api.ReasonAPINotInstalled, err)
+}
+
+// SecretReadForbidden classifies a Secret of another namespace the
+// operator may not read.
+func SecretReadForbidden(err error) error {
+	return wrap(api.ReasonSecretReadForbidden, err)
+}
+
+// SecretNotShared classifies a Secret of another namespace whose owner
+// didn't share it with the namespace of the CR.
+func SecretNotShared(err error) error {
+	return wrap(api.ReasonSecretNotShared, err)
+}
+
+// UnsupportedNetworkType classifies a tenant cluster running a network
+// plugin other than OVN-Kubernetes.
+func UnsupportedNetworkType(err error) error {
+	return wrap(api.ReasonUnsupportedNetworkType, err)
+}
+
+// InvalidUplinks classifies an inconsistent spec.uplinks.
+func InvalidUplinks(err error) error {
+	return wrap(api.ReasonInvalidUplinks, err)
+}
+
+// InsufficientPermissions classifies a request of the tenant identity
+// denied by the tenant cluster.
+func InsufficientPermissions(err error) error {
+	return wrap(api.ReasonInsufficientPermissions, err)
+}
+
+// PriorityClassNotAdmitted classifies a critical priority class the
+// ResourceQuotas of the namespace don't admit pods of.
+func PriorityClassNotAdmitted(err error) error {
+	return wrap(api.ReasonPriorityClassNotAdmitted, err)
+}
+
+// Conflict classifies an object already managed by someone else.
+func Conflict(err error) error {
+	return wrap(api.ReasonConflict, err)
+}
+
+func wrap(reason string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Reason: reason, Err: err}
+}
@@ -1,6 +1,7 @@
 package ovnkubesyncer
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
@@ -18,6 +20,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+	"github.com/openshift/dpu-network-operator/pkg/tracing"
 	"github.com/openshift/dpu-network-operator/pkg/utils"
 )
 
@@ -48,14 +51,36 @@ type SyncerConfig struct {
 
 	// Scheme used to convert resource objects. By default the global k8s Scheme is used.
 	Scheme *runtime.Scheme
+
+	// Transforms are applied in order to the tenant objects before they are written into the LocalNamespace.
+	Transforms []ObjectTransform
 }
 
 type OvnkubeSyncer struct {
-	ConfigmapSyncer resourceSyncer.Interface
-	SecretSyncer    resourceSyncer.Interface
-	syncerConfig    SyncerConfig
-	owner           *dpuv1alpha1.OVNKubeConfig
-	scheme          *runtime.Scheme
+	syncers      []resourceSyncer.Interface
+	syncerConfig SyncerConfig
+	owner        *dpuv1alpha1.OVNKubeConfig
+	scheme       *runtime.Scheme
+	// traceCtx carries the span of the reconcile which started the syncer,
+	// parent of the spans of the synced objects
+	traceCtx context.Context
+}
+
+// syncedObject is a tenant object copied to the infra cluster.
+type syncedObject struct {
+	resourceType runtime.Object
+	name         string
+}
+
+// syncedObjects are the tenant objects copied to the infra cluster. Each one
+// is watched by its own informer, restricted by a field selector on its name,
+// so the cache only holds these objects however many Secrets and ConfigMaps
+// the tenant namespace holds.
+var syncedObjects = []syncedObject{
+	{&corev1.Secret{}, utils.SecretNameOvnCert},
+	{&corev1.ConfigMap{}, utils.CmNameOvnCa},
+	{&corev1.ConfigMap{}, utils.CmNameOvnkubeConfig},
+	{&corev1.ConfigMap{}, utils.CmNameSignerCa},
 }
 
 func New(config SyncerConfig, owner *dpuv1alpha1.OVNKubeConfig, scheme *runtime.Scheme) (*OvnkubeSyncer, error) {
@@ -75,7 +100,9 @@ func New(config SyncerConfig, owner *dpuv1alpha1.OVNKubeConfig, scheme *runtime.
 	}
 
 	if config.TenantClient == nil {
-		config.TenantClient, err = dynamic.NewForConfig(config.TenantRestConfig)
+		tenantConfig := rest.CopyConfig(config.TenantRestConfig)
+		tenantConfig.Wrap(newRelistLimiter)
+		config.TenantClient, err = dynamic.NewForConfig(tenantConfig)
 		if err != nil {
 			return nil, fmt.Errorf("error creating dynamic client: %v", err)
 		}
@@ -89,56 +116,40 @@ func New(config SyncerConfig, owner *dpuv1alpha1.OVNKubeConfig, scheme *runtime.
 	return syncer, nil
 }
 
-func (s *OvnkubeSyncer) Start(stopCh <-chan struct{}) error {
-	var err error
+// Start starts syncing the tenant objects until stopCh is closed. The spans
+// of the synced objects are children of the span of ctx.
+func (s *OvnkubeSyncer) Start(ctx context.Context, stopCh <-chan struct{}) error {
 	klog.Info("Starting the ovnkube syncer")
+	s.traceCtx = tracing.Detach(ctx)
 	waitForCacheSync := true
 
-	s.SecretSyncer, err = resourceSyncer.NewResourceSyncer(&resourceSyncer.ResourceSyncerConfig{
-		Name:             "SecretSyncer",
-		SourceClient:     s.syncerConfig.TenantClient,
-		SourceNamespace:  s.syncerConfig.TenantNamespace,
-		Direction:        resourceSyncer.None,
-		RestMapper:       s.syncerConfig.RestMapper,
-		Federator:        broker.NewFederator(s.syncerConfig.LocalClient, s.syncerConfig.RestMapper, s.syncerConfig.LocalNamespace, "", "ownerReferences"),
-		ResourceType:     &corev1.Secret{},
-		Transform:        s.shouldSyncSecret,
-		WaitForCacheSync: &waitForCacheSync,
-		Scheme:           s.scheme,
-		ResyncPeriod:     5 * time.Second,
-	})
-	if err != nil {
-		return err
-	}
-
-	s.ConfigmapSyncer, err = resourceSyncer.NewResourceSyncer(&resourceSyncer.ResourceSyncerConfig{
-		Name:             "ConfigmapSyncer",
-		SourceClient:     s.syncerConfig.TenantClient,
-		SourceNamespace:  s.syncerConfig.TenantNamespace,
-		Direction:        resourceSyncer.None,
-		RestMapper:       s.syncerConfig.RestMapper,
-		Federator:        broker.NewFederator(s.syncerConfig.LocalClient, s.syncerConfig.RestMapper, s.syncerConfig.LocalNamespace, "", "ownerReferences"),
-		ResourceType:     &corev1.ConfigMap{},
-		Transform:        s.shouldSyncConfigMap,
-		WaitForCacheSync: &waitForCacheSync,
-		Scheme:           s.scheme,
-		ResyncPeriod:     5 * time.Second,
-	})
-	if err != nil {
-		return err
-	}
-
-	klog.Info("Starting serviceaccount syncer")
-
-	klog.Info("Starting secret syncer")
-	err = s.SecretSyncer.Start(stopCh)
-	if err != nil {
-		return err
-	}
-	klog.Info("Starting configmap syncer")
-	err = s.ConfigmapSyncer.Start(stopCh)
-	if err != nil {
-		return err
+	for _, o := range syncedObjects {
+		transform := s.shouldSyncConfigMap
+		if _, ok := o.resourceType.(*corev1.Secret); ok {
+			transform = s.shouldSyncSecret
+		}
+		syncer, err := resourceSyncer.NewResourceSyncer(&resourceSyncer.ResourceSyncerConfig{
+			Name:                o.name + "-syncer",
+			SourceClient:        s.syncerConfig.TenantClient,
+			SourceNamespace:     s.syncerConfig.TenantNamespace,
+			SourceFieldSelector: fields.OneTermEqualSelector("metadata.name", o.name).String(),
+			Direction:           resourceSyncer.None,
+			RestMapper:          s.syncerConfig.RestMapper,
+			Federator:           broker.NewFederator(s.syncerConfig.LocalClient, s.syncerConfig.RestMapper, s.syncerConfig.LocalNamespace, "", "ownerReferences"),
+			ResourceType:        o.resourceType,
+			Transform:           transform,
+			WaitForCacheSync:    &waitForCacheSync,
+			Scheme:              s.scheme,
+			ResyncPeriod:        5 * time.Second,
+		})
+		if err != nil {
+			return err
+		}
+		klog.Infof("Starting the %s syncer", o.name)
+		if err := syncer.Start(stopCh); err != nil {
+			return err
+		}
+		s.syncers = append(s.syncers, syncer)
 	}
 
 	klog.Info("ovnkube syncer started")
@@ -148,9 +159,16 @@ func (s *OvnkubeSyncer) Start(stopCh <-chan struct{}) error {
 
 func (s *OvnkubeSyncer) shouldSyncSecret(obj runtime.Object, numRequeues int, op resourceSyncer.Operation) (runtime.Object, bool) {
 	secret := obj.(*corev1.Secret)
-	secret.Namespace = s.syncerConfig.LocalNamespace
 	switch secret.Name {
 	case utils.SecretNameOvnCert:
+		_, span := tracing.Start(s.traceCtx, "sync tenant Secret", "name", secret.Name, "operation", op.String())
+		defer span.End()
+		if err := s.transform(secret, op); err != nil {
+			span.RecordError(err)
+			klog.Errorf("Not syncing the tenant Secret: %v", err)
+			return nil, false
+		}
+		secret.Namespace = s.syncerConfig.LocalNamespace
 		// clear owner
 		secret.OwnerReferences = []metav1.OwnerReference{}
 		if err := ctrl.SetControllerReference(s.owner, secret, s.scheme); err != nil {
@@ -163,9 +181,28 @@ func (s *OvnkubeSyncer) shouldSyncSecret(obj runtime.Object, numRequeues int, op
 
 func (s *OvnkubeSyncer) shouldSyncConfigMap(obj runtime.Object, numRequeues int, op resourceSyncer.Operation) (runtime.Object, bool) {
 	cm := obj.(*corev1.ConfigMap)
-	cm.Namespace = s.syncerConfig.LocalNamespace
 	switch cm.Name {
-	case utils.CmNameOvnCa, utils.CmNameOvnkubeConfig:
+	case utils.CmNameOvnCa, utils.CmNameOvnkubeConfig, utils.CmNameSignerCa:
+		_, span := tracing.Start(s.traceCtx, "sync tenant ConfigMap", "name", cm.Name, "operation", op.String())
+		defer span.End()
+		if err := s.transform(cm, op); err != nil {
+			span.RecordError(err)
+			klog.Errorf("Not syncing the tenant ConfigMap: %v", err)
+			return nil, false
+		}
+		// the operator is the single writer of the ConfigMap, it doesn't
+		// overwrite one written by e.g. another network operator
+		if manager := s.localManager(cm.Name); manager != "" {
+			span.RecordError(fmt.Errorf("managed by %s", manager))
+			klog.Errorf("Not syncing the tenant ConfigMap %s: the ConfigMap of namespace %s is managed by %s",
+				cm.Name, s.syncerConfig.LocalNamespace, manager)
+			return nil, false
+		}
+		cm.Namespace = s.syncerConfig.LocalNamespace
+		if cm.Annotations == nil {
+			cm.Annotations = map[string]string{}
+		}
+		cm.Annotations[utils.ManagedByAnnotation] = utils.ManagedByOperator
 		// clear owner
 		cm.OwnerReferences = []metav1.OwnerReference{}
 		if err := ctrl.SetControllerReference(s.owner, cm, s.scheme); err != nil {
@@ -175,3 +212,29 @@ func (s *OvnkubeSyncer) shouldSyncConfigMap(obj runtime.Object, numRequeues int,
 	}
 	return nil, false
 }
+
+// transform runs the transform pipeline on a tenant object. Deletions are
+// synced as is.
+func (s *OvnkubeSyncer) transform(obj runtime.Object, op resourceSyncer.Operation) error {
+	if op == resourceSyncer.Delete {
+		return nil
+	}
+	for _, t := range s.syncerConfig.Transforms {
+		if err := t(obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// localManager returns the manager of the ConfigMap name of the
+// LocalNamespace when it is not the operator, see utils.ForeignManager. A
+// ConfigMap which can't be read is left to the federator.
+func (s *OvnkubeSyncer) localManager(name string) string {
+	local, err := s.syncerConfig.LocalClient.Resource(corev1.SchemeGroupVersion.WithResource("configmaps")).
+		Namespace(s.syncerConfig.LocalNamespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return ""
+	}
+	return utils.ForeignManager(local, s.owner.UID)
+}
@@ -0,0 +1,111 @@
+package ovnkubesyncer
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/flowcontrol"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// relistQPS bounds the rate of the lists of the tenant informers, the
+	// burst lets them all list at once at start.
+	relistQPS = 0.5
+
+	relistBackoffBase = time.Second
+	relistBackoffCap  = time.Minute
+)
+
+var (
+	tenantRelists = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "dpu_network_operator_tenant_relists_total",
+		Help: "Number of lists of the tenant objects by the syncer informers.",
+	})
+	tenantRelistsDelayed = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "dpu_network_operator_tenant_relists_delayed_total",
+		Help: "Number of lists of the tenant objects held by the relist rate limit, a relist storm.",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(tenantRelists, tenantRelistsDelayed)
+}
+
+// relistLimiter paces the requests of the informers of the syncer to the
+// tenant cluster. After a network blip every informer relists and rewatches
+// at once: the lists are rate limited, and once a request failed all the
+// requests wait for a shared exponential backoff, jittered so the informers
+// reconnect spread out.
+type relistLimiter struct {
+	rt      http.RoundTripper
+	limiter flowcontrol.RateLimiter
+
+	lock     sync.Mutex
+	failures int
+}
+
+func newRelistLimiter(rt http.RoundTripper) http.RoundTripper {
+	return &relistLimiter{
+		rt:      rt,
+		limiter: flowcontrol.NewTokenBucketRateLimiter(relistQPS, len(syncedObjects)),
+	}
+}
+
+func (l *relistLimiter) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if err := l.waitBackoff(ctx); err != nil {
+		return nil, err
+	}
+	// the informers only list and watch
+	if req.URL.Query().Get("watch") != "true" {
+		tenantRelists.Inc()
+		if !l.limiter.TryAccept() {
+			tenantRelistsDelayed.Inc()
+			if err := l.limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+	}
+	resp, err := l.rt.RoundTrip(req)
+	l.record(resp, err)
+	return resp, err
+}
+
+// waitBackoff waits for the backoff of the failures of the previous
+// requests.
+func (l *relistLimiter) waitBackoff(ctx context.Context) error {
+	l.lock.Lock()
+	failures := l.failures
+	l.lock.Unlock()
+	if failures == 0 {
+		return nil
+	}
+	delay := relistBackoffCap
+	if failures < 7 {
+		delay = relistBackoffBase << (failures - 1)
+	}
+	if delay > relistBackoffCap {
+		delay = relistBackoffCap
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(wait.Jitter(delay, 0.5)):
+		return nil
+	}
+}
+
+func (l *relistLimiter) record(resp *http.Response, err error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		l.failures++
+		return
+	}
+	l.failures = 0
+}
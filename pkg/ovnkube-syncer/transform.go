@@ -0,0 +1,76 @@
+package ovnkubesyncer
+
+import (
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	dpuv1alpha1 "github.com/openshift/dpu-network-operator/api/v1alpha1"
+)
+
+// ObjectTransform rewrites a tenant object, a *corev1.ConfigMap or a
+// *corev1.Secret, before it is written into the infra namespace.
+type ObjectTransform func(obj runtime.Object) error
+
+// JSONPatchTransform applies the TenantObjectPatches returned by patches to
+// the objects they select. patches is called for every object, so changes to
+// the CR apply on the next resync.
+func JSONPatchTransform(patches func() ([]dpuv1alpha1.TenantObjectPatch, error)) ObjectTransform {
+	return func(obj runtime.Object) error {
+		kind, name := objectKindName(obj)
+		list, err := patches()
+		if err != nil {
+			return err
+		}
+		for _, p := range list {
+			if p.Kind != kind || p.Name != name {
+				continue
+			}
+			if err := applyJSONPatch(obj, p.Patch); err != nil {
+				return fmt.Errorf("failed to patch %s %s: %v", kind, name, err)
+			}
+		}
+		return nil
+	}
+}
+
+// ValidateJSONPatch checks that patch is a well-formed JSON Patch.
+func ValidateJSONPatch(patch string) error {
+	_, err := jsonpatch.DecodePatch([]byte(patch))
+	return err
+}
+
+func applyJSONPatch(obj runtime.Object, patch string) error {
+	p, err := jsonpatch.DecodePatch([]byte(patch))
+	if err != nil {
+		return err
+	}
+	doc, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	if doc, err = p.Apply(doc); err != nil {
+		return err
+	}
+	// reset the object, so the removed fields don't survive the unmarshal
+	switch o := obj.(type) {
+	case *corev1.ConfigMap:
+		*o = corev1.ConfigMap{}
+	case *corev1.Secret:
+		*o = corev1.Secret{}
+	}
+	return json.Unmarshal(doc, obj)
+}
+
+func objectKindName(obj runtime.Object) (string, string) {
+	switch o := obj.(type) {
+	case *corev1.ConfigMap:
+		return "ConfigMap", o.Name
+	case *corev1.Secret:
+		return "Secret", o.Name
+	}
+	return "", ""
+}
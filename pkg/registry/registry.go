@@ -0,0 +1,356 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package registry reads the platforms of container images from their
+// registry, with the distribution API: the platforms of a manifest list, or
+// the one of the config of a single-platform manifest.
+package registry
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	mediaTypeOCIIndex       = "application/vnd.oci.image.index.v1+json"
+	mediaTypeOCIManifest    = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeDockerList     = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+
+	// defaultRegistry is the registry of the images without a host, served
+	// by defaultRegistryAPIHost and keyed by defaultRegistryAuthKey in the
+	// docker configs.
+	defaultRegistry        = "docker.io"
+	defaultRegistryAPIHost = "registry-1.docker.io"
+	defaultRegistryAuthKey = "https://index.docker.io/v1/"
+
+	// requestTimeout bounds the requests reading the platforms of an image.
+	requestTimeout = 30 * time.Second
+	// maxResponseSize bounds the manifests, configs and tokens read.
+	maxResponseSize = 4 << 20
+)
+
+// Credentials are the basic auth credentials of a registry.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// Keychain returns the credentials of a registry host, if any.
+type Keychain func(host string) (Credentials, bool)
+
+// DockerConfigKeychain returns the credentials of the .dockerconfigjson
+// documents of pull secrets, the first one holding a host winning.
+func DockerConfigKeychain(configs ...[]byte) (Keychain, error) {
+	creds := map[string]Credentials{}
+	for _, data := range configs {
+		config := struct {
+			Auths map[string]struct {
+				Auth     string `json:"auth"`
+				Username string `json:"username"`
+				Password string `json:"password"`
+			} `json:"auths"`
+		}{}
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("invalid docker config: %v", err)
+		}
+		for key, entry := range config.Auths {
+			c := Credentials{Username: entry.Username, Password: entry.Password}
+			if entry.Auth != "" {
+				decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+				if err != nil {
+					return nil, fmt.Errorf("invalid auth of %s in the docker config: %v", key, err)
+				}
+				c.Username, c.Password, _ = strings.Cut(string(decoded), ":")
+			}
+			host := authKeyHost(key)
+			if _, ok := creds[host]; !ok {
+				creds[host] = c
+			}
+		}
+	}
+	return func(host string) (Credentials, bool) {
+		c, ok := creds[host]
+		return c, ok
+	}, nil
+}
+
+// authKeyHost returns the registry host of a key of the docker config,
+// which may be a URL.
+func authKeyHost(key string) string {
+	if key == defaultRegistryAuthKey {
+		return defaultRegistry
+	}
+	key = strings.TrimPrefix(strings.TrimPrefix(key, "https://"), "http://")
+	host, _, _ := strings.Cut(key, "/")
+	return host
+}
+
+// Reference is an image reference split into its parts.
+type Reference struct {
+	// Registry is the registry host, docker.io by default.
+	Registry string
+	// Repository is the path of the repository in the registry.
+	Repository string
+	// Reference is the digest of the image, or else its tag.
+	Reference string
+}
+
+// ParseReference splits image into its registry, repository and tag or
+// digest, defaulting like the container runtimes.
+func ParseReference(image string) Reference {
+	ref := Reference{Registry: defaultRegistry}
+	name := image
+	if i := strings.Index(name, "/"); i >= 0 {
+		host := name[:i]
+		if strings.ContainsAny(host, ".:") || host == "localhost" {
+			ref.Registry = host
+			name = name[i+1:]
+		}
+	}
+	if name, digest, ok := strings.Cut(name, "@"); ok {
+		ref.Repository, ref.Reference = name, digest
+		// a tag next to the digest is ignored by the runtimes
+		if i := strings.LastIndex(ref.Repository, ":"); i > strings.LastIndex(ref.Repository, "/") {
+			ref.Repository = ref.Repository[:i]
+		}
+	} else if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		ref.Repository, ref.Reference = name[:i], name[i+1:]
+	} else {
+		ref.Repository, ref.Reference = name, "latest"
+	}
+	if ref.Registry == defaultRegistry && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = "library/" + ref.Repository
+	}
+	return ref
+}
+
+// Platform is the platform an image runs on.
+type Platform struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+func (p Platform) String() string {
+	s := p.OS + "/" + p.Architecture
+	if p.Variant != "" {
+		s += "/" + p.Variant
+	}
+	return s
+}
+
+// Platforms returns the platforms image is built for.
+func Platforms(ctx context.Context, image string, keychain Keychain) ([]Platform, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	ref := ParseReference(image)
+	c := &client{ref: ref, keychain: keychain, http: http.DefaultClient}
+	body, mediaType, err := c.get(ctx, "manifests/"+ref.Reference,
+		strings.Join([]string{mediaTypeOCIIndex, mediaTypeDockerList, mediaTypeOCIManifest, mediaTypeDockerManifest}, ", "))
+	if err != nil {
+		return nil, err
+	}
+	manifest := struct {
+		MediaType string `json:"mediaType"`
+		Manifests []struct {
+			Platform *Platform `json:"platform"`
+		} `json:"manifests"`
+		Config struct {
+			Digest string `json:"digest"`
+		} `json:"config"`
+	}{}
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest of %s: %v", image, err)
+	}
+	if manifest.MediaType != "" {
+		mediaType = manifest.MediaType
+	}
+	switch mediaType {
+	case mediaTypeOCIIndex, mediaTypeDockerList:
+		platforms := []Platform{}
+		for _, m := range manifest.Manifests {
+			// the attestation manifests have no or an unknown platform
+			if m.Platform != nil && m.Platform.OS != "unknown" {
+				platforms = append(platforms, *m.Platform)
+			}
+		}
+		return platforms, nil
+	case mediaTypeOCIManifest, mediaTypeDockerManifest:
+		if manifest.Config.Digest == "" {
+			return nil, fmt.Errorf("manifest of %s has no config", image)
+		}
+		body, _, err := c.get(ctx, "blobs/"+manifest.Config.Digest, "")
+		if err != nil {
+			return nil, err
+		}
+		platform := Platform{}
+		if err := json.Unmarshal(body, &platform); err != nil {
+			return nil, fmt.Errorf("invalid config of %s: %v", image, err)
+		}
+		return []Platform{platform}, nil
+	default:
+		return nil, fmt.Errorf("unsupported manifest media type %q of %s", mediaType, image)
+	}
+}
+
+// client reads the manifests and blobs of a repository, getting a bearer
+// token from the auth server of the registry when it asks for one.
+type client struct {
+	ref           Reference
+	keychain      Keychain
+	http          *http.Client
+	authorization string
+}
+
+func (c *client) get(ctx context.Context, path, accept string) ([]byte, string, error) {
+	host := c.ref.Registry
+	if host == defaultRegistry {
+		host = defaultRegistryAPIHost
+	}
+	u := fmt.Sprintf("https://%s/v2/%s/%s", host, c.ref.Repository, path)
+	resp, err := c.do(ctx, u, accept)
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && c.authorization == "" {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if c.authorization, err = c.authorize(ctx, challenge); err != nil {
+			return nil, "", err
+		}
+		if resp, err = c.do(ctx, u, accept); err != nil {
+			return nil, "", err
+		}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, "", err
+	}
+	mediaType, _, _ := strings.Cut(resp.Header.Get("Content-Type"), ";")
+	return body, strings.TrimSpace(mediaType), nil
+}
+
+func (c *client) do(ctx context.Context, u, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if c.authorization != "" {
+		req.Header.Set("Authorization", c.authorization)
+	}
+	return c.http.Do(req)
+}
+
+// authorize returns the Authorization header answering the challenge of
+// the registry: the credentials of the keychain for Basic, or a token of
+// the auth server, anonymous without credentials, for Bearer.
+func (c *client) authorize(ctx context.Context, challenge string) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	creds, hasCreds := Credentials{}, false
+	if c.keychain != nil {
+		creds, hasCreds = c.keychain(c.ref.Registry)
+	}
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if !hasCreds {
+			return "", fmt.Errorf("registry %s requires credentials", c.ref.Registry)
+		}
+		return "Basic " + basicAuth(creds), nil
+	case "bearer":
+		realm, err := url.Parse(params["realm"])
+		if err != nil || realm.Host == "" {
+			return "", fmt.Errorf("invalid auth realm %q of registry %s", params["realm"], c.ref.Registry)
+		}
+		q := realm.Query()
+		if service := params["service"]; service != "" {
+			q.Set("service", service)
+		}
+		q.Set("scope", "repository:"+c.ref.Repository+":pull")
+		realm.RawQuery = q.Encode()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+		if err != nil {
+			return "", err
+		}
+		if hasCreds {
+			req.Header.Set("Authorization", "Basic "+basicAuth(creds))
+		}
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("token of registry %s: %s", c.ref.Registry, resp.Status)
+		}
+		token := struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}{}
+		if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&token); err != nil {
+			return "", fmt.Errorf("invalid token of registry %s: %v", c.ref.Registry, err)
+		}
+		if token.Token == "" {
+			token.Token = token.AccessToken
+		}
+		return "Bearer " + token.Token, nil
+	default:
+		return "", fmt.Errorf("unsupported auth challenge %q of registry %s", challenge, c.ref.Registry)
+	}
+}
+
+// parseChallenge splits a WWW-Authenticate header into its scheme and its
+// parameters, e.g. Bearer realm="https://auth.example.com/token",service="registry".
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := map[string]string{}
+	for rest != "" {
+		var key, value string
+		key, rest, _ = strings.Cut(strings.TrimLeft(rest, " ,"), "=")
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+			params[key] = value
+		}
+	}
+	return scheme, params
+}
+
+func basicAuth(c Credentials) string {
+	return base64.StdEncoding.EncodeToString([]byte(c.Username + ":" + c.Password))
+}
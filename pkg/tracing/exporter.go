@@ -0,0 +1,387 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	defaultServiceName = "dpu-network-operator"
+
+	exportInterval = 5 * time.Second
+	maxBatchSize   = 512
+	maxQueueSize   = 2048
+)
+
+var logger = log.Log.WithName("tracing")
+
+var (
+	exporterMu sync.RWMutex
+	exporter   *otlpExporter
+)
+
+func getExporter() *otlpExporter {
+	exporterMu.RLock()
+	defer exporterMu.RUnlock()
+	return exporter
+}
+
+// Setup starts exporting the spans if OTEL_EXPORTER_OTLP_TRACES_ENDPOINT or
+// OTEL_EXPORTER_OTLP_ENDPOINT is set. The queued spans are flushed when ctx
+// is done.
+func Setup(ctx context.Context) error {
+	e, err := newExporter()
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		logger.Info("No OTLP endpoint configured, tracing is disabled")
+		return nil
+	}
+	exporterMu.Lock()
+	exporter = e
+	exporterMu.Unlock()
+	logger.Info("Exporting traces", "endpoint", e.endpoint)
+	go e.run(ctx)
+	return nil
+}
+
+// newExporter returns the exporter configured by the OTEL_EXPORTER_OTLP_*
+// environment variables, nil when no endpoint is set.
+func newExporter() (*otlpExporter, error) {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	if endpoint == "" {
+		if base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); base != "" {
+			endpoint = strings.TrimSuffix(base, "/") + "/v1/traces"
+		}
+	}
+	if endpoint == "" {
+		return nil, nil
+	}
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+	headers, err := parseHeaders(signalEnv("HEADERS"))
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := exporterTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &otlpExporter{
+		endpoint:    endpoint,
+		serviceName: serviceName,
+		headers:     headers,
+		client:      &http.Client{Timeout: 10 * time.Second, Transport: transport},
+	}, nil
+}
+
+// signalEnv returns the OTEL_EXPORTER_OTLP_TRACES_<name> environment
+// variable, or OTEL_EXPORTER_OTLP_<name> when not set.
+func signalEnv(name string) string {
+	if v := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_" + name); v != "" {
+		return v
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_" + name)
+}
+
+// parseHeaders parses the headers of OTEL_EXPORTER_OTLP_HEADERS: comma
+// separated key=value pairs, the values being percent-encoded.
+func parseHeaders(s string) (http.Header, error) {
+	headers := http.Header{}
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid OTLP header %q, want key=value", pair)
+		}
+		value, err := url.PathUnescape(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of OTLP header %s: %w", key, err)
+		}
+		headers.Add(key, value)
+	}
+	return headers, nil
+}
+
+// exporterTLSConfig returns the TLS configuration of the exporter: the CA
+// bundle of OTEL_EXPORTER_OTLP_CERTIFICATE verifies the endpoint instead of
+// the system roots, and the client certificate and key of
+// OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE and OTEL_EXPORTER_OTLP_CLIENT_KEY
+// authenticate the operator.
+func exporterTLSConfig() (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if file := signalEnv("CERTIFICATE"); file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the OTLP CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificate found in the OTLP CA bundle %s", file)
+		}
+		config.RootCAs = pool
+	}
+	certFile, keyFile := signalEnv("CLIENT_CERTIFICATE"), signalEnv("CLIENT_KEY")
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the OTLP client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+type otlpExporter struct {
+	endpoint    string
+	serviceName string
+	headers     http.Header
+	client      *http.Client
+
+	mu    sync.Mutex
+	queue []*Span
+}
+
+func (e *otlpExporter) enqueue(s *Span) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.queue) >= maxQueueSize {
+		// drop the oldest span rather than blocking the reconciles
+		e.queue = e.queue[1:]
+	}
+	e.queue = append(e.queue, s)
+}
+
+func (e *otlpExporter) run(ctx context.Context) {
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			e.flush(context.Background(), false)
+			return
+		case <-ticker.C:
+			e.flush(ctx, true)
+		}
+	}
+}
+
+// flush exports the queued spans by batches. When retry is set, a batch
+// failing with a transient error is put back in the queue for the next
+// flush, rather than dropped.
+func (e *otlpExporter) flush(ctx context.Context, retry bool) {
+	for {
+		e.mu.Lock()
+		n := len(e.queue)
+		if n > maxBatchSize {
+			n = maxBatchSize
+		}
+		batch := e.queue[:n]
+		e.queue = e.queue[n:]
+		e.mu.Unlock()
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.export(ctx, batch); err != nil {
+			if retry && retryable(err) {
+				logger.Error(err, "failed to export spans, retrying at the next flush", "count", len(batch))
+				e.requeue(batch)
+			} else {
+				logger.Error(err, "failed to export spans", "count", len(batch))
+			}
+			return
+		}
+	}
+}
+
+// requeue puts the spans of a failed batch back at the head of the queue,
+// dropping the oldest spans beyond maxQueueSize.
+func (e *otlpExporter) requeue(batch []*Span) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	queue := make([]*Span, 0, len(batch)+len(e.queue))
+	queue = append(append(queue, batch...), e.queue...)
+	if len(queue) > maxQueueSize {
+		queue = queue[len(queue)-maxQueueSize:]
+	}
+	e.queue = queue
+}
+
+// exportError is the response of the endpoint to a failed export.
+type exportError struct {
+	endpoint string
+	status   string
+	code     int
+}
+
+func (e *exportError) Error() string {
+	return fmt.Sprintf("OTLP endpoint %s returned %s", e.endpoint, e.status)
+}
+
+// retryable reports whether the export failing with err may succeed later:
+// the endpoint could not be reached, or it answered with one of the
+// transient statuses of the OTLP/HTTP specification.
+func retryable(err error) bool {
+	eerr, ok := err.(*exportError)
+	if !ok {
+		return true
+	}
+	switch eerr.code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func (e *otlpExporter) export(ctx context.Context, spans []*Span) error {
+	body, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, values := range e.headers {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &exportError{endpoint: e.endpoint, status: resp.Status, code: resp.StatusCode}
+	}
+	return nil
+}
+
+// The types below are the subset of the OTLP ExportTraceServiceRequest used
+// by the exporter, in the OTLP/JSON encoding.
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+const (
+	spanKindInternal = 1
+	statusCodeOk     = 1
+	statusCodeError  = 2
+)
+
+func (e *otlpExporter) request(spans []*Span) *otlpRequest {
+	out := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		span := otlpSpan{
+			TraceID:           hex.EncodeToString(s.traceID[:]),
+			SpanID:            hex.EncodeToString(s.spanID[:]),
+			Name:              s.name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Status:            otlpStatus{Code: statusCodeOk},
+		}
+		if s.parentID != [8]byte{} {
+			span.ParentSpanID = hex.EncodeToString(s.parentID[:])
+		}
+		for k, v := range s.attrs {
+			span.Attributes = append(span.Attributes, otlpAttribute{Key: k, Value: otlpValue{StringValue: v}})
+		}
+		if s.err != nil {
+			span.Status = otlpStatus{Code: statusCodeError, Message: s.err.Error()}
+		}
+		out = append(out, span)
+	}
+	return &otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: []otlpAttribute{
+			{Key: "service.name", Value: otlpValue{StringValue: e.serviceName}},
+		}},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: "github.com/openshift/dpu-network-operator"},
+			Spans: out,
+		}},
+	}}}
+}
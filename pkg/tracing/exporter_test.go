@@ -0,0 +1,219 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// collector records the spans exported to it, answering with the next code
+// of codes, 200 once they are used.
+type collector struct {
+	codes   []int
+	headers http.Header
+	spans   []otlpSpan
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	c.headers = req.Header.Clone()
+	if len(c.codes) > 0 {
+		code := c.codes[0]
+		c.codes = c.codes[1:]
+		if code != http.StatusOK {
+			w.WriteHeader(code)
+			return
+		}
+	}
+	request := &otlpRequest{}
+	if err := json.NewDecoder(req.Body).Decode(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	for _, rs := range request.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			c.spans = append(c.spans, ss.Spans...)
+		}
+	}
+}
+
+// testExporter returns the exporter of the environment, with the endpoint
+// of server.
+func testExporter(t *testing.T, server *httptest.Server) *otlpExporter {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", server.URL)
+	e, err := newExporter()
+	if err != nil {
+		t.Fatalf("newExporter() error = %v", err)
+	}
+	return e
+}
+
+func queueSpan(e *otlpExporter, name string) {
+	_, s := Start(context.Background(), name)
+	s.end = s.start
+	e.enqueue(s)
+}
+
+func TestParseHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		want    http.Header
+		wantErr bool
+	}{
+		{name: "empty", env: "", want: http.Header{}},
+		{
+			name: "pairs",
+			env:  "Authorization=Bearer%20token, X-Scope-OrgID=tenant-a",
+			want: http.Header{"Authorization": {"Bearer token"}, "X-Scope-Orgid": {"tenant-a"}},
+		},
+		{name: "missing value", env: "Authorization", wantErr: true},
+		{name: "bad encoding", env: "Authorization=%zz", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseHeaders(tt.env)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseHeaders() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseHeaders() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExporterHeaders(t *testing.T) {
+	c := &collector{}
+	server := httptest.NewServer(c)
+	defer server.Close()
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "Authorization=Bearer%20generic")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_HEADERS", "Authorization=Bearer%20traces")
+	e := testExporter(t, server)
+	queueSpan(e, "reconcile")
+	e.flush(context.Background(), true)
+	if got := c.headers.Get("Authorization"); got != "Bearer traces" {
+		t.Errorf("Authorization = %q, want the header of the traces signal", got)
+	}
+	if got := c.headers.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	if len(c.spans) != 1 || c.spans[0].Name != "reconcile" {
+		t.Errorf("exported spans = %v, want the reconcile span", c.spans)
+	}
+}
+
+func TestExporterRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      int
+		retry     bool
+		wantSpans int
+	}{
+		{name: "unavailable", code: http.StatusServiceUnavailable, retry: true, wantSpans: 2},
+		{name: "throttled", code: http.StatusTooManyRequests, retry: true, wantSpans: 2},
+		{name: "rejected", code: http.StatusBadRequest, retry: true, wantSpans: 1},
+		{name: "final flush", code: http.StatusServiceUnavailable, wantSpans: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &collector{codes: []int{tt.code}}
+			server := httptest.NewServer(c)
+			defer server.Close()
+			e := testExporter(t, server)
+			queueSpan(e, "first")
+			e.flush(context.Background(), tt.retry)
+			queueSpan(e, "second")
+			e.flush(context.Background(), tt.retry)
+			if len(c.spans) != tt.wantSpans {
+				t.Fatalf("exported %d spans, want %d", len(c.spans), tt.wantSpans)
+			}
+			if tt.wantSpans == 2 && c.spans[0].Name != "first" {
+				t.Errorf("first exported span = %s, want the retried one", c.spans[0].Name)
+			}
+		})
+	}
+}
+
+func TestExporterRequeueBound(t *testing.T) {
+	e := &otlpExporter{}
+	for i := 0; i < maxQueueSize; i++ {
+		queueSpan(e, "queued")
+	}
+	_, failed := Start(context.Background(), "failed")
+	e.requeue([]*Span{failed})
+	if len(e.queue) != maxQueueSize {
+		t.Fatalf("queue holds %d spans, want %d", len(e.queue), maxQueueSize)
+	}
+	if e.queue[0] == failed {
+		t.Errorf("the oldest span is kept beyond the queue size")
+	}
+}
+
+func TestExporterCertificate(t *testing.T) {
+	c := &collector{}
+	server := httptest.NewTLSServer(c)
+	defer server.Close()
+
+	// without the CA bundle, the certificate of the test server is not
+	// trusted
+	e := testExporter(t, server)
+	queueSpan(e, "untrusted")
+	e.flush(context.Background(), false)
+	if len(c.spans) != 0 {
+		t.Fatalf("spans exported to an untrusted endpoint")
+	}
+
+	bundle := filepath.Join(t.TempDir(), "ca.crt")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(bundle, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("OTEL_EXPORTER_OTLP_CERTIFICATE", bundle)
+	e = testExporter(t, server)
+	queueSpan(e, "trusted")
+	e.flush(context.Background(), false)
+	if len(c.spans) != 1 {
+		t.Errorf("exported %d spans, want 1", len(c.spans))
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE", bundle)
+	if _, err := newExporter(); err == nil {
+		t.Errorf("newExporter() succeeded with a client certificate without key")
+	}
+}
+
+func TestDetach(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx, parent := Start(ctx, "start tenant syncer")
+	cancel()
+	detached := Detach(ctx)
+	if detached.Err() != nil {
+		t.Fatalf("the detached context is canceled with its parent")
+	}
+	_, child := Start(detached, "sync tenant Secret")
+	if child.traceID != parent.traceID || child.parentID != parent.spanID {
+		t.Errorf("the span of the detached context is not a child of %s", parent.name)
+	}
+}
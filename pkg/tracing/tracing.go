@@ -0,0 +1,100 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing records the phases of the reconciles as spans and exports
+// them to an OpenTelemetry collector over OTLP/HTTP, using the JSON encoding
+// of the protocol. It is configured with the standard OTEL_EXPORTER_OTLP_*
+// environment variables, and is a no-op when no endpoint is set.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// Span is a timed phase of a reconcile.
+type Span struct {
+	name     string
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	start    time.Time
+	end      time.Time
+	attrs    map[string]string
+	err      error
+	once     sync.Once
+}
+
+type spanKey struct{}
+
+// Start starts a span named name, child of the span of ctx if any, with the
+// attributes given as key, value pairs. The returned context carries the
+// span, so the spans started from it are its children.
+func Start(ctx context.Context, name string, keyValues ...string) (context.Context, *Span) {
+	s := &Span{name: name, start: time.Now(), attrs: map[string]string{}}
+	if parent, ok := ctx.Value(spanKey{}).(*Span); ok {
+		s.traceID = parent.traceID
+		s.parentID = parent.spanID
+	} else {
+		rand.Read(s.traceID[:])
+	}
+	rand.Read(s.spanID[:])
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		s.attrs[keyValues[i]] = keyValues[i+1]
+	}
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// Detach returns a context carrying the span of ctx, if any, without its
+// deadline nor cancellation, for the spans of the work started by ctx but
+// outliving it.
+func Detach(ctx context.Context) context.Context {
+	if s, ok := ctx.Value(spanKey{}).(*Span); ok {
+		return context.WithValue(context.Background(), spanKey{}, s)
+	}
+	return context.Background()
+}
+
+// SetAttribute sets an attribute of the span.
+func (s *Span) SetAttribute(key, value string) {
+	s.attrs[key] = value
+}
+
+// RecordError marks the span as failed if err is not nil.
+func (s *Span) RecordError(err error) {
+	if err != nil {
+		s.err = err
+	}
+}
+
+// End ends the span and queues it for export. Only the first call counts.
+func (s *Span) End() {
+	s.once.Do(func() {
+		s.end = time.Now()
+		if e := getExporter(); e != nil {
+			e.enqueue(s)
+		}
+	})
+}
+
+// TraceID returns the hex encoded trace ID of the span, to correlate the logs
+// with the traces.
+func (s *Span) TraceID() string {
+	return hex.EncodeToString(s.traceID[:])
+}
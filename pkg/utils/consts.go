@@ -4,11 +4,133 @@ const (
 	CmNameOvnkubeConfig   = "ovnkube-config"
 	CmNameTenantCLusterCA = "tenant-cluster-ca.crt"
 	CmNameOvnCa           = "ovn-ca"
+	// CmNameSignerCa holds the CA signing the OVN IPsec certificates of the
+	// tenant cluster
+	CmNameSignerCa = "signer-ca"
+	// OvnkubeConfKey is the key of the ovnkube configuration file in the
+	// ovnkube-config ConfigMap
+	OvnkubeConfKey = "ovnkube.conf"
 
 	SecretNameOvnCert = "ovn-cert"
+	// CmNameSriovDevicePlugin holds the configuration of the SR-IOV network
+	// device plugin in the tenant cluster
+	CmNameSriovDevicePlugin = "sriovdp-config"
+	// OvnCABundleKey is the key of the OVN CA bundle, in the ovn-ca
+	// ConfigMap or in the Secret referenced by spec.ovn.caSecretRef
+	OvnCABundleKey = "ca-bundle.crt"
 
-	OvnkubeNodeManifestPath = "./bindata/ovnkube-node"
-	SaNameOvnkubeNode       = "ovn-kubernetes-node"
-	LocalOvnkbueNamespace   = "openshift-ovn-kubernetes"
-	LocalOvnkbueNodeDsName  = "ovnkube-node"
+	OvnkubeNodeManifestPath    = "./bindata/ovnkube-node"
+	VfRepresentorsManifestPath = "./bindata/vf-representors"
+	HostConfigManifestPath     = "./bindata/host-config"
+	LogForwardingManifestPath  = "./bindata/log-forwarding"
+	CiliumAgentManifestPath    = "./bindata/cilium-agent"
+	SwitchdevCheckManifestPath = "./bindata/switchdev-check"
+	SaNameOvnkubeNode          = "ovn-kubernetes-node"
+	LocalOvnkbueNamespace      = "openshift-ovn-kubernetes"
+	LocalOvnkbueNodeDsName     = "ovnkube-node"
+
+	TemplateHashAnnotation = "dpu.openshift.io/template-hash"
+
+	// HostConfigRevisionAnnotation holds the revision of the switchdev
+	// configuration written to the hosts without the machine-config-operator
+	HostConfigRevisionAnnotation = "dpu.openshift.io/host-config-revision"
+	CmNameHostConfig             = "dpu-host-config"
+
+	// VfRepresentorsAnnotation holds the JSON mapping of the switchdev port
+	// names to the VF representor netdev names of a DPU node
+	VfRepresentorsAnnotation = "dpu.openshift.io/vf-representors"
+	CmNameVfRepresentors     = "vf-representors"
+
+	// ActiveUplinkAnnotation holds the uplink currently carrying the traffic
+	// of the bonded uplinks of a DPU node
+	ActiveUplinkAnnotation = "dpu.openshift.io/active-uplink"
+
+	// InterfaceAddressesAnnotation holds the JSON mapping of the interface
+	// names to the global addresses of a DPU node
+	InterfaceAddressesAnnotation = "dpu.openshift.io/interface-addresses"
+	CmNameEncapIPs               = "ovnkube-encap-ips"
+	// EswitchModesAnnotation holds the JSON mapping of the devlink devices
+	// to their eswitch mode, e.g. switchdev or legacy, of a DPU node
+	EswitchModesAnnotation = "dpu.openshift.io/eswitch-modes"
+	// NicFirmwareAnnotation holds the JSON mapping of the devlink devices
+	// to their running firmware version, of a DPU node
+	NicFirmwareAnnotation = "dpu.openshift.io/nic-firmware"
+	// CmNameOvnLogLevel holds the ovn-controller log level, applied without
+	// restarting the ovnkube-node pods
+	CmNameOvnLogLevel = "ovn-log-level"
+	// CmNameCiliumConfig holds the configuration of the cilium-agent of the
+	// Cilium data plane
+	CmNameCiliumConfig = "cilium-config"
+	// CmNameEnvOverrides holds the env overrides of ovnkube-node, by DPU
+	// node, among them the TENANT_K8S_NODE host of the DPU
+	CmNameEnvOverrides = "env-overrides"
+
+	// ManagedLabelsAnnotationPrefix, followed by the namespace of an
+	// OVNKubeConfig, holds the JSON map of the pool labels applied to a node
+	// for it
+	ManagedLabelsAnnotationPrefix = "managed-labels.dpu.openshift.io/"
+	// ManagedTaintsAnnotation holds the JSON list of the nodeTaints applied
+	// to a node by the operator
+	ManagedTaintsAnnotation = "dpu.openshift.io/managed-taints"
+	// ReadinessTaintKey is the key of the taint kept on the DPU nodes until
+	// their data plane is verified, with spec.readinessTaint
+	ReadinessTaintKey = "dpu.openshift.io/not-ready"
+	// TaintsOwnerAnnotation holds the namespace of the OVNKubeConfig which
+	// applied the taints of ManagedTaintsAnnotation
+	TaintsOwnerAnnotation = "dpu.openshift.io/taints-owner"
+	// OwnerLabel holds the namespace of the OVNKubeConfig which created a
+	// MachineConfigPool or MachineConfig, so another OVNKubeConfig of the
+	// same pool doesn't overwrite it, or an object of the tenant cluster,
+	// removed with the OVNKubeConfig
+	OwnerLabel = "dpu.openshift.io/owner"
+	// AdoptAnnotation set to "true" on a MachineConfigPool, MachineConfig,
+	// DaemonSet or ConfigMap created by hand lets the operator take it over
+	AdoptAnnotation = "dpu.openshift.io/adopt"
+	// SharedWithAnnotation lists, comma separated, the namespaces whose
+	// OVNKubeConfigs may use a tenant kubeconfig Secret of another namespace
+	SharedWithAnnotation = "dpu.openshift.io/shared-with-namespaces"
+	// ManagedByAnnotation is set to ManagedByOperator on the ConfigMaps of
+	// ovnkube-node the operator writes, its single writer
+	ManagedByAnnotation = "dpu.openshift.io/managed-by"
+	ManagedByOperator   = "dpu-network-operator"
+	// NodeTaintsFinalizer removes the nodeTaints of a deleted OVNKubeConfig
+	// from the nodes
+	NodeTaintsFinalizer = "dpu.openshift.io/node-taints"
+	// TenantCleanupFinalizer removes the objects created in the tenant
+	// cluster by a deleted OVNKubeConfig
+	TenantCleanupFinalizer = "dpu.openshift.io/tenant-cleanup"
+	// ClusterObjectsFinalizer removes the cluster-scoped objects rendered
+	// for a deleted OVNKubeConfig, which cannot be owned by it
+	ClusterObjectsFinalizer = "dpu.openshift.io/cluster-objects"
+	// OvnkubeShardLabel holds the ovnkube-node DaemonSet shard of a DPU
+	// node, when the rollout is sharded
+	OvnkubeShardLabel = "dpu.openshift.io/ovnkube-shard"
+	// ManagedPullSecretsAnnotation holds the JSON list of the image pull
+	// secrets added to a ServiceAccount by the operator
+	ManagedPullSecretsAnnotation = "dpu.openshift.io/managed-pull-secrets"
+	// RolloutHookLabel holds the target and phase of a rollout hook Job,
+	// e.g. daemonset-pre-rollout
+	RolloutHookLabel = "dpu.openshift.io/rollout-hook"
+	// FleetPolicyLabel holds the name of the DpuFleetPolicy which stamped
+	// out an OVNKubeConfig.
+	FleetPolicyLabel = "dpu.openshift.io/fleet-policy"
+	// NodePoolAnnotation holds the name of the DpuNodePool managing a node
+	NodePoolAnnotation = "dpu.openshift.io/node-pool"
+	// PoolLabelsAnnotation holds the JSON map of the labels applied to a
+	// node by its DpuNodePool
+	PoolLabelsAnnotation = "dpu.openshift.io/pool-labels"
+	// PoolTaintsAnnotation holds the JSON list of the taints applied to a
+	// node by its DpuNodePool
+	PoolTaintsAnnotation = "dpu.openshift.io/pool-taints"
+	// DpuTypeLabel holds the DPU model of a node of a DpuNodePool
+	DpuTypeLabel = "dpu.openshift.io/dpu-type"
+	// NodePoolFinalizer releases the nodes of a deleted DpuNodePool
+	NodePoolFinalizer = "dpu.openshift.io/node-pool"
+	// DelegatedFromLabel holds the namespace of the OVNKubeConfig a managed
+	// copy of an OVNKubeConfig or of its kubeconfig Secret was created from,
+	// in the spec.targetNamespace of the former
+	DelegatedFromLabel = "dpu.openshift.io/delegated-from"
+	// DelegationFinalizer removes the managed copies of a deleted
+	// OVNKubeConfig from its spec.targetNamespace
+	DelegationFinalizer = "dpu.openshift.io/delegation"
 )
@@ -6,8 +6,10 @@ import (
 
 var TenantNamespace string
 var Namespace string
+var ServiceAccount string
 
 func init() {
 	TenantNamespace = os.Getenv("TENANT_NAMESPACE")
 	Namespace = os.Getenv("NAMESPACE")
+	ServiceAccount = os.Getenv("SERVICE_ACCOUNT")
 }
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ListPageSize is the number of objects requested per page by ListAll.
+const ListPageSize = 500
+
+// ListAll lists the objects matching opts into list, a page of
+// ListPageSize objects at a time, so a big cluster doesn't return them in a
+// single response. The list restarts from the first page if the continue
+// token expires. It is meant for direct clients, e.g. of the tenant
+// cluster, the cached ones ignore the pagination.
+func ListAll(ctx context.Context, c client.Reader, list client.ObjectList, opts ...client.ListOption) error {
+	restarted := false
+	items := []runtime.Object{}
+	token := ""
+	for {
+		page := list.DeepCopyObject().(client.ObjectList)
+		pageOpts := append(append([]client.ListOption{}, opts...), client.Limit(ListPageSize), client.Continue(token))
+		if err := c.List(ctx, page, pageOpts...); err != nil {
+			if errors.IsResourceExpired(err) && token != "" && !restarted {
+				restarted = true
+				items = items[:0]
+				token = ""
+				continue
+			}
+			return err
+		}
+		pageItems, err := meta.ExtractList(page)
+		if err != nil {
+			return err
+		}
+		items = append(items, pageItems...)
+		token = page.GetContinue()
+		if token == "" {
+			list.SetResourceVersion(page.GetResourceVersion())
+			return meta.SetList(list, items)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ForeignManager returns the manager of obj when it is not the operator
+// acting for the owner of UID owner, "" otherwise: the controller of obj, or
+// without one, the field managers of an object neither annotated with
+// ManagedByAnnotation nor with AdoptAnnotation, e.g. created by another
+// network operator.
+func ForeignManager(obj metav1.Object, owner types.UID) string {
+	if ref := metav1.GetControllerOf(obj); ref != nil {
+		if ref.UID == owner {
+			return ""
+		}
+		return ref.Kind + " " + ref.Name
+	}
+	annotations := obj.GetAnnotations()
+	if annotations[ManagedByAnnotation] == ManagedByOperator || annotations[AdoptAnnotation] == "true" {
+		return ""
+	}
+	managers := []string{}
+	seen := map[string]bool{}
+	for _, f := range obj.GetManagedFields() {
+		if f.Manager != "" && !seen[f.Manager] {
+			seen[f.Manager] = true
+			managers = append(managers, f.Manager)
+		}
+	}
+	if len(managers) == 0 {
+		return "an unknown manager"
+	}
+	return strings.Join(managers, ", ")
+}
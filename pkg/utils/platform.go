@@ -0,0 +1,152 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// PlatformRefreshInterval is the interval between two detections of the
+// platform by DynamicPlatform.
+const PlatformRefreshInterval = time.Minute
+
+// Platform describes the OpenShift APIs of the infra cluster the operator
+// relies on, so the DPU side can also be an upstream Kubernetes cluster.
+type Platform struct {
+	// MachineConfig is set when the machine-config-operator manages the
+	// OS of the DPU nodes
+	MachineConfig bool
+	// SecurityContextConstraints is set when the pods are admitted by
+	// SCCs, rather than by the Pod Security Admission only
+	SecurityContextConstraints bool
+	// ClusterOperator is set when ClusterOperators can be published
+	ClusterOperator bool
+}
+
+var (
+	OpenShiftPlatform  = Platform{MachineConfig: true, SecurityContextConstraints: true, ClusterOperator: true}
+	KubernetesPlatform = Platform{}
+)
+
+// GetPlatform returns the platform named by the --platform flag: openshift,
+// kubernetes, or auto to detect it from the API groups served by the
+// cluster.
+func GetPlatform(name string, config *rest.Config) (Platform, error) {
+	switch name {
+	case "openshift":
+		return OpenShiftPlatform, nil
+	case "kubernetes":
+		return KubernetesPlatform, nil
+	case "auto":
+		return DetectPlatform(config)
+	}
+	return Platform{}, fmt.Errorf("unknown platform %q, must be auto, openshift or kubernetes", name)
+}
+
+// DetectPlatform probes the API groups served by the cluster. A cluster may
+// serve only some of them, e.g. MicroShift has SCCs but no
+// machine-config-operator.
+func DetectPlatform(config *rest.Config) (Platform, error) {
+	dc, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return Platform{}, err
+	}
+	groups, err := dc.ServerGroups()
+	if err != nil {
+		return Platform{}, fmt.Errorf("failed to discover the API groups: %v", err)
+	}
+	served := map[string]bool{}
+	for _, g := range groups.Groups {
+		served[g.Name] = true
+	}
+	return Platform{
+		MachineConfig:              served["machineconfiguration.openshift.io"],
+		SecurityContextConstraints: served["security.openshift.io"],
+		ClusterOperator:            served["config.openshift.io"],
+	}, nil
+}
+
+// DynamicPlatform is the Platform of the infra cluster, detected again every
+// PlatformRefreshInterval when it is auto, so an API installed after the
+// operator started, e.g. the machine-config-operator, is used without a
+// restart. It runs as a Runnable of the manager.
+type DynamicPlatform struct {
+	config *rest.Config
+	detect bool
+
+	mu        sync.RWMutex
+	platform  Platform
+	listeners []func(Platform)
+}
+
+// NewDynamicPlatform returns the platform named by the --platform flag, see
+// GetPlatform. A forced platform never changes.
+func NewDynamicPlatform(name string, config *rest.Config) (*DynamicPlatform, error) {
+	platform, err := GetPlatform(name, config)
+	if err != nil {
+		return nil, err
+	}
+	return &DynamicPlatform{config: config, detect: name == "auto", platform: platform}, nil
+}
+
+// Get returns the platform last detected.
+func (p *DynamicPlatform) Get() Platform {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.platform
+}
+
+// OnChange registers f to be called with the new platform whenever a
+// detection changes it.
+func (p *DynamicPlatform) OnChange(f func(Platform)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.listeners = append(p.listeners, f)
+}
+
+// Start detects the platform every PlatformRefreshInterval until ctx is
+// done. A failed detection keeps the previous platform.
+func (p *DynamicPlatform) Start(ctx context.Context) error {
+	if !p.detect {
+		return nil
+	}
+	logger := log.Log.WithName("platform")
+	ticker := time.NewTicker(PlatformRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		platform, err := DetectPlatform(p.config)
+		if err != nil {
+			logger.Error(err, "failed to detect the platform")
+			continue
+		}
+		p.mu.Lock()
+		changed := platform != p.platform
+		p.platform = platform
+		listeners := p.listeners
+		p.mu.Unlock()
+		if !changed {
+			continue
+		}
+		logger.Info("platform changed", "machineConfig", platform.MachineConfig,
+			"securityContextConstraints", platform.SecurityContextConstraints, "clusterOperator", platform.ClusterOperator)
+		for _, f := range listeners {
+			f(platform)
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable: every
+// replica keeps its platform up to date.
+func (p *DynamicPlatform) NeedLeaderElection() bool {
+	return false
+}
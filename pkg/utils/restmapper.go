@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// RESTMapperRefreshInterval is the age after which the mappings of the
+// RESTMapper returned by NewRESTMapper are discovered again.
+const RESTMapperRefreshInterval = 10 * time.Minute
+
+// refreshingRESTMapper is a dynamic RESTMapper, which discovers the API
+// resources again on a kind it doesn't know, e.g. of a CRD installed after
+// the operator started, replaced once older than RESTMapperRefreshInterval,
+// so the mappings of a removed or updated CRD don't linger.
+type refreshingRESTMapper struct {
+	config *rest.Config
+
+	mu     sync.RWMutex
+	mapper meta.RESTMapper
+	loaded time.Time
+}
+
+// NewRESTMapper returns the RESTMapper of the manager, see
+// refreshingRESTMapper.
+func NewRESTMapper(config *rest.Config) (meta.RESTMapper, error) {
+	mapper, err := apiutil.NewDynamicRESTMapper(config)
+	if err != nil {
+		return nil, err
+	}
+	return &refreshingRESTMapper{config: config, mapper: mapper, loaded: time.Now()}, nil
+}
+
+// current returns the dynamic RESTMapper, after replacing it when it is too
+// old. A failed discovery keeps the previous one until the next call.
+func (m *refreshingRESTMapper) current() meta.RESTMapper {
+	m.mu.RLock()
+	mapper, loaded := m.mapper, m.loaded
+	m.mu.RUnlock()
+	if time.Since(loaded) < RESTMapperRefreshInterval {
+		return mapper
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.loaded != loaded {
+		return m.mapper
+	}
+	if fresh, err := apiutil.NewDynamicRESTMapper(m.config); err == nil {
+		m.mapper = fresh
+	}
+	m.loaded = time.Now()
+	return m.mapper
+}
+
+func (m *refreshingRESTMapper) KindFor(resource schema.GroupVersionResource) (schema.GroupVersionKind, error) {
+	return m.current().KindFor(resource)
+}
+
+func (m *refreshingRESTMapper) KindsFor(resource schema.GroupVersionResource) ([]schema.GroupVersionKind, error) {
+	return m.current().KindsFor(resource)
+}
+
+func (m *refreshingRESTMapper) ResourceFor(input schema.GroupVersionResource) (schema.GroupVersionResource, error) {
+	return m.current().ResourceFor(input)
+}
+
+func (m *refreshingRESTMapper) ResourcesFor(input schema.GroupVersionResource) ([]schema.GroupVersionResource, error) {
+	return m.current().ResourcesFor(input)
+}
+
+func (m *refreshingRESTMapper) RESTMapping(gk schema.GroupKind, versions ...string) (*meta.RESTMapping, error) {
+	return m.current().RESTMapping(gk, versions...)
+}
+
+func (m *refreshingRESTMapper) RESTMappings(gk schema.GroupKind, versions ...string) ([]*meta.RESTMapping, error) {
+	return m.current().RESTMappings(gk, versions...)
+}
+
+func (m *refreshingRESTMapper) ResourceSingularizer(resource string) (string, error) {
+	return m.current().ResourceSingularizer(resource)
+}
@@ -4,11 +4,8 @@ import (
 	"fmt"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
-	"k8s.io/client-go/rest"
 )
 
-var TenantRestConfig *rest.Config
-
 type Config struct {
 	TenantHostname string `mapstructure:"TENANT_K8S_NODE"`
 }
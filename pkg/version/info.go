@@ -0,0 +1,107 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// Module is a Go module linked into the operator binary.
+type Module struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	Sum     string `json:"sum,omitempty"`
+	// Replace is the module replacing this one, e.g. a fork.
+	Replace *Module `json:"replace,omitempty"`
+}
+
+// Info is the build metadata of the operator binary: its version, the
+// revision it was built from, the Go toolchain and the modules linked in,
+// so fleet tooling can audit which builds are deployed where.
+type Info struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"gitCommit,omitempty"`
+	// GitTreeDirty is set when the binary was built from uncommitted
+	// changes.
+	GitTreeDirty bool     `json:"gitTreeDirty,omitempty"`
+	CommitTime   string   `json:"commitTime,omitempty"`
+	GoVersion    string   `json:"goVersion"`
+	Platform     string   `json:"platform"`
+	Modules      []Module `json:"modules,omitempty"`
+}
+
+var (
+	infoOnce sync.Once
+	info     Info
+)
+
+// GetInfo returns the build metadata embedded in the binary by the Go
+// toolchain. The revision is only known for the binaries built from a git
+// checkout.
+func GetInfo() Info {
+	infoOnce.Do(func() {
+		info = readInfo()
+	})
+	return info
+}
+
+func readInfo() Info {
+	info := Info{
+		Version:   Get(),
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, s := range build.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.GitCommit = s.Value
+		case "vcs.modified":
+			info.GitTreeDirty = s.Value == "true"
+		case "vcs.time":
+			info.CommitTime = s.Value
+		}
+	}
+	for _, dep := range build.Deps {
+		info.Modules = append(info.Modules, module(dep))
+	}
+	return info
+}
+
+func module(m *debug.Module) Module {
+	mod := Module{Path: m.Path, Version: m.Version, Sum: m.Sum}
+	if m.Replace != nil {
+		replace := module(m.Replace)
+		mod.Replace = &replace
+	}
+	return mod
+}
+
+// Handler serves the build metadata as JSON.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(GetInfo())
+	})
+}